@@ -18,6 +18,7 @@ import (
 
 	"github.com/joho/godotenv"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/config_access"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cmd"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
@@ -72,6 +73,13 @@ func main() {
 	var password string
 	var tuiMode bool
 	var standalone bool
+	var rotateAuthKey bool
+	var generateAuthKey bool
+	var migrateStore string
+	var dryRun bool
+	var backupPath string
+	var restorePath string
+	var validateConfig string
 
 	// Define command-line flags for different operation modes.
 	flag.BoolVar(&login, "login", false, "Login Google Account")
@@ -90,6 +98,13 @@ func main() {
 	flag.StringVar(&password, "password", "", "")
 	flag.BoolVar(&tuiMode, "tui", false, "Start with terminal management UI")
 	flag.BoolVar(&standalone, "standalone", false, "In TUI mode, start an embedded local server")
+	flag.BoolVar(&rotateAuthKey, "rotate-auth-key", false, "Re-encrypt all auth files with the primary auth encryption key")
+	flag.BoolVar(&generateAuthKey, "generate-auth-key", false, "Print a new random auth encryption key and exit")
+	flag.StringVar(&migrateStore, "migrate-store", "", "Copy config and auth records from the active store to a destination (file://, postgres://, s3://, git+https://)")
+	flag.BoolVar(&dryRun, "dry-run", false, "With -migrate-store, list what would be copied without writing")
+	flag.StringVar(&backupPath, "backup", "", "Write an encrypted backup of config and auth records to this file")
+	flag.StringVar(&restorePath, "restore", "", "Restore config and auth records from an encrypted backup file")
+	flag.StringVar(&validateConfig, "validate-config", "", "Check a config file for errors and warnings without applying it, then exit")
 
 	flag.CommandLine.Usage = func() {
 		out := flag.CommandLine.Output()
@@ -121,6 +136,23 @@ func main() {
 	// Parse the command-line flags.
 	flag.Parse()
 
+	if generateAuthKey {
+		key, errKey := authcrypt.GenerateKey()
+		if errKey != nil {
+			log.Errorf("failed to generate auth encryption key: %v", errKey)
+			return
+		}
+		fmt.Println(key)
+		return
+	}
+
+	if validateConfig != "" {
+		if !cmd.DoValidateConfig(validateConfig, configPath) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Core application variables.
 	var err error
 	var cfg *config.Config
@@ -144,6 +176,7 @@ func main() {
 		objectStoreSecret    string
 		objectStoreBucket    string
 		objectStoreLocalPath string
+		objectStoreSyncEvery time.Duration
 		objectStoreInst      *store.ObjectTokenStore
 	)
 
@@ -220,6 +253,13 @@ func main() {
 	if value, ok := lookupEnv("OBJECTSTORE_LOCAL_PATH", "objectstore_local_path"); ok {
 		objectStoreLocalPath = value
 	}
+	if value, ok := lookupEnv("OBJECTSTORE_SYNC_INTERVAL", "objectstore_sync_interval"); ok {
+		if parsed, errParse := time.ParseDuration(value); errParse == nil {
+			objectStoreSyncEvery = parsed
+		} else {
+			log.Warnf("invalid OBJECTSTORE_SYNC_INTERVAL %q: %v", value, errParse)
+		}
+	}
 
 	// Check for cloud deploy mode only on first execution
 	// Read env var name in uppercase: DEPLOY
@@ -298,13 +338,14 @@ func main() {
 		}
 		resolvedEndpoint = strings.TrimRight(resolvedEndpoint, "/")
 		objCfg := store.ObjectStoreConfig{
-			Endpoint:  resolvedEndpoint,
-			Bucket:    objectStoreBucket,
-			AccessKey: objectStoreAccess,
-			SecretKey: objectStoreSecret,
-			LocalRoot: objectStoreRoot,
-			UseSSL:    useSSL,
-			PathStyle: true,
+			Endpoint:     resolvedEndpoint,
+			Bucket:       objectStoreBucket,
+			AccessKey:    objectStoreAccess,
+			SecretKey:    objectStoreSecret,
+			LocalRoot:    objectStoreRoot,
+			UseSSL:       useSSL,
+			PathStyle:    true,
+			SyncInterval: objectStoreSyncEvery,
 		}
 		objectStoreInst, err = store.NewObjectTokenStore(objCfg)
 		if err != nil {
@@ -431,6 +472,12 @@ func main() {
 	} else {
 		cfg.AuthDir = resolvedAuthDir
 	}
+	keyring, errKeyring := authcrypt.LoadKeyring(cfg.AuthEncryption)
+	if errKeyring != nil {
+		log.Errorf("failed to load auth encryption key: %v", errKeyring)
+		return
+	}
+	authcrypt.SetKeyring(keyring)
 	managementasset.SetCurrentConfig(cfg)
 
 	// Create login options to be used in authentication flows.
@@ -455,7 +502,15 @@ func main() {
 
 	// Handle different command modes based on the provided flags.
 
-	if vertexImport != "" {
+	if rotateAuthKey {
+		cmd.DoRotateAuthKey(cfg)
+	} else if migrateStore != "" {
+		cmd.DoMigrateStore(cfg, configFilePath, migrateStore, dryRun)
+	} else if backupPath != "" {
+		cmd.DoBackup(cfg, configFilePath, backupPath)
+	} else if restorePath != "" {
+		cmd.DoRestore(cfg, configFilePath, restorePath)
+	} else if vertexImport != "" {
 		// Handle Vertex service account import
 		cmd.DoVertexImport(cfg, vertexImport)
 	} else if login {
@@ -485,6 +540,12 @@ func main() {
 			cmd.WaitForCloudDeploy()
 			return
 		}
+		// Mirror changes made by other replicas into the local workspace for the file watcher.
+		if pgStoreInst != nil {
+			pgStoreInst.StartSync(context.Background())
+		} else if objectStoreInst != nil {
+			objectStoreInst.StartSync(context.Background())
+		}
 		if tuiMode {
 			if standalone {
 				// Standalone mode: start an embedded local server and connect TUI client to it.
@@ -27,6 +27,7 @@ import (
 	iflowauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/iflow"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/kimi"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/qwen"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
@@ -35,6 +36,7 @@ import (
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
+	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -340,7 +342,7 @@ func (h *Handler) listAuthFilesFromDisk(c *gin.Context) {
 
 			// Read file to get type field
 			full := filepath.Join(h.cfg.AuthDir, name)
-			if data, errRead := os.ReadFile(full); errRead == nil {
+			if data, errRead := authcrypt.ReadFile(full); errRead == nil {
 				typeValue := gjson.GetBytes(data, "type").String()
 				emailValue := gjson.GetBytes(data, "email").String()
 				fileData["type"] = typeValue
@@ -425,6 +427,11 @@ func (h *Handler) buildAuthFileEntry(auth *coreauth.Auth) gin.H {
 	if claims := extractCodexIDTokenClaims(auth); claims != nil {
 		entry["id_token"] = claims
 	}
+	if h.authManager != nil {
+		if probe, ok := h.authManager.HealthProbe(auth.ID); ok {
+			entry["health_check"] = probe
+		}
+	}
 	return entry
 }
 
@@ -502,7 +509,9 @@ func isRuntimeOnlyAuth(auth *coreauth.Auth) bool {
 	return strings.EqualFold(strings.TrimSpace(auth.Attributes["runtime_only"]), "true")
 }
 
-// Download single auth file by name
+// Download single auth file by name. The stored (possibly encrypted) content is returned
+// unless the caller asks for scope=decrypted, auth-encryption.allow-decrypted-export is set and
+// the request carries auth-encryption.export-key in X-Export-Key. Every decrypted export is logged.
 func (h *Handler) DownloadAuthFile(c *gin.Context) {
 	name := c.Query("name")
 	if name == "" || strings.Contains(name, string(os.PathSeparator)) {
@@ -513,6 +522,16 @@ func (h *Handler) DownloadAuthFile(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "name must end with .json"})
 		return
 	}
+	decrypted := strings.EqualFold(strings.TrimSpace(c.Query("scope")), "decrypted")
+	if decrypted && !h.cfg.AuthEncryption.AllowDecryptedExport {
+		c.JSON(http.StatusForbidden, gin.H{"error": "decrypted export is not allowed; enable auth-encryption.allow-decrypted-export"})
+		return
+	}
+	if decrypted && !h.validExportKey(c.GetHeader("X-Export-Key")) {
+		log.Warnf("management: rejected decrypted export of auth file %s from %s: invalid export key", name, c.ClientIP())
+		c.JSON(http.StatusForbidden, gin.H{"error": "decrypted export requires a valid X-Export-Key"})
+		return
+	}
 	full := filepath.Join(h.cfg.AuthDir, name)
 	data, err := os.ReadFile(full)
 	if err != nil {
@@ -523,10 +542,26 @@ func (h *Handler) DownloadAuthFile(c *gin.Context) {
 		}
 		return
 	}
+	if decrypted {
+		if data, err = authcrypt.Open(data); err != nil {
+			c.JSON(500, gin.H{"error": fmt.Sprintf("failed to decrypt file: %v", err)})
+			return
+		}
+		log.Warnf("management: decrypted export of auth file %s to %s", name, c.ClientIP())
+	}
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", name))
 	c.Data(200, "application/json", data)
 }
 
+// validExportKey reports whether provided matches the configured decrypted export key.
+func (h *Handler) validExportKey(provided string) bool {
+	hash := h.cfg.AuthEncryption.ExportKey
+	if hash == "" || provided == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(provided)) == nil
+}
+
 // Upload auth file: multipart or raw JSON with ?name=
 func (h *Handler) UploadAuthFile(c *gin.Context) {
 	if h.authManager == nil {
@@ -546,13 +581,23 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 				dst = abs
 			}
 		}
-		if errSave := c.SaveUploadedFile(file, dst); errSave != nil {
-			c.JSON(500, gin.H{"error": fmt.Sprintf("failed to save file: %v", errSave)})
+		src, errOpen := file.Open()
+		if errOpen != nil {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("failed to open uploaded file: %v", errOpen)})
 			return
 		}
-		data, errRead := os.ReadFile(dst)
+		data, errRead := io.ReadAll(src)
+		_ = src.Close()
 		if errRead != nil {
-			c.JSON(500, gin.H{"error": fmt.Sprintf("failed to read saved file: %v", errRead)})
+			c.JSON(400, gin.H{"error": fmt.Sprintf("failed to read uploaded file: %v", errRead)})
+			return
+		}
+		if data, errRead = authcrypt.Open(data); errRead != nil {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("failed to decrypt file: %v", errRead)})
+			return
+		}
+		if errWrite := authcrypt.WriteFile(dst, data, 0o600); errWrite != nil {
+			c.JSON(500, gin.H{"error": fmt.Sprintf("failed to write file: %v", errWrite)})
 			return
 		}
 		if errReg := h.registerAuthFromFile(ctx, dst, data); errReg != nil {
@@ -582,7 +627,11 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 			dst = abs
 		}
 	}
-	if errWrite := os.WriteFile(dst, data, 0o600); errWrite != nil {
+	if data, err = authcrypt.Open(data); err != nil {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("failed to decrypt body: %v", err)})
+		return
+	}
+	if errWrite := authcrypt.WriteFile(dst, data, 0o600); errWrite != nil {
 		c.JSON(500, gin.H{"error": fmt.Sprintf("failed to write file: %v", errWrite)})
 		return
 	}
@@ -692,6 +741,10 @@ func (h *Handler) registerAuthFromFile(ctx context.Context, path string, data []
 			return fmt.Errorf("failed to read auth file: %w", err)
 		}
 	}
+	data, errOpen := authcrypt.Open(data)
+	if errOpen != nil {
+		return fmt.Errorf("failed to decrypt auth file: %w", errOpen)
+	}
 	metadata := make(map[string]any)
 	if err := json.Unmarshal(data, &metadata); err != nil {
 		return fmt.Errorf("invalid auth file: %w", err)
@@ -0,0 +1,56 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestDownloadAuthFileDecryptedRequiresExportKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"type":"codex"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("export-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{AuthDir: dir}
+	cfg.AuthEncryption.AllowDecryptedExport = true
+	cfg.AuthEncryption.ExportKey = string(hash)
+	h := &Handler{cfg: cfg}
+	router := gin.New()
+	router.GET("/auth-files/download", h.DownloadAuthFile)
+
+	download := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/auth-files/download?name=a.json&scope=decrypted", nil)
+		if key != "" {
+			req.Header.Set("X-Export-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := download(""); rec.Code != http.StatusForbidden {
+		t.Fatalf("without export key: %d, want 403", rec.Code)
+	}
+	if rec := download("wrong"); rec.Code != http.StatusForbidden {
+		t.Fatalf("wrong export key: %d, want 403", rec.Code)
+	}
+	if rec := download("export-secret"); rec.Code != http.StatusOK || rec.Body.String() != `{"type":"codex"}` {
+		t.Fatalf("valid export key: %d %s", rec.Code, rec.Body.String())
+	}
+
+	cfg.AuthEncryption.ExportKey = ""
+	if rec := download("export-secret"); rec.Code != http.StatusForbidden {
+		t.Fatalf("unset export key: %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,22 @@
+package management
+
+import (
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// GetCircuitBreakers returns the breaker state of openai-compatibility upstreams.
+// Upstreams that are not listed are closed.
+func (h *Handler) GetCircuitBreakers(c *gin.Context) {
+	breakers := []coreauth.CircuitBreakerStatus{}
+	if h != nil && h.authManager != nil {
+		if snapshot := h.authManager.CircuitBreakers(); snapshot != nil {
+			breakers = snapshot
+		}
+	}
+	enabled := false
+	if h != nil && h.cfg != nil {
+		enabled = h.cfg.CircuitBreaker.Enable
+	}
+	c.JSON(200, gin.H{"enabled": enabled, "circuit-breakers": breakers})
+}
@@ -0,0 +1,275 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/confighistory"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
+	log "github.com/sirupsen/logrus"
+)
+
+// configActorHeader lets management clients name the person or tool behind a config change.
+const configActorHeader = "X-Management-Actor"
+
+// configHistory returns the history store shared through the active token store, falling back
+// to a config-history directory next to the config file.
+func (h *Handler) configHistory() confighistory.Store {
+	if store, ok := h.tokenStore.(confighistory.Store); ok {
+		return store
+	}
+	return confighistory.NewDirStore(filepath.Join(filepath.Dir(h.configFilePath), "config-history"))
+}
+
+// configActor describes who issued the request for the history log.
+func configActor(c *gin.Context) string {
+	actor := strings.TrimSpace(c.GetHeader(configActorHeader))
+	if actor == "" {
+		return c.ClientIP()
+	}
+	return actor + "@" + c.ClientIP()
+}
+
+// readConfigSnapshot returns the config file contents, or nil when it cannot be read.
+func (h *Handler) readConfigSnapshot() []byte {
+	data, err := os.ReadFile(h.configFilePath)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// recordConfigVersion queues the config file as a new history version. before holds the file
+// contents prior to the write; when they differ from the latest version (first write, or an
+// edit made outside the management API) they are recorded first so the change can be undone.
+// Must be called with h.mu held so versions are queued in write order; the history store is
+// written in the background. History failures never fail the config write.
+func (h *Handler) recordConfigVersion(c *gin.Context, before []byte, reason string) {
+	data := h.readConfigSnapshot()
+	if len(data) == 0 {
+		return
+	}
+	h.history.push(configHistoryEntry{
+		store:  h.configHistory(),
+		before: before,
+		after:  data,
+		actor:  configActor(c),
+		reason: reason,
+	})
+}
+
+// configHistoryEntry is a config write captured under h.mu, waiting to be recorded.
+type configHistoryEntry struct {
+	store  confighistory.Store
+	before []byte
+	after  []byte
+	actor  string
+	reason string
+}
+
+func (e configHistoryEntry) record(ctx context.Context) {
+	if len(e.before) > 0 {
+		versions, err := confighistory.List(ctx, e.store)
+		if err != nil {
+			log.WithError(err).Warn("config history: list versions failed")
+			return
+		}
+		priorReason := "external change"
+		if len(versions) == 0 {
+			priorReason = "baseline"
+		}
+		if _, _, err = confighistory.Record(ctx, e.store, e.before, "", priorReason, confighistory.DefaultRetention); err != nil {
+			log.WithError(err).Warn("config history: record previous config failed")
+		}
+	}
+	if _, _, err := confighistory.Record(ctx, e.store, e.after, e.actor, e.reason, confighistory.DefaultRetention); err != nil {
+		log.WithError(err).Warn("config history: record config version failed")
+	}
+}
+
+// configHistoryQueue records entries on a background goroutine in the order they were queued,
+// so listing and pruning a slow history store never holds up config writes.
+type configHistoryQueue struct {
+	mu      sync.Mutex
+	idle    sync.Cond
+	pending []configHistoryEntry
+	running bool
+}
+
+func (q *configHistoryQueue) push(entry configHistoryEntry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, entry)
+	if !q.running {
+		q.running = true
+		go q.drain()
+	}
+}
+
+func (q *configHistoryQueue) drain() {
+	q.mu.Lock()
+	for len(q.pending) > 0 {
+		entry := q.pending[0]
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+		entry.record(context.Background())
+		q.mu.Lock()
+	}
+	q.running = false
+	if q.idle.L != nil {
+		q.idle.Broadcast()
+	}
+	q.mu.Unlock()
+}
+
+// wait blocks until every queued entry has been recorded, so readers see their own writes.
+func (q *configHistoryQueue) wait() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.idle.L == nil {
+		q.idle.L = &q.mu
+	}
+	for q.running {
+		q.idle.Wait()
+	}
+}
+
+// FlushConfigHistory blocks until every queued config version has been written to the history
+// store. Call it on shutdown so the last writes are not lost.
+func (h *Handler) FlushConfigHistory() {
+	h.history.wait()
+}
+
+// loadConfigBytes parses and validates config data the same way the server loads config.yaml.
+func (h *Handler) loadConfigBytes(data []byte) (*config.Config, error) {
+	tmpFile, err := os.CreateTemp(filepath.Dir(h.configFilePath), "config-validate-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	tempFile := tmpFile.Name()
+	defer func() {
+		_ = os.Remove(tempFile)
+	}()
+	if _, err = tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return nil, err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return nil, err
+	}
+	return config.LoadConfigOptional(tempFile, false)
+}
+
+// GetConfigHistory lists the stored config versions, newest first.
+func (h *Handler) GetConfigHistory(c *gin.Context) {
+	h.history.wait()
+	versions, err := confighistory.List(c.Request.Context(), h.configHistory())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "history_unavailable", "message": err.Error()})
+		return
+	}
+	if versions == nil {
+		versions = []confighistory.Version{}
+	}
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// GetConfigVersion returns one stored config version including its YAML content.
+func (h *Handler) GetConfigVersion(c *gin.Context) {
+	version, data, ok := h.loadConfigVersion(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"version": version, "content": string(data)})
+}
+
+// GetConfigVersionDiff lists the changes going from the "against" version (default: the current
+// config file) to the requested version, i.e. what rolling back to it would change.
+func (h *Handler) GetConfigVersionDiff(c *gin.Context) {
+	_, target, ok := h.loadConfigVersion(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	against := strings.TrimSpace(c.Query("against"))
+	var base []byte
+	if against == "" || against == "current" {
+		against = "current"
+		base = h.readConfigSnapshot()
+	} else if _, base, ok = h.loadConfigVersion(c, against); !ok {
+		return
+	}
+	baseCfg, err := h.loadConfigBytes(base)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid_config", "message": err.Error()})
+		return
+	}
+	targetCfg, err := h.loadConfigBytes(target)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid_config", "message": err.Error()})
+		return
+	}
+	changes := diff.BuildConfigChangeDetails(baseCfg, targetCfg)
+	if changes == nil {
+		changes = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{"from": against, "to": c.Param("id"), "changes": changes})
+}
+
+// PostConfigRollback replaces config.yaml with a stored version. The version is validated
+// before anything is written, and the rollback itself is recorded as a new version.
+func (h *Handler) PostConfigRollback(c *gin.Context) {
+	id := c.Param("id")
+	_, data, ok := h.loadConfigVersion(c, id)
+	if !ok {
+		return
+	}
+	if _, err := h.loadConfigBytes(data); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid_config", "message": err.Error()})
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	before := h.readConfigSnapshot()
+	if bytes.Equal(before, data) {
+		c.JSON(http.StatusOK, gin.H{"ok": true, "changed": []string{}})
+		return
+	}
+	if err := WriteConfig(h.configFilePath, data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "write_failed", "message": err.Error()})
+		return
+	}
+	newCfg, err := config.LoadConfig(h.configFilePath)
+	if err != nil {
+		// Put the previous file back so a failed reload does not leave a half-applied rollback.
+		if len(before) > 0 {
+			_ = WriteConfig(h.configFilePath, before)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "reload_failed", "message": err.Error()})
+		return
+	}
+	h.cfg = newCfg
+	h.recordConfigVersion(c, before, "rollback to "+id)
+	c.JSON(http.StatusOK, gin.H{"ok": true, "changed": []string{"config"}})
+}
+
+func (h *Handler) loadConfigVersion(c *gin.Context, id string) (confighistory.Version, []byte, bool) {
+	h.history.wait()
+	version, data, err := h.configHistory().LoadConfigVersion(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, confighistory.ErrVersionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "config version " + id + " not found"})
+			return confighistory.Version{}, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "history_unavailable", "message": err.Error()})
+		return confighistory.Version{}, nil, false
+	}
+	return version, data, true
+}
@@ -0,0 +1,147 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/confighistory"
+)
+
+func TestConfigHistoryRecordsDiffsAndRollsBack(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("port: 8317\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	h := &Handler{cfg: &config.Config{}, configFilePath: configPath, tokenStore: &memoryAuthStore{}}
+
+	router := gin.New()
+	router.PUT("/config.yaml", h.PutConfigYAML)
+	router.GET("/config/history", h.GetConfigHistory)
+	router.GET("/config/history/:id/diff", h.GetConfigVersionDiff)
+	router.POST("/config/history/:id/rollback", h.PostConfigRollback)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(configActorHeader, "alice")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodPut, "/config.yaml", "port: 9000\n"); rec.Code != http.StatusOK {
+		t.Fatalf("PutConfigYAML: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec := do(http.MethodGet, "/config/history", "")
+	var listed struct {
+		Versions []confighistory.Version `json:"versions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if len(listed.Versions) != 2 {
+		t.Fatalf("expected baseline and new version, got %+v", listed.Versions)
+	}
+	latest, baseline := listed.Versions[0], listed.Versions[1]
+	if baseline.Reason != "baseline" || !strings.HasPrefix(latest.Actor, "alice@") {
+		t.Fatalf("unexpected versions: %+v", listed.Versions)
+	}
+
+	rec = do(http.MethodGet, "/config/history/"+baseline.ID+"/diff", "")
+	var diffed struct {
+		Changes []string `json:"changes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &diffed); err != nil || len(diffed.Changes) != 1 || diffed.Changes[0] != "port: 9000 -> 8317" {
+		t.Fatalf("diff: %d %s", rec.Code, rec.Body.String())
+	}
+
+	if rec = do(http.MethodPost, "/config/history/"+baseline.ID+"/rollback", ""); rec.Code != http.StatusOK {
+		t.Fatalf("rollback: %d %s", rec.Code, rec.Body.String())
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil || string(data) != "port: 8317\n" {
+		t.Fatalf("config after rollback: %q %v", data, err)
+	}
+	if h.cfg.Port != 8317 {
+		t.Fatalf("handler config not reloaded, port %d", h.cfg.Port)
+	}
+
+	if rec = do(http.MethodPost, "/config/history/missing/rollback", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown version, got %d", rec.Code)
+	}
+}
+
+// blockingHistoryStore holds every history write until release is closed.
+type blockingHistoryStore struct {
+	*memoryAuthStore
+	confighistory.Store
+	release chan struct{}
+}
+
+func (s *blockingHistoryStore) SaveConfigVersion(ctx context.Context, version confighistory.Version, data []byte) error {
+	<-s.release
+	return s.Store.SaveConfigVersion(ctx, version, data)
+}
+
+func TestConfigWritesDoNotWaitForHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("port: 8317\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	store := &blockingHistoryStore{
+		memoryAuthStore: &memoryAuthStore{},
+		Store:           confighistory.NewDirStore(filepath.Join(dir, "history")),
+		release:         make(chan struct{}),
+	}
+	h := &Handler{cfg: &config.Config{}, configFilePath: configPath, tokenStore: store}
+	router := gin.New()
+	router.PUT("/config.yaml", h.PutConfigYAML)
+	router.GET("/config/history", h.GetConfigHistory)
+
+	for _, body := range []string{"port: 9000\n", "port: 9001\n"} {
+		done := make(chan int, 1)
+		go func() {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/config.yaml", strings.NewReader(body)))
+			done <- rec.Code
+		}()
+		select {
+		case code := <-done:
+			if code != http.StatusOK {
+				t.Fatalf("PutConfigYAML(%q) = %d", body, code)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("config write blocked on the history store")
+		}
+	}
+
+	close(store.release)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config/history", nil))
+	var listed struct {
+		Versions []confighistory.Version `json:"versions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	reasons := make([]string, 0, len(listed.Versions))
+	for _, version := range listed.Versions {
+		reasons = append(reasons, version.Reason)
+	}
+	if got := strings.Join(reasons, ","); got != "replace config.yaml,replace config.yaml,baseline" {
+		t.Fatalf("history reasons = %s", got)
+	}
+}
@@ -47,6 +47,8 @@ type Handler struct {
 	allowRemoteOverride bool
 	envSecret           string
 	logDir              string
+	history             configHistoryQueue
+	requestLogs         requestLogIndex
 }
 
 // NewHandler creates a new management handler instance.
@@ -270,11 +272,13 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 func (h *Handler) persist(c *gin.Context) bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	before := h.readConfigSnapshot()
 	// Preserve comments when writing
 	if err := config.SaveConfigPreserveComments(h.configFilePath, h.cfg); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save config: %v", err)})
 		return false
 	}
+	h.recordConfigVersion(c, before, c.Request.Method+" "+c.FullPath())
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	return true
 }
@@ -0,0 +1,180 @@
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logstream"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	logStreamKeepAlive = 15 * time.Second
+	logStreamMaxTail   = 500
+)
+
+var logStreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// StreamLogs pushes application log lines and per-request summary events as they happen.
+// Clients receive Server-Sent Events by default, or JSON text messages when the request is a
+// WebSocket upgrade. Query parameters:
+//
+//	level      minimum level: debug, info, warn, error
+//	types      comma separated event types: log, request
+//	provider   only events for this provider
+//	client-key only request events made with this client API key
+//	tail       number of recent events to replay first (max 500)
+func (h *Handler) StreamLogs(c *gin.Context) {
+	filter, tail, err := parseLogStreamQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub := logstream.Default().Subscribe(filter, 512, tail)
+	defer sub.Close()
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		h.streamLogsWebsocket(c, sub)
+		return
+	}
+	h.streamLogsSSE(c, sub)
+}
+
+func parseLogStreamQuery(c *gin.Context) (logstream.Filter, int, error) {
+	filter := logstream.Filter{
+		Provider:  strings.TrimSpace(c.Query("provider")),
+		ClientKey: strings.TrimSpace(c.Query("client-key")),
+	}
+	if level := strings.TrimSpace(c.Query("level")); level != "" {
+		if _, err := log.ParseLevel(level); err != nil {
+			return filter, 0, fmt.Errorf("invalid level %q", level)
+		}
+		filter.Level = level
+	}
+	if types := strings.TrimSpace(c.Query("types")); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			t = strings.TrimSpace(t)
+			if t != logstream.TypeLog && t != logstream.TypeRequest {
+				return filter, 0, fmt.Errorf("invalid event type %q", t)
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	tail := 0
+	if raw := strings.TrimSpace(c.Query("tail")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return filter, 0, fmt.Errorf("invalid tail %q", raw)
+		}
+		tail = min(n, logStreamMaxTail)
+	}
+	return filter, tail, nil
+}
+
+func (h *Handler) streamLogsSSE(c *gin.Context, sub *logstream.Subscription) {
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "streaming unsupported"})
+		return
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(logStreamKeepAlive)
+	defer keepAlive.Stop()
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, open := <-sub.Events():
+			if !open {
+				return
+			}
+			if err := writeLogStreamSSE(c, sub, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeLogStreamSSE(c *gin.Context, sub *logstream.Subscription, event logstream.Event) error {
+	if dropped := sub.Dropped(); dropped > 0 {
+		if _, err := fmt.Fprintf(c.Writer, "event: dropped\ndata: {\"count\":%d}\n\n", dropped); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil
+	}
+	_, err = fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
+
+func (h *Handler) streamLogsWebsocket(c *gin.Context, sub *logstream.Subscription) {
+	conn, err := logStreamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	// Drain client messages so close frames and pings are processed.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, errRead := conn.ReadMessage(); errRead != nil {
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(logStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-keepAlive.C:
+			if errPing := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); errPing != nil {
+				return
+			}
+		case event, open := <-sub.Events():
+			if !open {
+				return
+			}
+			if dropped := sub.Dropped(); dropped > 0 {
+				if errWrite := conn.WriteJSON(gin.H{"type": "dropped", "count": dropped}); errWrite != nil {
+					return
+				}
+			}
+			if errWrite := conn.WriteJSON(event); errWrite != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+)
+
+const (
+	defaultRequestLogListLimit = 100
+	maxRequestLogListLimit     = 1000
+)
+
+// requestLogEntry is one row of the request log listing.
+type requestLogEntry struct {
+	Name     string `json:"name"`
+	ID       string `json:"id"`
+	Size     int64  `json:"size"`
+	Modified int64  `json:"modified"`
+	Error    bool   `json:"error"`
+	logging.RequestLogSummary
+}
+
+// requestLogFilter selects request logs by status, model, path and time.
+type requestLogFilter struct {
+	status string // exact code, "2xx".."5xx" class, or "error" for >= 400
+	model  string
+	path   string
+	since  time.Time
+	until  time.Time
+}
+
+func (f requestLogFilter) matchesTime(modified time.Time) bool {
+	if !f.since.IsZero() && modified.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && modified.After(f.until) {
+		return false
+	}
+	return true
+}
+
+func (f requestLogFilter) matches(entry requestLogEntry) bool {
+	if f.model != "" && !strings.Contains(strings.ToLower(entry.Model), f.model) {
+		return false
+	}
+	if f.path != "" && !strings.Contains(strings.ToLower(entry.URL), f.path) {
+		return false
+	}
+	switch status := f.status; {
+	case status == "":
+	case status == "error":
+		return entry.Status >= 400 || entry.Status == 0 && entry.Error
+	case len(status) == 3 && strings.HasSuffix(status, "xx"):
+		return entry.Status/100 == int(status[0]-'0')
+	default:
+		return strconv.Itoa(entry.Status) == status
+	}
+	return true
+}
+
+// requestLogIndex caches the summary of every request log file so filtered listings only read
+// files that are new or changed since the previous listing.
+type requestLogIndex struct {
+	mu      sync.Mutex
+	entries map[string]indexedRequestLog
+}
+
+// indexedRequestLog is a cached summary, valid while the file keeps its size and mtime.
+type indexedRequestLog struct {
+	size     int64
+	modified time.Time
+	summary  logging.RequestLogSummary
+}
+
+// summary returns the summary of the log file name in dir, reading it only when the cached
+// entry is missing or stale.
+func (idx *requestLogIndex) summary(dir, name string, info os.FileInfo) (logging.RequestLogSummary, error) {
+	idx.mu.Lock()
+	cached, ok := idx.entries[name]
+	idx.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modified.Equal(info.ModTime()) {
+		return cached.summary, nil
+	}
+	summary, err := summarizeRequestLogFile(filepath.Join(dir, name))
+	if err != nil {
+		return summary, err
+	}
+	idx.mu.Lock()
+	if idx.entries == nil {
+		idx.entries = make(map[string]indexedRequestLog)
+	}
+	idx.entries[name] = indexedRequestLog{size: info.Size(), modified: info.ModTime(), summary: summary}
+	idx.mu.Unlock()
+	return summary, nil
+}
+
+// retain drops cached entries for files that no longer exist.
+func (idx *requestLogIndex) retain(names map[string]struct{}) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for name := range idx.entries {
+		if _, ok := names[name]; !ok {
+			delete(idx.entries, name)
+		}
+	}
+}
+
+// parseRequestLogTime accepts a unix timestamp, an RFC3339 time, or a duration meaning "that long ago".
+func parseRequestLogTime(raw string, now time.Time) (time.Time, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("expected unix seconds, RFC3339 time or duration")
+}
+
+// ListRequestLogs lists request and error logs written by the request logger, newest first.
+// Query parameters status, model, path, since, until and limit narrow the result; total counts
+// every matching log, not only the returned page.
+func (h *Handler) ListRequestLogs(c *gin.Context) {
+	if h == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "handler unavailable"})
+		return
+	}
+	if h.cfg == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "configuration unavailable"})
+		return
+	}
+
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid limit: %v", err)})
+		return
+	}
+	if limit == 0 {
+		limit = defaultRequestLogListLimit
+	}
+	if limit > maxRequestLogListLimit {
+		limit = maxRequestLogListLimit
+	}
+	now := time.Now()
+	filter := requestLogFilter{
+		status: strings.ToLower(strings.TrimSpace(c.Query("status"))),
+		model:  strings.ToLower(strings.TrimSpace(c.Query("model"))),
+		path:   strings.ToLower(strings.TrimSpace(c.Query("path"))),
+	}
+	if filter.since, err = parseRequestLogTime(c.Query("since"), now); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid since: %v", err)})
+		return
+	}
+	if filter.until, err = parseRequestLogTime(c.Query("until"), now); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid until: %v", err)})
+		return
+	}
+
+	dir := h.logDirectory()
+	if strings.TrimSpace(dir) == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "log directory not configured"})
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.JSON(http.StatusOK, gin.H{"files": []any{}})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to list request logs: %v", err)})
+		return
+	}
+
+	type candidate struct {
+		name string
+		meta logging.RequestLogName
+		info os.FileInfo
+	}
+	candidates := make([]candidate, 0, len(entries))
+	present := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		meta, ok := logging.ParseRequestLogName(entry.Name())
+		if !ok {
+			continue
+		}
+		present[entry.Name()] = struct{}{}
+		info, errInfo := entry.Info()
+		if errInfo != nil || !filter.matchesTime(info.ModTime()) {
+			continue
+		}
+		candidates = append(candidates, candidate{name: entry.Name(), meta: meta, info: info})
+	}
+	h.requestLogs.retain(present)
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].info.ModTime().After(candidates[j].info.ModTime())
+	})
+
+	files := make([]requestLogEntry, 0, limit)
+	total := 0
+	for _, cand := range candidates {
+		summary, errSummary := h.requestLogs.summary(dir, cand.name, cand.info)
+		if errSummary != nil {
+			continue
+		}
+		entry := requestLogEntry{
+			Name:              cand.name,
+			ID:                cand.meta.RequestID,
+			Size:              cand.info.Size(),
+			Modified:          cand.info.ModTime().Unix(),
+			Error:             cand.meta.Error,
+			RequestLogSummary: summary,
+		}
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = cand.meta.Timestamp
+		}
+		if !filter.matches(entry) {
+			continue
+		}
+		total++
+		if len(files) < limit {
+			files = append(files, entry)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"files": files, "total": total})
+}
+
+// GetRequestLogDetail returns a request log file parsed into its client, upstream and response sections.
+func (h *Handler) GetRequestLogDetail(c *gin.Context) {
+	if h == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "handler unavailable"})
+		return
+	}
+	if h.cfg == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "configuration unavailable"})
+		return
+	}
+
+	dir := h.logDirectory()
+	if strings.TrimSpace(dir) == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "log directory not configured"})
+		return
+	}
+
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" || strings.Contains(name, "/") || strings.Contains(name, "\\") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log file name"})
+		return
+	}
+	meta, ok := logging.ParseRequestLogName(name)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "log file not found"})
+		return
+	}
+
+	dirAbs, errAbs := filepath.Abs(dir)
+	if errAbs != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to resolve log directory: %v", errAbs)})
+		return
+	}
+	fullPath := filepath.Clean(filepath.Join(dirAbs, name))
+	if !strings.HasPrefix(fullPath, dirAbs+string(os.PathSeparator)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log file path"})
+		return
+	}
+
+	file, errOpen := os.Open(fullPath)
+	if errOpen != nil {
+		if os.IsNotExist(errOpen) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "log file not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to read log file: %v", errOpen)})
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	record, errParse := logging.ParseRequestLog(file)
+	if errParse != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to parse log file: %v", errParse)})
+		return
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = meta.Timestamp
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":  name,
+		"id":    meta.RequestID,
+		"error": meta.Error,
+		"log":   record,
+	})
+}
+
+func summarizeRequestLogFile(path string) (logging.RequestLogSummary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return logging.RequestLogSummary{}, err
+	}
+	defer func() { _ = file.Close() }()
+	return logging.SummarizeRequestLog(file)
+}
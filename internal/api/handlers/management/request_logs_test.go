@@ -0,0 +1,110 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func writeRequestLog(t *testing.T, dir, name, url, model string, status int) {
+	t.Helper()
+	content := "=== REQUEST INFO ===\nURL: " + url + "\nMethod: POST\n\n=== HEADERS ===\n\n=== REQUEST BODY ===\n" +
+		`{"model":"` + model + `"}` + "\n\n=== RESPONSE ===\nStatus: " + strconv.Itoa(status) + "\n\nok\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+}
+
+func TestListRequestLogsFiltersAndDetail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	writeRequestLog(t, dir, "v1-chat-completions-2025-01-02T030405-aaaa1111.log", "/v1/chat/completions", "gpt-5", 200)
+	writeRequestLog(t, dir, "error-v1-messages-2025-01-02T030406-bbbb2222.log", "/v1/messages", "claude-sonnet", 529)
+	if err := os.WriteFile(filepath.Join(dir, "main.log"), []byte("app log\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &Handler{cfg: &config.Config{}, logDir: dir}
+	router := gin.New()
+	router.GET("/request-logs", h.ListRequestLogs)
+	router.GET("/request-logs/:name", h.GetRequestLogDetail)
+
+	list := func(query string) ([]requestLogEntry, int) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/request-logs"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list %q: %d %s", query, rec.Code, rec.Body.String())
+		}
+		var body struct {
+			Files []requestLogEntry `json:"files"`
+			Total int               `json:"total"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode list: %v", err)
+		}
+		return body.Files, body.Total
+	}
+
+	if files, total := list(""); len(files) != 2 || total != 2 {
+		t.Fatalf("unfiltered list = %+v (total %d), want 2 request logs", files, total)
+	}
+	if files, total := list("?status=5xx"); len(files) != 1 || total != 1 || files[0].ID != "bbbb2222" || !files[0].Error {
+		t.Fatalf("status=5xx = %+v (total %d)", files, total)
+	}
+	if files, _ := list("?model=GPT&path=/chat"); len(files) != 1 || files[0].Status != 200 {
+		t.Fatalf("model/path filter = %+v", files)
+	}
+	if files, total := list("?since=2030-01-01T00:00:00Z"); len(files) != 0 || total != 0 {
+		t.Fatalf("since filter = %+v (total %d)", files, total)
+	}
+
+	// total counts every match while files is limited to one page.
+	writeRequestLog(t, dir, "error-v1-messages-2025-01-02T030407-cccc3333.log", "/v1/messages", "claude-sonnet", 503)
+	if files, total := list("?status=5xx&limit=1"); len(files) != 1 || total != 2 {
+		t.Fatalf("status=5xx&limit=1 = %+v (total %d), want 1 file of 2", files, total)
+	}
+
+	// The summary index follows files that are rewritten or removed.
+	writeRequestLog(t, dir, "error-v1-messages-2025-01-02T030407-cccc3333.log", "/v1/messages", "claude-sonnet-rewritten", 429)
+	if files, total := list("?status=5xx"); total != 1 || files[0].ID != "bbbb2222" {
+		t.Fatalf("status=5xx after rewrite = %+v (total %d)", files, total)
+	}
+	if err := os.Remove(filepath.Join(dir, "error-v1-messages-2025-01-02T030407-cccc3333.log")); err != nil {
+		t.Fatal(err)
+	}
+	if _, total := list(""); total != 2 || len(h.requestLogs.entries) != 2 {
+		t.Fatalf("after removal total = %d, index size = %d", total, len(h.requestLogs.entries))
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/request-logs/error-v1-messages-2025-01-02T030406-bbbb2222.log", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("detail: %d %s", rec.Code, rec.Body.String())
+	}
+	var detail struct {
+		Log struct {
+			Model        string `json:"model"`
+			RequestBody  string `json:"request_body"`
+			ResponseBody string `json:"response_body"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("decode detail: %v", err)
+	}
+	if detail.Log.Model != "claude-sonnet" || detail.Log.ResponseBody != "ok" {
+		t.Fatalf("detail = %+v", detail.Log)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/request-logs/main.log", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("main.log detail status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,11 @@
+package management
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+)
+
+// GetSignatureCache returns the thinking signature cache backend and its hit/miss counters.
+func (h *Handler) GetSignatureCache(c *gin.Context) {
+	c.JSON(200, gin.H{"signature-cache": cache.GetSignatureCacheStats()})
+}
@@ -0,0 +1,12 @@
+package management
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/httppool"
+)
+
+// GetTransportPools returns the upstream connection pools with their settings and
+// connection and request counters.
+func (h *Handler) GetTransportPools(c *gin.Context) {
+	c.JSON(200, gin.H{"transport-pools": httppool.Shared().Stats()})
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers/openai"
+)
+
+// Response headers describing the credential that served a playground request.
+const (
+	playgroundAuthHeader     = "X-Playground-Auth"
+	playgroundProviderHeader = "X-Playground-Provider"
+	playgroundAccountHeader  = "X-Playground-Account"
+)
+
+// playgroundChatCompletions serves OpenAI chat completions for management clients such as the
+// TUI playground. The optional auth_id query parameter pins the request to one credential; the
+// credential that served it is reported in the X-Playground-* response headers.
+func (s *Server) playgroundChatCompletions(h *openai.OpenAIAPIHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if authID := strings.TrimSpace(c.Query("auth_id")); authID != "" {
+			if s.handlers == nil || s.handlers.AuthManager == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+				return
+			}
+			if _, ok := s.handlers.AuthManager.GetByID(authID); !ok {
+				c.JSON(http.StatusNotFound, gin.H{"error": "auth not found"})
+				return
+			}
+			ctx = handlers.WithPinnedAuthID(ctx, authID)
+		}
+		ctx = handlers.WithSelectedAuthIDCallback(ctx, func(authID string) {
+			s.setPlaygroundAuthHeaders(c, authID)
+		})
+		c.Request = c.Request.WithContext(ctx)
+		h.ChatCompletions(c)
+	}
+}
+
+// setPlaygroundAuthHeaders records the selected credential. Retries call it again before the
+// response is written, so the headers always name the credential that answered.
+func (s *Server) setPlaygroundAuthHeaders(c *gin.Context, authID string) {
+	if c.Writer.Written() {
+		return
+	}
+	header := c.Writer.Header()
+	header.Set(playgroundAuthHeader, authID)
+	header.Del(playgroundProviderHeader)
+	header.Del(playgroundAccountHeader)
+	if s.handlers == nil || s.handlers.AuthManager == nil {
+		return
+	}
+	auth, ok := s.handlers.AuthManager.GetByID(authID)
+	if !ok || auth == nil {
+		return
+	}
+	if name := strings.TrimSpace(auth.FileName); name != "" {
+		header.Set(playgroundAuthHeader, name)
+	}
+	header.Set(playgroundProviderHeader, auth.Provider)
+	if _, account := auth.AccountInfo(); account != "" {
+		header.Set(playgroundAccountHeader, account)
+	}
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers/openai"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestPlaygroundChatCompletionsRejectsUnknownAuth(t *testing.T) {
+	server := newTestServer(t)
+	engine := gin.New()
+	engine.POST("/playground", server.playgroundChatCompletions(openai.NewOpenAIAPIHandler(server.handlers)))
+
+	req := httptest.NewRequest(http.MethodPost, "/playground?auth_id=missing", strings.NewReader(`{"model":"m"}`))
+	rr := httptest.NewRecorder()
+	engine.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d; body=%s", rr.Code, http.StatusNotFound, rr.Body.String())
+	}
+}
+
+func TestSetPlaygroundAuthHeaders(t *testing.T) {
+	server := newTestServer(t)
+	if _, err := server.handlers.AuthManager.Register(context.Background(), &auth.Auth{
+		ID:       "auth-1",
+		FileName: "claude-user.json",
+		Provider: "claude",
+		Metadata: map[string]any{"email": "user@example.com"},
+	}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	c.Writer.Header().Set(playgroundAccountHeader, "stale")
+	server.setPlaygroundAuthHeaders(c, "unknown")
+	if got := c.Writer.Header().Get(playgroundAuthHeader); got != "unknown" {
+		t.Fatalf("auth header = %q, want unknown", got)
+	}
+	if got := c.Writer.Header().Get(playgroundAccountHeader); got != "" {
+		t.Fatalf("account header should be cleared on retry, got %q", got)
+	}
+
+	server.setPlaygroundAuthHeaders(c, "auth-1")
+	header := c.Writer.Header()
+	if got := header.Get(playgroundAuthHeader); got != "claude-user.json" {
+		t.Errorf("auth header = %q", got)
+	}
+	if got := header.Get(playgroundProviderHeader); got != "claude" {
+		t.Errorf("provider header = %q", got)
+	}
+	if got := header.Get(playgroundAccountHeader); got != "user@example.com" {
+		t.Errorf("account header = %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+package vertex
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultLocation is the region used when a credential does not set one.
+const DefaultLocation = "us-central1"
+
+// Locations returns the ordered, de-duplicated regions of a credential. The "location" field
+// accepts a single region or a comma separated failover list (e.g. "us-central1,global"); a
+// "locations" array is accepted as well. It returns DefaultLocation when neither is set.
+func Locations(metadata map[string]any) []string {
+	var raw []string
+	if metadata != nil {
+		if v, ok := metadata["location"].(string); ok {
+			raw = append(raw, strings.Split(v, ",")...)
+		}
+		raw = append(raw, stringList(metadata["locations"])...)
+	}
+	out := make([]string, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, item := range raw {
+		location := strings.ToLower(strings.TrimSpace(item))
+		if location == "" {
+			continue
+		}
+		if _, ok := seen[location]; ok {
+			continue
+		}
+		seen[location] = struct{}{}
+		out = append(out, location)
+	}
+	if len(out) == 0 {
+		return []string{DefaultLocation}
+	}
+	return out
+}
+
+// ModelLocations returns the per-model region availability of a credential from its
+// "model_locations" field: model names (supporting '*' wildcards) mapped to the regions that
+// serve them. Models without an entry are assumed to be served in every region.
+func ModelLocations(metadata map[string]any) map[string][]string {
+	if metadata == nil {
+		return nil
+	}
+	raw, ok := metadata["model_locations"].(map[string]any)
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(raw))
+	for model, value := range raw {
+		model = strings.TrimSpace(model)
+		if model == "" {
+			continue
+		}
+		var locations []string
+		for _, location := range stringList(value) {
+			if location = strings.ToLower(strings.TrimSpace(location)); location != "" {
+				locations = append(locations, location)
+			}
+		}
+		out[model] = locations
+	}
+	return out
+}
+
+// ExcludedModelsForLocation lists the model patterns of modelLocations that are not served in
+// location, sorted so the result is stable across calls.
+func ExcludedModelsForLocation(modelLocations map[string][]string, location string) []string {
+	var out []string
+	for model, locations := range modelLocations {
+		served := false
+		for _, candidate := range locations {
+			if strings.EqualFold(candidate, location) {
+				served = true
+				break
+			}
+		}
+		if !served {
+			out = append(out, model)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Split(v, ",")
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
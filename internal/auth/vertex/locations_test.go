@@ -0,0 +1,33 @@
+package vertex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExcludedModelsForLocationIsSorted(t *testing.T) {
+	modelLocations := ModelLocations(map[string]any{
+		"model_locations": map[string]any{
+			"gemini-3-pro-*":   []any{"global"},
+			"claude-*":         "us-east5, europe-west1",
+			"gemini-2.5-flash": []any{"us-central1", "global"},
+			"imagen-*":         "us-central1",
+		},
+	})
+
+	tests := []struct {
+		location string
+		want     []string
+	}{
+		{location: "global", want: []string{"claude-*", "imagen-*"}},
+		{location: "us-central1", want: []string{"claude-*", "gemini-3-pro-*"}},
+		{location: "europe-west4", want: []string{"claude-*", "gemini-2.5-flash", "gemini-3-pro-*", "imagen-*"}},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if got := ExcludedModelsForLocation(modelLocations, tt.location); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExcludedModelsForLocation(%q) = %v, want %v", tt.location, got, tt.want)
+			}
+		}
+	}
+}
@@ -8,8 +8,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
-	log "github.com/sirupsen/logrus"
 )
 
 // VertexCredentialStorage stores the service account JSON for Vertex AI access.
@@ -26,8 +26,12 @@ type VertexCredentialStorage struct {
 	Email string `json:"email"`
 
 	// Location optionally sets a default region (e.g., us-central1) for Vertex endpoints.
+	// A comma separated list (e.g., "us-central1,global") enables failover between regions.
 	Location string `json:"location,omitempty"`
 
+	// ModelLocations optionally maps model names to the regions that serve them.
+	ModelLocations map[string][]string `json:"model_locations,omitempty"`
+
 	// Type is the provider identifier stored alongside credentials. Always "vertex".
 	Type string `json:"type"`
 }
@@ -48,19 +52,12 @@ func (s *VertexCredentialStorage) SaveTokenToFile(authFilePath string) error {
 	if err := os.MkdirAll(filepath.Dir(authFilePath), 0o700); err != nil {
 		return fmt.Errorf("vertex credential: create directory failed: %w", err)
 	}
-	f, err := os.Create(authFilePath)
+	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
-		return fmt.Errorf("vertex credential: create file failed: %w", err)
-	}
-	defer func() {
-		if errClose := f.Close(); errClose != nil {
-			log.Errorf("vertex credential: failed to close file: %v", errClose)
-		}
-	}()
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	if err = enc.Encode(s); err != nil {
 		return fmt.Errorf("vertex credential: encode failed: %w", err)
 	}
+	if err = authcrypt.WriteFile(authFilePath, data, 0o600); err != nil {
+		return fmt.Errorf("vertex credential: write file failed: %w", err)
+	}
 	return nil
 }
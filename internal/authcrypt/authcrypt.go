@@ -0,0 +1,406 @@
+// Package authcrypt provides envelope encryption for auth records at rest.
+//
+// Every record is encrypted with a random data key (AES-256-GCM); the data key is wrapped
+// with the configured key-encryption key. Sealed records stay valid JSON so every token
+// store can keep treating auth files as opaque JSON blobs. Records without the envelope
+// marker are treated as plaintext, which keeps existing deployments readable until they
+// are migrated.
+package authcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// EnvelopeVersion marks sealed records.
+	EnvelopeVersion = "v1"
+	envelopeField   = "cliproxy_envelope"
+	keySize         = 32
+
+	envKey          = "CLIPROXY_AUTH_ENCRYPTION_KEY"
+	envKeyFile      = "CLIPROXY_AUTH_ENCRYPTION_KEY_FILE"
+	envPreviousKeys = "CLIPROXY_AUTH_ENCRYPTION_PREVIOUS_KEYS"
+)
+
+// ErrKeyUnavailable is returned when a sealed record cannot be opened with the configured keys.
+var ErrKeyUnavailable = errors.New("authcrypt: encryption key unavailable")
+
+type envelope struct {
+	Version    string `json:"cliproxy_envelope"`
+	KeyID      string `json:"kid"`
+	Algorithm  string `json:"alg"`
+	WrappedKey string `json:"wrapped_key"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Keyring holds the primary key used for sealing and any retired keys still accepted for opening.
+type Keyring struct {
+	primaryID string
+	keys      map[string][]byte
+}
+
+var active atomic.Pointer[Keyring]
+
+// SetKeyring installs k as the process-wide keyring; nil disables encryption.
+func SetKeyring(k *Keyring) {
+	active.Store(k)
+}
+
+// ActiveKeyring returns the process-wide keyring, or nil when encryption is disabled.
+func ActiveKeyring() *Keyring {
+	return active.Load()
+}
+
+// Enabled reports whether new records are sealed.
+func Enabled() bool {
+	return ActiveKeyring() != nil
+}
+
+// NewKeyring builds a keyring from a primary key and optional retired keys.
+func NewKeyring(primary []byte, previous ...[]byte) (*Keyring, error) {
+	if len(primary) != keySize {
+		return nil, fmt.Errorf("authcrypt: key must be %d bytes, got %d", keySize, len(primary))
+	}
+	k := &Keyring{primaryID: keyID(primary), keys: make(map[string][]byte, 1+len(previous))}
+	k.keys[k.primaryID] = append([]byte(nil), primary...)
+	for _, key := range previous {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("authcrypt: previous key must be %d bytes, got %d", keySize, len(key))
+		}
+		k.keys[keyID(key)] = append([]byte(nil), key...)
+	}
+	return k, nil
+}
+
+// PrimaryKeyID returns the identifier of the key used for sealing.
+func (k *Keyring) PrimaryKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.primaryID
+}
+
+// LoadKeyring resolves keys from the environment and cfg. It returns nil without error when
+// encryption is neither configured nor required.
+func LoadKeyring(cfg config.AuthEncryptionConfig) (*Keyring, error) {
+	var primary []byte
+	var err error
+	switch {
+	case strings.TrimSpace(os.Getenv(envKey)) != "":
+		primary, err = ParseKey([]byte(os.Getenv(envKey)))
+	case strings.TrimSpace(os.Getenv(envKeyFile)) != "":
+		primary, err = readKeyFile(os.Getenv(envKeyFile))
+	case strings.TrimSpace(cfg.KeyFile) != "":
+		primary, err = readKeyFile(cfg.KeyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if primary == nil {
+		if cfg.Enable {
+			return nil, fmt.Errorf("authcrypt: auth-encryption is enabled but no key is configured (set %s or auth-encryption.key-file)", envKey)
+		}
+		return nil, nil
+	}
+	var previous [][]byte
+	for _, raw := range strings.Split(os.Getenv(envPreviousKeys), ",") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		key, errParse := ParseKey([]byte(raw))
+		if errParse != nil {
+			return nil, fmt.Errorf("authcrypt: %s: %w", envPreviousKeys, errParse)
+		}
+		previous = append(previous, key)
+	}
+	for _, path := range cfg.PreviousKeyFiles {
+		if strings.TrimSpace(path) == "" {
+			continue
+		}
+		key, errRead := readKeyFile(path)
+		if errRead != nil {
+			return nil, errRead
+		}
+		previous = append(previous, key)
+	}
+	return NewKeyring(primary, previous...)
+}
+
+// ParseKey decodes a 32-byte key given as raw bytes, base64, or hex.
+func ParseKey(raw []byte) ([]byte, error) {
+	if len(raw) == keySize {
+		return append([]byte(nil), raw...), nil
+	}
+	text := strings.TrimSpace(string(raw))
+	if decoded, err := hex.DecodeString(text); err == nil && len(decoded) == keySize {
+		return decoded, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := enc.DecodeString(text); err == nil && len(decoded) == keySize {
+			return decoded, nil
+		}
+	}
+	if len(text) == keySize {
+		return []byte(text), nil
+	}
+	return nil, fmt.Errorf("authcrypt: key must be %d bytes encoded as raw, base64, or hex", keySize)
+}
+
+// GenerateKey returns a new random key encoded as base64.
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return nil, fmt.Errorf("authcrypt: read key file: %w", err)
+	}
+	key, err := ParseKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("authcrypt: key file %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// IsSealed reports whether data is a sealed envelope.
+func IsSealed(data []byte) bool {
+	return gjson.GetBytes(data, envelopeField).String() == EnvelopeVersion
+}
+
+// Seal encrypts plaintext with a fresh data key wrapped by the primary key.
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	if k == nil {
+		return nil, ErrKeyUnavailable
+	}
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("authcrypt: generate data key: %w", err)
+	}
+	nonce, ciphertext, err := gcmSeal(dataKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := k.wrap(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope{
+		Version:    EnvelopeVersion,
+		KeyID:      k.primaryID,
+		Algorithm:  "AES-256-GCM",
+		WrappedKey: wrapped,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	})
+}
+
+// Open decrypts a sealed envelope. Plaintext input is returned unchanged.
+func (k *Keyring) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	env, dataKey, err := k.unwrap(data)
+	if err != nil {
+		return nil, err
+	}
+	nonce, errNonce := base64.StdEncoding.DecodeString(env.Nonce)
+	ciphertext, errCipher := base64.StdEncoding.DecodeString(env.Ciphertext)
+	if errNonce != nil || errCipher != nil {
+		return nil, fmt.Errorf("authcrypt: malformed envelope")
+	}
+	return gcmOpen(dataKey, nonce, ciphertext)
+}
+
+// Rewrap re-seals data under the primary key. Plaintext records are sealed; records sealed
+// with a retired key only have their data key re-wrapped. changed is false when data is
+// already sealed with the primary key.
+func (k *Keyring) Rewrap(data []byte) (out []byte, changed bool, err error) {
+	if k == nil {
+		return nil, false, ErrKeyUnavailable
+	}
+	if !IsSealed(data) {
+		sealed, errSeal := k.Seal(data)
+		return sealed, errSeal == nil, errSeal
+	}
+	env, dataKey, err := k.unwrap(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if env.KeyID == k.primaryID {
+		return data, false, nil
+	}
+	wrapped, err := k.wrap(dataKey)
+	if err != nil {
+		return nil, false, err
+	}
+	env.KeyID = k.primaryID
+	env.WrappedKey = wrapped
+	out, err = json.Marshal(env)
+	return out, err == nil, err
+}
+
+func (k *Keyring) wrap(dataKey []byte) (string, error) {
+	nonce, ciphertext, err := gcmSeal(k.keys[k.primaryID], dataKey)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(append(nonce, ciphertext...)), nil
+}
+
+func (k *Keyring) unwrap(data []byte) (envelope, []byte, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return env, nil, fmt.Errorf("authcrypt: malformed envelope: %w", err)
+	}
+	if k == nil {
+		return env, nil, ErrKeyUnavailable
+	}
+	key, ok := k.keys[env.KeyID]
+	if !ok {
+		return env, nil, fmt.Errorf("%w: no key with id %s", ErrKeyUnavailable, env.KeyID)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(env.WrappedKey)
+	if err != nil || len(wrapped) < 12 {
+		return env, nil, fmt.Errorf("authcrypt: malformed wrapped key")
+	}
+	dataKey, err := gcmOpen(key, wrapped[:12], wrapped[12:])
+	if err != nil {
+		return env, nil, err
+	}
+	return env, dataKey, nil
+}
+
+func gcmSeal(key, plaintext []byte) (nonce, ciphertext []byte, err error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("authcrypt: generate nonce: %w", err)
+	}
+	return nonce, aead.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func gcmOpen(key, nonce, ciphertext []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("authcrypt: decrypt failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("authcrypt: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts plaintext with the active keyring, or returns it unchanged when encryption is disabled.
+func Seal(plaintext []byte) ([]byte, error) {
+	k := ActiveKeyring()
+	if k == nil {
+		return plaintext, nil
+	}
+	return k.Seal(plaintext)
+}
+
+// Open decrypts data with the active keyring. Plaintext input is returned unchanged.
+func Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	return ActiveKeyring().Open(data)
+}
+
+// ReadFile reads path and decrypts it when sealed.
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Open(data)
+}
+
+// WriteFile seals plaintext with the active keyring and writes it to path atomically.
+func WriteFile(path string, plaintext []byte, perm os.FileMode) error {
+	data, err := Seal(plaintext)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, data, perm)
+}
+
+// RewrapFile re-seals path under the primary key of the active keyring. It reports whether
+// the file was rewritten.
+func RewrapFile(path string) (bool, error) {
+	k := ActiveKeyring()
+	if k == nil {
+		return false, ErrKeyUnavailable
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if len(data) == 0 {
+		return false, nil
+	}
+	out, changed, err := k.Rewrap(data)
+	if err != nil || !changed {
+		return false, err
+	}
+	return true, writeAtomic(path, out, 0o600)
+}
+
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(tmpName, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+	}
+	return err
+}
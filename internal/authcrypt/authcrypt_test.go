@@ -0,0 +1,112 @@
+package authcrypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func TestKeyringSealOpenRoundTrip(t *testing.T) {
+	k, err := NewKeyring(testKey(1))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	plaintext := []byte(`{"type":"claude","access_token":"secret"}`)
+	sealed, err := k.Seal(plaintext)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsSealed(sealed) {
+		t.Fatalf("expected sealed envelope, got %s", sealed)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Fatalf("sealed record leaks plaintext: %s", sealed)
+	}
+	opened, err := k.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Fatalf("round trip mismatch: %s", opened)
+	}
+	passthrough, err := k.Open(plaintext)
+	if err != nil || !bytes.Equal(passthrough, plaintext) {
+		t.Fatalf("expected plaintext passthrough, got %s, %v", passthrough, err)
+	}
+}
+
+func TestKeyringOpenWithWrongKey(t *testing.T) {
+	k1, _ := NewKeyring(testKey(1))
+	k2, _ := NewKeyring(testKey(2))
+	sealed, err := k1.Seal([]byte(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if _, err = k2.Open(sealed); !errors.Is(err, ErrKeyUnavailable) {
+		t.Fatalf("expected ErrKeyUnavailable, got %v", err)
+	}
+}
+
+func TestKeyringRewrapWithPreviousKey(t *testing.T) {
+	oldKeyring, _ := NewKeyring(testKey(1))
+	sealed, _ := oldKeyring.Seal([]byte(`{"a":1}`))
+
+	rotated, err := NewKeyring(testKey(2), testKey(1))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	out, changed, err := rotated.Rewrap(sealed)
+	if err != nil || !changed {
+		t.Fatalf("Rewrap: changed=%v err=%v", changed, err)
+	}
+	newOnly, _ := NewKeyring(testKey(2))
+	opened, err := newOnly.Open(out)
+	if err != nil || string(opened) != `{"a":1}` {
+		t.Fatalf("expected rewrapped record to open with new key, got %s, %v", opened, err)
+	}
+	if _, changed, _ = rotated.Rewrap(out); changed {
+		t.Fatal("expected record sealed with the primary key to be left unchanged")
+	}
+}
+
+func TestWriteFileAndReadFile(t *testing.T) {
+	k, _ := NewKeyring(testKey(3))
+	SetKeyring(k)
+	t.Cleanup(func() { SetKeyring(nil) })
+
+	path := filepath.Join(t.TempDir(), "auth.json")
+	plaintext := []byte(`{"type":"codex"}`)
+	if err := WriteFile(path, plaintext, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	raw, _ := os.ReadFile(path)
+	if !IsSealed(raw) {
+		t.Fatalf("expected file to be sealed, got %s", raw)
+	}
+	opened, err := ReadFile(path)
+	if err != nil || !bytes.Equal(opened, plaintext) {
+		t.Fatalf("ReadFile: got %s, %v", opened, err)
+	}
+}
+
+func TestParseKeyEncodings(t *testing.T) {
+	generated, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	if _, err = ParseKey([]byte(generated)); err != nil {
+		t.Fatalf("ParseKey(generated): %v", err)
+	}
+	if _, err = ParseKey([]byte("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20")); err != nil {
+		t.Fatalf("ParseKey(hex): %v", err)
+	}
+	if _, err = ParseKey([]byte("too-short")); err == nil {
+		t.Fatal("expected short key to be rejected")
+	}
+}
@@ -0,0 +1,296 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// signatureBackendTimeout bounds a backend lookup on the request path.
+	signatureBackendTimeout = 2 * time.Second
+
+	// signatureWriteQueueSize bounds the pending backend writes; writes beyond it are dropped.
+	signatureWriteQueueSize = 1024
+
+	// DefaultSignatureMaintenanceInterval is how often the backend is pruned (and snapshots written)
+	// when no interval is configured.
+	DefaultSignatureMaintenanceInterval = time.Minute
+)
+
+// SignatureBackend persists thinking signatures outside the process so they survive restarts
+// and can be shared between replicas. Entries are keyed by model group and text hash; the
+// in-memory cache stays in front of the backend.
+type SignatureBackend interface {
+	// LoadSignature returns the entry stored for groupKey and textHash.
+	LoadSignature(ctx context.Context, groupKey, textHash string) (SignatureEntry, bool, error)
+	// StoreSignature inserts or refreshes an entry.
+	StoreSignature(ctx context.Context, groupKey, textHash string, entry SignatureEntry) error
+	// ClearSignatures removes the entries of groupKey, or every entry when groupKey is empty.
+	ClearSignatures(ctx context.Context, groupKey string) error
+}
+
+// SignatureMaintainer is implemented by backends that prune expired entries, enforce a size
+// bound, or flush buffered state. It runs periodically and when the backend is replaced.
+type SignatureMaintainer interface {
+	MaintainSignatures(ctx context.Context, ttl time.Duration, maxEntries int) error
+}
+
+// SignatureCacheOptions configures the signature cache.
+type SignatureCacheOptions struct {
+	// MaxEntries bounds the entries kept in memory and in the backend. Zero means unbounded.
+	MaxEntries int
+	// Backend persists entries beyond the process. Nil keeps signatures in memory only.
+	Backend SignatureBackend
+	// BackendName is reported in the cache statistics.
+	BackendName string
+	// MaintenanceInterval controls how often a SignatureMaintainer backend runs.
+	MaintenanceInterval time.Duration
+}
+
+// SignatureCacheStats reports signature cache counters since process start.
+type SignatureCacheStats struct {
+	Backend       string `json:"backend"`
+	Entries       int64  `json:"entries"`
+	MaxEntries    int    `json:"max_entries"`
+	Hits          int64  `json:"hits"`
+	BackendHits   int64  `json:"backend_hits"`
+	Misses        int64  `json:"misses"`
+	Stores        int64  `json:"stores"`
+	Evictions     int64  `json:"evictions"`
+	BackendErrors int64  `json:"backend_errors"`
+	DroppedWrites int64  `json:"dropped_writes"`
+}
+
+var (
+	signatureEntries atomic.Int64
+	signatureStats   struct {
+		hits, backendHits, misses, stores, evictions, backendErrors, droppedWrites atomic.Int64
+	}
+	signatureMaxEntries atomic.Int64
+	signatureRuntime    atomic.Pointer[signatureBackendRuntime]
+	signatureConfigMu   sync.Mutex
+	signatureEvictMu    sync.Mutex
+)
+
+type signatureWrite struct {
+	groupKey string
+	textHash string
+	entry    SignatureEntry
+}
+
+type signatureBackendRuntime struct {
+	backend  SignatureBackend
+	name     string
+	writes   chan signatureWrite
+	stop     chan struct{}
+	done     chan struct{}
+	interval time.Duration
+}
+
+// ConfigureSignatureCache applies opts, replacing the previous backend. Pending writes to the
+// previous backend are flushed before it is released.
+func ConfigureSignatureCache(opts SignatureCacheOptions) {
+	signatureConfigMu.Lock()
+	defer signatureConfigMu.Unlock()
+
+	if opts.MaxEntries < 0 {
+		opts.MaxEntries = 0
+	}
+	signatureMaxEntries.Store(int64(opts.MaxEntries))
+	stopSignatureRuntime(signatureRuntime.Swap(nil))
+	enforceSignatureBound()
+	if opts.Backend == nil {
+		return
+	}
+	if opts.MaintenanceInterval <= 0 {
+		opts.MaintenanceInterval = DefaultSignatureMaintenanceInterval
+	}
+	rt := &signatureBackendRuntime{
+		backend:  opts.Backend,
+		name:     opts.BackendName,
+		writes:   make(chan signatureWrite, signatureWriteQueueSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		interval: opts.MaintenanceInterval,
+	}
+	go rt.run()
+	signatureRuntime.Store(rt)
+}
+
+// ShutdownSignatureCache flushes pending writes and releases the backend.
+func ShutdownSignatureCache() {
+	signatureConfigMu.Lock()
+	defer signatureConfigMu.Unlock()
+	stopSignatureRuntime(signatureRuntime.Swap(nil))
+}
+
+// GetSignatureCacheStats returns the current signature cache counters.
+func GetSignatureCacheStats() SignatureCacheStats {
+	stats := SignatureCacheStats{
+		Backend:       "memory",
+		Entries:       signatureEntries.Load(),
+		MaxEntries:    int(signatureMaxEntries.Load()),
+		Hits:          signatureStats.hits.Load(),
+		BackendHits:   signatureStats.backendHits.Load(),
+		Misses:        signatureStats.misses.Load(),
+		Stores:        signatureStats.stores.Load(),
+		Evictions:     signatureStats.evictions.Load(),
+		BackendErrors: signatureStats.backendErrors.Load(),
+		DroppedWrites: signatureStats.droppedWrites.Load(),
+	}
+	if rt := signatureRuntime.Load(); rt != nil && rt.name != "" {
+		stats.Backend = rt.name
+	}
+	return stats
+}
+
+func stopSignatureRuntime(rt *signatureBackendRuntime) {
+	if rt == nil {
+		return
+	}
+	close(rt.stop)
+	<-rt.done
+}
+
+func (rt *signatureBackendRuntime) run() {
+	defer close(rt.done)
+	ticker := time.NewTicker(rt.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case w := <-rt.writes:
+			rt.store(w)
+		case <-ticker.C:
+			rt.maintain()
+		case <-rt.stop:
+			for {
+				select {
+				case w := <-rt.writes:
+					rt.store(w)
+				default:
+					rt.maintain()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (rt *signatureBackendRuntime) store(w signatureWrite) {
+	ctx, cancel := context.WithTimeout(context.Background(), signatureBackendTimeout)
+	defer cancel()
+	if err := rt.backend.StoreSignature(ctx, w.groupKey, w.textHash, w.entry); err != nil {
+		signatureStats.backendErrors.Add(1)
+		log.Debugf("signature cache: store in %s backend failed: %v", rt.name, err)
+	}
+}
+
+func (rt *signatureBackendRuntime) maintain() {
+	maintainer, ok := rt.backend.(SignatureMaintainer)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := maintainer.MaintainSignatures(ctx, SignatureCacheTTL, int(signatureMaxEntries.Load())); err != nil {
+		signatureStats.backendErrors.Add(1)
+		log.Warnf("signature cache: %s backend maintenance failed: %v", rt.name, err)
+	}
+}
+
+// enqueueBackendWrite hands an entry to the backend writer without blocking the caller.
+func enqueueBackendWrite(groupKey, textHash string, entry SignatureEntry) {
+	rt := signatureRuntime.Load()
+	if rt == nil {
+		return
+	}
+	select {
+	case rt.writes <- signatureWrite{groupKey: groupKey, textHash: textHash, entry: entry}:
+	default:
+		signatureStats.droppedWrites.Add(1)
+	}
+}
+
+// loadBackendSignature looks up an entry missing from memory and caches it locally.
+func loadBackendSignature(groupKey, textHash string) (string, bool) {
+	rt := signatureRuntime.Load()
+	if rt == nil {
+		return "", false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), signatureBackendTimeout)
+	defer cancel()
+	entry, ok, err := rt.backend.LoadSignature(ctx, groupKey, textHash)
+	if err != nil {
+		signatureStats.backendErrors.Add(1)
+		log.Debugf("signature cache: load from %s backend failed: %v", rt.name, err)
+		return "", false
+	}
+	if !ok || entry.Signature == "" || time.Since(entry.Timestamp) > SignatureCacheTTL {
+		return "", false
+	}
+	entry.Timestamp = time.Now()
+	storeLocalSignature(groupKey, textHash, entry)
+	return entry.Signature, true
+}
+
+func clearBackendSignatures(groupKey string) {
+	rt := signatureRuntime.Load()
+	if rt == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), signatureBackendTimeout)
+	defer cancel()
+	if err := rt.backend.ClearSignatures(ctx, groupKey); err != nil {
+		signatureStats.backendErrors.Add(1)
+		log.Warnf("signature cache: clear %s backend failed: %v", rt.name, err)
+	}
+}
+
+// enforceSignatureBound evicts the least recently used entries once the in-memory cache
+// exceeds its bound. It evicts down to 90% of the bound so eviction does not run on every insert.
+func enforceSignatureBound() {
+	limit := signatureMaxEntries.Load()
+	if limit <= 0 || signatureEntries.Load() <= limit {
+		return
+	}
+	signatureEvictMu.Lock()
+	defer signatureEvictMu.Unlock()
+	if signatureEntries.Load() <= limit {
+		return
+	}
+
+	type candidate struct {
+		sc        *groupCache
+		textHash  string
+		timestamp time.Time
+	}
+	var candidates []candidate
+	signatureCache.Range(func(_, value any) bool {
+		sc := value.(*groupCache)
+		sc.mu.RLock()
+		for textHash, entry := range sc.entries {
+			candidates = append(candidates, candidate{sc: sc, textHash: textHash, timestamp: entry.Timestamp})
+		}
+		sc.mu.RUnlock()
+		return true
+	})
+	excess := len(candidates) - int(limit-limit/10)
+	if excess <= 0 {
+		return
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].timestamp.Before(candidates[j].timestamp) })
+	for _, c := range candidates[:excess] {
+		c.sc.mu.Lock()
+		if entry, ok := c.sc.entries[c.textHash]; ok && entry.Timestamp.Equal(c.timestamp) {
+			delete(c.sc.entries, c.textHash)
+			signatureEntries.Add(-1)
+			signatureStats.evictions.Add(1)
+		}
+		c.sc.mu.Unlock()
+	}
+}
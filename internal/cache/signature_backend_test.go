@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSignatureCache_FileBackendSurvivesRestart(t *testing.T) {
+	t.Cleanup(func() { ConfigureSignatureCache(SignatureCacheOptions{}) })
+	ClearSignatureCache("")
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	backend, err := NewFileSignatureBackend(path)
+	if err != nil {
+		t.Fatalf("NewFileSignatureBackend() error = %v", err)
+	}
+	ConfigureSignatureCache(SignatureCacheOptions{Backend: backend, BackendName: "file"})
+
+	signature := "persisted_sig_12345678901234567890123456789012345678901234567890"
+	CacheSignature(testModelName, "persisted thinking", signature)
+	// Replacing the backend flushes pending writes and writes the snapshot.
+	ConfigureSignatureCache(SignatureCacheOptions{})
+
+	// Simulate a restart: the in-memory cache is empty and a new backend reads the snapshot.
+	deleteGroupCache(GetModelGroup(testModelName))
+	restarted, err := NewFileSignatureBackend(path)
+	if err != nil {
+		t.Fatalf("NewFileSignatureBackend() after restart error = %v", err)
+	}
+	ConfigureSignatureCache(SignatureCacheOptions{Backend: restarted, BackendName: "file"})
+	before := GetSignatureCacheStats()
+	if got := GetCachedSignature(testModelName, "persisted thinking"); got != signature {
+		t.Fatalf("GetCachedSignature() after restart = %q, want %q", got, signature)
+	}
+	if got := GetCachedSignature(testModelName, "persisted thinking"); got != signature {
+		t.Fatalf("GetCachedSignature() second lookup = %q", got)
+	}
+	after := GetSignatureCacheStats()
+	if after.Backend != "file" || after.BackendHits-before.BackendHits != 1 || after.Hits-before.Hits != 1 {
+		t.Fatalf("stats = %+v, before = %+v", after, before)
+	}
+
+	ClearSignatureCache("")
+	if _, ok, _ := restarted.LoadSignature(context.Background(), GetModelGroup(testModelName), hashText("persisted thinking")); ok {
+		t.Fatal("ClearSignatureCache() did not clear the backend")
+	}
+}
+
+func TestSignatureCache_MaxEntriesEvictsOldest(t *testing.T) {
+	t.Cleanup(func() { ConfigureSignatureCache(SignatureCacheOptions{}) })
+	ClearSignatureCache("")
+	ConfigureSignatureCache(SignatureCacheOptions{MaxEntries: 10})
+
+	sig := "bounded_sig_1234567890123456789012345678901234567890123456789"
+	for i := 0; i < 11; i++ {
+		CacheSignature(testModelName, string(rune('a'+i)), sig)
+		time.Sleep(time.Millisecond)
+	}
+	if entries := GetSignatureCacheStats().Entries; entries != 9 {
+		t.Fatalf("entries = %d, want 9 after evicting down to 90%% of the bound", entries)
+	}
+	if got := GetCachedSignature(testModelName, "a"); got != "" {
+		t.Fatalf("oldest entry survived eviction: %q", got)
+	}
+	if got := GetCachedSignature(testModelName, "k"); got != sig {
+		t.Fatalf("newest entry evicted: %q", got)
+	}
+}
+
+func TestFileSignatureBackend_MaintainBoundsSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	backend, err := NewFileSignatureBackend(path)
+	if err != nil {
+		t.Fatalf("NewFileSignatureBackend() error = %v", err)
+	}
+	ctx := context.Background()
+	now := time.Now()
+	_ = backend.StoreSignature(ctx, "claude", "old", SignatureEntry{Signature: "s1", Timestamp: now.Add(-time.Hour)})
+	_ = backend.StoreSignature(ctx, "claude", "new", SignatureEntry{Signature: "s2", Timestamp: now})
+	_ = backend.StoreSignature(ctx, "claude", "expired", SignatureEntry{Signature: "s3", Timestamp: now.Add(-2 * SignatureCacheTTL)})
+	if err = backend.MaintainSignatures(ctx, SignatureCacheTTL, 1); err != nil {
+		t.Fatalf("MaintainSignatures() error = %v", err)
+	}
+
+	reloaded, err := NewFileSignatureBackend(path)
+	if err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	for hash, want := range map[string]bool{"new": true, "old": false, "expired": false} {
+		if _, ok, _ := reloaded.LoadSignature(ctx, "claude", hash); ok != want {
+			t.Fatalf("entry %s present = %v, want %v", hash, ok, want)
+		}
+	}
+}
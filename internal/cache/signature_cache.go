@@ -81,6 +81,7 @@ func purgeExpiredCaches() {
 		for k, entry := range sc.entries {
 			if now.Sub(entry.Timestamp) > SignatureCacheTTL {
 				delete(sc.entries, k)
+				signatureEntries.Add(-1)
 			}
 		}
 		isEmpty := len(sc.entries) == 0
@@ -105,14 +106,25 @@ func CacheSignature(modelName, text, signature string) {
 
 	groupKey := GetModelGroup(modelName)
 	textHash := hashText(text)
-	sc := getOrCreateGroupCache(groupKey)
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
-
-	sc.entries[textHash] = SignatureEntry{
+	entry := SignatureEntry{
 		Signature: signature,
 		Timestamp: time.Now(),
 	}
+	storeLocalSignature(groupKey, textHash, entry)
+	signatureStats.stores.Add(1)
+	enqueueBackendWrite(groupKey, textHash, entry)
+}
+
+// storeLocalSignature puts an entry into the in-memory cache and enforces its size bound.
+func storeLocalSignature(groupKey, textHash string, entry SignatureEntry) {
+	sc := getOrCreateGroupCache(groupKey)
+	sc.mu.Lock()
+	if _, exists := sc.entries[textHash]; !exists {
+		signatureEntries.Add(1)
+	}
+	sc.entries[textHash] = entry
+	sc.mu.Unlock()
+	enforceSignatureBound()
 }
 
 // GetCachedSignature retrieves a cached signature for a given model group and text.
@@ -126,56 +138,83 @@ func GetCachedSignature(modelName, text string) string {
 		}
 		return ""
 	}
+	textHash := hashText(text)
+	if signature, ok := getLocalSignature(groupKey, textHash); ok {
+		signatureStats.hits.Add(1)
+		return signature
+	}
+	if signature, ok := loadBackendSignature(groupKey, textHash); ok {
+		signatureStats.backendHits.Add(1)
+		return signature
+	}
+	signatureStats.misses.Add(1)
+	if groupKey == "gemini" {
+		return "skip_thought_signature_validator"
+	}
+	return ""
+}
+
+// getLocalSignature looks an entry up in the in-memory cache and refreshes its TTL.
+func getLocalSignature(groupKey, textHash string) (string, bool) {
 	val, ok := signatureCache.Load(groupKey)
 	if !ok {
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
-		}
-		return ""
+		return "", false
 	}
 	sc := val.(*groupCache)
 
-	textHash := hashText(text)
-
 	now := time.Now()
 
 	sc.mu.Lock()
 	entry, exists := sc.entries[textHash]
 	if !exists {
 		sc.mu.Unlock()
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
-		}
-		return ""
+		return "", false
 	}
 	if now.Sub(entry.Timestamp) > SignatureCacheTTL {
 		delete(sc.entries, textHash)
+		signatureEntries.Add(-1)
 		sc.mu.Unlock()
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
-		}
-		return ""
+		return "", false
 	}
 
 	// Refresh TTL on access (sliding expiration).
+	lastSeen := entry.Timestamp
 	entry.Timestamp = now
 	sc.entries[textHash] = entry
 	sc.mu.Unlock()
 
-	return entry.Signature
+	// Let the backend see the refresh so other replicas do not expire a signature in use.
+	if now.Sub(lastSeen) > CacheCleanupInterval {
+		enqueueBackendWrite(groupKey, textHash, entry)
+	}
+	return entry.Signature, true
 }
 
-// ClearSignatureCache clears signature cache for a specific model group or all groups.
+// ClearSignatureCache clears signature cache for a specific model group or all groups,
+// including the entries held by the configured backend.
 func ClearSignatureCache(modelName string) {
+	groupKey := ""
 	if modelName == "" {
 		signatureCache.Range(func(key, _ any) bool {
-			signatureCache.Delete(key)
+			deleteGroupCache(key.(string))
 			return true
 		})
+	} else {
+		groupKey = GetModelGroup(modelName)
+		deleteGroupCache(groupKey)
+	}
+	clearBackendSignatures(groupKey)
+}
+
+func deleteGroupCache(groupKey string) {
+	val, ok := signatureCache.LoadAndDelete(groupKey)
+	if !ok {
 		return
 	}
-	groupKey := GetModelGroup(modelName)
-	signatureCache.Delete(groupKey)
+	sc := val.(*groupCache)
+	sc.mu.Lock()
+	signatureEntries.Add(-int64(len(sc.entries)))
+	sc.mu.Unlock()
 }
 
 // HasValidSignature checks if a signature is valid (non-empty and long enough)
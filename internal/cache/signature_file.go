@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileSignatureBackend keeps signatures in memory and writes them to a JSON snapshot file
+// during maintenance, so they survive restarts of a single instance.
+type FileSignatureBackend struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]map[string]SignatureEntry
+	dirty   bool
+}
+
+type signatureSnapshot struct {
+	Version int                                  `json:"version"`
+	Groups  map[string]map[string]SignatureEntry `json:"groups"`
+}
+
+// NewFileSignatureBackend loads the snapshot at path, if present. Expired entries are dropped.
+func NewFileSignatureBackend(path string) (*FileSignatureBackend, error) {
+	b := &FileSignatureBackend{path: path, entries: make(map[string]map[string]SignatureEntry)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return b, nil
+		}
+		return nil, fmt.Errorf("signature cache: read snapshot: %w", err)
+	}
+	var snapshot signatureSnapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("signature cache: parse snapshot %s: %w", path, err)
+	}
+	now := time.Now()
+	for groupKey, group := range snapshot.Groups {
+		for textHash, entry := range group {
+			if entry.Signature == "" || now.Sub(entry.Timestamp) > SignatureCacheTTL {
+				continue
+			}
+			if b.entries[groupKey] == nil {
+				b.entries[groupKey] = make(map[string]SignatureEntry)
+			}
+			b.entries[groupKey][textHash] = entry
+		}
+	}
+	return b, nil
+}
+
+// LoadSignature implements SignatureBackend.
+func (b *FileSignatureBackend) LoadSignature(_ context.Context, groupKey, textHash string) (SignatureEntry, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.entries[groupKey][textHash]
+	return entry, ok, nil
+}
+
+// StoreSignature implements SignatureBackend.
+func (b *FileSignatureBackend) StoreSignature(_ context.Context, groupKey, textHash string, entry SignatureEntry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.entries[groupKey] == nil {
+		b.entries[groupKey] = make(map[string]SignatureEntry)
+	}
+	b.entries[groupKey][textHash] = entry
+	b.dirty = true
+	return nil
+}
+
+// ClearSignatures implements SignatureBackend.
+func (b *FileSignatureBackend) ClearSignatures(_ context.Context, groupKey string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if groupKey == "" {
+		b.entries = make(map[string]map[string]SignatureEntry)
+	} else {
+		delete(b.entries, groupKey)
+	}
+	b.dirty = true
+	return nil
+}
+
+// MaintainSignatures drops expired entries, keeps the newest maxEntries, and writes the
+// snapshot when anything changed since the last write.
+func (b *FileSignatureBackend) MaintainSignatures(_ context.Context, ttl time.Duration, maxEntries int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	type ref struct {
+		groupKey, textHash string
+		timestamp          time.Time
+	}
+	var refs []ref
+	now := time.Now()
+	for groupKey, group := range b.entries {
+		for textHash, entry := range group {
+			if now.Sub(entry.Timestamp) > ttl {
+				delete(group, textHash)
+				b.dirty = true
+				continue
+			}
+			refs = append(refs, ref{groupKey: groupKey, textHash: textHash, timestamp: entry.Timestamp})
+		}
+		if len(group) == 0 {
+			delete(b.entries, groupKey)
+		}
+	}
+	if maxEntries > 0 && len(refs) > maxEntries {
+		sort.Slice(refs, func(i, j int) bool { return refs[i].timestamp.After(refs[j].timestamp) })
+		for _, r := range refs[maxEntries:] {
+			delete(b.entries[r.groupKey], r.textHash)
+			if len(b.entries[r.groupKey]) == 0 {
+				delete(b.entries, r.groupKey)
+			}
+		}
+		b.dirty = true
+	}
+	if !b.dirty {
+		return nil
+	}
+	if err := b.writeSnapshotLocked(); err != nil {
+		return err
+	}
+	b.dirty = false
+	return nil
+}
+
+func (b *FileSignatureBackend) writeSnapshotLocked() error {
+	data, err := json.Marshal(signatureSnapshot{Version: 1, Groups: b.entries})
+	if err != nil {
+		return fmt.Errorf("signature cache: encode snapshot: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(b.path), 0o700); err != nil {
+		return fmt.Errorf("signature cache: create snapshot directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(b.path), filepath.Base(b.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("signature cache: create snapshot: %w", err)
+	}
+	tmpName := tmp.Name()
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmpName, b.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("signature cache: write snapshot: %w", err)
+	}
+	return nil
+}
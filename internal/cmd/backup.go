@@ -0,0 +1,271 @@
+// Package cmd contains CLI helpers. This file implements encrypted backup and restore of the
+// config file and every auth record of the active token store.
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	backupFormatVersion = "v1"
+	backupPassphraseEnv = "CLIPROXY_BACKUP_PASSPHRASE"
+	backupConfigEntry   = "config.yaml"
+	backupAuthPrefix    = "auths/"
+	backupKDFScrypt     = "scrypt"
+	backupKDFKeyring    = "keyring"
+)
+
+// backupFile is the on-disk backup format: a gzipped tar of the config and auth records,
+// sealed with a key derived from a passphrase or with the auth encryption keyring.
+type backupFile struct {
+	Version   string          `json:"cliproxy_backup"`
+	CreatedAt time.Time       `json:"created_at"`
+	KDF       string          `json:"kdf"`
+	Salt      []byte          `json:"salt,omitempty"`
+	Envelope  json.RawMessage `json:"envelope"`
+}
+
+// backupContents is the decrypted payload of a backup.
+type backupContents struct {
+	Config []byte
+	// Auths maps record IDs (slash separated) to plaintext auth JSON.
+	Auths map[string][]byte
+}
+
+// DoBackup writes the config and every auth record of the active token store to a single
+// encrypted archive. The archive is sealed with CLIPROXY_BACKUP_PASSPHRASE when set, otherwise
+// with the auth encryption key.
+func DoBackup(cfg *config.Config, configPath, archivePath string) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	ctx := context.Background()
+	auths, err := activeTokenStore(cfg).List(ctx)
+	if err != nil {
+		log.Errorf("backup: list auths failed: %v", err)
+		return
+	}
+	contents := backupContents{Auths: make(map[string][]byte, len(auths))}
+	for _, auth := range auths {
+		raw, errMarshal := json.Marshal(auth.Metadata)
+		if errMarshal != nil {
+			log.Errorf("backup: encode auth %s failed: %v", auth.ID, errMarshal)
+			return
+		}
+		contents.Auths[filepath.ToSlash(auth.ID)] = raw
+	}
+	contents.Config, err = os.ReadFile(configPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Errorf("backup: read config failed: %v", err)
+		return
+	}
+
+	data, err := sealBackup(contents, os.Getenv(backupPassphraseEnv), authcrypt.ActiveKeyring())
+	if err != nil {
+		log.Errorf("backup: %v", err)
+		return
+	}
+	if err = os.WriteFile(archivePath, data, 0o600); err != nil {
+		log.Errorf("backup: write archive failed: %v", err)
+		return
+	}
+	fmt.Printf("Backed up config and %d auth record(s) to %s\n", len(contents.Auths), archivePath)
+}
+
+// DoRestore restores a backup created by DoBackup into the active token store. Existing
+// records with the same ID and the config file are overwritten; other records are kept.
+func DoRestore(cfg *config.Config, configPath, archivePath string) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		log.Errorf("restore: read archive failed: %v", err)
+		return
+	}
+	contents, err := openBackup(data, os.Getenv(backupPassphraseEnv), authcrypt.ActiveKeyring())
+	if err != nil {
+		log.Errorf("restore: %v", err)
+		return
+	}
+
+	ctx := context.Background()
+	tokenStore := activeTokenStore(cfg)
+	restored := 0
+	for id, raw := range contents.Auths {
+		metadata := make(map[string]any)
+		if errUnmarshal := json.Unmarshal(raw, &metadata); errUnmarshal != nil {
+			log.Errorf("restore: decode auth %s failed: %v", id, errUnmarshal)
+			continue
+		}
+		provider, _ := metadata["type"].(string)
+		record := &coreauth.Auth{ID: id, FileName: id, Provider: provider, Metadata: metadata, Storage: recordStorage{metadata: metadata}}
+		if _, errSave := tokenStore.Save(ctx, record); errSave != nil {
+			log.Errorf("restore: save auth %s failed: %v", id, errSave)
+			continue
+		}
+		restored++
+	}
+	if len(contents.Config) > 0 {
+		if errWrite := os.WriteFile(configPath, contents.Config, 0o600); errWrite != nil {
+			log.Errorf("restore: write config failed: %v", errWrite)
+		} else if persister, ok := tokenStore.(interface{ PersistConfig(context.Context) error }); ok {
+			if errPersist := persister.PersistConfig(ctx); errPersist != nil {
+				log.Errorf("restore: persist config failed: %v", errPersist)
+			}
+		}
+	}
+	fmt.Printf("Restored %d of %d auth record(s) from %s\n", restored, len(contents.Auths), archivePath)
+}
+
+// sealBackup archives contents and seals them with passphrase, or with keyring when no
+// passphrase is given.
+func sealBackup(contents backupContents, passphrase string, keyring *authcrypt.Keyring) ([]byte, error) {
+	archive, err := packBackup(contents)
+	if err != nil {
+		return nil, fmt.Errorf("build archive: %w", err)
+	}
+	out := backupFile{Version: backupFormatVersion, CreatedAt: time.Now().UTC()}
+	switch {
+	case passphrase != "":
+		out.KDF = backupKDFScrypt
+		out.Salt = make([]byte, 16)
+		if _, err = rand.Read(out.Salt); err != nil {
+			return nil, err
+		}
+		keyring, err = passphraseKeyring(passphrase, out.Salt)
+		if err != nil {
+			return nil, err
+		}
+	case keyring != nil:
+		out.KDF = backupKDFKeyring
+	default:
+		return nil, fmt.Errorf("no backup key: set %s or enable auth-encryption", backupPassphraseEnv)
+	}
+	out.Envelope, err = keyring.Seal(archive)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt archive: %w", err)
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// openBackup decrypts and unpacks a backup produced by sealBackup.
+func openBackup(data []byte, passphrase string, keyring *authcrypt.Keyring) (backupContents, error) {
+	var in backupFile
+	if err := json.Unmarshal(data, &in); err != nil || in.Version != backupFormatVersion {
+		return backupContents{}, fmt.Errorf("not a backup archive")
+	}
+	switch in.KDF {
+	case backupKDFScrypt:
+		if passphrase == "" {
+			return backupContents{}, fmt.Errorf("archive is passphrase protected: set %s", backupPassphraseEnv)
+		}
+		var err error
+		if keyring, err = passphraseKeyring(passphrase, in.Salt); err != nil {
+			return backupContents{}, err
+		}
+	case backupKDFKeyring:
+		if keyring == nil {
+			return backupContents{}, fmt.Errorf("archive is sealed with the auth encryption key, which is not configured")
+		}
+	default:
+		return backupContents{}, fmt.Errorf("unsupported key derivation %q", in.KDF)
+	}
+	archive, err := keyring.Open(in.Envelope)
+	if err != nil {
+		return backupContents{}, fmt.Errorf("decrypt archive: %w", err)
+	}
+	return unpackBackup(archive)
+}
+
+func passphraseKeyring(passphrase string, salt []byte) (*authcrypt.Keyring, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	return authcrypt.NewKeyring(key)
+}
+
+func packBackup(contents backupContents) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if len(contents.Config) > 0 {
+		if err := write(backupConfigEntry, contents.Config); err != nil {
+			return nil, err
+		}
+	}
+	for id, data := range contents.Auths {
+		if err := write(backupAuthPrefix+id, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unpackBackup(archive []byte) (backupContents, error) {
+	contents := backupContents{Auths: make(map[string][]byte)}
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return contents, fmt.Errorf("read archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, errNext := tr.Next()
+		if errors.Is(errNext, io.EOF) {
+			break
+		}
+		if errNext != nil {
+			return contents, fmt.Errorf("read archive: %w", errNext)
+		}
+		data, errRead := io.ReadAll(tr)
+		if errRead != nil {
+			return contents, fmt.Errorf("read archive entry %s: %w", header.Name, errRead)
+		}
+		switch {
+		case header.Name == backupConfigEntry:
+			contents.Config = data
+		case strings.HasPrefix(header.Name, backupAuthPrefix):
+			id := path.Clean(strings.TrimPrefix(header.Name, backupAuthPrefix))
+			if id == "." || !filepath.IsLocal(filepath.FromSlash(id)) {
+				return contents, fmt.Errorf("archive entry %s escapes the auth directory", header.Name)
+			}
+			contents.Auths[id] = data
+		}
+	}
+	return contents, nil
+}
@@ -0,0 +1,324 @@
+// Package cmd contains CLI helpers. This file implements copying every auth record and the
+// config from the active token store to another storage backend.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/store"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// storeTarget is a token store opened from a destination spec together with its config location.
+type storeTarget struct {
+	store      coreauth.Store
+	configPath string
+	cleanup    func()
+}
+
+// DoMigrateStore copies every auth record and the config file from the active token store to
+// the store described by destination. Supported destinations:
+//
+//	file:///path/to/auths[?config=/path/to/config.yaml]   (or a bare directory path)
+//	postgres://user@host:5432/db[?schema=name]             (password from PGSTORE_PASSWORD)
+//	s3://endpoint/bucket[/prefix]                          (OBJECTSTORE_ACCESS_KEY/OBJECTSTORE_SECRET_KEY; s3+http:// for plain HTTP)
+//	git+https://host/org/repo.git                          (GITSTORE_GIT_USERNAME/GITSTORE_GIT_TOKEN)
+//
+// Secrets are never taken from the destination itself, so they do not end up in shell history
+// or process listings.
+// With dryRun set, the records that would be copied are listed without writing anything.
+// After copying, the destination is read back and compared with the source.
+func DoMigrateStore(cfg *config.Config, configPath, destination string, dryRun bool) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	ctx := context.Background()
+	source := activeTokenStore(cfg)
+	auths, err := source.List(ctx)
+	if err != nil {
+		log.Errorf("migrate-store: list source auths failed: %v", err)
+		return
+	}
+	sortAuthsByID(auths)
+	configData, errConfig := os.ReadFile(configPath)
+	if errConfig != nil && !errors.Is(errConfig, fs.ErrNotExist) {
+		log.Errorf("migrate-store: read source config failed: %v", errConfig)
+		return
+	}
+
+	if dryRun {
+		fmt.Printf("Dry run: would migrate %d auth record(s) to %s\n", len(auths), redactStoreSpec(destination))
+		for _, auth := range auths {
+			fmt.Printf("  auth %s (%s)\n", auth.ID, auth.Provider)
+		}
+		if len(configData) > 0 {
+			fmt.Printf("  config %s (%d bytes)\n", configPath, len(configData))
+		}
+		return
+	}
+
+	target, err := openStoreTarget(ctx, destination)
+	if err != nil {
+		log.Errorf("migrate-store: open destination failed: %v", err)
+		return
+	}
+	defer target.cleanup()
+
+	failed := 0
+	for _, auth := range auths {
+		if _, errSave := target.store.Save(ctx, migrationRecord(auth)); errSave != nil {
+			failed++
+			log.Errorf("migrate-store: copy auth %s failed: %v", auth.ID, errSave)
+		}
+	}
+	if len(configData) > 0 {
+		if errWrite := writeTargetConfig(ctx, target, configData); errWrite != nil {
+			failed++
+			log.Errorf("migrate-store: copy config failed: %v", errWrite)
+		}
+	}
+	mismatches := verifyMigration(ctx, auths, target)
+	for _, mismatch := range mismatches {
+		log.Errorf("migrate-store: verification failed: %s", mismatch)
+	}
+	fmt.Printf("Migrated %d of %d auth record(s) to %s", len(auths)-failed, len(auths), redactStoreSpec(destination))
+	if len(mismatches) == 0 && failed == 0 {
+		fmt.Println("; verification passed")
+		return
+	}
+	fmt.Printf("; %d error(s), %d verification mismatch(es)\n", failed, len(mismatches))
+}
+
+// activeTokenStore returns the registered token store, pointing file-based stores at the auth dir.
+func activeTokenStore(cfg *config.Config) coreauth.Store {
+	tokenStore := sdkAuth.GetTokenStore()
+	if setter, ok := tokenStore.(interface{ SetBaseDir(string) }); ok {
+		setter.SetBaseDir(cfg.AuthDir)
+	}
+	return tokenStore
+}
+
+// migrationRecord prepares a listed auth for saving into another store: it drops the source
+// file path so the destination resolves its own location from the record ID.
+func migrationRecord(auth *coreauth.Auth) *coreauth.Auth {
+	clone := auth.Clone()
+	id := filepath.ToSlash(auth.ID)
+	clone.ID = id
+	clone.FileName = id
+	clone.Storage = recordStorage{metadata: auth.Metadata}
+	// Disabled records are still copied; the flag lives in the metadata.
+	clone.Disabled = false
+	if clone.Attributes != nil {
+		delete(clone.Attributes, "path")
+	}
+	return clone
+}
+
+// recordStorage writes an auth record's metadata verbatim. Saving through Storage keeps
+// fields such as "disabled" exactly as they were in the source.
+type recordStorage struct {
+	metadata map[string]any
+}
+
+// SaveTokenToFile writes the metadata as JSON to authFilePath.
+func (r recordStorage) SaveTokenToFile(authFilePath string) error {
+	raw, err := json.Marshal(r.metadata)
+	if err != nil {
+		return err
+	}
+	return authcrypt.WriteFile(authFilePath, raw, 0o600)
+}
+
+func writeTargetConfig(ctx context.Context, target *storeTarget, data []byte) error {
+	if target.configPath == "" {
+		log.Warn("migrate-store: destination has no config location; pass ?config=/path for file destinations")
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target.configPath), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target.configPath, data, 0o600); err != nil {
+		return err
+	}
+	if persister, ok := target.store.(interface{ PersistConfig(context.Context) error }); ok {
+		return persister.PersistConfig(ctx)
+	}
+	return nil
+}
+
+// verifyMigration reads the destination back and reports records that are missing or differ.
+func verifyMigration(ctx context.Context, auths []*coreauth.Auth, target *storeTarget) []string {
+	copied, err := target.store.List(ctx)
+	if err != nil {
+		return []string{fmt.Sprintf("list destination: %v", err)}
+	}
+	byID := make(map[string]*coreauth.Auth, len(copied))
+	for _, auth := range copied {
+		byID[filepath.ToSlash(auth.ID)] = auth
+	}
+	var mismatches []string
+	for _, auth := range auths {
+		id := filepath.ToSlash(auth.ID)
+		dst, ok := byID[id]
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("auth %s missing in destination", id))
+			continue
+		}
+		if !sameMetadata(auth.Metadata, dst.Metadata) {
+			mismatches = append(mismatches, fmt.Sprintf("auth %s differs in destination", id))
+		}
+	}
+	return mismatches
+}
+
+func sameMetadata(a, b map[string]any) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(rawA) == string(rawB)
+}
+
+func sortAuthsByID(auths []*coreauth.Auth) {
+	sort.Slice(auths, func(i, j int) bool { return auths[i].ID < auths[j].ID })
+}
+
+// openStoreTarget opens the store described by spec. Remote stores get a temporary spool
+// directory that is removed by cleanup.
+func openStoreTarget(ctx context.Context, spec string) (*storeTarget, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("destination is empty")
+	}
+	parsed, errParse := url.Parse(spec)
+	scheme := ""
+	// Single-letter schemes are Windows drive letters, i.e. bare paths.
+	if errParse == nil && len(parsed.Scheme) > 1 {
+		scheme = strings.ToLower(parsed.Scheme)
+	}
+	switch scheme {
+	case "", "file":
+		dir := spec
+		configPath := ""
+		if scheme == "file" {
+			dir = parsed.Path
+			configPath = parsed.Query().Get("config")
+		}
+		if strings.TrimSpace(dir) == "" {
+			return nil, fmt.Errorf("file destination needs a directory")
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+		fileStore := sdkAuth.NewFileTokenStore()
+		fileStore.SetBaseDir(dir)
+		return &storeTarget{store: fileStore, configPath: configPath, cleanup: func() {}}, nil
+	}
+
+	if parsed.User != nil {
+		if _, hasPassword := parsed.User.Password(); hasPassword || scheme == "s3" || scheme == "s3+http" || scheme == "s3+https" {
+			return nil, fmt.Errorf("destination must not embed credentials; set them through the %s environment variables", storeEnvPrefix(scheme))
+		}
+	}
+	spool, err := os.MkdirTemp("", "cliproxy-migrate-*")
+	if err != nil {
+		return nil, err
+	}
+	removeSpool := func() { _ = os.RemoveAll(spool) }
+	switch scheme {
+	case "postgres", "postgresql":
+		query := parsed.Query()
+		schema := query.Get("schema")
+		query.Del("schema")
+		parsed.RawQuery = query.Encode()
+		if password := strings.TrimSpace(os.Getenv("PGSTORE_PASSWORD")); password != "" {
+			parsed.User = url.UserPassword(parsed.User.Username(), password)
+		}
+		pgStore, errOpen := store.NewPostgresStore(ctx, store.PostgresStoreConfig{DSN: parsed.String(), Schema: schema, SpoolDir: spool})
+		if errOpen != nil {
+			removeSpool()
+			return nil, errOpen
+		}
+		if errBootstrap := pgStore.Bootstrap(ctx, ""); errBootstrap != nil {
+			_ = pgStore.Close()
+			removeSpool()
+			return nil, errBootstrap
+		}
+		return &storeTarget{store: pgStore, configPath: pgStore.ConfigPath(), cleanup: func() {
+			_ = pgStore.Close()
+			removeSpool()
+		}}, nil
+	case "s3", "s3+http", "s3+https":
+		bucket, prefix, _ := strings.Cut(strings.Trim(parsed.Path, "/"), "/")
+		objectStore, errOpen := store.NewObjectTokenStore(store.ObjectStoreConfig{
+			Endpoint:  parsed.Host,
+			Bucket:    bucket,
+			AccessKey: os.Getenv("OBJECTSTORE_ACCESS_KEY"),
+			SecretKey: os.Getenv("OBJECTSTORE_SECRET_KEY"),
+			Prefix:    prefix,
+			LocalRoot: spool,
+			UseSSL:    scheme != "s3+http",
+			PathStyle: true,
+		})
+		if errOpen != nil {
+			removeSpool()
+			return nil, errOpen
+		}
+		if errBootstrap := objectStore.Bootstrap(ctx, ""); errBootstrap != nil {
+			removeSpool()
+			return nil, errBootstrap
+		}
+		return &storeTarget{store: objectStore, configPath: objectStore.ConfigPath(), cleanup: removeSpool}, nil
+	case "git+https", "git+http":
+		username := parsed.User.Username()
+		if value := strings.TrimSpace(os.Getenv("GITSTORE_GIT_USERNAME")); value != "" {
+			username = value
+		}
+		password := strings.TrimSpace(os.Getenv("GITSTORE_GIT_TOKEN"))
+		remote := *parsed
+		remote.Scheme = strings.TrimPrefix(scheme, "git+")
+		remote.User = nil
+		gitStore := store.NewGitTokenStore(remote.String(), username, password)
+		gitStore.SetBaseDir(filepath.Join(spool, "auths"))
+		if errRepo := gitStore.EnsureRepository(); errRepo != nil {
+			removeSpool()
+			return nil, errRepo
+		}
+		return &storeTarget{store: gitStore, configPath: gitStore.ConfigPath(), cleanup: removeSpool}, nil
+	}
+	removeSpool()
+	return nil, fmt.Errorf("unsupported destination scheme %q", scheme)
+}
+
+// storeEnvPrefix names the environment variable family holding credentials for scheme.
+func storeEnvPrefix(scheme string) string {
+	switch scheme {
+	case "postgres", "postgresql":
+		return "PGSTORE_*"
+	case "git+https", "git+http":
+		return "GITSTORE_*"
+	case "s3", "s3+http", "s3+https":
+		return "OBJECTSTORE_*"
+	}
+	return "store"
+}
+
+// redactStoreSpec hides credentials embedded in a destination spec.
+func redactStoreSpec(spec string) string {
+	parsed, err := url.Parse(spec)
+	if err != nil || parsed.User == nil {
+		return spec
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,63 @@
+// Package cmd contains CLI helpers. This file implements re-encrypting every auth
+// file under the primary auth encryption key, which also migrates plaintext files.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// DoRotateAuthKey re-encrypts every auth file in the auth directory with the primary
+// encryption key. Files sealed with a previous key are rewrapped and plaintext files are
+// sealed. Changed files are pushed to the remote token store when one is configured.
+func DoRotateAuthKey(cfg *config.Config) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if !authcrypt.Enabled() {
+		log.Errorf("rotate-auth-key: auth-encryption is not enabled")
+		return
+	}
+	var changed []string
+	errWalk := filepath.WalkDir(cfg.AuthDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".json") {
+			return nil
+		}
+		rewrapped, errRewrap := authcrypt.RewrapFile(path)
+		if errRewrap != nil {
+			return fmt.Errorf("%s: %w", path, errRewrap)
+		}
+		if rewrapped {
+			changed = append(changed, path)
+		}
+		return nil
+	})
+	if errWalk != nil {
+		log.Errorf("rotate-auth-key: %v", errWalk)
+		return
+	}
+	if len(changed) == 0 {
+		fmt.Println("All auth files are already encrypted with the primary key.")
+		return
+	}
+	if persister, ok := sdkAuth.GetTokenStore().(interface {
+		PersistAuthFiles(ctx context.Context, message string, paths ...string) error
+	}); ok {
+		if errPersist := persister.PersistAuthFiles(context.Background(), "Rotate auth encryption key", changed...); errPersist != nil {
+			log.Errorf("rotate-auth-key: persist changed files failed: %v", errPersist)
+			return
+		}
+	}
+	fmt.Printf("Re-encrypted %d auth file(s) with key %s\n", len(changed), authcrypt.ActiveKeyring().PrimaryKeyID())
+}
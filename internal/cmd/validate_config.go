@@ -0,0 +1,61 @@
+// Package cmd contains CLI helpers. This file implements the -validate-config dry run.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/configcheck"
+)
+
+// DoValidateConfig checks the config file at path without applying it and prints every error
+// and warning with its line number. When currentPath holds a loadable config, the changes
+// relative to it are previewed. It reports whether the file is free of errors.
+func DoValidateConfig(path, currentPath string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "validate-config: %v\n", err)
+		return false
+	}
+	var current *config.Config
+	if currentPath != "" && currentPath != path {
+		// The live file is loaded from a copy so plaintext secrets in it are not rewritten as hashes.
+		if currentData, errRead := os.ReadFile(currentPath); errRead == nil {
+			if loaded, errLoad := configcheck.Load(currentData); errLoad == nil {
+				current = loaded
+			}
+		}
+	}
+	report := configcheck.Check(data, current)
+
+	for _, issue := range append(report.Errors, report.Warnings...) {
+		location := path
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", path, issue.Line)
+		}
+		if issue.Path != "" {
+			fmt.Printf("%s: %s: %s: %s\n", location, issue.Severity, issue.Path, issue.Message)
+		} else {
+			fmt.Printf("%s: %s: %s\n", location, issue.Severity, issue.Message)
+		}
+	}
+	if preview := report.Preview; preview != nil {
+		fmt.Printf("Changes compared with %s:\n", currentPath)
+		for _, change := range preview.Changes {
+			fmt.Printf("  %s\n", change)
+		}
+		printPreviewList("added client", preview.AddedClients)
+		printPreviewList("removed client", preview.RemovedClients)
+		printPreviewList("added model", preview.AddedModels)
+		printPreviewList("removed model", preview.RemovedModels)
+	}
+	fmt.Printf("%d error(s), %d warning(s)\n", len(report.Errors), len(report.Warnings))
+	return report.Valid
+}
+
+func printPreviewList(label string, items []string) {
+	for _, item := range items {
+		fmt.Printf("  %s: %s\n", label, item)
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateConfigLeavesCurrentConfigUntouched(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "config.yaml")
+	original := "port: 8317\nremote-management:\n  secret-key: plaintext-secret\nauth-encryption:\n  export-key: plaintext-export\n"
+	if err := os.WriteFile(current, []byte(original), 0o600); err != nil {
+		t.Fatalf("write current: %v", err)
+	}
+	candidate := filepath.Join(dir, "candidate.yaml")
+	if err := os.WriteFile(candidate, []byte("port: 8318\n"), 0o600); err != nil {
+		t.Fatalf("write candidate: %v", err)
+	}
+
+	if !DoValidateConfig(candidate, current) {
+		t.Fatal("expected candidate to validate")
+	}
+	data, err := os.ReadFile(current)
+	if err != nil {
+		t.Fatalf("read current: %v", err)
+	}
+	if string(data) != original {
+		t.Fatalf("current config was rewritten:\n%s", data)
+	}
+}
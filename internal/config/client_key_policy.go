@@ -0,0 +1,121 @@
+package config
+
+import "strings"
+
+// ClientKeyPolicy restricts what a single client API key (from api-keys) may see and call.
+type ClientKeyPolicy struct {
+	// APIKey is the client API key this policy applies to.
+	APIKey string `yaml:"api-key" json:"api-key"`
+
+	// AllowedModels lists model name patterns (wildcards supported, e.g. "gemini-*") the key may use.
+	// When empty, every model is allowed unless excluded below.
+	AllowedModels []string `yaml:"allowed-models,omitempty" json:"allowed-models,omitempty"`
+
+	// ExcludedModels lists model name patterns the key may never use. Exclusions win over allowances.
+	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Prefix forces requests from this key onto credentials carrying the given model prefix
+	// (e.g., "teamA" routes "gemini-3-pro-preview" as "teamA/gemini-3-pro-preview").
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// Payload defines additional payload rules applied only to requests from this key.
+	// Default rules take precedence over global defaults; override rules are applied after global overrides.
+	Payload *PayloadConfig `yaml:"payload,omitempty" json:"payload,omitempty"`
+}
+
+// ClientKeyPolicyFor returns the policy configured for the supplied client API key, or nil when none matches.
+func (cfg *SDKConfig) ClientKeyPolicyFor(apiKey string) *ClientKeyPolicy {
+	if cfg == nil || len(cfg.ClientKeyPolicies) == 0 {
+		return nil
+	}
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return nil
+	}
+	for i := range cfg.ClientKeyPolicies {
+		if cfg.ClientKeyPolicies[i].APIKey == apiKey {
+			return &cfg.ClientKeyPolicies[i]
+		}
+	}
+	return nil
+}
+
+// AllowsModel reports whether the policy permits the given model name.
+// The forced prefix, when present, is ignored for pattern matching so patterns can name plain models.
+func (p *ClientKeyPolicy) AllowsModel(model string) bool {
+	if p == nil {
+		return true
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return false
+	}
+	candidates := []string{model}
+	if prefix := strings.ToLower(p.Prefix); prefix != "" {
+		if trimmed := strings.TrimPrefix(model, prefix+"/"); trimmed != model {
+			candidates = append(candidates, trimmed)
+		}
+	}
+	for _, pattern := range p.ExcludedModels {
+		for _, candidate := range candidates {
+			if MatchModelWildcard(pattern, candidate) {
+				return false
+			}
+		}
+	}
+	if len(p.AllowedModels) == 0 {
+		return true
+	}
+	for _, pattern := range p.AllowedModels {
+		for _, candidate := range candidates {
+			if MatchModelWildcard(pattern, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ApplyPrefix rewrites model so it targets credentials carrying the policy prefix.
+// Models that already carry the prefix, in any letter case, are returned unchanged.
+func (p *ClientKeyPolicy) ApplyPrefix(model string) string {
+	if p == nil || p.Prefix == "" {
+		return model
+	}
+	model = strings.TrimSpace(model)
+	if model == "" || strings.HasPrefix(strings.ToLower(model), strings.ToLower(p.Prefix)+"/") {
+		return model
+	}
+	return p.Prefix + "/" + model
+}
+
+// SanitizeClientKeyPolicies normalizes client key policies, dropping entries without an API key
+// and keeping only the first policy for each key.
+func (cfg *SDKConfig) SanitizeClientKeyPolicies() {
+	if cfg == nil || len(cfg.ClientKeyPolicies) == 0 {
+		return
+	}
+	seen := make(map[string]struct{}, len(cfg.ClientKeyPolicies))
+	out := make([]ClientKeyPolicy, 0, len(cfg.ClientKeyPolicies))
+	for i := range cfg.ClientKeyPolicies {
+		entry := cfg.ClientKeyPolicies[i]
+		entry.APIKey = strings.TrimSpace(entry.APIKey)
+		if entry.APIKey == "" {
+			continue
+		}
+		if _, exists := seen[entry.APIKey]; exists {
+			continue
+		}
+		seen[entry.APIKey] = struct{}{}
+		entry.AllowedModels = NormalizeExcludedModels(entry.AllowedModels)
+		entry.ExcludedModels = NormalizeExcludedModels(entry.ExcludedModels)
+		entry.Prefix = normalizeModelPrefix(entry.Prefix)
+		if entry.Payload != nil {
+			entry.Payload.DefaultRaw = sanitizePayloadRawRules(entry.Payload.DefaultRaw, "client-key-policies.payload.default-raw")
+			entry.Payload.OverrideRaw = sanitizePayloadRawRules(entry.Payload.OverrideRaw, "client-key-policies.payload.override-raw")
+			entry.Payload.Scripts = sanitizePayloadScriptRules(entry.Payload.Scripts, "client-key-policies.payload.scripts")
+		}
+		out = append(out, entry)
+	}
+	cfg.ClientKeyPolicies = out
+}
@@ -0,0 +1,91 @@
+package config
+
+import "testing"
+
+func TestSanitizeClientKeyPolicies_DropsEmptyAndDuplicateKeys(t *testing.T) {
+	cfg := &SDKConfig{
+		ClientKeyPolicies: []ClientKeyPolicy{
+			{APIKey: " "},
+			{APIKey: " team-key ", AllowedModels: []string{" Gemini-* ", "gemini-*"}, Prefix: " /teamA/ "},
+			{APIKey: "team-key", AllowedModels: []string{"gpt-*"}},
+		},
+	}
+
+	cfg.SanitizeClientKeyPolicies()
+
+	if len(cfg.ClientKeyPolicies) != 1 {
+		t.Fatalf("expected 1 policy, got %d", len(cfg.ClientKeyPolicies))
+	}
+	policy := cfg.ClientKeyPolicies[0]
+	if policy.APIKey != "team-key" {
+		t.Fatalf("expected trimmed api key, got %q", policy.APIKey)
+	}
+	if len(policy.AllowedModels) != 1 || policy.AllowedModels[0] != "gemini-*" {
+		t.Fatalf("expected normalized allowed models, got %v", policy.AllowedModels)
+	}
+	if policy.Prefix != "teamA" {
+		t.Fatalf("expected normalized prefix, got %q", policy.Prefix)
+	}
+}
+
+func TestClientKeyPolicy_AllowsModel(t *testing.T) {
+	policy := &ClientKeyPolicy{
+		AllowedModels:  []string{"gemini-*", "claude-sonnet-*"},
+		ExcludedModels: []string{"*-preview"},
+		Prefix:         "teamA",
+	}
+
+	cases := []struct {
+		model string
+		want  bool
+	}{
+		{"gemini-2.5-pro", true},
+		{"teamA/gemini-2.5-pro", true},
+		{"Claude-Sonnet-4-5", true},
+		{"gemini-3-pro-preview", false},
+		{"claude-opus-4-5", false},
+		{"", false},
+	}
+	for _, tc := range cases {
+		if got := policy.AllowsModel(tc.model); got != tc.want {
+			t.Fatalf("AllowsModel(%q) = %v, want %v", tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestClientKeyPolicy_ApplyPrefix(t *testing.T) {
+	policy := &ClientKeyPolicy{Prefix: "teamA"}
+	if got := policy.ApplyPrefix("gemini-2.5-pro"); got != "teamA/gemini-2.5-pro" {
+		t.Fatalf("expected prefixed model, got %q", got)
+	}
+	if got := policy.ApplyPrefix("teamA/gemini-2.5-pro"); got != "teamA/gemini-2.5-pro" {
+		t.Fatalf("expected model unchanged, got %q", got)
+	}
+	if got := policy.ApplyPrefix("TEAMA/gemini-2.5-pro"); got != "TEAMA/gemini-2.5-pro" {
+		t.Fatalf("expected prefix match to ignore case, got %q", got)
+	}
+	var nilPolicy *ClientKeyPolicy
+	if got := nilPolicy.ApplyPrefix("gemini-2.5-pro"); got != "gemini-2.5-pro" {
+		t.Fatalf("expected nil policy to keep model, got %q", got)
+	}
+}
+
+func TestMatchModelWildcard(t *testing.T) {
+	cases := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"gemini-2.5-pro", "Gemini-2.5-Pro", true},
+		{"GPT-*", "gpt-5", true},
+		{"*-mini", "o4-mini", true},
+		{"claude-*-sonnet*", "claude-3-7-sonnet-latest", true},
+		{"claude-*-opus", "claude-3-7-sonnet", false},
+		{"", "anything", false},
+	}
+	for _, tc := range cases {
+		if got := MatchModelWildcard(tc.pattern, tc.value); got != tc.want {
+			t.Fatalf("MatchModelWildcard(%q, %q) = %v, want %v", tc.pattern, tc.value, got, tc.want)
+		}
+	}
+}
@@ -41,6 +41,9 @@ type Config struct {
 	// AuthDir is the directory where authentication token files are stored.
 	AuthDir string `yaml:"auth-dir" json:"-"`
 
+	// AuthEncryption configures envelope encryption of auth files at rest.
+	AuthEncryption AuthEncryptionConfig `yaml:"auth-encryption,omitempty" json:"-"`
+
 	// Debug enables or disables debug-level logging and other debug features.
 	Debug bool `yaml:"debug" json:"debug"`
 
@@ -78,6 +81,33 @@ type Config struct {
 	// Routing controls credential selection behavior.
 	Routing RoutingConfig `yaml:"routing" json:"routing"`
 
+	// CircuitBreaker short-circuits openai-compatibility upstreams that keep failing.
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit-breaker,omitempty" json:"circuit-breaker,omitempty"`
+
+	// Hedging sends a duplicate non-streaming request to another credential when the first is slow.
+	Hedging HedgingConfig `yaml:"hedging,omitempty" json:"hedging,omitempty"`
+
+	// Tracing exports OpenTelemetry spans for request handling to an OTLP collector.
+	Tracing TracingConfig `yaml:"tracing,omitempty" json:"tracing,omitempty"`
+
+	// HealthCheck periodically probes every enabled credential with a minimal synthetic request.
+	HealthCheck HealthCheckConfig `yaml:"health-check,omitempty" json:"health-check,omitempty"`
+
+	// Transport tunes the upstream HTTP connection pool. Credentials may override it.
+	Transport TransportConfig `yaml:"transport,omitempty" json:"transport,omitempty"`
+
+	// StreamBridge pins the upstream call mode (streaming or not) per provider and model.
+	StreamBridge []StreamBridgeRule `yaml:"stream-bridge,omitempty" json:"stream-bridge,omitempty"`
+
+	// WebTools emulates the built-in web search and fetch tools for backends without them.
+	WebTools WebToolsConfig `yaml:"web-tools,omitempty" json:"web-tools,omitempty"`
+
+	// SignatureCache selects where thinking signatures are kept across restarts and replicas.
+	SignatureCache SignatureCacheConfig `yaml:"signature-cache,omitempty" json:"signature-cache,omitempty"`
+
+	// Plugins launches out-of-process executors that serve additional providers.
+	Plugins []ExecutorPlugin `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+
 	// WebsocketAuth enables or disables authentication for the WebSocket API.
 	WebsocketAuth bool `yaml:"ws-auth" json:"ws-auth"`
 
@@ -118,6 +148,9 @@ type Config struct {
 	// Payload defines default and override rules for provider payload parameters.
 	Payload PayloadConfig `yaml:"payload" json:"payload"`
 
+	// Redaction removes secrets and personal data from upstream requests.
+	Redaction RedactionConfig `yaml:"redaction,omitempty" json:"redaction,omitempty"`
+
 	legacyMigrationPending bool `yaml:"-" json:"-"`
 }
 
@@ -176,6 +209,62 @@ type RoutingConfig struct {
 	// Strategy selects the credential selection strategy.
 	// Supported values: "round-robin" (default), "fill-first".
 	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// SessionAffinity keeps consecutive turns of a conversation on the same credential.
+	SessionAffinity SessionAffinityConfig `yaml:"session-affinity,omitempty" json:"session-affinity,omitempty"`
+}
+
+// SessionAffinityConfig configures session-sticky credential selection.
+// Sessions are identified by explicit client identifiers (X-Session-ID / Session_id headers,
+// Claude metadata.user_id, OpenAI prompt_cache_key or user) or, optionally, by a hash of the
+// conversation prefix. A pinned credential is abandoned as soon as it enters cooldown.
+type SessionAffinityConfig struct {
+	// Enable toggles session affinity.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// TTLSeconds controls how long an idle session stays pinned. Defaults to 3600.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+
+	// HashConversationPrefix derives a session key from the system prompt and first message
+	// when the client supplies no explicit session identifier.
+	HashConversationPrefix bool `yaml:"hash-conversation-prefix,omitempty" json:"hash-conversation-prefix,omitempty"`
+}
+
+// AuthEncryptionConfig configures envelope encryption for auth files in every token store.
+// The key-encryption key may also be supplied through the CLIPROXY_AUTH_ENCRYPTION_KEY or
+// CLIPROXY_AUTH_ENCRYPTION_KEY_FILE environment variables, which take precedence.
+type AuthEncryptionConfig struct {
+	// Enable requires auth files to be encrypted; startup fails when no key is available.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// KeyFile points to a file holding the 32-byte key (raw, base64, or hex).
+	KeyFile string `yaml:"key-file,omitempty" json:"key-file,omitempty"`
+
+	// PreviousKeyFiles lists retired keys that remain valid for decryption during rotation.
+	PreviousKeyFiles []string `yaml:"previous-key-files,omitempty" json:"previous-key-files,omitempty"`
+
+	// AllowDecryptedExport permits /auth-files/download?scope=decrypted to return plaintext.
+	AllowDecryptedExport bool `yaml:"allow-decrypted-export,omitempty" json:"allow-decrypted-export,omitempty"`
+
+	// ExportKey is the credential (plaintext or bcrypt hashed) a decrypted export must present
+	// in the X-Export-Key header in addition to the management key. Decrypted export stays
+	// disabled while it is empty.
+	ExportKey string `yaml:"export-key,omitempty" json:"-"`
+}
+
+// CircuitBreakerConfig configures the provider-level circuit breaker for openai-compatibility
+// upstreams. Breakers are keyed on base URL, so every credential sharing an endpoint trips together.
+type CircuitBreakerConfig struct {
+	// Enable toggles the circuit breaker.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// FailureThreshold is the number of consecutive connection errors or 5xx responses that
+	// opens the breaker. Defaults to 5.
+	FailureThreshold int `yaml:"failure-threshold,omitempty" json:"failure-threshold,omitempty"`
+
+	// OpenSeconds is how long an open breaker rejects requests before letting a single probe
+	// request through (half-open). Defaults to 30.
+	OpenSeconds int `yaml:"open-seconds,omitempty" json:"open-seconds,omitempty"`
 }
 
 // OAuthModelAlias defines a model ID alias for a specific channel.
@@ -257,6 +346,8 @@ type PayloadConfig struct {
 	OverrideRaw []PayloadRule `yaml:"override-raw" json:"override-raw"`
 	// Filter defines rules that remove parameters from the payload by JSON path.
 	Filter []PayloadFilterRule `yaml:"filter" json:"filter"`
+	// Scripts defines transform scripts run on upstream requests and response payloads.
+	Scripts []PayloadScriptRule `yaml:"scripts,omitempty" json:"scripts,omitempty"`
 }
 
 // PayloadFilterRule describes a rule to remove specific JSON paths from matching model payloads.
@@ -334,6 +425,9 @@ type ClaudeKey struct {
 
 	// Cloak configures request cloaking for non-Claude-Code clients.
 	Cloak *CloakConfig `yaml:"cloak,omitempty" json:"cloak,omitempty"`
+
+	// Transport overrides the global upstream connection pool settings for this key.
+	Transport *TransportConfig `yaml:"transport,omitempty" json:"transport,omitempty"`
 }
 
 func (k ClaudeKey) GetAPIKey() string  { return k.APIKey }
@@ -382,6 +476,9 @@ type CodexKey struct {
 
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Transport overrides the global upstream connection pool settings for this key.
+	Transport *TransportConfig `yaml:"transport,omitempty" json:"transport,omitempty"`
 }
 
 func (k CodexKey) GetAPIKey() string  { return k.APIKey }
@@ -426,6 +523,9 @@ type GeminiKey struct {
 
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Transport overrides the global upstream connection pool settings for this key.
+	Transport *TransportConfig `yaml:"transport,omitempty" json:"transport,omitempty"`
 }
 
 func (k GeminiKey) GetAPIKey() string  { return k.APIKey }
@@ -465,8 +565,22 @@ type OpenAICompatibility struct {
 	// Models defines the model configurations including aliases for routing.
 	Models []OpenAICompatibilityModel `yaml:"models" json:"models"`
 
+	// DiscoverModels periodically lists the upstream GET /models endpoint and serves every
+	// model it returns in addition to the configured aliases.
+	DiscoverModels bool `yaml:"discover-models,omitempty" json:"discover-models,omitempty"`
+
+	// DiscoverIntervalSeconds is the time between upstream model listings. Defaults to 3600.
+	DiscoverIntervalSeconds int `yaml:"discover-interval-seconds,omitempty" json:"discover-interval-seconds,omitempty"`
+
+	// ExcludedModels lists model IDs that should be excluded for this provider.
+	// Wildcards are supported and apply to discovered models as well.
+	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
 	// Headers optionally adds extra HTTP headers for requests sent to this provider.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// Transport overrides the global upstream connection pool settings for this provider.
+	Transport *TransportConfig `yaml:"transport,omitempty" json:"transport,omitempty"`
 }
 
 // OpenAICompatibilityAPIKey represents an API key configuration with optional proxy setting.
@@ -587,6 +701,16 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
 	}
 
+	// Hash the decrypted export key the same way.
+	if cfg.AuthEncryption.ExportKey != "" && !looksLikeBcrypt(cfg.AuthEncryption.ExportKey) {
+		hashed, errHash := hashSecret(cfg.AuthEncryption.ExportKey)
+		if errHash != nil {
+			return nil, fmt.Errorf("failed to hash auth export key: %w", errHash)
+		}
+		cfg.AuthEncryption.ExportKey = hashed
+		_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"auth-encryption", "export-key"}, hashed)
+	}
+
 	cfg.RemoteManagement.PanelGitHubRepository = strings.TrimSpace(cfg.RemoteManagement.PanelGitHubRepository)
 	if cfg.RemoteManagement.PanelGitHubRepository == "" {
 		cfg.RemoteManagement.PanelGitHubRepository = DefaultPanelGitHubRepository
@@ -620,6 +744,9 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Sanitize OpenAI compatibility providers: drop entries without base-url
 	cfg.SanitizeOpenAICompatibility()
 
+	// Sanitize executor plugins: drop entries without a name or launch target
+	cfg.SanitizeExecutorPlugins()
+
 	// Normalize OAuth provider model exclusion map.
 	cfg.OAuthExcludedModels = NormalizeOAuthExcludedModels(cfg.OAuthExcludedModels)
 
@@ -629,6 +756,24 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Validate raw payload rules and drop invalid entries.
 	cfg.SanitizePayloadRules()
 
+	// Validate redaction detectors and drop invalid entries.
+	cfg.SanitizeRedaction()
+
+	// Normalize upstream transport settings.
+	cfg.SanitizeTransport()
+
+	// Validate stream bridge rules and drop invalid entries.
+	cfg.SanitizeStreamBridge()
+
+	// Apply web tool emulation defaults.
+	cfg.SanitizeWebTools()
+
+	// Normalize client key policies.
+	cfg.SanitizeClientKeyPolicies()
+
+	// Normalize weighted model splits.
+	cfg.SanitizeModelSplits()
+
 	// NOTE: Legacy migration persistence is intentionally disabled together with
 	// startup legacy migration to keep startup read-only for config.yaml.
 	// Re-enable the block below if automatic startup migration is needed again.
@@ -648,13 +793,14 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	return &cfg, nil
 }
 
-// SanitizePayloadRules validates raw JSON payload rule params and drops invalid rules.
+// SanitizePayloadRules validates raw JSON payload rule params and payload scripts and drops invalid rules.
 func (cfg *Config) SanitizePayloadRules() {
 	if cfg == nil {
 		return
 	}
 	cfg.Payload.DefaultRaw = sanitizePayloadRawRules(cfg.Payload.DefaultRaw, "default-raw")
 	cfg.Payload.OverrideRaw = sanitizePayloadRawRules(cfg.Payload.OverrideRaw, "override-raw")
+	cfg.Payload.Scripts = sanitizePayloadScriptRules(cfg.Payload.Scripts, "scripts")
 }
 
 func sanitizePayloadRawRules(rules []PayloadRule, section string) []PayloadRule {
@@ -755,6 +901,10 @@ func (cfg *Config) SanitizeOpenAICompatibility() {
 		e.Prefix = normalizeModelPrefix(e.Prefix)
 		e.BaseURL = strings.TrimSpace(e.BaseURL)
 		e.Headers = NormalizeHeaders(e.Headers)
+		e.ExcludedModels = NormalizeExcludedModels(e.ExcludedModels)
+		if e.DiscoverIntervalSeconds < 0 {
+			e.DiscoverIntervalSeconds = 0
+		}
 		if e.BaseURL == "" {
 			// Skip providers with no base-url; treated as removed
 			continue
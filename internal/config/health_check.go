@@ -0,0 +1,47 @@
+package config
+
+import "strings"
+
+// HealthCheckConfig configures scheduled health-check probes. Each enabled credential
+// periodically receives a minimal synthetic request pinned to it, so revoked or suspended
+// accounts are demoted before a real request lands on them.
+type HealthCheckConfig struct {
+	// Enable toggles the probe scheduler.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// IntervalSeconds is the time between probes of the same credential. Defaults to 1800.
+	IntervalSeconds int `yaml:"interval-seconds,omitempty" json:"interval-seconds,omitempty"`
+
+	// TimeoutSeconds bounds a single probe. Defaults to 60.
+	TimeoutSeconds int `yaml:"timeout-seconds,omitempty" json:"timeout-seconds,omitempty"`
+
+	// Concurrency caps how many probes run at the same time. Defaults to 2.
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// Providers overrides the probe model and interval per provider, keyed by provider name
+	// (e.g. "claude", "gemini-cli", "codex").
+	Providers map[string]HealthCheckProviderConfig `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// HealthCheckProviderConfig overrides health-check settings for one provider.
+type HealthCheckProviderConfig struct {
+	// Disable skips probing credentials of this provider.
+	Disable bool `yaml:"disable,omitempty" json:"disable,omitempty"`
+
+	// Model is the model probed. When empty, the cheapest-looking model the credential serves is used.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+
+	// IntervalSeconds overrides the global probe interval for this provider.
+	IntervalSeconds int `yaml:"interval-seconds,omitempty" json:"interval-seconds,omitempty"`
+}
+
+// ForProvider returns the override for provider, matched case-insensitively.
+func (h HealthCheckConfig) ForProvider(provider string) HealthCheckProviderConfig {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for name, override := range h.Providers {
+		if strings.ToLower(strings.TrimSpace(name)) == provider {
+			return override
+		}
+	}
+	return HealthCheckProviderConfig{}
+}
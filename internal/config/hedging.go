@@ -0,0 +1,45 @@
+package config
+
+import "strings"
+
+// HedgingConfig configures hedged non-streaming requests. When the first attempt has not
+// answered within the observed latency percentile, a second attempt is sent to a different
+// credential; the first success wins and the other attempt is cancelled.
+type HedgingConfig struct {
+	// Enable toggles request hedging.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// Models limits hedging to matching client model names (supports '*' wildcards).
+	// When empty, every non-streaming request is eligible.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Percentile of recent successful latencies used as the hedge delay. Defaults to 95.
+	Percentile float64 `yaml:"percentile,omitempty" json:"percentile,omitempty"`
+
+	// MinDelayMS is the lower bound for the hedge delay in milliseconds. Defaults to 50.
+	MinDelayMS int `yaml:"min-delay-ms,omitempty" json:"min-delay-ms,omitempty"`
+
+	// MaxDelayMS is the upper bound for the hedge delay in milliseconds, also used until enough
+	// latency samples exist. Defaults to 2000.
+	MaxDelayMS int `yaml:"max-delay-ms,omitempty" json:"max-delay-ms,omitempty"`
+
+	// BudgetRatio caps hedged attempts as a fraction of eligible requests. Defaults to 0.1.
+	BudgetRatio float64 `yaml:"budget-ratio,omitempty" json:"budget-ratio,omitempty"`
+}
+
+// AppliesTo reports whether requests for model are eligible for hedging.
+func (h HedgingConfig) AppliesTo(model string) bool {
+	if !h.Enable {
+		return false
+	}
+	if len(h.Models) == 0 {
+		return true
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, pattern := range h.Models {
+		if MatchModelWildcard(pattern, model) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+)
+
+const (
+	// ModelSplitStickyNone picks a target independently for every request.
+	ModelSplitStickyNone = "none"
+	// ModelSplitStickyClientKey keeps every request of a client API key on the same target.
+	ModelSplitStickyClientKey = "client-key"
+	// ModelSplitStickyConversation keeps every request of a conversation on the same target.
+	ModelSplitStickyConversation = "conversation"
+)
+
+// ModelSplit routes a client-visible alias to several upstream models by weight,
+// e.g. to canary a new model on a fraction of traffic.
+type ModelSplit struct {
+	// Alias is the client-facing model name that triggers the split.
+	Alias string `yaml:"alias" json:"alias"`
+
+	// Sticky controls how targets are assigned: "none", "client-key" (default), or "conversation".
+	Sticky string `yaml:"sticky,omitempty" json:"sticky,omitempty"`
+
+	// Targets lists the upstream models and their relative weights.
+	Targets []ModelSplitTarget `yaml:"targets" json:"targets"`
+}
+
+// ModelSplitTarget is a single weighted arm of a ModelSplit.
+type ModelSplitTarget struct {
+	// Model is the model name requests are rewritten to when this arm is chosen.
+	Model string `yaml:"model" json:"model"`
+
+	// Weight is the relative share of traffic for this arm; values <= 0 disable the arm.
+	Weight int `yaml:"weight" json:"weight"`
+}
+
+// ModelSplitFor returns the split configured for alias (case-insensitive), or nil when none matches.
+func (cfg *SDKConfig) ModelSplitFor(alias string) *ModelSplit {
+	if cfg == nil || len(cfg.ModelSplits) == 0 {
+		return nil
+	}
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return nil
+	}
+	for i := range cfg.ModelSplits {
+		if strings.EqualFold(cfg.ModelSplits[i].Alias, alias) {
+			return &cfg.ModelSplits[i]
+		}
+	}
+	return nil
+}
+
+// Pick chooses a target model. A non-empty stickyKey always maps to the same target
+// for a given target list; an empty key picks randomly by weight.
+func (s *ModelSplit) Pick(stickyKey string) string {
+	if s == nil || len(s.Targets) == 0 {
+		return ""
+	}
+	total := 0
+	for _, target := range s.Targets {
+		total += target.Weight
+	}
+	if total <= 0 {
+		return ""
+	}
+	var point int
+	if stickyKey == "" {
+		point = rand.IntN(total)
+	} else {
+		hasher := fnv.New64a()
+		_, _ = hasher.Write([]byte(strings.ToLower(s.Alias)))
+		_, _ = hasher.Write([]byte{0})
+		_, _ = hasher.Write([]byte(stickyKey))
+		point = int(hasher.Sum64() % uint64(total))
+	}
+	for _, target := range s.Targets {
+		if point < target.Weight {
+			return target.Model
+		}
+		point -= target.Weight
+	}
+	return s.Targets[len(s.Targets)-1].Model
+}
+
+// SanitizeModelSplits normalizes weighted model splits. It drops arms without a model or
+// with a non-positive weight, drops splits without an alias or arms, and keeps only the
+// first split for each alias.
+func (cfg *SDKConfig) SanitizeModelSplits() {
+	if cfg == nil || len(cfg.ModelSplits) == 0 {
+		return
+	}
+	seen := make(map[string]struct{}, len(cfg.ModelSplits))
+	out := make([]ModelSplit, 0, len(cfg.ModelSplits))
+	for i := range cfg.ModelSplits {
+		entry := cfg.ModelSplits[i]
+		entry.Alias = strings.TrimSpace(entry.Alias)
+		if entry.Alias == "" {
+			continue
+		}
+		key := strings.ToLower(entry.Alias)
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		switch sticky := strings.ToLower(strings.TrimSpace(entry.Sticky)); sticky {
+		case ModelSplitStickyNone, ModelSplitStickyConversation:
+			entry.Sticky = sticky
+		default:
+			entry.Sticky = ModelSplitStickyClientKey
+		}
+		targets := make([]ModelSplitTarget, 0, len(entry.Targets))
+		for _, target := range entry.Targets {
+			target.Model = strings.TrimSpace(target.Model)
+			if target.Model == "" || target.Weight <= 0 || strings.EqualFold(target.Model, entry.Alias) {
+				continue
+			}
+			targets = append(targets, target)
+		}
+		if len(targets) == 0 {
+			continue
+		}
+		entry.Targets = targets
+		seen[key] = struct{}{}
+		out = append(out, entry)
+	}
+	cfg.ModelSplits = out
+}
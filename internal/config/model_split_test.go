@@ -0,0 +1,66 @@
+package config
+
+import "testing"
+
+func TestSanitizeModelSplits(t *testing.T) {
+	cfg := &SDKConfig{
+		ModelSplits: []ModelSplit{
+			{Alias: " coder ", Sticky: "Conversation", Targets: []ModelSplitTarget{
+				{Model: " claude-sonnet-4-5 ", Weight: 90},
+				{Model: "gpt-5-codex", Weight: 10},
+				{Model: "disabled", Weight: 0},
+				{Model: "coder", Weight: 5},
+			}},
+			{Alias: "CODER", Targets: []ModelSplitTarget{{Model: "gpt-5", Weight: 1}}},
+			{Alias: "empty", Targets: []ModelSplitTarget{{Model: "", Weight: 1}}},
+		},
+	}
+
+	cfg.SanitizeModelSplits()
+
+	if len(cfg.ModelSplits) != 1 {
+		t.Fatalf("expected 1 split, got %d", len(cfg.ModelSplits))
+	}
+	split := cfg.ModelSplits[0]
+	if split.Alias != "coder" || split.Sticky != ModelSplitStickyConversation {
+		t.Fatalf("unexpected split header: alias=%q sticky=%q", split.Alias, split.Sticky)
+	}
+	if len(split.Targets) != 2 || split.Targets[0].Model != "claude-sonnet-4-5" {
+		t.Fatalf("unexpected targets: %+v", split.Targets)
+	}
+}
+
+func TestModelSplitPick_StickyAndWeighted(t *testing.T) {
+	split := &ModelSplit{Alias: "coder", Targets: []ModelSplitTarget{
+		{Model: "a", Weight: 90},
+		{Model: "b", Weight: 10},
+	}}
+
+	first := split.Pick("client-1")
+	for i := 0; i < 20; i++ {
+		if got := split.Pick("client-1"); got != first {
+			t.Fatalf("expected sticky assignment %q, got %q", first, got)
+		}
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		counts[split.Pick(string(rune('a'+i%26))+string(rune(i)))]++
+	}
+	if counts["a"] < counts["b"] {
+		t.Fatalf("expected heavier arm to receive more traffic, got %v", counts)
+	}
+	if counts["a"]+counts["b"] != 2000 {
+		t.Fatalf("expected all picks to land on configured arms, got %v", counts)
+	}
+}
+
+func TestSDKConfigModelSplitFor(t *testing.T) {
+	cfg := &SDKConfig{ModelSplits: []ModelSplit{{Alias: "Coder", Targets: []ModelSplitTarget{{Model: "a", Weight: 1}}}}}
+	if cfg.ModelSplitFor("coder") == nil {
+		t.Fatal("expected case-insensitive alias lookup")
+	}
+	if cfg.ModelSplitFor("other") != nil {
+		t.Fatal("expected no split for unknown alias")
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/payloadscript"
+	log "github.com/sirupsen/logrus"
+)
+
+// PayloadScriptRule attaches transform scripts to matching models. The request script runs on
+// the translated upstream request after the static payload rules; the response script runs on
+// every response chunk (or the whole non-streaming response) in the client's format. The
+// language is documented in internal/payloadscript.
+type PayloadScriptRule struct {
+	// Models lists model entries with name pattern and protocol constraint. For response
+	// scripts the protocol is the client's format (e.g. "openai", "claude").
+	Models []PayloadModelRule `yaml:"models" json:"models"`
+	// Request is the script applied to upstream requests.
+	Request string `yaml:"request,omitempty" json:"request,omitempty"`
+	// Response is the script applied to response payloads.
+	Response string `yaml:"response,omitempty" json:"response,omitempty"`
+	// TimeoutMs bounds each script run. Defaults to 50ms.
+	TimeoutMs int `yaml:"timeout-ms,omitempty" json:"timeout-ms,omitempty"`
+}
+
+// sanitizePayloadScriptRules drops rules without models, without scripts, or whose scripts
+// fail to compile.
+func sanitizePayloadScriptRules(rules []PayloadScriptRule, section string) []PayloadScriptRule {
+	if len(rules) == 0 {
+		return rules
+	}
+	out := make([]PayloadScriptRule, 0, len(rules))
+	for i := range rules {
+		rule := rules[i]
+		rule.Request = strings.TrimSpace(rule.Request)
+		rule.Response = strings.TrimSpace(rule.Response)
+		if rule.TimeoutMs < 0 {
+			rule.TimeoutMs = 0
+		}
+		if len(rule.Models) == 0 || (rule.Request == "" && rule.Response == "") {
+			continue
+		}
+		valid := true
+		for phase, src := range map[string]string{"request": rule.Request, "response": rule.Response} {
+			if src == "" {
+				continue
+			}
+			if _, err := payloadscript.CompileCached(src); err != nil {
+				log.WithFields(log.Fields{
+					"section":    section,
+					"rule_index": i + 1,
+					"phase":      phase,
+				}).Warnf("payload script rule dropped: %v", err)
+				valid = false
+			}
+		}
+		if valid {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+// PayloadScriptsFor returns the script rules matching protocol and any of models, global rules
+// first followed by the rules of the client key's policy.
+func (cfg *Config) PayloadScriptsFor(clientKey, protocol string, models ...string) []PayloadScriptRule {
+	if cfg == nil {
+		return nil
+	}
+	rules := cfg.Payload.Scripts
+	if policy := cfg.ClientKeyPolicyFor(clientKey); policy != nil && policy.Payload != nil && len(policy.Payload.Scripts) > 0 {
+		rules = append(append([]PayloadScriptRule(nil), rules...), policy.Payload.Scripts...)
+	}
+	var out []PayloadScriptRule
+	for i := range rules {
+		if payloadScriptRuleMatches(rules[i].Models, protocol, models) {
+			out = append(out, rules[i])
+		}
+	}
+	return out
+}
+
+func payloadScriptRuleMatches(entries []PayloadModelRule, protocol string, models []string) bool {
+	for _, model := range models {
+		model = strings.ToLower(strings.TrimSpace(model))
+		if model == "" {
+			continue
+		}
+		for _, entry := range entries {
+			if ep := strings.TrimSpace(entry.Protocol); ep != "" && protocol != "" && !strings.EqualFold(ep, protocol) {
+				continue
+			}
+			if MatchModelWildcard(entry.Name, model) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package config
+
+import "strings"
+
+// ExecutorPlugin configures an out-of-process provider executor. The plugin is launched
+// from Command (or reached at Socket) and serves the provider key Name with the protocol
+// documented in sdk/cliproxy/plugin.
+type ExecutorPlugin struct {
+	// Name is the provider key of the plugin. It must not collide with a built-in provider.
+	Name string `yaml:"name" json:"name"`
+
+	// Command is the executable to launch; the protocol runs over its stdin and stdout.
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+
+	// Args are passed to Command.
+	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
+
+	// Env adds environment variables for Command.
+	Env map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// Socket is the unix socket path of an already running plugin. It takes precedence over Command.
+	Socket string `yaml:"socket,omitempty" json:"socket,omitempty"`
+
+	// Prefix optionally namespaces the plugin's models (e.g., "internal/model-x").
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// Priority controls selection preference when multiple providers or credentials match.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// ExcludedModels lists model IDs (wildcards supported) that are not served from this plugin.
+	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Accounts defines the credentials of the plugin. Each account becomes its own auth
+	// record; without accounts a single credential without attributes is created.
+	Accounts []ExecutorPluginAccount `yaml:"accounts,omitempty" json:"accounts,omitempty"`
+}
+
+// ExecutorPluginAccount is one credential served by a plugin.
+type ExecutorPluginAccount struct {
+	// Label is shown in management views and logs.
+	Label string `yaml:"label,omitempty" json:"label,omitempty"`
+
+	// Attributes are handed to the plugin with every call (e.g. an API key or endpoint).
+	Attributes map[string]string `yaml:"attributes,omitempty" json:"attributes,omitempty"`
+
+	// ProxyURL is handed to the plugin for its upstream connections.
+	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+}
+
+// SanitizeExecutorPlugins lowercases plugin names and drops entries without a name, without
+// a command or socket, or repeating an earlier name.
+func (cfg *Config) SanitizeExecutorPlugins() {
+	if cfg == nil || len(cfg.Plugins) == 0 {
+		return
+	}
+	seen := make(map[string]struct{}, len(cfg.Plugins))
+	out := make([]ExecutorPlugin, 0, len(cfg.Plugins))
+	for i := range cfg.Plugins {
+		entry := cfg.Plugins[i]
+		entry.Name = strings.ToLower(strings.TrimSpace(entry.Name))
+		entry.Command = strings.TrimSpace(entry.Command)
+		entry.Socket = strings.TrimSpace(entry.Socket)
+		entry.Prefix = normalizeModelPrefix(entry.Prefix)
+		entry.ExcludedModels = NormalizeExcludedModels(entry.ExcludedModels)
+		if entry.Name == "" || (entry.Command == "" && entry.Socket == "") {
+			continue
+		}
+		if _, exists := seen[entry.Name]; exists {
+			continue
+		}
+		seen[entry.Name] = struct{}{}
+		out = append(out, entry)
+	}
+	cfg.Plugins = out
+}
+
+// PluginByName returns the plugin serving provider, if any.
+func (cfg *Config) PluginByName(provider string) *ExecutorPlugin {
+	if cfg == nil {
+		return nil
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for i := range cfg.Plugins {
+		if cfg.Plugins[i].Name == provider {
+			return &cfg.Plugins[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/redaction"
+	log "github.com/sirupsen/logrus"
+)
+
+// RedactionConfig scans translated upstream requests for secrets and personal data before
+// they leave the proxy. Every rule blocks the request, masks the match, or replaces it with
+// a token that is restored in the response.
+type RedactionConfig struct {
+	// Enable toggles the redaction stage.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// Detectors maps built-in detector names (aws-key, jwt, private-key, email) to an action
+	// (block, mask, tokenize). Detectors not listed or without an action are disabled.
+	Detectors map[string]string `yaml:"detectors,omitempty" json:"detectors,omitempty"`
+
+	// Patterns defines custom detectors.
+	Patterns []RedactionPattern `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+}
+
+// RedactionPattern is a custom detector matched with a Go regular expression.
+type RedactionPattern struct {
+	// Name identifies the detector in tokens, errors and the request log.
+	Name string `yaml:"name" json:"name"`
+
+	// Pattern is the regular expression to match.
+	Pattern string `yaml:"pattern" json:"pattern"`
+
+	// Action is one of block, mask, or tokenize. Defaults to mask.
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
+}
+
+// SanitizeRedaction normalizes detector names, actions and custom patterns and validates
+// them. Invalid entries are kept and logged: redaction fails closed, so requests are rejected
+// until the configuration is fixed rather than sent without the broken detector.
+func (cfg *Config) SanitizeRedaction() {
+	if cfg == nil {
+		return
+	}
+	r := &cfg.Redaction
+	if len(r.Detectors) > 0 {
+		detectors := make(map[string]string, len(r.Detectors))
+		for name, action := range r.Detectors {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if parsed, ok := redaction.ParseAction(action); ok {
+				action = string(parsed)
+			}
+			detectors[name] = action
+		}
+		r.Detectors = detectors
+	}
+	if len(r.Patterns) > 0 {
+		out := make([]RedactionPattern, 0, len(r.Patterns))
+		for i := range r.Patterns {
+			entry := r.Patterns[i]
+			entry.Name = strings.ToLower(strings.TrimSpace(entry.Name))
+			if entry.Name == "" && strings.TrimSpace(entry.Pattern) == "" {
+				continue
+			}
+			if strings.TrimSpace(entry.Action) == "" {
+				entry.Action = string(redaction.ActionMask)
+			}
+			if action, ok := redaction.ParseAction(entry.Action); ok {
+				entry.Action = string(action)
+			}
+			out = append(out, entry)
+		}
+		r.Patterns = out
+	}
+	if r.Enable {
+		for _, err := range r.problems() {
+			log.Errorf("redaction: %v; requests will be rejected until it is fixed", err)
+		}
+	}
+}
+
+// problems lists every detector and custom pattern that cannot be applied.
+func (r RedactionConfig) problems() []error {
+	var errs []error
+	names := make([]string, 0, len(r.Detectors))
+	for name := range r.Detectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := redaction.BuiltinPattern(name); !ok {
+			errs = append(errs, fmt.Errorf("unknown detector %q", name))
+		} else if _, ok = redaction.ParseAction(r.Detectors[name]); !ok && strings.TrimSpace(r.Detectors[name]) != "" {
+			errs = append(errs, fmt.Errorf("detector %q: invalid action %q", name, r.Detectors[name]))
+		}
+	}
+	seen := make(map[string]struct{}, len(r.Patterns))
+	for i, entry := range r.Patterns {
+		switch _, duplicate := seen[entry.Name]; {
+		case entry.Name == "":
+			errs = append(errs, fmt.Errorf("pattern %d: missing name", i))
+		case duplicate:
+			errs = append(errs, fmt.Errorf("pattern %q: duplicate name", entry.Name))
+		case strings.TrimSpace(entry.Pattern) == "":
+			errs = append(errs, fmt.Errorf("pattern %q: missing pattern", entry.Name))
+		}
+		seen[entry.Name] = struct{}{}
+		if _, ok := redaction.ParseAction(entry.Action); !ok && strings.TrimSpace(entry.Action) != "" {
+			errs = append(errs, fmt.Errorf("pattern %q: invalid action %q", entry.Name, entry.Action))
+		}
+		if _, err := regexp.Compile(entry.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("pattern %q: %w", entry.Name, err))
+		}
+	}
+	return errs
+}
+
+// Rules returns the enabled detectors in a stable order: built-in detectors first, then the
+// custom patterns in configuration order. It fails when any detector or pattern is invalid.
+func (r RedactionConfig) Rules() ([]redaction.Rule, error) {
+	if !r.Enable {
+		return nil, nil
+	}
+	if errs := r.problems(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid redaction configuration: %w", errors.Join(errs...))
+	}
+	var rules []redaction.Rule
+	for _, name := range redaction.BuiltinNames() {
+		action, ok := redaction.ParseAction(r.Detectors[name])
+		if !ok {
+			continue
+		}
+		pattern, _ := redaction.BuiltinPattern(name)
+		rules = append(rules, redaction.Rule{Name: name, Pattern: pattern, Action: action})
+	}
+	for _, entry := range r.Patterns {
+		action, ok := redaction.ParseAction(entry.Action)
+		if !ok {
+			action = redaction.ActionMask
+		}
+		rules = append(rules, redaction.Rule{Name: entry.Name, Pattern: entry.Pattern, Action: action})
+	}
+	return rules, nil
+}
@@ -20,6 +20,13 @@ type SDKConfig struct {
 	// APIKeys is a list of keys for authenticating clients to this proxy server.
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 
+	// ClientKeyPolicies restricts individual client API keys to a subset of models,
+	// an optional credential prefix, and per-key payload rules.
+	ClientKeyPolicies []ClientKeyPolicy `yaml:"client-key-policies,omitempty" json:"client-key-policies,omitempty"`
+
+	// ModelSplits defines weighted aliases that spread requests for one model name across several targets.
+	ModelSplits []ModelSplit `yaml:"model-splits,omitempty" json:"model-splits,omitempty"`
+
 	// PassthroughHeaders controls whether upstream response headers are forwarded to downstream clients.
 	// Default is false (disabled).
 	PassthroughHeaders bool `yaml:"passthrough-headers" json:"passthrough-headers"`
@@ -42,4 +49,19 @@ type StreamingConfig struct {
 	// to allow auth rotation / transient recovery.
 	// <= 0 disables bootstrap retries. Default is 0.
 	BootstrapRetries int `yaml:"bootstrap-retries,omitempty" json:"bootstrap-retries,omitempty"`
+
+	// IdleTimeoutSeconds aborts an upstream stream that delivers no data for this long and
+	// ends the client stream with an error event. <= 0 disables stall detection. Default is 0.
+	IdleTimeoutSeconds int `yaml:"idle-timeout-seconds,omitempty" json:"idle-timeout-seconds,omitempty"`
+
+	// ProviderIdleTimeoutSeconds overrides IdleTimeoutSeconds per provider. Keys are provider
+	// identifiers (e.g. "claude", "gemini", "codex") or openai-compatibility names.
+	ProviderIdleTimeoutSeconds map[string]int `yaml:"provider-idle-timeout-seconds,omitempty" json:"provider-idle-timeout-seconds,omitempty"`
+
+	// StallRecovery re-issues a stalled stream with the partial assistant output appended as a
+	// prefill and continues the client stream, on providers that support prefill (Claude).
+	StallRecovery bool `yaml:"stall-recovery,omitempty" json:"stall-recovery,omitempty"`
+
+	// StallRecoveryAttempts bounds continuations per request when StallRecovery is on. Default is 1.
+	StallRecoveryAttempts int `yaml:"stall-recovery-attempts,omitempty" json:"stall-recovery-attempts,omitempty"`
 }
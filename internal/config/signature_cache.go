@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSignatureCacheMaxEntries bounds the signature cache when max-entries is not set.
+	DefaultSignatureCacheMaxEntries = 20000
+	// DefaultSignatureCacheFile is the snapshot file name of the file backend, relative to the config directory.
+	DefaultSignatureCacheFile = "signature-cache.json"
+)
+
+// SignatureCacheConfig selects where Claude/Antigravity thinking signatures are kept. The
+// in-memory cache always sits in front of the backend.
+type SignatureCacheConfig struct {
+	// Backend is one of "memory" (default), "file" (snapshot that survives restarts), or
+	// "postgres" (shared between replicas; requires the Postgres token store).
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+
+	// File is the snapshot path of the file backend. Relative paths resolve against the config directory.
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+
+	// MaxEntries bounds the cached signatures. Defaults to 20000; -1 disables the bound.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+
+	// MaintenanceIntervalSeconds controls how often the backend is pruned and the snapshot
+	// written. Defaults to 60.
+	MaintenanceIntervalSeconds int `yaml:"maintenance-interval-seconds,omitempty" json:"maintenance-interval-seconds,omitempty"`
+}
+
+// NormalizedBackend returns the lowercased backend name, defaulting to "memory".
+func (c SignatureCacheConfig) NormalizedBackend() string {
+	switch backend := strings.ToLower(strings.TrimSpace(c.Backend)); backend {
+	case "file", "postgres":
+		return backend
+	default:
+		return "memory"
+	}
+}
+
+// EffectiveMaxEntries returns the entry bound, zero meaning unbounded.
+func (c SignatureCacheConfig) EffectiveMaxEntries() int {
+	switch {
+	case c.MaxEntries < 0:
+		return 0
+	case c.MaxEntries == 0:
+		return DefaultSignatureCacheMaxEntries
+	default:
+		return c.MaxEntries
+	}
+}
+
+// MaintenanceInterval returns the backend maintenance interval.
+func (c SignatureCacheConfig) MaintenanceInterval() time.Duration {
+	if c.MaintenanceIntervalSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(c.MaintenanceIntervalSeconds) * time.Second
+}
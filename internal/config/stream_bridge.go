@@ -0,0 +1,94 @@
+package config
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// StreamBridgeModeStream always calls the upstream in streaming mode and aggregates the
+	// events into a single response for non-streaming clients.
+	StreamBridgeModeStream = "stream"
+	// StreamBridgeModeNonStream always calls the upstream in non-streaming mode and replays the
+	// response as a stream for streaming clients.
+	StreamBridgeModeNonStream = "non-stream"
+)
+
+// StreamBridgeRule pins the upstream call mode for matching providers and models, converting
+// to whatever mode the client asked for. Rules are evaluated in order; the first match wins.
+type StreamBridgeRule struct {
+	// Provider matches the provider identifier (e.g. "claude", "gemini", "antigravity") or an
+	// openai-compatibility name. Empty or "*" matches every provider.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Models limits the rule to matching upstream model names (supports '*' wildcards).
+	// When empty, every model of the provider matches.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Mode is "stream" or "non-stream".
+	Mode string `yaml:"mode" json:"mode"`
+}
+
+// SanitizeStreamBridge normalizes rule providers and modes and drops rules with an unknown mode.
+func (cfg *Config) SanitizeStreamBridge() {
+	if cfg == nil || len(cfg.StreamBridge) == 0 {
+		return
+	}
+	out := make([]StreamBridgeRule, 0, len(cfg.StreamBridge))
+	for _, rule := range cfg.StreamBridge {
+		rule.Provider = strings.ToLower(strings.TrimSpace(rule.Provider))
+		if rule.Provider == "*" {
+			rule.Provider = ""
+		}
+		switch mode := strings.ToLower(strings.TrimSpace(rule.Mode)); mode {
+		case StreamBridgeModeStream, StreamBridgeModeNonStream:
+			rule.Mode = mode
+		case "nonstream", "non_stream":
+			rule.Mode = StreamBridgeModeNonStream
+		default:
+			log.WithField("provider", rule.Provider).Warnf("stream-bridge rule dropped: invalid mode %q", rule.Mode)
+			continue
+		}
+		out = append(out, rule)
+	}
+	cfg.StreamBridge = out
+}
+
+// StreamBridgeMode returns the upstream mode pinned for a request, or "" when no rule matches.
+// providers lists the names the credential is known by, such as its provider identifier and
+// openai-compatibility name.
+func (cfg *Config) StreamBridgeMode(providers []string, model string) string {
+	if cfg == nil {
+		return ""
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, rule := range cfg.StreamBridge {
+		if rule.Provider != "" && !containsFold(providers, rule.Provider) {
+			continue
+		}
+		if len(rule.Models) > 0 {
+			matched := false
+			for _, pattern := range rule.Models {
+				if MatchModelWildcard(pattern, model) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		return rule.Mode
+	}
+	return ""
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package config
+
+// TracingConfig configures optional OpenTelemetry tracing exported over OTLP/HTTP.
+type TracingConfig struct {
+	// Enable toggles span export. When disabled, spans are not recorded.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// Endpoint is the OTLP/HTTP collector address, either host:port or a full URL
+	// such as "https://otel.example.com/v1/traces". Defaults to the exporter's standard
+	// environment variables, or localhost:4318.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+
+	// Insecure sends spans over plain HTTP when Endpoint is host:port.
+	Insecure bool `yaml:"insecure,omitempty" json:"insecure,omitempty"`
+
+	// Headers are added to every export request, e.g. collector authentication.
+	Headers map[string]string `yaml:"headers,omitempty" json:"-"`
+
+	// ServiceName is reported as service.name. Defaults to "cli-proxy-api".
+	ServiceName string `yaml:"service-name,omitempty" json:"service-name,omitempty"`
+
+	// SampleRatio is the fraction of new traces recorded, between 0 and 1. Requests carrying
+	// a sampled inbound traceparent are always recorded. Defaults to 1.
+	SampleRatio float64 `yaml:"sample-ratio,omitempty" json:"sample-ratio,omitempty"`
+}
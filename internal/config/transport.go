@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// TransportConfig tunes the upstream HTTP connection pool. The global block applies to every
+// upstream request; a credential-level block overrides the fields it sets. Zero values keep
+// the defaults noted on each field.
+type TransportConfig struct {
+	// MaxIdleConns bounds idle connections across all hosts. Defaults to 256.
+	MaxIdleConns int `yaml:"max-idle-conns,omitempty" json:"max-idle-conns,omitempty"`
+
+	// MaxIdleConnsPerHost bounds idle connections kept per upstream host. Defaults to 64.
+	MaxIdleConnsPerHost int `yaml:"max-idle-conns-per-host,omitempty" json:"max-idle-conns-per-host,omitempty"`
+
+	// MaxConnsPerHost bounds all connections (dialing, active, idle) per host. Zero means unlimited.
+	MaxConnsPerHost int `yaml:"max-conns-per-host,omitempty" json:"max-conns-per-host,omitempty"`
+
+	// IdleConnTimeoutSeconds closes idle connections after this long. Defaults to 90.
+	IdleConnTimeoutSeconds int `yaml:"idle-conn-timeout-seconds,omitempty" json:"idle-conn-timeout-seconds,omitempty"`
+
+	// DialTimeoutSeconds bounds establishing a TCP connection. Defaults to 30.
+	DialTimeoutSeconds int `yaml:"dial-timeout-seconds,omitempty" json:"dial-timeout-seconds,omitempty"`
+
+	// KeepAliveSeconds is the TCP keep-alive probe interval. Defaults to 30.
+	KeepAliveSeconds int `yaml:"keep-alive-seconds,omitempty" json:"keep-alive-seconds,omitempty"`
+
+	// TLSHandshakeTimeoutSeconds bounds the TLS handshake. Defaults to 10.
+	TLSHandshakeTimeoutSeconds int `yaml:"tls-handshake-timeout-seconds,omitempty" json:"tls-handshake-timeout-seconds,omitempty"`
+
+	// ResponseHeaderTimeoutSeconds bounds the wait for response headers after the request was
+	// written. Zero means no limit, which suits slow non-streaming generations.
+	ResponseHeaderTimeoutSeconds int `yaml:"response-header-timeout-seconds,omitempty" json:"response-header-timeout-seconds,omitempty"`
+
+	// HTTP2 is "auto" (default, negotiated over TLS) or "off" (HTTP/1.1 only).
+	HTTP2 string `yaml:"http2,omitempty" json:"http2,omitempty"`
+
+	// HTTP2StrictMaxConcurrentStreams queues requests when every HTTP/2 connection to a host
+	// is at the server's stream limit instead of opening another connection.
+	HTTP2StrictMaxConcurrentStreams *bool `yaml:"http2-strict-max-concurrent-streams,omitempty" json:"http2-strict-max-concurrent-streams,omitempty"`
+
+	// HTTP2PingIntervalSeconds sends a health-check ping on an HTTP/2 connection that has been
+	// silent this long, so dead sockets are detected. Zero disables pings.
+	HTTP2PingIntervalSeconds int `yaml:"http2-ping-interval-seconds,omitempty" json:"http2-ping-interval-seconds,omitempty"`
+
+	// HTTP2PingTimeoutSeconds closes a connection whose ping is not answered in time. Defaults to 15.
+	HTTP2PingTimeoutSeconds int `yaml:"http2-ping-timeout-seconds,omitempty" json:"http2-ping-timeout-seconds,omitempty"`
+}
+
+// TransportAttributeKey is the auth attribute carrying a credential's transport overrides.
+const TransportAttributeKey = "transport"
+
+// Overlay returns t with the fields set in override replacing its own.
+func (t TransportConfig) Overlay(override *TransportConfig) TransportConfig {
+	if override == nil {
+		return t
+	}
+	out := t
+	overlayInt := func(dst *int, src int) {
+		if src != 0 {
+			*dst = src
+		}
+	}
+	overlayInt(&out.MaxIdleConns, override.MaxIdleConns)
+	overlayInt(&out.MaxIdleConnsPerHost, override.MaxIdleConnsPerHost)
+	overlayInt(&out.MaxConnsPerHost, override.MaxConnsPerHost)
+	overlayInt(&out.IdleConnTimeoutSeconds, override.IdleConnTimeoutSeconds)
+	overlayInt(&out.DialTimeoutSeconds, override.DialTimeoutSeconds)
+	overlayInt(&out.KeepAliveSeconds, override.KeepAliveSeconds)
+	overlayInt(&out.TLSHandshakeTimeoutSeconds, override.TLSHandshakeTimeoutSeconds)
+	overlayInt(&out.ResponseHeaderTimeoutSeconds, override.ResponseHeaderTimeoutSeconds)
+	overlayInt(&out.HTTP2PingIntervalSeconds, override.HTTP2PingIntervalSeconds)
+	overlayInt(&out.HTTP2PingTimeoutSeconds, override.HTTP2PingTimeoutSeconds)
+	if strings.TrimSpace(override.HTTP2) != "" {
+		out.HTTP2 = override.HTTP2
+	}
+	if override.HTTP2StrictMaxConcurrentStreams != nil {
+		strict := *override.HTTP2StrictMaxConcurrentStreams
+		out.HTTP2StrictMaxConcurrentStreams = &strict
+	}
+	return out
+}
+
+// HTTP2Disabled reports whether HTTP/2 is turned off.
+func (t TransportConfig) HTTP2Disabled() bool {
+	return strings.EqualFold(strings.TrimSpace(t.HTTP2), "off")
+}
+
+// IsZero reports whether no field is set.
+func (t TransportConfig) IsZero() bool {
+	return t == TransportConfig{}
+}
+
+// EncodeTransportAttribute serializes credential transport overrides for auth attributes.
+// It returns an empty string when override is nil or empty.
+func EncodeTransportAttribute(override *TransportConfig) string {
+	if override == nil || override.IsZero() {
+		return ""
+	}
+	data, err := json.Marshal(override)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// DecodeTransportAttribute parses overrides written by EncodeTransportAttribute.
+func DecodeTransportAttribute(raw string) *TransportConfig {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	var override TransportConfig
+	if err := json.Unmarshal([]byte(raw), &override); err != nil {
+		return nil
+	}
+	return &override
+}
+
+// SanitizeTransport normalizes the HTTP/2 mode and clears negative values of the global and
+// credential transport blocks.
+func (cfg *Config) SanitizeTransport() {
+	if cfg == nil {
+		return
+	}
+	sanitizeTransportConfig(&cfg.Transport)
+	for i := range cfg.GeminiKey {
+		sanitizeTransportConfig(cfg.GeminiKey[i].Transport)
+	}
+	for i := range cfg.ClaudeKey {
+		sanitizeTransportConfig(cfg.ClaudeKey[i].Transport)
+	}
+	for i := range cfg.CodexKey {
+		sanitizeTransportConfig(cfg.CodexKey[i].Transport)
+	}
+	for i := range cfg.VertexCompatAPIKey {
+		sanitizeTransportConfig(cfg.VertexCompatAPIKey[i].Transport)
+	}
+	for i := range cfg.OpenAICompatibility {
+		sanitizeTransportConfig(cfg.OpenAICompatibility[i].Transport)
+	}
+}
+
+func sanitizeTransportConfig(t *TransportConfig) {
+	if t == nil {
+		return
+	}
+	for _, field := range []*int{
+		&t.MaxIdleConns, &t.MaxIdleConnsPerHost, &t.MaxConnsPerHost, &t.IdleConnTimeoutSeconds,
+		&t.DialTimeoutSeconds, &t.KeepAliveSeconds, &t.TLSHandshakeTimeoutSeconds,
+		&t.ResponseHeaderTimeoutSeconds, &t.HTTP2PingIntervalSeconds, &t.HTTP2PingTimeoutSeconds,
+	} {
+		if *field < 0 {
+			*field = 0
+		}
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(t.HTTP2)); mode {
+	case "off", "auto":
+		t.HTTP2 = mode
+	default:
+		t.HTTP2 = ""
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+)
+
+// WebToolsConfig enables server-side emulation of the built-in web search and URL fetch tools
+// for backends that do not run them natively. The proxy offers the model equivalent function
+// tools, runs its calls against the configured backends and continues the conversation until
+// the model produces a final answer.
+type WebToolsConfig struct {
+	// Enable turns the emulation on.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// SearchURL is a SearXNG-compatible search endpoint queried with "q" and "format=json".
+	// Web search tools are left untouched when it is empty.
+	SearchURL string `yaml:"search-url,omitempty" json:"search-url,omitempty"`
+
+	// Providers limits emulation to these provider identifiers or openai-compatibility names.
+	// When empty, every provider except the natively searching "claude" and "codex" is covered.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// MaxResults bounds the results returned per search. Defaults to 5.
+	MaxResults int `yaml:"max-results,omitempty" json:"max-results,omitempty"`
+
+	// MaxIterations bounds the model round trips per request. Defaults to 5.
+	MaxIterations int `yaml:"max-iterations,omitempty" json:"max-iterations,omitempty"`
+
+	// FetchMaxBytes bounds the page text returned by a fetch. Defaults to 100000.
+	FetchMaxBytes int `yaml:"fetch-max-bytes,omitempty" json:"fetch-max-bytes,omitempty"`
+
+	// TimeoutSeconds bounds each search or fetch. Defaults to 15.
+	TimeoutSeconds int `yaml:"timeout-seconds,omitempty" json:"timeout-seconds,omitempty"`
+
+	// AllowPrivateNetworks lets fetches reach loopback, private and link-local addresses.
+	AllowPrivateNetworks bool `yaml:"allow-private-networks,omitempty" json:"allow-private-networks,omitempty"`
+}
+
+// webToolsNativeProviders run the built-in web tools themselves.
+var webToolsNativeProviders = []string{"claude", "codex"}
+
+// SanitizeWebTools trims the search URL and provider names and applies defaults.
+func (cfg *Config) SanitizeWebTools() {
+	if cfg == nil {
+		return
+	}
+	w := &cfg.WebTools
+	w.SearchURL = strings.TrimSpace(w.SearchURL)
+	providers := make([]string, 0, len(w.Providers))
+	for _, provider := range w.Providers {
+		if provider = strings.ToLower(strings.TrimSpace(provider)); provider != "" {
+			providers = append(providers, provider)
+		}
+	}
+	w.Providers = providers
+	if w.MaxResults <= 0 {
+		w.MaxResults = 5
+	}
+	if w.MaxIterations <= 0 {
+		w.MaxIterations = 5
+	}
+	if w.FetchMaxBytes <= 0 {
+		w.FetchMaxBytes = 100000
+	}
+	if w.TimeoutSeconds <= 0 {
+		w.TimeoutSeconds = 15
+	}
+}
+
+// Emulates reports whether web tools are emulated for a credential known by the given names,
+// such as its provider identifier and openai-compatibility name.
+func (w WebToolsConfig) Emulates(names []string) bool {
+	if !w.Enable {
+		return false
+	}
+	if len(w.Providers) > 0 {
+		for _, provider := range w.Providers {
+			if containsFold(names, provider) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, native := range webToolsNativeProviders {
+		if containsFold(names, native) {
+			return false
+		}
+	}
+	return true
+}
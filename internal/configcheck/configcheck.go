@@ -0,0 +1,653 @@
+// Package configcheck performs a dry-run validation of config.yaml content. Beyond the parse
+// check done by config.LoadConfigOptional it reports semantic problems that the loader would
+// otherwise accept silently (duplicate keys, alias collisions, unreachable exclusions, bad proxy
+// URLs, malformed payload paths, shadowing prefixes), each tied to a YAML line, and previews
+// which credentials and models would change if the content were applied.
+package configcheck
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity classifies an Issue.
+type Severity string
+
+const (
+	// SeverityError marks content that fails to load or is certainly misconfigured.
+	SeverityError Severity = "error"
+	// SeverityWarning marks content that loads but probably does not do what was intended.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a single validation finding.
+type Issue struct {
+	Severity Severity `json:"severity"`
+	// Path is the YAML path of the offending value, e.g. "claude-api-key[1].proxy-url".
+	Path    string `json:"path,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Preview summarizes what applying the checked content would change.
+type Preview struct {
+	// Changes lists field-level changes in the same format as config reload logs.
+	Changes        []string `json:"changes"`
+	AddedClients   []string `json:"added_clients"`
+	RemovedClients []string `json:"removed_clients"`
+	AddedModels    []string `json:"added_models"`
+	RemovedModels  []string `json:"removed_models"`
+}
+
+// Report is the result of Check.
+type Report struct {
+	Valid    bool     `json:"valid"`
+	Errors   []Issue  `json:"errors"`
+	Warnings []Issue  `json:"warnings"`
+	Preview  *Preview `json:"preview,omitempty"`
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// Check validates data as config.yaml content. When current is non-nil the report includes a
+// preview of the changes relative to it.
+func Check(data []byte, current *config.Config) Report {
+	c := &checker{}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		c.addAt(SeverityError, "", yamlErrorLine(err), 0, err.Error())
+		return c.report()
+	}
+	c.root = documentRoot(&root)
+
+	// raw is decoded without sanitizing so entries the loader silently drops are still visible.
+	var raw config.Config
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		c.addAt(SeverityError, "", yamlErrorLine(err), 0, err.Error())
+		return c.report()
+	}
+	loaded, err := loadConfig(data)
+	if err != nil {
+		c.addAt(SeverityError, "", yamlErrorLine(err), 0, err.Error())
+		return c.report()
+	}
+
+	c.checkClientKeys(&raw)
+	c.checkProviderKeys(&raw)
+	c.checkProxyURLs(&raw)
+	c.checkOAuthAliases(&raw)
+	c.checkExcludedModels(&raw)
+	c.checkPayloadRules(&raw)
+	c.checkPrefixes(&raw)
+
+	rep := c.report()
+	if current != nil {
+		rep.Preview = buildPreview(current, loaded)
+	}
+	return rep
+}
+
+// Load parses data as config.yaml content the way the server would, without touching the file
+// it came from. Plaintext secrets are hashed in the returned Config only.
+func Load(data []byte) (*config.Config, error) {
+	return loadConfig(data)
+}
+
+// loadConfig runs content through the regular loader so load-time errors are reported exactly
+// as the server would report them.
+func loadConfig(data []byte) (*config.Config, error) {
+	tmp, err := os.CreateTemp("", "config-check-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	name := tmp.Name()
+	defer func() {
+		_ = os.Remove(name)
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return nil, err
+	}
+	if err = tmp.Close(); err != nil {
+		return nil, err
+	}
+	return config.LoadConfigOptional(name, false)
+}
+
+func yamlErrorLine(err error) int {
+	if m := yamlLinePattern.FindStringSubmatch(err.Error()); len(m) == 2 {
+		line, _ := strconv.Atoi(m[1])
+		return line
+	}
+	return 0
+}
+
+type checker struct {
+	root   *yaml.Node
+	issues []Issue
+}
+
+func (c *checker) report() Report {
+	rep := Report{Errors: []Issue{}, Warnings: []Issue{}}
+	sort.SliceStable(c.issues, func(i, j int) bool { return c.issues[i].Line < c.issues[j].Line })
+	for _, issue := range c.issues {
+		if issue.Severity == SeverityError {
+			rep.Errors = append(rep.Errors, issue)
+		} else {
+			rep.Warnings = append(rep.Warnings, issue)
+		}
+	}
+	rep.Valid = len(rep.Errors) == 0
+	return rep
+}
+
+func (c *checker) addAt(severity Severity, path string, line, column int, message string) {
+	c.issues = append(c.issues, Issue{Severity: severity, Path: path, Line: line, Column: column, Message: message})
+}
+
+// add records an issue located at path, given as mapping keys and sequence indexes.
+func (c *checker) add(severity Severity, message string, path ...any) {
+	node := lookup(c.root, path...)
+	line, column := 0, 0
+	if node != nil {
+		line, column = node.Line, node.Column
+	}
+	c.addAt(severity, formatPath(path), line, column, message)
+}
+
+func documentRoot(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	return node
+}
+
+// lookup resolves path against the YAML tree. It returns the deepest node found, so issues
+// about missing values still point at their parent.
+func lookup(node *yaml.Node, path ...any) *yaml.Node {
+	for _, part := range path {
+		if node == nil {
+			return nil
+		}
+		var next *yaml.Node
+		switch key := part.(type) {
+		case string:
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == key {
+						next = node.Content[i+1]
+						// Point scalars at their key; it is where editors put the cursor.
+						if next.Kind == yaml.ScalarNode {
+							next = &yaml.Node{Kind: yaml.ScalarNode, Value: next.Value, Line: node.Content[i].Line, Column: node.Content[i].Column}
+						}
+						break
+					}
+				}
+			}
+		case int:
+			if node.Kind == yaml.SequenceNode && key >= 0 && key < len(node.Content) {
+				next = node.Content[key]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+func formatPath(path []any) string {
+	var b strings.Builder
+	for _, part := range path {
+		switch key := part.(type) {
+		case string:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(key)
+		case int:
+			fmt.Fprintf(&b, "[%d]", key)
+		}
+	}
+	return b.String()
+}
+
+// checkClientKeys reports duplicate client API keys.
+func (c *checker) checkClientKeys(cfg *config.Config) {
+	seen := make(map[string]int)
+	for i, key := range cfg.APIKeys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			c.add(SeverityWarning, "empty client API key is ignored", "api-keys", i)
+			continue
+		}
+		if first, ok := seen[key]; ok {
+			c.add(SeverityWarning, fmt.Sprintf("duplicate client API key %s (first defined at api-keys[%d])", util.HideAPIKey(key), first), "api-keys", i)
+			continue
+		}
+		seen[key] = i
+	}
+}
+
+// providerEntry is the part of a provider credential entry the checks look at.
+type providerEntry struct {
+	section  string
+	index    int
+	channel  string
+	apiKey   string
+	baseURL  string
+	proxyURL string
+	prefix   string
+	models   []string
+	excluded []string
+}
+
+func providerEntries(cfg *config.Config) []providerEntry {
+	var out []providerEntry
+	for i, e := range cfg.GeminiKey {
+		models := make([]string, 0, len(e.Models))
+		for _, m := range e.Models {
+			models = append(models, m.Name, m.Alias)
+		}
+		out = append(out, providerEntry{"gemini-api-key", i, "gemini", e.APIKey, e.BaseURL, e.ProxyURL, e.Prefix, models, e.ExcludedModels})
+	}
+	for i, e := range cfg.ClaudeKey {
+		models := make([]string, 0, len(e.Models))
+		for _, m := range e.Models {
+			models = append(models, m.Name, m.Alias)
+		}
+		out = append(out, providerEntry{"claude-api-key", i, "claude", e.APIKey, e.BaseURL, e.ProxyURL, e.Prefix, models, e.ExcludedModels})
+	}
+	for i, e := range cfg.CodexKey {
+		models := make([]string, 0, len(e.Models))
+		for _, m := range e.Models {
+			models = append(models, m.Name, m.Alias)
+		}
+		out = append(out, providerEntry{"codex-api-key", i, "codex", e.APIKey, e.BaseURL, e.ProxyURL, e.Prefix, models, e.ExcludedModels})
+	}
+	for i, e := range cfg.VertexCompatAPIKey {
+		models := make([]string, 0, len(e.Models))
+		for _, m := range e.Models {
+			models = append(models, m.Name, m.Alias)
+		}
+		out = append(out, providerEntry{"vertex-api-key", i, "vertex", e.APIKey, e.BaseURL, e.ProxyURL, e.Prefix, models, nil})
+	}
+	return out
+}
+
+// checkProviderKeys reports provider credentials configured more than once.
+func (c *checker) checkProviderKeys(cfg *config.Config) {
+	seen := make(map[string]string)
+	for _, e := range providerEntries(cfg) {
+		key := strings.TrimSpace(e.apiKey)
+		if key == "" {
+			continue
+		}
+		id := e.section + "|" + key + "|" + strings.TrimSpace(e.baseURL)
+		if first, ok := seen[id]; ok {
+			c.add(SeverityWarning, fmt.Sprintf("duplicate %s credential %s (same key and base-url as %s)", e.section, util.HideAPIKey(key), first), e.section, e.index, "api-key")
+			continue
+		}
+		seen[id] = fmt.Sprintf("%s[%d]", e.section, e.index)
+	}
+	for i, compat := range cfg.OpenAICompatibility {
+		keys := make(map[string]int)
+		for j, entry := range compat.APIKeyEntries {
+			key := strings.TrimSpace(entry.APIKey)
+			if key == "" {
+				continue
+			}
+			if first, ok := keys[key]; ok {
+				c.add(SeverityWarning, fmt.Sprintf("duplicate API key %s (first defined at api-key-entries[%d])", util.HideAPIKey(key), first), "openai-compatibility", i, "api-key-entries", j, "api-key")
+				continue
+			}
+			keys[key] = j
+		}
+	}
+}
+
+// checkProxyURLs reports proxy URLs the transport layer would silently ignore.
+func (c *checker) checkProxyURLs(cfg *config.Config) {
+	c.checkProxyURL(cfg.ProxyURL, "proxy-url")
+	for _, e := range providerEntries(cfg) {
+		c.checkProxyURL(e.proxyURL, e.section, e.index, "proxy-url")
+	}
+	for i, compat := range cfg.OpenAICompatibility {
+		for j, entry := range compat.APIKeyEntries {
+			c.checkProxyURL(entry.ProxyURL, "openai-compatibility", i, "api-key-entries", j, "proxy-url")
+		}
+	}
+}
+
+func (c *checker) checkProxyURL(raw string, path ...any) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		c.add(SeverityError, fmt.Sprintf("invalid proxy-url: %v", err), path...)
+		return
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		c.add(SeverityError, fmt.Sprintf("unsupported proxy-url scheme %q (use http, https or socks5)", parsed.Scheme), path...)
+		return
+	}
+	if parsed.Host == "" {
+		c.add(SeverityError, "proxy-url has no host", path...)
+	}
+}
+
+// checkOAuthAliases reports aliases the loader would drop and aliases shared across channels.
+func (c *checker) checkOAuthAliases(cfg *config.Config) {
+	channels := make([]string, 0, len(cfg.OAuthModelAlias))
+	for channel := range cfg.OAuthModelAlias {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	owners := make(map[string][]string)
+	for _, channel := range channels {
+		seen := make(map[string]int)
+		for i, entry := range cfg.OAuthModelAlias[channel] {
+			name, alias := strings.TrimSpace(entry.Name), strings.TrimSpace(entry.Alias)
+			if name == "" || alias == "" {
+				c.add(SeverityWarning, "alias entry needs both name and alias and is ignored", "oauth-model-alias", channel, i)
+				continue
+			}
+			key := strings.ToLower(alias)
+			if first, ok := seen[key]; ok {
+				c.add(SeverityError, fmt.Sprintf("alias %q is already defined at oauth-model-alias.%s[%d]; this entry is dropped", alias, channel, first), "oauth-model-alias", channel, i, "alias")
+				continue
+			}
+			seen[key] = i
+			owners[key] = append(owners[key], channel)
+			if len(owners[key]) == 2 {
+				c.add(SeverityWarning, fmt.Sprintf("alias %q is also defined for channel %s; requests may resolve to either channel", alias, owners[key][0]), "oauth-model-alias", channel, i, "alias")
+			}
+		}
+	}
+}
+
+// checkExcludedModels reports exclusion patterns that match none of the models a credential serves.
+func (c *checker) checkExcludedModels(cfg *config.Config) {
+	for _, e := range providerEntries(cfg) {
+		known := e.models
+		if len(known) == 0 {
+			known = staticModelIDs(e.channel)
+		}
+		for i, pattern := range e.excluded {
+			c.checkExclusion(pattern, known, e.section, e.index, "excluded-models", i)
+		}
+	}
+	for channel, patterns := range cfg.OAuthExcludedModels {
+		known := staticModelIDs(channel)
+		for _, alias := range cfg.OAuthModelAlias[channel] {
+			known = append(known, alias.Alias)
+		}
+		for i, pattern := range patterns {
+			c.checkExclusion(pattern, known, "oauth-excluded-models", channel, i)
+		}
+	}
+}
+
+func (c *checker) checkExclusion(pattern string, known []string, path ...any) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" || len(known) == 0 {
+		return
+	}
+	for _, model := range known {
+		if model != "" && config.MatchModelWildcard(pattern, model) {
+			return
+		}
+	}
+	c.add(SeverityWarning, fmt.Sprintf("excluded model pattern %q matches no known model", pattern), path...)
+}
+
+func staticModelIDs(channel string) []string {
+	models := registry.GetStaticModelDefinitionsByChannel(channel)
+	ids := make([]string, 0, len(models))
+	for _, model := range models {
+		if model != nil {
+			ids = append(ids, model.ID)
+		}
+	}
+	return ids
+}
+
+// checkPayloadRules reports payload rules whose paths or raw values cannot be applied.
+func (c *checker) checkPayloadRules(cfg *config.Config) {
+	sections := []struct {
+		name  string
+		rules []config.PayloadRule
+		raw   bool
+	}{
+		{"default", cfg.Payload.Default, false},
+		{"default-raw", cfg.Payload.DefaultRaw, true},
+		{"override", cfg.Payload.Override, false},
+		{"override-raw", cfg.Payload.OverrideRaw, true},
+	}
+	for _, section := range sections {
+		for i, rule := range section.rules {
+			c.checkPayloadModels(rule.Models, "payload", section.name, i)
+			for path, value := range rule.Params {
+				if msg := payloadPathProblem(path); msg != "" {
+					c.add(SeverityError, fmt.Sprintf("payload path %q %s", path, msg), "payload", section.name, i, "params", path)
+					continue
+				}
+				if !section.raw {
+					continue
+				}
+				if text, ok := value.(string); ok && !gjson.Valid(text) {
+					c.add(SeverityError, fmt.Sprintf("raw value for %q is not valid JSON", path), "payload", section.name, i, "params", path)
+				}
+			}
+		}
+	}
+	for i, rule := range cfg.Payload.Filter {
+		c.checkPayloadModels(rule.Models, "payload", "filter", i)
+		for j, path := range rule.Params {
+			if msg := payloadPathProblem(path); msg != "" {
+				c.add(SeverityError, fmt.Sprintf("payload path %q %s", path, msg), "payload", "filter", i, "params", j)
+			}
+		}
+	}
+}
+
+func (c *checker) checkPayloadModels(models []config.PayloadModelRule, path ...any) {
+	if len(models) == 0 {
+		c.add(SeverityWarning, "payload rule has no models and never applies", path...)
+		return
+	}
+	for j, model := range models {
+		if strings.TrimSpace(model.Name) == "" {
+			c.add(SeverityWarning, "payload model entry has an empty name and never matches", append(path, "models", j)...)
+		}
+	}
+}
+
+// payloadPathProblem describes why path is not a usable gjson/sjson path, or returns "".
+func payloadPathProblem(path string) string {
+	trimmed := strings.TrimSpace(path)
+	switch {
+	case trimmed == "":
+		return "is empty"
+	case trimmed != path:
+		return "has surrounding whitespace"
+	case strings.HasPrefix(path, ".") || strings.HasSuffix(path, "."):
+		return "starts or ends with '.'"
+	case strings.Contains(path, ".."):
+		return "contains an empty segment"
+	case strings.ContainsAny(path, "#@|"):
+		return "uses query syntax that cannot be written"
+	}
+	return ""
+}
+
+// checkPrefixes reports prefixes the loader drops and prefixes that shadow real model names.
+func (c *checker) checkPrefixes(cfg *config.Config) {
+	known := make(map[string]struct{})
+	for _, channel := range []string{"claude", "gemini", "vertex", "gemini-cli", "aistudio", "codex", "qwen", "iflow", "kimi"} {
+		for _, id := range staticModelIDs(channel) {
+			known[strings.ToLower(id)] = struct{}{}
+		}
+	}
+	for _, e := range providerEntries(cfg) {
+		for _, model := range e.models {
+			known[strings.ToLower(model)] = struct{}{}
+		}
+	}
+	for _, compat := range cfg.OpenAICompatibility {
+		for _, model := range compat.Models {
+			known[strings.ToLower(model.Name)] = struct{}{}
+			known[strings.ToLower(model.Alias)] = struct{}{}
+		}
+	}
+
+	check := func(prefix string, path ...any) {
+		trimmed := strings.Trim(strings.TrimSpace(prefix), "/")
+		if trimmed == "" {
+			return
+		}
+		if strings.Contains(trimmed, "/") {
+			c.add(SeverityError, fmt.Sprintf("prefix %q contains '/' and is ignored", prefix), path...)
+			return
+		}
+		lower := strings.ToLower(trimmed)
+		if _, ok := known[lower]; ok {
+			c.add(SeverityWarning, fmt.Sprintf("prefix %q equals a model name", trimmed), path...)
+			return
+		}
+		for model := range known {
+			if strings.HasPrefix(model, lower+"/") {
+				c.add(SeverityWarning, fmt.Sprintf("prefix %q shadows model %q", trimmed, model), path...)
+				return
+			}
+		}
+	}
+	for _, e := range providerEntries(cfg) {
+		check(e.prefix, e.section, e.index, "prefix")
+	}
+	for i, compat := range cfg.OpenAICompatibility {
+		check(compat.Prefix, "openai-compatibility", i, "prefix")
+	}
+}
+
+// buildPreview compares the running config with the checked one.
+func buildPreview(current, next *config.Config) *Preview {
+	changes := diff.BuildConfigChangeDetails(current, next)
+	if changes == nil {
+		changes = []string{}
+	}
+	addedClients, removedClients := diffSets(clientIdentities(current), clientIdentities(next))
+	addedModels, removedModels := diffSets(modelIDs(current), modelIDs(next))
+	return &Preview{
+		Changes:        changes,
+		AddedClients:   addedClients,
+		RemovedClients: removedClients,
+		AddedModels:    addedModels,
+		RemovedModels:  removedModels,
+	}
+}
+
+// clientIdentities names every configured upstream credential without exposing secrets.
+func clientIdentities(cfg *config.Config) map[string]struct{} {
+	out := make(map[string]struct{})
+	for _, e := range providerEntries(cfg) {
+		id := e.section + " " + util.HideAPIKey(e.apiKey)
+		if base := strings.TrimSpace(e.baseURL); base != "" {
+			id += " @ " + base
+		}
+		out[id] = struct{}{}
+	}
+	for _, compat := range cfg.OpenAICompatibility {
+		for _, entry := range compat.APIKeyEntries {
+			out["openai-compatibility "+compat.Name+" "+util.HideAPIKey(entry.APIKey)] = struct{}{}
+		}
+	}
+	return out
+}
+
+// modelIDs collects the client-visible model IDs declared in cfg.
+func modelIDs(cfg *config.Config) map[string]struct{} {
+	out := make(map[string]struct{})
+	addModel := func(prefix, name, alias string) {
+		id := strings.TrimSpace(alias)
+		if id == "" {
+			id = strings.TrimSpace(name)
+		}
+		if id == "" {
+			return
+		}
+		out[id] = struct{}{}
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			out[prefix+"/"+id] = struct{}{}
+		}
+	}
+	for _, e := range cfg.GeminiKey {
+		for _, m := range e.Models {
+			addModel(e.Prefix, m.Name, m.Alias)
+		}
+	}
+	for _, e := range cfg.ClaudeKey {
+		for _, m := range e.Models {
+			addModel(e.Prefix, m.Name, m.Alias)
+		}
+	}
+	for _, e := range cfg.CodexKey {
+		for _, m := range e.Models {
+			addModel(e.Prefix, m.Name, m.Alias)
+		}
+	}
+	for _, e := range cfg.VertexCompatAPIKey {
+		for _, m := range e.Models {
+			addModel(e.Prefix, m.Name, m.Alias)
+		}
+	}
+	for _, compat := range cfg.OpenAICompatibility {
+		for _, m := range compat.Models {
+			addModel(compat.Prefix, m.Name, m.Alias)
+		}
+	}
+	for _, aliases := range cfg.OAuthModelAlias {
+		for _, alias := range aliases {
+			addModel("", alias.Name, alias.Alias)
+		}
+	}
+	for _, split := range cfg.ModelSplits {
+		addModel("", split.Alias, "")
+	}
+	return out
+}
+
+func diffSets(before, after map[string]struct{}) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
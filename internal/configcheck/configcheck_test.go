@@ -0,0 +1,118 @@
+package configcheck
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+const problemConfig = `port: 8317
+api-keys:
+  - key-one
+  - key-one
+claude-api-key:
+  - api-key: sk-ant-123456789
+    proxy-url: ftp://proxy.local:21
+    excluded-models:
+      - "no-such-model-*"
+  - api-key: sk-ant-987654321
+    prefix: team/a
+oauth-model-alias:
+  gemini-cli:
+    - name: gemini-2.5-pro
+      alias: pro
+    - name: gemini-2.5-flash
+      alias: pro
+  antigravity:
+    - name: gemini-2.5-pro
+      alias: pro
+payload:
+  override:
+    - models:
+        - name: "gpt-*"
+      params:
+        "reasoning..effort": high
+  override-raw:
+    - models:
+        - name: "gpt-*"
+      params:
+        "response_format": "{not json"
+openai-compatibility:
+  - name: router
+    base-url: https://router.example.com/v1
+    prefix: openai
+    models:
+      - name: openai/gpt-4o
+`
+
+func TestCheckReportsSemanticProblemsWithLines(t *testing.T) {
+	report := Check([]byte(problemConfig), nil)
+	if report.Valid {
+		t.Fatal("expected report to be invalid")
+	}
+
+	expect := []struct {
+		severity Severity
+		path     string
+		line     int
+		contains string
+	}{
+		{SeverityWarning, "api-keys[1]", 4, "duplicate client API key"},
+		{SeverityError, "claude-api-key[0].proxy-url", 7, "unsupported proxy-url scheme"},
+		{SeverityWarning, "claude-api-key[0].excluded-models[0]", 9, "matches no known model"},
+		{SeverityError, "claude-api-key[1].prefix", 11, "contains '/'"},
+		{SeverityError, "oauth-model-alias.gemini-cli[1].alias", 17, "already defined"},
+		{SeverityWarning, "oauth-model-alias.gemini-cli[0].alias", 15, "also defined for channel antigravity"},
+		{SeverityError, "payload.override[0].params.reasoning..effort", 26, "empty segment"},
+		{SeverityError, "payload.override-raw[0].params.response_format", 31, "not valid JSON"},
+		{SeverityWarning, "openai-compatibility[0].prefix", 35, "shadows model"},
+	}
+	all := append(append([]Issue{}, report.Errors...), report.Warnings...)
+	for _, want := range expect {
+		found := false
+		for _, issue := range all {
+			if issue.Path == want.path && issue.Severity == want.severity && strings.Contains(issue.Message, want.contains) {
+				found = true
+				if issue.Line != want.line {
+					t.Errorf("%s: expected line %d, got %d", want.path, want.line, issue.Line)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("missing %s %s (%s); got %+v", want.severity, want.path, want.contains, all)
+		}
+	}
+}
+
+func TestCheckReportsParseErrorLine(t *testing.T) {
+	report := Check([]byte("port: 8317\napi-keys:\n  - a\n bad: [\n"), nil)
+	if report.Valid || len(report.Errors) != 1 || report.Errors[0].Line == 0 {
+		t.Fatalf("expected one located parse error, got %+v", report.Errors)
+	}
+}
+
+func TestCheckPreviewsClientAndModelChanges(t *testing.T) {
+	current := &config.Config{}
+	current.ClaudeKey = []config.ClaudeKey{{APIKey: "sk-ant-old-000000"}}
+	next := `claude-api-key:
+  - api-key: sk-ant-new-111111
+    models:
+      - name: claude-sonnet-4-5
+        alias: sonnet
+`
+	report := Check([]byte(next), current)
+	if !report.Valid || report.Preview == nil {
+		t.Fatalf("expected valid report with preview, got %+v", report)
+	}
+	preview := report.Preview
+	if len(preview.AddedClients) != 1 || len(preview.RemovedClients) != 1 {
+		t.Fatalf("unexpected client changes: %+v", preview)
+	}
+	if strings.Contains(preview.AddedClients[0], "sk-ant-new-111111") {
+		t.Fatalf("preview leaks API key: %s", preview.AddedClients[0])
+	}
+	if len(preview.AddedModels) != 1 || preview.AddedModels[0] != "sonnet" {
+		t.Fatalf("unexpected model changes: %+v", preview.AddedModels)
+	}
+}
@@ -0,0 +1,222 @@
+// Package confighistory keeps versioned snapshots of config.yaml so management edits can be
+// listed, compared and rolled back. Snapshots live in the active token store when it supports
+// history (git, object storage, postgres), so every replica sees the same history; plain file
+// deployments keep them in a directory next to the config file.
+package confighistory
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultRetention is the number of versions kept; older versions are pruned on every save.
+const DefaultRetention = 100
+
+// ErrVersionNotFound is returned when a version ID does not exist.
+var ErrVersionNotFound = errors.New("config version not found")
+
+// Version describes one stored config snapshot.
+type Version struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Actor     string    `json:"actor,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	SHA256    string    `json:"sha256"`
+	Size      int       `json:"size"`
+}
+
+// Store persists config versions. Token stores implement it to share history across replicas.
+type Store interface {
+	SaveConfigVersion(ctx context.Context, version Version, data []byte) error
+	ListConfigVersions(ctx context.Context) ([]Version, error)
+	LoadConfigVersion(ctx context.Context, id string) (Version, []byte, error)
+	DeleteConfigVersion(ctx context.Context, id string) error
+}
+
+// NewVersion builds the metadata for a snapshot of data. IDs sort chronologically.
+func NewVersion(data []byte, actor, reason string, now time.Time) Version {
+	sum := sha256.Sum256(data)
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	now = now.UTC()
+	return Version{
+		ID:        now.Format("20060102T150405.000000000Z") + "-" + hex.EncodeToString(suffix),
+		CreatedAt: now,
+		Actor:     actor,
+		Reason:    reason,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Size:      len(data),
+	}
+}
+
+// ValidID reports whether id has the shape produced by NewVersion, so IDs can be used as
+// file names and object keys safely.
+func ValidID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '.' || r == '-') {
+			return false
+		}
+	}
+	return !strings.Contains(id, "..")
+}
+
+// List returns all versions, newest first.
+func List(ctx context.Context, store Store) ([]Version, error) {
+	versions, err := store.ListConfigVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ID > versions[j].ID })
+	return versions, nil
+}
+
+// Record stores data as a new version unless it matches the latest version, then prunes
+// versions beyond keep. It reports the stored version and whether a new one was created.
+func Record(ctx context.Context, store Store, data []byte, actor, reason string, keep int) (Version, bool, error) {
+	versions, err := List(ctx, store)
+	if err != nil {
+		return Version{}, false, err
+	}
+	version := NewVersion(data, actor, reason, time.Now())
+	if len(versions) > 0 {
+		if versions[0].SHA256 == version.SHA256 {
+			return versions[0], false, nil
+		}
+		// Keep IDs ordered even when the clock of this replica lags behind the latest version.
+		if version.ID <= versions[0].ID {
+			version = NewVersion(data, actor, reason, versions[0].CreatedAt.Add(time.Microsecond))
+		}
+	}
+	if err = store.SaveConfigVersion(ctx, version, data); err != nil {
+		return Version{}, false, err
+	}
+	if keep <= 0 {
+		keep = DefaultRetention
+	}
+	// versions does not include the new one yet, so keep-1 of the old ones survive.
+	for i := keep - 1; i < len(versions); i++ {
+		if errDelete := store.DeleteConfigVersion(ctx, versions[i].ID); errDelete != nil {
+			return version, true, fmt.Errorf("prune version %s: %w", versions[i].ID, errDelete)
+		}
+	}
+	return version, true, nil
+}
+
+// DirStore keeps versions as <id>.yaml plus <id>.json metadata files in a directory.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore returns a Store backed by dir, which is created on first save.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+// Dir returns the directory holding the versions.
+func (s *DirStore) Dir() string { return s.dir }
+
+// Paths returns the content and metadata file paths of version id.
+func (s *DirStore) Paths(id string) (content, meta string) {
+	return filepath.Join(s.dir, id+".yaml"), filepath.Join(s.dir, id+".json")
+}
+
+// SaveConfigVersion writes the version content and metadata files.
+func (s *DirStore) SaveConfigVersion(_ context.Context, version Version, data []byte) error {
+	if !ValidID(version.ID) {
+		return fmt.Errorf("invalid version id %q", version.ID)
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(version)
+	if err != nil {
+		return err
+	}
+	contentPath, metaPath := s.Paths(version.ID)
+	if err = os.WriteFile(contentPath, data, 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, meta, 0o600)
+}
+
+// ListConfigVersions reads every metadata file in the directory.
+func (s *DirStore) ListConfigVersions(_ context.Context) ([]Version, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	versions := make([]Version, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		raw, errRead := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if errRead != nil {
+			return nil, errRead
+		}
+		var version Version
+		if errUnmarshal := json.Unmarshal(raw, &version); errUnmarshal != nil || !ValidID(version.ID) {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// LoadConfigVersion reads version id.
+func (s *DirStore) LoadConfigVersion(_ context.Context, id string) (Version, []byte, error) {
+	if !ValidID(id) {
+		return Version{}, nil, ErrVersionNotFound
+	}
+	contentPath, metaPath := s.Paths(id)
+	raw, err := os.ReadFile(metaPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Version{}, nil, ErrVersionNotFound
+		}
+		return Version{}, nil, err
+	}
+	var version Version
+	if err = json.Unmarshal(raw, &version); err != nil {
+		return Version{}, nil, err
+	}
+	data, err := os.ReadFile(contentPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Version{}, nil, ErrVersionNotFound
+		}
+		return Version{}, nil, err
+	}
+	return version, data, nil
+}
+
+// DeleteConfigVersion removes version id; missing versions are ignored.
+func (s *DirStore) DeleteConfigVersion(_ context.Context, id string) error {
+	if !ValidID(id) {
+		return nil
+	}
+	contentPath, metaPath := s.Paths(id)
+	for _, path := range []string{contentPath, metaPath} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package confighistory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecordSkipsUnchangedAndPrunes(t *testing.T) {
+	ctx := context.Background()
+	store := NewDirStore(t.TempDir())
+
+	first, created, err := Record(ctx, store, []byte("port: 1\n"), "alice", "initial", 2)
+	if err != nil || !created {
+		t.Fatalf("Record first: created=%v err=%v", created, err)
+	}
+	again, created, err := Record(ctx, store, []byte("port: 1\n"), "bob", "noop", 2)
+	if err != nil || created || again.ID != first.ID {
+		t.Fatalf("expected unchanged config to reuse %s, got %s created=%v err=%v", first.ID, again.ID, created, err)
+	}
+	if _, _, err = Record(ctx, store, []byte("port: 2\n"), "bob", "second", 2); err != nil {
+		t.Fatalf("Record second: %v", err)
+	}
+	third, _, err := Record(ctx, store, []byte("port: 3\n"), "carol", "third", 2)
+	if err != nil {
+		t.Fatalf("Record third: %v", err)
+	}
+
+	versions, err := List(ctx, store)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions after pruning, got %d", len(versions))
+	}
+	if versions[0].ID != third.ID || versions[0].Actor != "carol" {
+		t.Fatalf("expected newest version first, got %+v", versions[0])
+	}
+	if _, _, err = store.LoadConfigVersion(ctx, first.ID); !errors.Is(err, ErrVersionNotFound) {
+		t.Fatalf("expected pruned version to be gone, got %v", err)
+	}
+	version, data, err := store.LoadConfigVersion(ctx, third.ID)
+	if err != nil || string(data) != "port: 3\n" || version.Size != len(data) {
+		t.Fatalf("LoadConfigVersion: %+v %q %v", version, data, err)
+	}
+}
+
+func TestValidIDRejectsPaths(t *testing.T) {
+	for _, id := range []string{"", "../config", "a/b", `a\b`, "x..y"} {
+		if ValidID(id) {
+			t.Fatalf("expected %q to be rejected", id)
+		}
+	}
+	if !ValidID(NewVersion(nil, "", "", time.Now()).ID) {
+		t.Fatal("expected generated id to be valid")
+	}
+}
@@ -0,0 +1,9 @@
+package constant
+
+// Keys of values the API handlers record on the gin context for later stages of a request.
+const (
+	// ModelSplitAliasContextKey stores the weighted alias requested by the client.
+	ModelSplitAliasContextKey = "modelSplitAlias"
+	// ModelSplitTargetContextKey stores the split arm selected for the request.
+	ModelSplitTargetContextKey = "modelSplitTarget"
+)
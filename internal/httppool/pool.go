@@ -0,0 +1,361 @@
+// Package httppool shares upstream HTTP transports between executors. Transports are keyed
+// by proxy URL and transport settings, so credentials with the same proxy and tuning reuse
+// one connection pool, and every pool reports connection and request counters.
+package httppool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"golang.org/x/net/proxy"
+)
+
+const (
+	defaultMaxIdleConns        = 256
+	defaultMaxIdleConnsPerHost = 64
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultHTTP2PingTimeout    = 15 * time.Second
+
+	// idleEntryTTL is how long a pool may go unused before its transport is released.
+	idleEntryTTL = 15 * time.Minute
+	// pruneInterval limits how often unused pools are looked for.
+	pruneInterval = time.Minute
+)
+
+// Pool caches transports by proxy URL and settings.
+type Pool struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastPrune time.Time
+}
+
+// Stats reports counters of one connection pool.
+type Stats struct {
+	Proxy           string                 `json:"proxy,omitempty"`
+	Settings        config.TransportConfig `json:"settings"`
+	HTTP2           bool                   `json:"http2"`
+	OpenConns       int64                  `json:"open_conns"`
+	Dials           int64                  `json:"dials"`
+	DialErrors      int64                  `json:"dial_errors"`
+	Requests        int64                  `json:"requests"`
+	InFlight        int64                  `json:"in_flight"`
+	ReusedConns     int64                  `json:"reused_conns"`
+	HTTP1Responses  int64                  `json:"http1_responses"`
+	HTTP2Responses  int64                  `json:"http2_responses"`
+	TransportErrors int64                  `json:"transport_errors"`
+	LastUsed        time.Time              `json:"last_used"`
+}
+
+type entry struct {
+	proxy     string
+	settings  config.TransportConfig
+	transport *http.Transport
+
+	openConns, dials, dialErrors                 atomic.Int64
+	requests, inFlight, reused                   atomic.Int64
+	http1Responses, http2Responses, transportErr atomic.Int64
+	lastUsed                                     atomic.Int64
+}
+
+var shared = New()
+
+// Shared returns the process-wide pool used by executors and the default RoundTripper provider.
+func Shared() *Pool { return shared }
+
+// New returns an empty pool.
+func New() *Pool {
+	return &Pool{entries: make(map[string]*entry)}
+}
+
+// Settings returns the transport settings for a credential: the global block of cfg with the
+// overrides stored in the credential attributes applied on top.
+func Settings(cfg *config.Config, attributes map[string]string) config.TransportConfig {
+	var settings config.TransportConfig
+	if cfg != nil {
+		settings = cfg.Transport
+	}
+	if attributes != nil {
+		settings = settings.Overlay(config.DecodeTransportAttribute(attributes[config.TransportAttributeKey]))
+	}
+	return settings
+}
+
+// RoundTripper returns the pooled round tripper for proxyURL and settings, creating it on
+// first use. An empty proxyURL dials upstreams directly.
+func (p *Pool) RoundTripper(proxyURL string, settings config.TransportConfig) (http.RoundTripper, error) {
+	proxyURL = strings.TrimSpace(proxyURL)
+	key, err := poolKey(proxyURL, settings)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pruneLocked()
+	if e := p.entries[key]; e != nil {
+		return e, nil
+	}
+	e := &entry{proxy: redactProxy(proxyURL), settings: settings}
+	transport, err := e.buildTransport(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	e.transport = transport
+	e.lastUsed.Store(time.Now().UnixNano())
+	p.entries[key] = e
+	return e, nil
+}
+
+// Stats returns the counters of every pool, most recently used first.
+func (p *Pool) Stats() []Stats {
+	p.mu.Lock()
+	entries := make([]*entry, 0, len(p.entries))
+	for _, e := range p.entries {
+		entries = append(entries, e)
+	}
+	p.mu.Unlock()
+
+	out := make([]Stats, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, Stats{
+			Proxy:           e.proxy,
+			Settings:        e.settings,
+			HTTP2:           !e.settings.HTTP2Disabled(),
+			OpenConns:       e.openConns.Load(),
+			Dials:           e.dials.Load(),
+			DialErrors:      e.dialErrors.Load(),
+			Requests:        e.requests.Load(),
+			InFlight:        e.inFlight.Load(),
+			ReusedConns:     e.reused.Load(),
+			HTTP1Responses:  e.http1Responses.Load(),
+			HTTP2Responses:  e.http2Responses.Load(),
+			TransportErrors: e.transportErr.Load(),
+			LastUsed:        time.Unix(0, e.lastUsed.Load()),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastUsed.After(out[j].LastUsed) })
+	return out
+}
+
+// CloseIdleConnections closes idle connections of every pool.
+func (p *Pool) CloseIdleConnections() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.entries {
+		e.transport.CloseIdleConnections()
+	}
+}
+
+// pruneLocked releases pools that have been idle for idleEntryTTL, so settings changed by a
+// config reload do not keep their old transports alive.
+func (p *Pool) pruneLocked() {
+	now := time.Now()
+	if now.Sub(p.lastPrune) < pruneInterval {
+		return
+	}
+	p.lastPrune = now
+	for key, e := range p.entries {
+		if e.inFlight.Load() > 0 || now.Sub(time.Unix(0, e.lastUsed.Load())) < idleEntryTTL {
+			continue
+		}
+		e.transport.CloseIdleConnections()
+		delete(p.entries, key)
+	}
+}
+
+func poolKey(proxyURL string, settings config.TransportConfig) (string, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", fmt.Errorf("httppool: encode settings: %w", err)
+	}
+	return proxyURL + "|" + string(data), nil
+}
+
+// redactProxy masks the password of a proxy URL before it is reported.
+func redactProxy(proxyURL string) string {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Redacted()
+}
+
+func durationOr(seconds int, fallback time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+func intOr(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func (e *entry) buildTransport(proxyURL string) (*http.Transport, error) {
+	s := e.settings
+	dialer := &net.Dialer{
+		Timeout:   durationOr(s.DialTimeoutSeconds, defaultDialTimeout),
+		KeepAlive: durationOr(s.KeepAliveSeconds, defaultKeepAlive),
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           e.countDials(dialer.DialContext),
+		MaxIdleConns:          intOr(s.MaxIdleConns, defaultMaxIdleConns),
+		MaxIdleConnsPerHost:   intOr(s.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:       s.MaxConnsPerHost,
+		IdleConnTimeout:       durationOr(s.IdleConnTimeoutSeconds, defaultIdleConnTimeout),
+		TLSHandshakeTimeout:   durationOr(s.TLSHandshakeTimeoutSeconds, defaultTLSHandshakeTimeout),
+		ResponseHeaderTimeout: durationOr(s.ResponseHeaderTimeoutSeconds, 0),
+		ExpectContinueTimeout: time.Second,
+	}
+
+	if proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("httppool: parse proxy URL: %w", err)
+		}
+		switch parsed.Scheme {
+		case "socks5":
+			var proxyAuth *proxy.Auth
+			if parsed.User != nil {
+				password, _ := parsed.User.Password()
+				proxyAuth = &proxy.Auth{User: parsed.User.Username(), Password: password}
+			}
+			socks, err := proxy.SOCKS5("tcp", parsed.Host, proxyAuth, dialer)
+			if err != nil {
+				return nil, fmt.Errorf("httppool: create SOCKS5 dialer: %w", err)
+			}
+			contextDialer, ok := socks.(proxy.ContextDialer)
+			if !ok {
+				return nil, fmt.Errorf("httppool: SOCKS5 dialer does not support contexts")
+			}
+			transport.Proxy = nil
+			transport.DialContext = e.countDials(contextDialer.DialContext)
+		case "http", "https":
+			transport.Proxy = http.ProxyURL(parsed)
+		default:
+			return nil, fmt.Errorf("httppool: unsupported proxy scheme: %s", parsed.Scheme)
+		}
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if !s.HTTP2Disabled() {
+		protocols.SetHTTP2(true)
+		h2 := &http.HTTP2Config{
+			SendPingTimeout: durationOr(s.HTTP2PingIntervalSeconds, 0),
+			PingTimeout:     durationOr(s.HTTP2PingTimeoutSeconds, defaultHTTP2PingTimeout),
+		}
+		if s.HTTP2StrictMaxConcurrentStreams != nil {
+			h2.StrictMaxConcurrentRequests = *s.HTTP2StrictMaxConcurrentStreams
+		}
+		transport.HTTP2 = h2
+	}
+	transport.Protocols = protocols
+	return transport, nil
+}
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (e *entry) countDials(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		e.dials.Add(1)
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			e.dialErrors.Add(1)
+			return nil, err
+		}
+		e.openConns.Add(1)
+		return &countedConn{Conn: conn, open: &e.openConns}, nil
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (e *entry) RoundTrip(req *http.Request) (*http.Response, error) {
+	e.requests.Add(1)
+	e.inFlight.Add(1)
+	e.lastUsed.Store(time.Now().UnixNano())
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				e.reused.Add(1)
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	resp, err := e.transport.RoundTrip(req)
+	if err != nil {
+		e.transportErr.Add(1)
+		e.inFlight.Add(-1)
+		return nil, err
+	}
+	if resp.ProtoMajor == 2 {
+		e.http2Responses.Add(1)
+	} else {
+		e.http1Responses.Add(1)
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		e.inFlight.Add(-1)
+		return resp, nil
+	}
+	// Streaming responses stay in flight until the body is drained or closed.
+	resp.Body = &trackedBody{ReadCloser: resp.Body, inFlight: &e.inFlight}
+	return resp, nil
+}
+
+// CloseIdleConnections lets http.Client.CloseIdleConnections reach the pooled transport.
+func (e *entry) CloseIdleConnections() {
+	e.transport.CloseIdleConnections()
+}
+
+type countedConn struct {
+	net.Conn
+	open *atomic.Int64
+	once sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() { c.open.Add(-1) })
+	return c.Conn.Close()
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	inFlight *atomic.Int64
+	once     sync.Once
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done()
+	return err
+}
+
+func (b *trackedBody) done() {
+	b.once.Do(func() { b.inFlight.Add(-1) })
+}
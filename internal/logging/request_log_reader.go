@@ -0,0 +1,366 @@
+package logging
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// requestLogNamePattern matches the file names produced by FileRequestLogger.generateFilename,
+// optionally prefixed with "error-" for forced error logs.
+var requestLogNamePattern = regexp.MustCompile(`^(error-)?(.+)-(\d{4}-\d{2}-\d{2}T\d{6})-([^-]+)\.log$`)
+
+// requestLogSectionPattern matches section headers such as "=== API REQUEST 2 ===".
+var requestLogSectionPattern = regexp.MustCompile(`^=== ([A-Z ]+?)(?: (\d+))? ===$`)
+
+// summaryBodyLimit bounds how much of the request body is buffered when summarising a log.
+const summaryBodyLimit = 64 << 10
+
+// RequestLogName describes the parts encoded in a request log file name.
+type RequestLogName struct {
+	Path      string
+	Timestamp time.Time
+	RequestID string
+	Error     bool
+}
+
+// ParseRequestLogName reports whether name was written by FileRequestLogger and, if so, what it encodes.
+func ParseRequestLogName(name string) (RequestLogName, bool) {
+	match := requestLogNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return RequestLogName{}, false
+	}
+	ts, err := time.ParseInLocation("2006-01-02T150405", match[3], time.Local)
+	if err != nil {
+		return RequestLogName{}, false
+	}
+	return RequestLogName{
+		Path:      match[2],
+		Timestamp: ts,
+		RequestID: match[4],
+		Error:     match[1] != "",
+	}, true
+}
+
+// RequestLogSummary holds the fields of a request log needed to list and filter it.
+type RequestLogSummary struct {
+	URL       string    `json:"url"`
+	Method    string    `json:"method"`
+	Model     string    `json:"model,omitempty"`
+	Status    int       `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	Attempts  int       `json:"attempts"`
+}
+
+// RequestLogAttempt is one upstream request and its response within a request log.
+type RequestLogAttempt struct {
+	Index           int                 `json:"index"`
+	Timestamp       string              `json:"timestamp,omitempty"`
+	UpstreamURL     string              `json:"upstream_url,omitempty"`
+	Method          string              `json:"method,omitempty"`
+	Auth            string              `json:"auth,omitempty"`
+	RequestHeaders  map[string][]string `json:"request_headers,omitempty"`
+	RequestBody     string              `json:"request_body,omitempty"`
+	Status          int                 `json:"status,omitempty"`
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+	ResponseChunks  []string            `json:"response_chunks,omitempty"`
+	Errors          []string            `json:"errors,omitempty"`
+}
+
+// RequestLogError is an "API ERROR RESPONSE" entry of a request log.
+type RequestLogError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// RequestLogRecord is the structured form of a request log file.
+type RequestLogRecord struct {
+	RequestLogSummary
+	Version         string              `json:"version,omitempty"`
+	RequestHeaders  map[string][]string `json:"request_headers,omitempty"`
+	RequestBody     string              `json:"request_body"`
+	Attempts        []RequestLogAttempt `json:"upstream_attempts"`
+	Errors          []RequestLogError   `json:"upstream_errors,omitempty"`
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+	ResponseBody    string              `json:"response_body"`
+}
+
+// requestLogParser walks a request log line by line, tracking the current section.
+type requestLogParser struct {
+	// summaryOnly limits buffering to what RequestLogSummary needs.
+	summaryOnly bool
+	record      RequestLogRecord
+
+	section  string
+	attempt  *RequestLogAttempt
+	part     string // "", "headers" or "body" inside API REQUEST/RESPONSE sections
+	sawBlank bool
+	body     strings.Builder
+}
+
+// ParseRequestLog reads a complete request log written by FileRequestLogger.
+func ParseRequestLog(r io.Reader) (*RequestLogRecord, error) {
+	p := &requestLogParser{}
+	if err := p.run(r); err != nil {
+		return nil, err
+	}
+	return &p.record, nil
+}
+
+// SummarizeRequestLog extracts the listing fields of a request log without keeping bodies in memory.
+func SummarizeRequestLog(r io.Reader) (RequestLogSummary, error) {
+	p := &requestLogParser{summaryOnly: true}
+	if err := p.run(r); err != nil {
+		return RequestLogSummary{}, err
+	}
+	return p.record.RequestLogSummary, nil
+}
+
+func (p *requestLogParser) run(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" || err == nil {
+			p.line(strings.TrimRight(line, "\r\n"))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	p.flush()
+	p.record.RequestLogSummary.Attempts = len(p.record.Attempts)
+	if p.summaryOnly {
+		p.record.Attempts = nil
+	}
+	if p.record.Model == "" {
+		p.record.Model = modelFromURL(p.record.URL)
+	}
+	return nil
+}
+
+func (p *requestLogParser) line(line string) {
+	if match := requestLogSectionPattern.FindStringSubmatch(line); match != nil {
+		p.flush()
+		p.section = match[1]
+		p.part = ""
+		p.sawBlank = false
+		index, _ := strconv.Atoi(match[2])
+		switch p.section {
+		case "API REQUEST":
+			p.record.Attempts = append(p.record.Attempts, RequestLogAttempt{Index: index})
+			p.attempt = &p.record.Attempts[len(p.record.Attempts)-1]
+		case "API RESPONSE":
+			p.attempt = p.attemptByIndex(index)
+		case "API ERROR RESPONSE":
+			p.record.Errors = append(p.record.Errors, RequestLogError{})
+		}
+		return
+	}
+
+	switch p.section {
+	case "REQUEST INFO":
+		key, value, ok := splitLogField(line)
+		if !ok {
+			return
+		}
+		switch key {
+		case "Version":
+			p.record.Version = value
+		case "URL":
+			p.record.URL = value
+		case "Method":
+			p.record.Method = value
+		case "Timestamp":
+			p.record.Timestamp, _ = time.Parse(time.RFC3339Nano, value)
+		}
+	case "HEADERS":
+		if !p.summaryOnly {
+			p.record.RequestHeaders = addLogHeader(p.record.RequestHeaders, line)
+		}
+	case "REQUEST BODY":
+		p.appendBody(line)
+	case "API REQUEST":
+		p.attemptRequestLine(line)
+	case "API RESPONSE":
+		p.attemptResponseLine(line)
+	case "API ERROR RESPONSE":
+		entry := &p.record.Errors[len(p.record.Errors)-1]
+		if key, value, ok := splitLogField(line); ok && key == "HTTP Status" && entry.Status == 0 {
+			entry.Status, _ = strconv.Atoi(value)
+			return
+		}
+		p.appendBody(line)
+	case "RESPONSE":
+		if !p.sawBlank {
+			if line == "" {
+				p.sawBlank = true
+				return
+			}
+			if key, value, ok := splitLogField(line); ok && key == "Status" && p.record.Status == 0 {
+				p.record.Status, _ = strconv.Atoi(value)
+				return
+			}
+			if !p.summaryOnly {
+				p.record.ResponseHeaders = addLogHeader(p.record.ResponseHeaders, line)
+			}
+			return
+		}
+		p.appendBody(line)
+	}
+}
+
+func (p *requestLogParser) attemptRequestLine(line string) {
+	if p.summaryOnly {
+		return
+	}
+	a := p.attempt
+	switch {
+	case p.part == "" && line == "Headers:":
+		p.part = "headers"
+	case p.part == "headers" && line == "Body:":
+		p.part = "body"
+	case p.part == "":
+		key, value, ok := splitLogField(line)
+		if !ok {
+			return
+		}
+		switch key {
+		case "Timestamp":
+			a.Timestamp = value
+		case "Upstream URL":
+			a.UpstreamURL = value
+		case "HTTP Method":
+			a.Method = value
+		case "Auth":
+			a.Auth = value
+		}
+	case p.part == "headers":
+		a.RequestHeaders = addLogHeader(a.RequestHeaders, line)
+	default:
+		p.appendBody(line)
+	}
+}
+
+func (p *requestLogParser) attemptResponseLine(line string) {
+	if p.summaryOnly {
+		return
+	}
+	a := p.attempt
+	switch {
+	case p.part == "body":
+		p.appendBody(line)
+	case line == "Headers:":
+		p.part = "headers"
+	case line == "Body:":
+		p.part = "body"
+	case p.part == "headers":
+		a.ResponseHeaders = addLogHeader(a.ResponseHeaders, line)
+	default:
+		key, value, ok := splitLogField(line)
+		if !ok {
+			return
+		}
+		switch key {
+		case "Timestamp":
+			if a.Timestamp == "" {
+				a.Timestamp = value
+			}
+		case "Status":
+			a.Status, _ = strconv.Atoi(value)
+		case "Error":
+			a.Errors = append(a.Errors, value)
+		}
+	}
+}
+
+// attemptByIndex returns the attempt a response section belongs to, creating it when the
+// request section is missing.
+func (p *requestLogParser) attemptByIndex(index int) *RequestLogAttempt {
+	for i := range p.record.Attempts {
+		if p.record.Attempts[i].Index == index {
+			return &p.record.Attempts[i]
+		}
+	}
+	p.record.Attempts = append(p.record.Attempts, RequestLogAttempt{Index: index})
+	return &p.record.Attempts[len(p.record.Attempts)-1]
+}
+
+func (p *requestLogParser) appendBody(line string) {
+	if p.summaryOnly && (p.section != "REQUEST BODY" || p.body.Len() > summaryBodyLimit) {
+		return
+	}
+	if p.body.Len() > 0 || line != "" {
+		p.body.WriteString(line)
+		p.body.WriteByte('\n')
+	}
+}
+
+// flush stores the body collected for the section that just ended.
+func (p *requestLogParser) flush() {
+	body := strings.TrimRight(p.body.String(), "\n")
+	p.body.Reset()
+	switch p.section {
+	case "REQUEST BODY":
+		if p.summaryOnly {
+			p.record.Model = gjson.Get(body, "model").String()
+			return
+		}
+		p.record.RequestBody = body
+		p.record.Model = gjson.Get(body, "model").String()
+	case "API REQUEST":
+		if p.attempt != nil {
+			p.attempt.RequestBody = body
+		}
+	case "API RESPONSE":
+		if p.attempt != nil && body != "" {
+			p.attempt.ResponseChunks = strings.Split(body, "\n\n")
+		}
+	case "API ERROR RESPONSE":
+		if len(p.record.Errors) > 0 {
+			p.record.Errors[len(p.record.Errors)-1].Message = body
+		}
+	case "RESPONSE":
+		p.record.ResponseBody = body
+	}
+}
+
+func splitLogField(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, ": ")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(value), true
+}
+
+func addLogHeader(headers map[string][]string, line string) map[string][]string {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok || strings.TrimSpace(key) == "" {
+		return headers
+	}
+	if headers == nil {
+		headers = make(map[string][]string)
+	}
+	key = strings.TrimSpace(key)
+	headers[key] = append(headers[key], strings.TrimSpace(value))
+	return headers
+}
+
+// modelFromURL extracts the model from Gemini-style paths such as /v1beta/models/{model}:generateContent.
+func modelFromURL(url string) string {
+	_, rest, ok := strings.Cut(url, "/models/")
+	if !ok {
+		return ""
+	}
+	if idx := strings.IndexAny(rest, ":/?"); idx >= 0 {
+		rest = rest[:idx]
+	}
+	return rest
+}
@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseRequestLogRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewFileRequestLogger(true, dir, "", 0)
+
+	apiRequest := []byte("=== API REQUEST 1 ===\nTimestamp: 2025-01-02T03:04:05Z\nUpstream URL: https://api.example.com/v1/messages\nHTTP Method: POST\nAuth: claude-user.json\n\nHeaders:\nContent-Type: application/json\n\nBody:\n{\"model\":\"claude-x\",\"messages\":[]}\n\n" +
+		"=== API REQUEST 2 ===\nTimestamp: 2025-01-02T03:04:06Z\nUpstream URL: https://api.example.com/v1/messages\n\nHeaders:\n\nBody:\n{\"model\":\"claude-x\"}\n\n")
+	apiResponse := []byte("=== API RESPONSE 1 ===\nTimestamp: 2025-01-02T03:04:05Z\n\nStatus: 429\nHeaders:\nRetry-After: 5\n\nBody:\n{\"error\":\"rate limited\"}\n\n" +
+		"=== API RESPONSE 2 ===\nTimestamp: 2025-01-02T03:04:06Z\n\nStatus: 200\nHeaders:\n\nBody:\ndata: {\"a\":1}\n\ndata: {\"b\":2}\n")
+	err := logger.LogRequest("/v1/chat/completions", "POST",
+		map[string][]string{"Content-Type": {"application/json"}},
+		[]byte(`{"model":"gpt-test","stream":true}`),
+		200, map[string][]string{"Content-Type": {"text/event-stream"}},
+		[]byte("data: {\"done\":true}\n\ndata: [DONE]"),
+		apiRequest, apiResponse, nil, "abc123", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("LogRequest() error = %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+	if len(matches) != 1 {
+		t.Fatalf("log files = %v, want 1", matches)
+	}
+	name := filepath.Base(matches[0])
+	parsedName, ok := ParseRequestLogName(name)
+	if !ok || parsedName.Path != "v1-chat-completions" || parsedName.RequestID != "abc123" || parsedName.Error {
+		t.Fatalf("ParseRequestLogName(%q) = %+v, %v", name, parsedName, ok)
+	}
+
+	f, err := os.Open(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	record, err := ParseRequestLog(f)
+	if err != nil {
+		t.Fatalf("ParseRequestLog() error = %v", err)
+	}
+
+	if record.URL != "/v1/chat/completions" || record.Method != "POST" || record.Model != "gpt-test" || record.Status != 200 {
+		t.Fatalf("summary = %+v", record.RequestLogSummary)
+	}
+	if record.RequestBody != `{"model":"gpt-test","stream":true}` {
+		t.Errorf("request body = %q", record.RequestBody)
+	}
+	if got := record.ResponseHeaders["Content-Type"]; len(got) != 1 || got[0] != "text/event-stream" {
+		t.Errorf("response headers = %v", record.ResponseHeaders)
+	}
+	if record.ResponseBody != "data: {\"done\":true}\n\ndata: [DONE]" {
+		t.Errorf("response body = %q", record.ResponseBody)
+	}
+	if len(record.Attempts) != 2 || record.RequestLogSummary.Attempts != 2 {
+		t.Fatalf("attempts = %+v", record.Attempts)
+	}
+	first, second := record.Attempts[0], record.Attempts[1]
+	if first.Auth != "claude-user.json" || first.Status != 429 || first.RequestBody != `{"model":"claude-x","messages":[]}` {
+		t.Errorf("first attempt = %+v", first)
+	}
+	if got := first.ResponseHeaders["Retry-After"]; len(got) != 1 || got[0] != "5" {
+		t.Errorf("first attempt response headers = %v", first.ResponseHeaders)
+	}
+	if second.Status != 200 || len(second.ResponseChunks) != 2 || second.ResponseChunks[1] != `data: {"b":2}` {
+		t.Errorf("second attempt = %+v", second)
+	}
+
+	if _, err = f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := SummarizeRequestLog(f)
+	if err != nil {
+		t.Fatalf("SummarizeRequestLog() error = %v", err)
+	}
+	if summary.Model != "gpt-test" || summary.Status != 200 || summary.Attempts != 2 {
+		t.Errorf("summary = %+v", summary)
+	}
+}
+
+func TestParseRequestLogNameRejectsAppLogs(t *testing.T) {
+	for _, name := range []string{"main.log", "main-2025-01-02T15-04-05.000.log", "main.log.1"} {
+		if _, ok := ParseRequestLogName(name); ok {
+			t.Errorf("ParseRequestLogName(%q) matched an application log", name)
+		}
+	}
+	parsed, ok := ParseRequestLogName("error-v1beta-models-gemini-pro-generateContent-2025-12-23T195811-a1b2c3d4.log")
+	if !ok || !parsed.Error || parsed.RequestID != "a1b2c3d4" {
+		t.Errorf("error log name parsed as %+v, %v", parsed, ok)
+	}
+}
+
+func TestModelFromURL(t *testing.T) {
+	if got := modelFromURL("/v1beta/models/gemini-2.5-pro:streamGenerateContent?alt=sse"); got != "gemini-2.5-pro" {
+		t.Errorf("modelFromURL = %q", got)
+	}
+	if got := modelFromURL("/v1/chat/completions"); got != "" {
+		t.Errorf("modelFromURL = %q, want empty", got)
+	}
+}
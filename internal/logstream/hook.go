@@ -0,0 +1,49 @@
+package logstream
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus hook publishing every log entry to a broker as a log event.
+type Hook struct {
+	broker    *Broker
+	formatter log.Formatter
+}
+
+// NewHook creates a hook that renders entries with formatter, matching the log file output.
+func NewHook(broker *Broker, formatter log.Formatter) *Hook {
+	return &Hook{broker: broker, formatter: formatter}
+}
+
+// Levels implements log.Hook.
+func (h *Hook) Levels() []log.Level { return log.AllLevels }
+
+// Fire implements log.Hook.
+func (h *Hook) Fire(entry *log.Entry) error {
+	e := Event{
+		Type:    TypeLog,
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: strings.TrimRight(entry.Message, "\r\n"),
+	}
+	if id, ok := entry.Data["request_id"].(string); ok {
+		e.RequestID = id
+	}
+	if provider, ok := entry.Data["provider"]; ok {
+		e.Provider = fmt.Sprint(provider)
+	}
+	e.Line = e.Message
+	if h.formatter != nil {
+		// Format a copy so the entry's output buffer is left alone.
+		clone := *entry
+		clone.Buffer = nil
+		if b, err := h.formatter.Format(&clone); err == nil {
+			e.Line = strings.TrimRight(string(b), "\r\n")
+		}
+	}
+	h.broker.Publish(e)
+	return nil
+}
@@ -0,0 +1,209 @@
+// Package logstream fans out application log lines and per-request summary events to live
+// subscribers such as the management stream endpoint. Publishing never blocks: slow subscribers
+// lose events instead of stalling the logger or the request path.
+package logstream
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Event types.
+const (
+	TypeLog     = "log"
+	TypeRequest = "request"
+)
+
+// defaultBacklog is the number of recent events replayed to new subscribers on request.
+const defaultBacklog = 500
+
+// Tokens is the token usage of a request event.
+type Tokens struct {
+	Input     int64 `json:"input"`
+	Output    int64 `json:"output"`
+	Reasoning int64 `json:"reasoning"`
+	Cached    int64 `json:"cached"`
+	Total     int64 `json:"total"`
+}
+
+// Event is a single streamed log line or request summary.
+type Event struct {
+	Type  string    `json:"type"`
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	// Line is the formatted log line as written to the log file (log events only).
+	Line      string `json:"line,omitempty"`
+	Message   string `json:"message,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+	Model     string `json:"model,omitempty"`
+	AuthIndex string `json:"auth_index,omitempty"`
+	// ClientKey is the masked client API key; clientKey holds the raw key for filtering.
+	ClientKey string  `json:"client_key,omitempty"`
+	Status    int     `json:"status,omitempty"`
+	LatencyMs int64   `json:"latency_ms,omitempty"`
+	Failed    bool    `json:"failed,omitempty"`
+	Tokens    *Tokens `json:"tokens,omitempty"`
+
+	clientKey string
+}
+
+// SetClientKey records the raw client key used for filtering; it is never serialized.
+func (e *Event) SetClientKey(key string) { e.clientKey = key }
+
+// Filter selects the events delivered to a subscriber. A filter on a field excludes events that
+// do not carry that field, so filtering by provider or client key hides unrelated log lines.
+type Filter struct {
+	// Level drops events less severe than this level ("debug", "info", "warn", "error"); empty keeps all.
+	Level string
+	// Types limits delivery to the listed event types; empty means all.
+	Types     []string
+	Provider  string
+	ClientKey string
+}
+
+// Match reports whether e passes the filter.
+func (f Filter) Match(e Event) bool {
+	if f.Level != "" {
+		minLevel, errMin := log.ParseLevel(f.Level)
+		level, errLevel := log.ParseLevel(e.Level)
+		if errMin == nil && errLevel == nil && level > minLevel {
+			return false
+		}
+	}
+	if len(f.Types) > 0 {
+		matched := false
+		for _, t := range f.Types {
+			if t == e.Type {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.Provider != "" && !strings.EqualFold(f.Provider, e.Provider) {
+		return false
+	}
+	if f.ClientKey != "" && f.ClientKey != e.clientKey {
+		return false
+	}
+	return true
+}
+
+// Subscription receives events matching its filter until Close is called.
+type Subscription struct {
+	broker  *Broker
+	filter  Filter
+	ch      chan Event
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// Events returns the channel delivering events. It is closed by Close.
+func (s *Subscription) Events() <-chan Event { return s.ch }
+
+// Dropped returns and resets the number of events lost because the subscriber fell behind.
+func (s *Subscription) Dropped() int64 { return s.dropped.Swap(0) }
+
+// Close unsubscribes and closes the event channel.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		delete(s.broker.subs, s)
+		s.broker.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// Broker distributes events to subscribers and keeps a short backlog for late joiners.
+type Broker struct {
+	mu      sync.Mutex
+	subs    map[*Subscription]struct{}
+	backlog []Event
+	next    int
+	full    bool
+}
+
+// NewBroker creates a broker keeping the last backlog events (defaultBacklog when <= 0).
+func NewBroker(backlog int) *Broker {
+	if backlog <= 0 {
+		backlog = defaultBacklog
+	}
+	return &Broker{subs: make(map[*Subscription]struct{}), backlog: make([]Event, backlog)}
+}
+
+var defaultBroker = NewBroker(defaultBacklog)
+
+// Default returns the process-wide broker.
+func Default() *Broker { return defaultBroker }
+
+// Publish records e in the backlog and delivers it to every matching subscriber.
+func (b *Broker) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.backlog[b.next] = e
+	b.next = (b.next + 1) % len(b.backlog)
+	if b.next == 0 {
+		b.full = true
+	}
+	for sub := range b.subs {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. Up to tail matching backlog events are queued first.
+func (b *Broker) Subscribe(filter Filter, buffer, tail int) *Subscription {
+	if buffer <= 0 {
+		buffer = 256
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	replay := b.recentLocked(filter, tail)
+	if len(replay) > buffer {
+		buffer = len(replay)
+	}
+	sub := &Subscription{broker: b, filter: filter, ch: make(chan Event, buffer)}
+	for _, e := range replay {
+		sub.ch <- e
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// recentLocked returns up to n of the newest backlog events matching filter, oldest first.
+func (b *Broker) recentLocked(filter Filter, n int) []Event {
+	if n <= 0 {
+		return nil
+	}
+	size := b.next
+	if b.full {
+		size = len(b.backlog)
+	}
+	var out []Event
+	for i := 1; i <= size && len(out) < n; i++ {
+		e := b.backlog[(b.next-i+len(b.backlog))%len(b.backlog)]
+		if filter.Match(e) {
+			out = append(out, e)
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
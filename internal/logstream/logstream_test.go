@@ -0,0 +1,86 @@
+package logstream
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestFilterMatch(t *testing.T) {
+	req := Event{Type: TypeRequest, Level: "info", Provider: "claude"}
+	req.SetClientKey("client-a")
+	debug := Event{Type: TypeLog, Level: "debug", Line: "noise"}
+
+	cases := []struct {
+		name   string
+		filter Filter
+		event  Event
+		want   bool
+	}{
+		{"empty filter keeps all", Filter{}, debug, true},
+		{"level drops less severe", Filter{Level: "info"}, debug, false},
+		{"level keeps equal", Filter{Level: "info"}, req, true},
+		{"type mismatch", Filter{Types: []string{TypeLog}}, req, false},
+		{"provider case insensitive", Filter{Provider: "Claude"}, req, true},
+		{"provider excludes events without it", Filter{Provider: "claude"}, debug, false},
+		{"client key matches raw key", Filter{ClientKey: "client-a"}, req, true},
+		{"client key mismatch", Filter{ClientKey: "client-b"}, req, false},
+	}
+	for _, tc := range cases {
+		if got := tc.filter.Match(tc.event); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSubscribeReplaysMatchingTail(t *testing.T) {
+	b := NewBroker(4)
+	for i, level := range []string{"info", "debug", "warning", "info", "error", "debug"} {
+		b.Publish(Event{Type: TypeLog, Level: level, Message: string(rune('a' + i))})
+	}
+	sub := b.Subscribe(Filter{Level: "info"}, 8, 10)
+	defer sub.Close()
+
+	var got string
+	for len(sub.Events()) > 0 {
+		got += (<-sub.Events()).Message
+	}
+	// The backlog holds the last four events (c..f); debug entries are filtered out.
+	if got != "cde" {
+		t.Fatalf("replayed %q, want %q", got, "cde")
+	}
+}
+
+func TestPublishCountsDropsForSlowSubscriber(t *testing.T) {
+	b := NewBroker(8)
+	sub := b.Subscribe(Filter{}, 1, 0)
+	defer sub.Close()
+	for i := 0; i < 3; i++ {
+		b.Publish(Event{Type: TypeLog, Level: "info"})
+	}
+	if dropped := sub.Dropped(); dropped != 2 {
+		t.Fatalf("dropped = %d, want 2", dropped)
+	}
+	if dropped := sub.Dropped(); dropped != 0 {
+		t.Fatalf("Dropped did not reset, got %d", dropped)
+	}
+}
+
+func TestHookPublishesFormattedLine(t *testing.T) {
+	b := NewBroker(8)
+	sub := b.Subscribe(Filter{}, 4, 0)
+	defer sub.Close()
+
+	logger := log.New()
+	logger.SetLevel(log.DebugLevel)
+	logger.AddHook(NewHook(b, &log.TextFormatter{DisableTimestamp: true}))
+	logger.WithField("provider", "gemini").Warn("upstream slow")
+
+	e := <-sub.Events()
+	if e.Type != TypeLog || e.Level != "warning" || e.Provider != "gemini" || e.Message != "upstream slow" {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	if e.Line == "" {
+		t.Fatal("expected formatted line")
+	}
+}
@@ -0,0 +1,168 @@
+package payloadscript
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	starlarkjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+)
+
+// builtins are the functions scripts use to work with the payload:
+//
+//	get(path)                         value at path decoded from JSON, None when missing
+//	exists(path)                      whether path is present
+//	set(path, value)                  writes value, encoded as JSON, at path
+//	set_raw(path, json)               writes a raw JSON fragment at path
+//	delete(path)                      removes path
+//	payload()                         the whole payload as JSON text
+//	set_payload(text)                 replaces the whole payload
+//	tokens(s)                         rough token estimate of a string (4 bytes per token)
+//	matches(s, pattern)               RE2 regular expression test
+//	regex_replace(s, pattern, repl)   RE2 replacement, repl may reference groups as $1
+var builtins = starlark.StringDict{
+	"get":           starlark.NewBuiltin("get", builtinGet),
+	"exists":        starlark.NewBuiltin("exists", builtinExists),
+	"set":           starlark.NewBuiltin("set", builtinSet),
+	"set_raw":       starlark.NewBuiltin("set_raw", builtinSetRaw),
+	"delete":        starlark.NewBuiltin("delete", builtinDelete),
+	"payload":       starlark.NewBuiltin("payload", builtinPayload),
+	"set_payload":   starlark.NewBuiltin("set_payload", builtinSetPayload),
+	"tokens":        starlark.NewBuiltin("tokens", builtinTokens),
+	"matches":       starlark.NewBuiltin("matches", builtinMatches),
+	"regex_replace": starlark.NewBuiltin("regex_replace", builtinRegexReplace),
+	"json":          starlarkjson.Module,
+}
+
+// runOf returns the run state Run attached to thread.
+func runOf(thread *starlark.Thread) *run {
+	return thread.Local(runLocalKey).(*run)
+}
+
+func builtinGet(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &path); err != nil {
+		return nil, err
+	}
+	r := runOf(thread)
+	res := gjson.GetBytes(r.payload, r.path(path))
+	if !res.Exists() {
+		return starlark.None, nil
+	}
+	return starlark.Call(thread, starlarkjson.Module.Members["decode"], starlark.Tuple{starlark.String(res.Raw)}, nil)
+}
+
+func builtinExists(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &path); err != nil {
+		return nil, err
+	}
+	r := runOf(thread)
+	return starlark.Bool(gjson.GetBytes(r.payload, r.path(path)).Exists()), nil
+}
+
+func builtinSet(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		path  string
+		value starlark.Value
+	)
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &path, &value); err != nil {
+		return nil, err
+	}
+	encoded, err := starlark.Call(thread, starlarkjson.Module.Members["encode"], starlark.Tuple{value}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return setRaw(thread, b, path, string(encoded.(starlark.String)))
+}
+
+func builtinSetRaw(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path, raw string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &path, &raw); err != nil {
+		return nil, err
+	}
+	if !gjson.Valid(raw) {
+		return nil, fmt.Errorf("%s: invalid JSON for %s", b.Name(), path)
+	}
+	return setRaw(thread, b, path, raw)
+}
+
+func setRaw(thread *starlark.Thread, b *starlark.Builtin, path, raw string) (starlark.Value, error) {
+	r := runOf(thread)
+	updated, err := sjson.SetRawBytes(r.payload, r.path(path), []byte(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", b.Name(), path, err)
+	}
+	r.payload = updated
+	return starlark.None, nil
+}
+
+func builtinDelete(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &path); err != nil {
+		return nil, err
+	}
+	r := runOf(thread)
+	updated, err := sjson.DeleteBytes(r.payload, r.path(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", b.Name(), path, err)
+	}
+	r.payload = updated
+	return starlark.None, nil
+}
+
+func builtinPayload(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.String(runOf(thread).payload), nil
+}
+
+func builtinSetPayload(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var text string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &text); err != nil {
+		return nil, err
+	}
+	runOf(thread).payload = []byte(text)
+	return starlark.None, nil
+}
+
+func builtinTokens(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &s); err != nil {
+		return nil, err
+	}
+	return starlark.MakeInt((len(s) + 3) / 4), nil
+}
+
+func builtinMatches(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var s, pattern string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &s, &pattern); err != nil {
+		return nil, err
+	}
+	re, err := regexps.get(pattern, regexp.Compile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.Bool(re.MatchString(s)), nil
+}
+
+func builtinRegexReplace(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var s, pattern, repl string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 3, &s, &pattern, &repl); err != nil {
+		return nil, err
+	}
+	re, err := regexps.get(pattern, regexp.Compile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.String(re.ReplaceAllString(s, repl)), nil
+}
+
+// regexCacheSize bounds the compiled patterns kept across all scripts. Patterns built at
+// runtime from payload data would otherwise grow the cache without limit.
+const regexCacheSize = 256
+
+var regexps = newLRUCache[*regexp.Regexp](regexCacheSize)
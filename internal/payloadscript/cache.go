@@ -0,0 +1,60 @@
+package payloadscript
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache keeps the most recently used values built from string keys, up to limit entries.
+type lruCache[V any] struct {
+	mu      sync.Mutex
+	limit   int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newLRUCache[V any](limit int) *lruCache[V] {
+	return &lruCache[V]{limit: limit, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// get returns the cached value for key, building and storing it on a miss. Build errors are
+// not cached.
+func (c *lruCache[V]) get(key string, build func(string) (V, error)) (V, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		value := elem.Value.(*lruEntry[V]).value
+		c.mu.Unlock()
+		return value, nil
+	}
+	c.mu.Unlock()
+
+	value, err := build(key)
+	if err != nil {
+		return value, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*lruEntry[V]).value, nil
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value})
+	for c.order.Len() > c.limit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+	return value, nil
+}
+
+func (c *lruCache[V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
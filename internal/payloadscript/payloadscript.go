@@ -0,0 +1,166 @@
+// Package payloadscript runs the transform scripts of payload script rules. Scripts are
+// Starlark (https://github.com/bazelbuild/starlark) and run against a single JSON payload (a
+// translated request or one response event) with read-only request metadata. They have no
+// access to the network, the file system or the clock, cannot recurse or use while loops,
+// and every run is bounded by a step budget and a timeout.
+//
+//	# comments start with '#'
+//	if client_key == "team-a" and protocol == "openai":
+//	    set("messages.0.content", "Answer briefly.")
+//	elif tokens(payload()) > 8000:
+//	    set("max_tokens", min(get("max_tokens") or 1024, 1024))
+//	delete("metadata.user_id")
+//	set_payload(regex_replace(payload(), "sk-[A-Za-z0-9]+", "[redacted]"))
+//
+// The variables phase, client_key, model, requested_model, protocol, provider, auth_id,
+// auth_label and stream describe the request. The payload functions are listed in
+// builtins.go; paths use gjson/sjson syntax and are relative to the payload root of the
+// provider. Besides the Starlark built-ins, the json module (json.encode, json.decode) is
+// available.
+package payloadscript
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+const (
+	// DefaultTimeout bounds a script run when no timeout is configured.
+	DefaultTimeout = 50 * time.Millisecond
+	// DefaultMaxSteps bounds the number of Starlark execution steps per run.
+	DefaultMaxSteps = 100000
+)
+
+// Phases reported to scripts through the phase variable.
+const (
+	PhaseRequest  = "request"
+	PhaseResponse = "response"
+)
+
+// Env is the request metadata visible to scripts as read-only variables.
+type Env struct {
+	Phase          string
+	ClientKey      string
+	Model          string
+	RequestedModel string
+	Protocol       string
+	Provider       string
+	AuthID         string
+	AuthLabel      string
+	Stream         bool
+}
+
+// variables maps the read-only script variables to their Env fields.
+var variables = map[string]func(Env) starlark.Value{
+	"phase":           func(e Env) starlark.Value { return starlark.String(e.Phase) },
+	"client_key":      func(e Env) starlark.Value { return starlark.String(e.ClientKey) },
+	"model":           func(e Env) starlark.Value { return starlark.String(e.Model) },
+	"requested_model": func(e Env) starlark.Value { return starlark.String(e.RequestedModel) },
+	"protocol":        func(e Env) starlark.Value { return starlark.String(e.Protocol) },
+	"provider":        func(e Env) starlark.Value { return starlark.String(e.Provider) },
+	"auth_id":         func(e Env) starlark.Value { return starlark.String(e.AuthID) },
+	"auth_label":      func(e Env) starlark.Value { return starlark.String(e.AuthLabel) },
+	"stream":          func(e Env) starlark.Value { return starlark.Bool(e.Stream) },
+}
+
+// fileOptions allows if and for at top level, which is where transform scripts live.
+// while loops, recursion and the set type stay disabled.
+var fileOptions = &syntax.FileOptions{TopLevelControl: true, GlobalReassign: true}
+
+func isPredeclared(name string) bool {
+	if _, ok := variables[name]; ok {
+		return true
+	}
+	return builtins.Has(name)
+}
+
+// Limits bounds a script run. Zero values select the defaults.
+type Limits struct {
+	Timeout  time.Duration
+	MaxSteps int
+}
+
+// Program is a compiled script. Programs are immutable and safe for concurrent use.
+type Program struct {
+	prog *starlark.Program
+}
+
+// Compile parses and resolves src into a Program. Unknown names are compile errors.
+func Compile(src string) (*Program, error) {
+	_, prog, err := starlark.SourceProgramOptions(fileOptions, "payload-script", src, isPredeclared)
+	if err != nil {
+		return nil, fmt.Errorf("payload script: %w", err)
+	}
+	if prog.NumLoads() > 0 {
+		return nil, fmt.Errorf("payload script: load statements are not supported")
+	}
+	return &Program{prog: prog}, nil
+}
+
+// programCacheSize bounds the compiled scripts kept across config reloads. Scripts that were
+// edited or removed fall out of the cache once newer sources displace them.
+const programCacheSize = 128
+
+var programs = newLRUCache[*Program](programCacheSize)
+
+// CompileCached compiles src once and reuses the Program for identical sources.
+func CompileCached(src string) (*Program, error) {
+	return programs.get(src, Compile)
+}
+
+// Run executes the program against payload and returns the transformed payload. Paths are
+// resolved relative to root when it is not empty. On error the original payload is returned
+// together with the error.
+func (p *Program) Run(ctx context.Context, payload []byte, root string, env Env, limits Limits) ([]byte, error) {
+	if limits.Timeout <= 0 {
+		limits.Timeout = DefaultTimeout
+	}
+	if limits.MaxSteps <= 0 {
+		limits.MaxSteps = DefaultMaxSteps
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r := &run{root: strings.TrimSpace(root), payload: payload}
+	thread := &starlark.Thread{Name: "payload-script", Print: func(*starlark.Thread, string) {}}
+	thread.SetLocal(runLocalKey, r)
+	thread.SetMaxExecutionSteps(uint64(limits.MaxSteps))
+	timer := time.AfterFunc(limits.Timeout, func() { thread.Cancel("timeout exceeded") })
+	defer timer.Stop()
+	stop := context.AfterFunc(ctx, func() { thread.Cancel(ctx.Err().Error()) })
+	defer stop()
+
+	predeclared := make(starlark.StringDict, len(variables)+len(builtins))
+	for name, value := range builtins {
+		predeclared[name] = value
+	}
+	for name, get := range variables {
+		predeclared[name] = get(env)
+	}
+	if _, err := p.prog.Init(thread, predeclared); err != nil {
+		return payload, fmt.Errorf("payload script: %w", err)
+	}
+	return r.payload, nil
+}
+
+// runLocalKey stores the run state on the Starlark thread for the payload builtins.
+const runLocalKey = "payloadscript.run"
+
+// run is the mutable state of one script execution.
+type run struct {
+	root    string
+	payload []byte
+}
+
+func (r *run) path(path string) string {
+	path = strings.TrimSpace(path)
+	if r.root == "" {
+		return path
+	}
+	return r.root + "." + strings.TrimPrefix(path, ".")
+}
@@ -0,0 +1,152 @@
+package payloadscript
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunTransformsPayload(t *testing.T) {
+	src := `
+# rewrite the system prompt for one client
+if client_key == "team-a" and get("messages.0.role") == "system":
+    set("messages.0.content", "Answer briefly. " + get("messages.0.content"))
+limit = 100
+if client_key and tokens(payload()) > 10:
+    set("max_tokens", min(get("max_tokens"), limit))
+    set_raw("metadata", json.encode({"auth": auth_id}))
+    delete("user")
+`
+	prog, err := Compile(src)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	payload := []byte(`{"messages":[{"role":"system","content":"Be nice."}],"max_tokens":4096,"user":"u1"}`)
+	env := Env{Phase: PhaseRequest, ClientKey: "team-a", AuthID: "auth-1"}
+	out, err := prog.Run(context.Background(), payload, "", env, Limits{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := `{"messages":[{"role":"system","content":"Answer briefly. Be nice."}],"max_tokens":100,"metadata":{"auth":"auth-1"}}`
+	if string(out) != want {
+		t.Fatalf("Run() = %s, want %s", out, want)
+	}
+
+	// Without a client key the script leaves the payload alone.
+	out, err = prog.Run(context.Background(), payload, "", Env{}, Limits{})
+	if err != nil || string(out) != string(payload) {
+		t.Fatalf("Run() without client key = %s, %v", out, err)
+	}
+}
+
+func TestRunWithRootAndPayloadRewrite(t *testing.T) {
+	prog, err := Compile(`
+set("generationConfig.maxOutputTokens", 512)
+set_payload(regex_replace(payload(), "sk-[a-z0-9]+", "[redacted]"))
+`)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	out, err := prog.Run(context.Background(), []byte(`{"request":{"text":"key sk-abc123"}}`), "request", Env{}, Limits{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := `{"request":{"text":"key [redacted]","generationConfig":{"maxOutputTokens":512}}}`; string(out) != want {
+		t.Fatalf("Run() = %s, want %s", out, want)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown function": `set("a", nope(1))`,
+		"unterminated":     `set("a", "x)`,
+		"while loop":       "while True:\n    pass",
+		"load":             `load("other.star", "x")`,
+	}
+	for name, src := range cases {
+		if _, err := Compile(src); err == nil {
+			t.Errorf("%s: Compile(%q) succeeded", name, src)
+		}
+	}
+}
+
+func TestRunErrorsKeepPayload(t *testing.T) {
+	payload := []byte(`{"a":1}`)
+	for name, src := range map[string]string{
+		"arity":     `x = payload("x")`,
+		"set_raw":   `set_raw("a", "{not json")`,
+		"recursion": "def f(n):\n    return f(n)\nf(1)",
+		"regex":     `matches("x", "(")`,
+	} {
+		prog, err := Compile(src)
+		if err != nil {
+			t.Fatalf("%s: Compile() error = %v", name, err)
+		}
+		out, err := prog.Run(context.Background(), payload, "", Env{}, Limits{})
+		if err == nil || string(out) != string(payload) {
+			t.Errorf("%s: Run() = %s, %v, want error with the original payload", name, out, err)
+		}
+	}
+}
+
+func TestRunLimits(t *testing.T) {
+	prog, err := Compile("for i in range(1000000):\n    x = i + 1")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	payload := []byte(`{}`)
+	out, err := prog.Run(context.Background(), payload, "", Env{}, Limits{MaxSteps: 100})
+	if err == nil || !strings.Contains(err.Error(), "too many steps") || string(out) != "{}" {
+		t.Fatalf("Run() = %s, %v, want step budget error", out, err)
+	}
+	if _, err = prog.Run(context.Background(), payload, "", Env{}, Limits{Timeout: time.Millisecond, MaxSteps: 1 << 40}); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("Run() error = %v, want timeout", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = prog.Run(ctx, payload, "", Env{}, Limits{MaxSteps: 1 << 40, Timeout: time.Minute}); err == nil || !strings.Contains(err.Error(), "canceled") {
+		t.Fatalf("Run() error = %v, want cancellation", err)
+	}
+	quick, _ := Compile(`x = 1`)
+	if _, err = quick.Run(context.Background(), payload, "", Env{}, Limits{}); err != nil {
+		t.Fatalf("Run() with default limits error = %v", err)
+	}
+}
+
+func TestRegexCacheIsBounded(t *testing.T) {
+	prog, err := Compile(`set("ok", matches("abc", "a" + str(get("n"))))`)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	for i := 0; i < regexCacheSize*2; i++ {
+		if _, err = prog.Run(context.Background(), []byte(fmt.Sprintf(`{"n":%d}`, i)), "", Env{}, Limits{}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+	if size := regexps.len(); size > regexCacheSize {
+		t.Fatalf("regex cache holds %d patterns, want at most %d", size, regexCacheSize)
+	}
+}
+
+func TestCompileCachedIsBounded(t *testing.T) {
+	first, err := CompileCached(`set("n", 0)`)
+	if err != nil {
+		t.Fatalf("CompileCached() error = %v", err)
+	}
+	if again, _ := CompileCached(`set("n", 0)`); again != first {
+		t.Fatal("expected identical sources to share a Program")
+	}
+	for i := 1; i <= programCacheSize*2; i++ {
+		if _, err = CompileCached(fmt.Sprintf(`set("n", %d)`, i)); err != nil {
+			t.Fatalf("CompileCached() error = %v", err)
+		}
+	}
+	if size := programs.len(); size > programCacheSize {
+		t.Fatalf("program cache holds %d scripts, want at most %d", size, programCacheSize)
+	}
+	if again, _ := CompileCached(`set("n", 0)`); again == first {
+		t.Fatal("expected the oldest script to have been evicted")
+	}
+}
@@ -0,0 +1,309 @@
+// Package redaction removes secrets and personal data from outbound prompts. Detectors are
+// regular expressions matched against every JSON string value of a request payload; a match
+// either blocks the request, is masked, or is replaced by a reversible token that is restored
+// in the response.
+package redaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// Action is what happens to a detector match.
+type Action string
+
+const (
+	// ActionBlock rejects the request.
+	ActionBlock Action = "block"
+	// ActionMask replaces the match with a fixed marker.
+	ActionMask Action = "mask"
+	// ActionTokenize replaces the match with a numbered token restored in responses.
+	ActionTokenize Action = "tokenize"
+)
+
+// ParseAction returns the action named s, if valid.
+func ParseAction(s string) (Action, bool) {
+	switch a := Action(strings.ToLower(strings.TrimSpace(s))); a {
+	case ActionBlock, ActionMask, ActionTokenize:
+		return a, true
+	}
+	return "", false
+}
+
+// builtinPatterns are the detectors available by name.
+var builtinPatterns = map[string]string{
+	"aws-key":     `\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b|(?i:aws_secret_access_key)\s*[=:]\s*["']?[A-Za-z0-9/+=]{40}`,
+	"jwt":         `\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`,
+	"private-key": `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+	"email":       `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`,
+}
+
+// BuiltinPattern returns the regular expression of a built-in detector.
+func BuiltinPattern(name string) (string, bool) {
+	pattern, ok := builtinPatterns[name]
+	return pattern, ok
+}
+
+// BuiltinNames lists the built-in detectors in a stable order.
+func BuiltinNames() []string {
+	return []string{"aws-key", "jwt", "private-key", "email"}
+}
+
+// Rule is one detector with its action.
+type Rule struct {
+	Name    string
+	Pattern string
+	Action  Action
+}
+
+type compiledRule struct {
+	Rule
+	re     *regexp.Regexp
+	marker string
+}
+
+// Redactor applies a fixed rule set. It is safe for concurrent use.
+type Redactor struct {
+	rules []compiledRule
+}
+
+var cache sync.Map
+
+// Compile builds a Redactor for rules, reusing an earlier one for the same rule set.
+func Compile(rules []Rule) (*Redactor, error) {
+	var key strings.Builder
+	for _, rule := range rules {
+		fmt.Fprintf(&key, "%s\x00%s\x00%s\x01", rule.Name, rule.Action, rule.Pattern)
+	}
+	if cached, ok := cache.Load(key.String()); ok {
+		return cached.(*Redactor), nil
+	}
+	r := &Redactor{rules: make([]compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		if _, ok := ParseAction(string(rule.Action)); !ok {
+			return nil, fmt.Errorf("redaction rule %s: invalid action %q", rule.Name, rule.Action)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction rule %s: %w", rule.Name, err)
+		}
+		marker := "REDACTED_" + strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(rule.Name))
+		r.rules = append(r.rules, compiledRule{Rule: rule, re: re, marker: marker})
+	}
+	actual, _ := cache.LoadOrStore(key.String(), r)
+	return actual.(*Redactor), nil
+}
+
+// Finding records one redacted match. The matched text itself is never recorded.
+type Finding struct {
+	Rule   string
+	Action Action
+	Path   string
+	Token  string
+}
+
+// BlockedError is returned when a block rule matches.
+type BlockedError struct {
+	Rule string
+	Path string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("request blocked by redaction rule %q (at %s)", e.Rule, e.Path)
+}
+
+// Session holds the findings and tokens of one redacted request.
+type Session struct {
+	Findings []Finding
+	tokens   map[string]string // original text -> token
+	restore  []string          // token, escaped original pairs for strings.NewReplacer
+}
+
+// ErrNotJSON is returned by Redact for payloads it cannot inspect.
+var ErrNotJSON = errors.New("payload is not JSON and cannot be inspected for redaction")
+
+// Redact returns payload with every detector match in its JSON string values replaced. It
+// returns a *BlockedError when a block rule matches and ErrNotJSON when the payload is not
+// JSON, so nothing is sent without being inspected.
+func (r *Redactor) Redact(payload []byte) ([]byte, *Session, error) {
+	s := &Session{}
+	if r == nil || len(r.rules) == 0 {
+		return payload, s, nil
+	}
+	if !gjson.ValidBytes(payload) {
+		return payload, s, ErrNotJSON
+	}
+	type span struct {
+		start, end int
+		raw        string
+	}
+	var spans []span
+	var blocked error
+	walkStrings(gjson.ParseBytes(payload), "", func(path string, value gjson.Result) bool {
+		text := value.Str
+		changed := false
+		for i := range r.rules {
+			rule := &r.rules[i]
+			if !rule.re.MatchString(text) {
+				continue
+			}
+			if rule.Action == ActionBlock {
+				blocked = &BlockedError{Rule: rule.Name, Path: path}
+				return false
+			}
+			text = rule.re.ReplaceAllStringFunc(text, func(match string) string {
+				token := s.replacement(rule, match)
+				s.Findings = append(s.Findings, Finding{Rule: rule.Name, Action: rule.Action, Path: path, Token: token})
+				return token
+			})
+			changed = true
+		}
+		if changed {
+			encoded, _ := json.Marshal(text)
+			spans = append(spans, span{start: value.Index, end: value.Index + len(value.Raw), raw: string(encoded)})
+		}
+		return true
+	})
+	if blocked != nil {
+		return payload, s, blocked
+	}
+	if len(spans) == 0 {
+		return payload, s, nil
+	}
+	out := make([]byte, 0, len(payload))
+	last := 0
+	for _, sp := range spans {
+		out = append(out, payload[last:sp.start]...)
+		out = append(out, sp.raw...)
+		last = sp.end
+	}
+	out = append(out, payload[last:]...)
+	return out, s, nil
+}
+
+func (s *Session) replacement(rule *compiledRule, match string) string {
+	if rule.Action != ActionTokenize {
+		return "[" + rule.marker + "]"
+	}
+	if token, ok := s.tokens[match]; ok {
+		return token
+	}
+	if s.tokens == nil {
+		s.tokens = make(map[string]string)
+	}
+	token := "[" + rule.marker + "_" + strconv.Itoa(len(s.tokens)+1) + "]"
+	s.tokens[match] = token
+	encoded, _ := json.Marshal(match)
+	s.restore = append(s.restore, token, string(encoded[1:len(encoded)-1]))
+	return token
+}
+
+// Restore replaces the tokens of the session in a response payload with the original text,
+// JSON-escaped so it can be placed inside JSON strings. Streams use a StreamRestorer, which
+// also restores tokens split across chunks.
+func (s *Session) Restore(data []byte) []byte {
+	if s == nil || len(s.restore) == 0 || len(data) == 0 {
+		return data
+	}
+	text := string(data)
+	restored := strings.NewReplacer(s.restore...).Replace(text)
+	if restored == text {
+		return data
+	}
+	return []byte(restored)
+}
+
+// walkStrings calls visit for every string value below value with its gjson path. Walking
+// stops when visit returns false.
+func walkStrings(value gjson.Result, path string, visit func(string, gjson.Result) bool) bool {
+	switch {
+	case value.Type == gjson.String:
+		return visit(path, value)
+	case value.IsObject(), value.IsArray():
+		isArray := value.IsArray()
+		index := 0
+		cont := true
+		value.ForEach(func(key, child gjson.Result) bool {
+			name := key.String()
+			if isArray {
+				name = strconv.Itoa(index)
+				index++
+			}
+			childPath := escapePath(name)
+			if path != "" {
+				childPath = path + "." + escapePath(name)
+			}
+			cont = walkStrings(child, childPath, visit)
+			return cont
+		})
+		return cont
+	}
+	return true
+}
+
+// escapePath escapes the gjson path syntax characters in an object key.
+func escapePath(name string) string {
+	if !strings.ContainsAny(name, `.*?|#@\`) {
+		return name
+	}
+	var b strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(`.*?|#@\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+type trackerKey struct{}
+
+// Tracker carries the session of a redacted request from the executor that sent it back to
+// the caller that restores the response. It is safe for concurrent use.
+type Tracker struct {
+	mu      sync.Mutex
+	session *Session
+}
+
+// WithTracker returns a context whose redacted requests are recorded on the returned Tracker.
+func WithTracker(ctx context.Context) (context.Context, *Tracker) {
+	t := &Tracker{}
+	return context.WithValue(ctx, trackerKey{}, t), t
+}
+
+// Track records s on the Tracker of ctx, replacing an earlier session. It does nothing when
+// ctx carries no Tracker or s has no tokens to restore.
+func Track(ctx context.Context, s *Session) {
+	if ctx == nil || s == nil || len(s.restore) == 0 {
+		return
+	}
+	t, _ := ctx.Value(trackerKey{}).(*Tracker)
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.session = s
+	t.mu.Unlock()
+}
+
+// Restore restores the tokens of the tracked session in data.
+func (t *Tracker) Restore(data []byte) []byte {
+	return t.current().Restore(data)
+}
+
+func (t *Tracker) current() *Session {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.session
+}
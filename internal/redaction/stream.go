@@ -0,0 +1,186 @@
+package redaction
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// StreamRestorer restores tokens in a streamed response. Models emit text in small deltas, so
+// a token may start in one event and end in the next. When a JSON string value ends with the
+// beginning of a token, the chunk is held back and that text is moved to the same value of the
+// next event before restoring it. At most one chunk is held at a time.
+//
+// A StreamRestorer is not safe for concurrent use.
+type StreamRestorer struct {
+	tracker *Tracker
+	held    []*streamPart
+	carries map[string]carry
+}
+
+// carry is the partial token at the end of the string at a path of an earlier event.
+type carry struct {
+	event  *streamPart
+	suffix string
+}
+
+// streamPart is a piece of a chunk: framing text, or the JSON object of one event.
+type streamPart struct {
+	data  []byte
+	event bool
+}
+
+// Stream returns a StreamRestorer for the session tracked on t.
+func (t *Tracker) Stream() *StreamRestorer {
+	return &StreamRestorer{tracker: t}
+}
+
+// Push restores the tokens in chunk and returns the chunks ready to be sent, in order. It
+// returns no chunk when chunk is held back and two when a held chunk is released with it.
+func (r *StreamRestorer) Push(chunk []byte) [][]byte {
+	s := r.tracker.current()
+	if s == nil || len(s.restore) == 0 {
+		return [][]byte{chunk}
+	}
+	parts := splitStream(chunk)
+	replacer := strings.NewReplacer(s.restore...)
+	for _, part := range parts {
+		if !part.event {
+			part.data = []byte(replacer.Replace(string(part.data)))
+			continue
+		}
+		r.restoreEvent(s, replacer, part)
+	}
+	var out [][]byte
+	if r.held != nil {
+		out = append(out, joinParts(r.held))
+		r.held = nil
+	}
+	if len(r.carries) > 0 {
+		r.held = parts
+		return out
+	}
+	return append(out, joinParts(parts))
+}
+
+// Flush returns the chunk still held back, if any. Its partial tokens are sent as they are.
+func (r *StreamRestorer) Flush() [][]byte {
+	r.carries = nil
+	if r.held == nil {
+		return nil
+	}
+	out := [][]byte{joinParts(r.held)}
+	r.held = nil
+	return out
+}
+
+// restoreEvent restores the string values of one event. Partial tokens carried from the
+// previous event are moved to the value at the same path; carries the event does not
+// continue are dropped and their text stays where it was.
+func (r *StreamRestorer) restoreEvent(s *Session, replacer *strings.Replacer, part *streamPart) {
+	if !gjson.ValidBytes(part.data) {
+		part.data = []byte(replacer.Replace(string(part.data)))
+		r.carries = nil
+		return
+	}
+	type value struct{ path, inner string }
+	var values []value
+	walkStrings(gjson.ParseBytes(part.data), "", func(path string, v gjson.Result) bool {
+		values = append(values, value{path: path, inner: v.Raw[1 : len(v.Raw)-1]})
+		return true
+	})
+	previous := r.carries
+	r.carries = nil
+	for _, v := range values {
+		inner := v.inner
+		if c, ok := previous[v.path]; ok {
+			setInner(c.event, v.path, func(src string) string { return strings.TrimSuffix(src, c.suffix) })
+			inner = c.suffix + inner
+		}
+		restored := replacer.Replace(inner)
+		if suffix := s.partialToken(restored); suffix != "" {
+			if r.carries == nil {
+				r.carries = make(map[string]carry)
+			}
+			r.carries[v.path] = carry{event: part, suffix: suffix}
+		}
+		if restored != v.inner {
+			setInner(part, v.path, func(string) string { return restored })
+		}
+	}
+}
+
+// partialToken returns the end of text when it is the beginning of one of the session's
+// tokens, or an empty string.
+func (s *Session) partialToken(text string) string {
+	start := strings.LastIndexByte(text, '[')
+	if start < 0 || strings.IndexByte(text[start:], ']') >= 0 {
+		return ""
+	}
+	suffix := text[start:]
+	for i := 0; i < len(s.restore); i += 2 {
+		if token := s.restore[i]; len(suffix) < len(token) && strings.HasPrefix(token, suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+// setInner rewrites the raw contents of the JSON string at path in an event.
+func setInner(part *streamPart, path string, update func(string) string) {
+	current := gjson.GetBytes(part.data, path)
+	if current.Type != gjson.String {
+		return
+	}
+	raw := `"` + update(current.Raw[1:len(current.Raw)-1]) + `"`
+	if updated, err := sjson.SetRawBytes(part.data, path, []byte(raw)); err == nil {
+		part.data = updated
+	}
+}
+
+// splitStream splits a chunk into framing text and event objects. A chunk is either a bare
+// JSON object or server-sent event lines whose data fields may hold JSON objects.
+func splitStream(chunk []byte) []*streamPart {
+	trimmed := bytes.TrimSpace(chunk)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		start := bytes.Index(chunk, trimmed)
+		return []*streamPart{
+			{data: chunk[:start]},
+			{data: trimmed, event: true},
+			{data: chunk[start+len(trimmed):]},
+		}
+	}
+	var parts []*streamPart
+	for _, line := range bytes.SplitAfter(chunk, []byte("\n")) {
+		body := bytes.TrimRight(line, "\r\n")
+		if !bytes.HasPrefix(body, []byte("data:")) {
+			parts = append(parts, &streamPart{data: line})
+			continue
+		}
+		data := bytes.TrimLeft(body[len("data:"):], " ")
+		if len(data) == 0 || data[0] != '{' {
+			parts = append(parts, &streamPart{data: line})
+			continue
+		}
+		parts = append(parts,
+			&streamPart{data: body[:len(body)-len(data)]},
+			&streamPart{data: data, event: true},
+			&streamPart{data: line[len(body):]},
+		)
+	}
+	return parts
+}
+
+func joinParts(parts []*streamPart) []byte {
+	size := 0
+	for _, part := range parts {
+		size += len(part.data)
+	}
+	out := make([]byte, 0, size)
+	for _, part := range parts {
+		out = append(out, part.data...)
+	}
+	return out
+}
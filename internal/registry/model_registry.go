@@ -47,6 +47,9 @@ type ModelInfo struct {
 	MaxCompletionTokens int `json:"max_completion_tokens,omitempty"`
 	// SupportedParameters lists supported parameters
 	SupportedParameters []string `json:"supported_parameters,omitempty"`
+	// Pricing holds upstream-reported prices keyed by component (e.g., "prompt", "completion"),
+	// kept verbatim as the upstream formats them.
+	Pricing map[string]string `json:"pricing,omitempty"`
 
 	// Thinking holds provider-specific reasoning/thinking budget capabilities.
 	// This is optional and currently used for Gemini thinking budget normalization.
@@ -499,6 +502,12 @@ func cloneModelInfo(model *ModelInfo) *ModelInfo {
 	if len(model.SupportedParameters) > 0 {
 		copyModel.SupportedParameters = append([]string(nil), model.SupportedParameters...)
 	}
+	if len(model.Pricing) > 0 {
+		copyModel.Pricing = make(map[string]string, len(model.Pricing))
+		for k, v := range model.Pricing {
+			copyModel.Pricing[k] = v
+		}
+	}
 	return &copyModel
 }
 
@@ -1023,6 +1032,9 @@ func (r *ModelRegistry) convertModelToMap(model *ModelInfo, handlerType string)
 		if len(model.SupportedParameters) > 0 {
 			result["supported_parameters"] = model.SupportedParameters
 		}
+		if len(model.Pricing) > 0 {
+			result["pricing"] = model.Pricing
+		}
 		return result
 
 	case "claude":
@@ -24,6 +24,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/streambridge"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
@@ -138,8 +139,8 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *cliproxyauth.Au
 		originalPayloadSource = opts.OriginalRequest
 	}
 	originalPayload := originalPayloadSource
-	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, false)
-	translated := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+	originalTranslated := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, originalPayload, false)
+	translated := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, req.Payload, false)
 
 	translated, err = thinking.ApplyThinking(translated, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
@@ -147,7 +148,11 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *cliproxyauth.Au
 	}
 
 	requestedModel := payloadRequestedModel(opts, req.Model)
-	translated = applyPayloadConfigWithRoot(e.cfg, baseModel, "antigravity", "request", translated, originalTranslated, requestedModel)
+	translated = applyPayloadConfigWithRoot(payloadConfigForClient(ctx, e.cfg), baseModel, "antigravity", "request", translated, originalTranslated, requestedModel)
+	translated = applyPayloadScripts(ctx, e.cfg, auth, opts, baseModel, "antigravity", "request", translated)
+	if translated, err = applyRedaction(ctx, e.cfg, translated); err != nil {
+		return resp, err
+	}
 
 	baseURLs := antigravityBaseURLFallbackOrder(auth)
 	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
@@ -280,8 +285,8 @@ func (e *AntigravityExecutor) executeClaudeNonStream(ctx context.Context, auth *
 		originalPayloadSource = opts.OriginalRequest
 	}
 	originalPayload := originalPayloadSource
-	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, true)
-	translated := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, true)
+	originalTranslated := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, originalPayload, true)
+	translated := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, req.Payload, true)
 
 	translated, err = thinking.ApplyThinking(translated, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
@@ -289,7 +294,11 @@ func (e *AntigravityExecutor) executeClaudeNonStream(ctx context.Context, auth *
 	}
 
 	requestedModel := payloadRequestedModel(opts, req.Model)
-	translated = applyPayloadConfigWithRoot(e.cfg, baseModel, "antigravity", "request", translated, originalTranslated, requestedModel)
+	translated = applyPayloadConfigWithRoot(payloadConfigForClient(ctx, e.cfg), baseModel, "antigravity", "request", translated, originalTranslated, requestedModel)
+	translated = applyPayloadScripts(ctx, e.cfg, auth, opts, baseModel, "antigravity", "request", translated)
+	if translated, err = applyRedaction(ctx, e.cfg, translated); err != nil {
+		return resp, err
+	}
 
 	baseURLs := antigravityBaseURLFallbackOrder(auth)
 	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
@@ -383,6 +392,7 @@ attemptLoop:
 				return resp, err
 			}
 
+			httpResp.Body = watchStreamStall(ctx, e.cfg, auth, e.Identifier(), httpResp.Body)
 			out := make(chan cliproxyexecutor.StreamChunk)
 			go func(resp *http.Response) {
 				defer close(out)
@@ -463,185 +473,7 @@ attemptLoop:
 }
 
 func (e *AntigravityExecutor) convertStreamToNonStream(stream []byte) []byte {
-	responseTemplate := ""
-	var traceID string
-	var finishReason string
-	var modelVersion string
-	var responseID string
-	var role string
-	var usageRaw string
-	parts := make([]map[string]interface{}, 0)
-	var pendingKind string
-	var pendingText strings.Builder
-	var pendingThoughtSig string
-
-	flushPending := func() {
-		if pendingKind == "" {
-			return
-		}
-		text := pendingText.String()
-		switch pendingKind {
-		case "text":
-			if strings.TrimSpace(text) == "" {
-				pendingKind = ""
-				pendingText.Reset()
-				pendingThoughtSig = ""
-				return
-			}
-			parts = append(parts, map[string]interface{}{"text": text})
-		case "thought":
-			if strings.TrimSpace(text) == "" && pendingThoughtSig == "" {
-				pendingKind = ""
-				pendingText.Reset()
-				pendingThoughtSig = ""
-				return
-			}
-			part := map[string]interface{}{"thought": true}
-			part["text"] = text
-			if pendingThoughtSig != "" {
-				part["thoughtSignature"] = pendingThoughtSig
-			}
-			parts = append(parts, part)
-		}
-		pendingKind = ""
-		pendingText.Reset()
-		pendingThoughtSig = ""
-	}
-
-	normalizePart := func(partResult gjson.Result) map[string]interface{} {
-		var m map[string]interface{}
-		_ = json.Unmarshal([]byte(partResult.Raw), &m)
-		if m == nil {
-			m = map[string]interface{}{}
-		}
-		sig := partResult.Get("thoughtSignature").String()
-		if sig == "" {
-			sig = partResult.Get("thought_signature").String()
-		}
-		if sig != "" {
-			m["thoughtSignature"] = sig
-			delete(m, "thought_signature")
-		}
-		if inlineData, ok := m["inline_data"]; ok {
-			m["inlineData"] = inlineData
-			delete(m, "inline_data")
-		}
-		return m
-	}
-
-	for _, line := range bytes.Split(stream, []byte("\n")) {
-		trimmed := bytes.TrimSpace(line)
-		if len(trimmed) == 0 || !gjson.ValidBytes(trimmed) {
-			continue
-		}
-
-		root := gjson.ParseBytes(trimmed)
-		responseNode := root.Get("response")
-		if !responseNode.Exists() {
-			if root.Get("candidates").Exists() {
-				responseNode = root
-			} else {
-				continue
-			}
-		}
-		responseTemplate = responseNode.Raw
-
-		if traceResult := root.Get("traceId"); traceResult.Exists() && traceResult.String() != "" {
-			traceID = traceResult.String()
-		}
-
-		if roleResult := responseNode.Get("candidates.0.content.role"); roleResult.Exists() {
-			role = roleResult.String()
-		}
-
-		if finishResult := responseNode.Get("candidates.0.finishReason"); finishResult.Exists() && finishResult.String() != "" {
-			finishReason = finishResult.String()
-		}
-
-		if modelResult := responseNode.Get("modelVersion"); modelResult.Exists() && modelResult.String() != "" {
-			modelVersion = modelResult.String()
-		}
-		if responseIDResult := responseNode.Get("responseId"); responseIDResult.Exists() && responseIDResult.String() != "" {
-			responseID = responseIDResult.String()
-		}
-		if usageResult := responseNode.Get("usageMetadata"); usageResult.Exists() {
-			usageRaw = usageResult.Raw
-		} else if usageMetadataResult := root.Get("usageMetadata"); usageMetadataResult.Exists() {
-			usageRaw = usageMetadataResult.Raw
-		}
-
-		if partsResult := responseNode.Get("candidates.0.content.parts"); partsResult.IsArray() {
-			for _, part := range partsResult.Array() {
-				hasFunctionCall := part.Get("functionCall").Exists()
-				hasInlineData := part.Get("inlineData").Exists() || part.Get("inline_data").Exists()
-				sig := part.Get("thoughtSignature").String()
-				if sig == "" {
-					sig = part.Get("thought_signature").String()
-				}
-				text := part.Get("text").String()
-				thought := part.Get("thought").Bool()
-
-				if hasFunctionCall || hasInlineData {
-					flushPending()
-					parts = append(parts, normalizePart(part))
-					continue
-				}
-
-				if thought || part.Get("text").Exists() {
-					kind := "text"
-					if thought {
-						kind = "thought"
-					}
-					if pendingKind != "" && pendingKind != kind {
-						flushPending()
-					}
-					pendingKind = kind
-					pendingText.WriteString(text)
-					if kind == "thought" && sig != "" {
-						pendingThoughtSig = sig
-					}
-					continue
-				}
-
-				flushPending()
-				parts = append(parts, normalizePart(part))
-			}
-		}
-	}
-	flushPending()
-
-	if responseTemplate == "" {
-		responseTemplate = `{"candidates":[{"content":{"role":"model","parts":[]}}]}`
-	}
-
-	partsJSON, _ := json.Marshal(parts)
-	responseTemplate, _ = sjson.SetRaw(responseTemplate, "candidates.0.content.parts", string(partsJSON))
-	if role != "" {
-		responseTemplate, _ = sjson.Set(responseTemplate, "candidates.0.content.role", role)
-	}
-	if finishReason != "" {
-		responseTemplate, _ = sjson.Set(responseTemplate, "candidates.0.finishReason", finishReason)
-	}
-	if modelVersion != "" {
-		responseTemplate, _ = sjson.Set(responseTemplate, "modelVersion", modelVersion)
-	}
-	if responseID != "" {
-		responseTemplate, _ = sjson.Set(responseTemplate, "responseId", responseID)
-	}
-	if usageRaw != "" {
-		responseTemplate, _ = sjson.SetRaw(responseTemplate, "usageMetadata", usageRaw)
-	} else if !gjson.Get(responseTemplate, "usageMetadata").Exists() {
-		responseTemplate, _ = sjson.Set(responseTemplate, "usageMetadata.promptTokenCount", 0)
-		responseTemplate, _ = sjson.Set(responseTemplate, "usageMetadata.candidatesTokenCount", 0)
-		responseTemplate, _ = sjson.Set(responseTemplate, "usageMetadata.totalTokenCount", 0)
-	}
-
-	output := `{"response":{},"traceId":""}`
-	output, _ = sjson.SetRaw(output, "response", responseTemplate)
-	if traceID != "" {
-		output, _ = sjson.Set(output, "traceId", traceID)
-	}
-	return []byte(output)
+	return streambridge.AggregateGemini([][]byte{stream}, true)
 }
 
 // ExecuteStream performs a streaming request to the Antigravity API.
@@ -672,8 +504,8 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *cliproxya
 		originalPayloadSource = opts.OriginalRequest
 	}
 	originalPayload := originalPayloadSource
-	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, true)
-	translated := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, true)
+	originalTranslated := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, originalPayload, true)
+	translated := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, req.Payload, true)
 
 	translated, err = thinking.ApplyThinking(translated, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
@@ -681,7 +513,11 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *cliproxya
 	}
 
 	requestedModel := payloadRequestedModel(opts, req.Model)
-	translated = applyPayloadConfigWithRoot(e.cfg, baseModel, "antigravity", "request", translated, originalTranslated, requestedModel)
+	translated = applyPayloadConfigWithRoot(payloadConfigForClient(ctx, e.cfg), baseModel, "antigravity", "request", translated, originalTranslated, requestedModel)
+	translated = applyPayloadScripts(ctx, e.cfg, auth, opts, baseModel, "antigravity", "request", translated)
+	if translated, err = applyRedaction(ctx, e.cfg, translated); err != nil {
+		return nil, err
+	}
 
 	baseURLs := antigravityBaseURLFallbackOrder(auth)
 	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
@@ -774,6 +610,7 @@ attemptLoop:
 				return nil, err
 			}
 
+			httpResp.Body = watchStreamStall(ctx, e.cfg, auth, e.Identifier(), httpResp.Body)
 			out := make(chan cliproxyexecutor.StreamChunk)
 			go func(resp *http.Response) {
 				defer close(out)
@@ -874,7 +711,7 @@ func (e *AntigravityExecutor) CountTokens(ctx context.Context, auth *cliproxyaut
 	respCtx := context.WithValue(ctx, "alt", opts.Alt)
 
 	// Prepare payload once (doesn't depend on baseURL)
-	payload := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+	payload := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, req.Payload, false)
 
 	payload, err := thinking.ApplyThinking(payload, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
@@ -884,6 +721,9 @@ func (e *AntigravityExecutor) CountTokens(ctx context.Context, auth *cliproxyaut
 	payload = deleteJSONField(payload, "project")
 	payload = deleteJSONField(payload, "model")
 	payload = deleteJSONField(payload, "request.safetySettings")
+	if payload, err = applyRedaction(ctx, e.cfg, payload); err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
 
 	baseURLs := antigravityBaseURLFallbackOrder(auth)
 	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
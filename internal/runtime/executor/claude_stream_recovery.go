@@ -0,0 +1,297 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// claudeStallRecoveryAttempts returns how many continuations a stalled Claude stream may use,
+// or zero when recovery is off or the request cannot be continued with a prefill: extended
+// thinking rejects assistant prefill, and a request that already ends with an assistant turn
+// would need its own prefill merged.
+func claudeStallRecoveryAttempts(cfg *config.Config, auth *cliproxyauth.Auth, body []byte) int {
+	if cfg == nil || !cfg.Streaming.StallRecovery || streamIdleTimeout(cfg, auth, "claude") <= 0 {
+		return 0
+	}
+	switch gjson.GetBytes(body, "thinking.type").String() {
+	case "enabled", "adaptive":
+		return 0
+	}
+	if messages := gjson.GetBytes(body, "messages").Array(); len(messages) > 0 && messages[len(messages)-1].Get("role").String() == "assistant" {
+		return 0
+	}
+	if attempts := cfg.Streaming.StallRecoveryAttempts; attempts > 0 {
+		return attempts
+	}
+	return 1
+}
+
+// claudePrefillBody appends the partial assistant output as the final turn of body.
+func claudePrefillBody(body []byte, prefill string) ([]byte, error) {
+	message, err := json.Marshal(map[string]any{
+		"role":    "assistant",
+		"content": []map[string]string{{"type": "text", "text": prefill}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sjson.SetRawBytes(body, "messages.-1", message)
+}
+
+// continueStalledStream re-sends body with prefill as the final assistant turn and returns the
+// decoded, stall-watched continuation stream.
+func (e *ClaudeExecutor) continueStalledStream(ctx context.Context, auth *cliproxyauth.Auth, apiKey, url string, body []byte, extraBetas []string, prefill string) (io.ReadCloser, error) {
+	prefilled, err := claudePrefillBody(body, prefill)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(prefilled))
+	if err != nil {
+		return nil, err
+	}
+	applyClaudeHeaders(httpReq, auth, apiKey, true, extraBetas, e.cfg)
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	recordAPIRequest(ctx, e.cfg, upstreamRequestLog{
+		URL:       url,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      prefilled,
+		Provider:  e.Identifier(),
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+	httpResp, err := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0).Do(httpReq)
+	if err != nil {
+		recordAPIResponseError(ctx, e.cfg, err)
+		return nil, err
+	}
+	recordAPIResponseMetadata(ctx, e.cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		b, _ := io.ReadAll(httpResp.Body)
+		appendAPIResponseChunk(ctx, e.cfg, b)
+		_ = httpResp.Body.Close()
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(b)}
+	}
+	decodedBody, err := decodeResponseBody(httpResp.Body, httpResp.Header.Get("Content-Encoding"))
+	if err != nil {
+		_ = httpResp.Body.Close()
+		return nil, err
+	}
+	return watchStreamStall(ctx, e.cfg, auth, e.Identifier(), decodedBody), nil
+}
+
+// claudeStreamContinuation reads a Claude SSE stream event by event. When the upstream stalls
+// after emitting text, it re-issues the request with that text as an assistant prefill and
+// splices the continuation into the same event stream: the second message_start is dropped,
+// the continued text block is merged into the open one, and later block indices are shifted.
+// The client therefore sees one uninterrupted message.
+//
+// Trailing whitespace is trimmed from the prefill because the API rejects it, so the
+// continuation may repeat that whitespace. Streams that produced thinking or tool blocks are
+// not continued.
+type claudeStreamContinuation struct {
+	ctx          context.Context
+	current      io.ReadCloser
+	reader       *bufio.Reader
+	reissue      func(prefill string) (io.ReadCloser, error)
+	attemptsLeft int
+	pending      bytes.Buffer
+
+	text       strings.Builder
+	eligible   bool
+	blockIndex int
+	blockOpen  bool
+	finished   bool
+
+	continuing  bool
+	mergeFirst  bool
+	mergeIndex  int
+	indexOffset int
+}
+
+func newClaudeStreamContinuation(ctx context.Context, body io.ReadCloser, attempts int, reissue func(prefill string) (io.ReadCloser, error)) *claudeStreamContinuation {
+	return &claudeStreamContinuation{
+		ctx:          ctx,
+		current:      body,
+		reader:       bufio.NewReaderSize(body, 64*1024),
+		reissue:      reissue,
+		attemptsLeft: attempts,
+		eligible:     true,
+		blockIndex:   -1,
+	}
+}
+
+func (c *claudeStreamContinuation) Read(p []byte) (int, error) {
+	for c.pending.Len() == 0 {
+		event, err := c.readEvent()
+		if len(event) > 0 {
+			c.pending.Write(c.process(event))
+		}
+		if err == nil {
+			continue
+		}
+		if c.pending.Len() > 0 {
+			// Deliver what was read; the error is raised again on the next read.
+			break
+		}
+		if isStreamStall(err) && c.resume() {
+			continue
+		}
+		return 0, err
+	}
+	return c.pending.Read(p)
+}
+
+func (c *claudeStreamContinuation) Close() error {
+	return c.current.Close()
+}
+
+// readEvent returns the lines of one SSE event including its terminating blank line.
+func (c *claudeStreamContinuation) readEvent() ([]byte, error) {
+	var event []byte
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		event = append(event, line...)
+		if err != nil {
+			return event, err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			return event, nil
+		}
+	}
+}
+
+// process rewrites one event for the client and records the stream state.
+func (c *claudeStreamContinuation) process(event []byte) []byte {
+	data := claudeEventData(event)
+	if len(data) == 0 {
+		return event
+	}
+	eventType := gjson.GetBytes(data, "type").String()
+	if c.continuing {
+		switch eventType {
+		case "message_start":
+			return nil
+		case "content_block_start", "content_block_delta", "content_block_stop":
+			index := int(gjson.GetBytes(data, "index").Int())
+			var prefix []byte
+			if c.mergeFirst && index == 0 {
+				if eventType == "content_block_start" {
+					if gjson.GetBytes(data, "content_block.type").String() == "text" {
+						return nil
+					}
+					// The continuation opened a different block; close the interrupted one.
+					prefix = claudeBlockStopEvent(c.mergeIndex)
+					c.blockOpen = false
+					c.mergeFirst = false
+					c.indexOffset = c.mergeIndex + 1
+				}
+			}
+			mapped := index + c.indexOffset
+			if rewritten, err := sjson.SetBytes(data, "index", mapped); err == nil {
+				event = replaceClaudeEventData(event, rewritten)
+				data = rewritten
+			}
+			event = append(prefix, event...)
+		}
+	}
+	c.observe(eventType, data)
+	return event
+}
+
+func (c *claudeStreamContinuation) observe(eventType string, data []byte) {
+	switch eventType {
+	case "content_block_start":
+		c.blockIndex = int(gjson.GetBytes(data, "index").Int())
+		c.blockOpen = true
+		if gjson.GetBytes(data, "content_block.type").String() != "text" {
+			c.eligible = false
+		}
+	case "content_block_delta":
+		delta := gjson.GetBytes(data, "delta")
+		if delta.Get("type").String() != "text_delta" {
+			c.eligible = false
+			return
+		}
+		c.text.WriteString(delta.Get("text").String())
+	case "content_block_stop":
+		c.blockOpen = false
+	case "message_delta", "message_stop":
+		c.finished = true
+	case "error":
+		c.eligible = false
+	}
+}
+
+// resume re-issues the request with the text received so far as a prefill.
+func (c *claudeStreamContinuation) resume() bool {
+	if c.attemptsLeft <= 0 || !c.eligible || c.finished || c.reissue == nil {
+		return false
+	}
+	prefill := strings.TrimRight(c.text.String(), " \t\r\n")
+	if prefill == "" {
+		return false
+	}
+	c.attemptsLeft--
+	next, err := c.reissue(prefill)
+	if err != nil {
+		logWithRequestID(c.ctx).Warnf("claude stream recovery failed: %v", err)
+		return false
+	}
+	logWithRequestID(c.ctx).Infof("claude stream stalled, continuing with %d characters of prefill", len(prefill))
+	_ = c.current.Close()
+	c.current = next
+	c.reader = bufio.NewReaderSize(next, 64*1024)
+	c.continuing = true
+	c.mergeFirst = c.blockOpen
+	c.mergeIndex = c.blockIndex
+	if c.blockOpen {
+		c.indexOffset = c.blockIndex
+	} else {
+		c.indexOffset = c.blockIndex + 1
+	}
+	return true
+}
+
+func claudeEventData(event []byte) []byte {
+	for _, line := range bytes.Split(event, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, dataTag) {
+			return bytes.TrimSpace(line[len(dataTag):])
+		}
+	}
+	return nil
+}
+
+func replaceClaudeEventData(event, data []byte) []byte {
+	lines := bytes.Split(event, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(bytes.TrimSpace(line), dataTag) {
+			lines[i] = append([]byte("data: "), data...)
+			break
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+func claudeBlockStopEvent(index int) []byte {
+	data, _ := sjson.SetBytes([]byte(`{"type":"content_block_stop"}`), "index", index)
+	return append(append([]byte("event: content_block_stop\ndata: "), data...), '\n', '\n')
+}
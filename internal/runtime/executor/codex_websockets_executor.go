@@ -5,6 +5,7 @@ package executor
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -169,8 +170,8 @@ func (e *CodexWebsocketsExecutor) Execute(ctx context.Context, auth *cliproxyaut
 		originalPayloadSource = opts.OriginalRequest
 	}
 	originalPayload := originalPayloadSource
-	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, false)
-	body := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+	originalTranslated := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, originalPayload, false)
+	body := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, req.Payload, false)
 
 	body, err = thinking.ApplyThinking(body, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
@@ -178,7 +179,11 @@ func (e *CodexWebsocketsExecutor) Execute(ctx context.Context, auth *cliproxyaut
 	}
 
 	requestedModel := payloadRequestedModel(opts, req.Model)
-	body = applyPayloadConfigWithRoot(e.cfg, baseModel, to.String(), "", body, originalTranslated, requestedModel)
+	body = applyPayloadConfigWithRoot(payloadConfigForClient(ctx, e.cfg), baseModel, to.String(), "", body, originalTranslated, requestedModel)
+	body = applyPayloadScripts(ctx, e.cfg, auth, opts, baseModel, to.String(), "", body)
+	if body, err = applyRedaction(ctx, e.cfg, body); err != nil {
+		return resp, err
+	}
 	body, _ = sjson.SetBytes(body, "model", baseModel)
 	body, _ = sjson.SetBytes(body, "stream", true)
 	body, _ = sjson.DeleteBytes(body, "previous_response_id")
@@ -318,7 +323,7 @@ func (e *CodexWebsocketsExecutor) Execute(ctx context.Context, auth *cliproxyaut
 		if ctx != nil && ctx.Err() != nil {
 			return resp, ctx.Err()
 		}
-		msgType, payload, errRead := readCodexWebsocketMessage(ctx, sess, conn, readCh)
+		msgType, payload, errRead := readCodexWebsocketMessage(ctx, sess, conn, readCh, 0)
 		if errRead != nil {
 			recordAPIResponseError(ctx, e.cfg, errRead)
 			return resp, errRead
@@ -391,7 +396,11 @@ func (e *CodexWebsocketsExecutor) ExecuteStream(ctx context.Context, auth *clipr
 	}
 
 	requestedModel := payloadRequestedModel(opts, req.Model)
-	body = applyPayloadConfigWithRoot(e.cfg, baseModel, to.String(), "", body, body, requestedModel)
+	body = applyPayloadConfigWithRoot(payloadConfigForClient(ctx, e.cfg), baseModel, to.String(), "", body, body, requestedModel)
+	body = applyPayloadScripts(ctx, e.cfg, auth, opts, baseModel, to.String(), "", body)
+	if body, err = applyRedaction(ctx, e.cfg, body); err != nil {
+		return nil, err
+	}
 
 	httpURL := strings.TrimSuffix(baseURL, "/") + "/responses"
 	wsURL, err := buildCodexResponsesWebsocketURL(httpURL)
@@ -548,6 +557,7 @@ func (e *CodexWebsocketsExecutor) ExecuteStream(ctx context.Context, auth *clipr
 			}
 		}
 
+		idle := streamIdleTimeout(e.cfg, auth, e.Identifier())
 		var param any
 		for {
 			if ctx != nil && ctx.Err() != nil {
@@ -556,7 +566,7 @@ func (e *CodexWebsocketsExecutor) ExecuteStream(ctx context.Context, auth *clipr
 				_ = send(cliproxyexecutor.StreamChunk{Err: ctx.Err()})
 				return
 			}
-			msgType, payload, errRead := readCodexWebsocketMessage(ctx, sess, conn, readCh)
+			msgType, payload, errRead := readCodexWebsocketMessage(ctx, sess, conn, readCh, idle)
 			if errRead != nil {
 				if sess != nil && ctx != nil && ctx.Err() != nil {
 					terminateReason = "context_done"
@@ -564,6 +574,20 @@ func (e *CodexWebsocketsExecutor) ExecuteStream(ctx context.Context, auth *clipr
 					_ = send(cliproxyexecutor.StreamChunk{Err: ctx.Err()})
 					return
 				}
+				if isStreamStall(errRead) {
+					logWithRequestID(ctx).Warnf("upstream stream stalled for %s, aborting", idle)
+					terminateReason = "stream_stall"
+					terminateErr = errRead
+					recordAPIResponseError(ctx, e.cfg, errRead)
+					reporter.publishFailure(ctx)
+					// The upstream response is still in flight; drop the connection so its
+					// late events do not reach the next request of the session.
+					if sess != nil {
+						e.invalidateUpstreamConn(sess, conn, "stream_stall", errRead)
+					}
+					_ = send(cliproxyexecutor.StreamChunk{Err: errRead})
+					return
+				}
 				terminateReason = "read_error"
 				terminateErr = errRead
 				recordAPIResponseError(ctx, e.cfg, errRead)
@@ -691,13 +715,24 @@ func buildCodexWebsocketRequestBody(body []byte, allowAppend bool) []byte {
 	return fallback
 }
 
-func readCodexWebsocketMessage(ctx context.Context, sess *codexWebsocketSession, conn *websocket.Conn, readCh chan codexWebsocketRead) (int, []byte, error) {
+// readCodexWebsocketMessage waits for the next upstream message. A positive idle bounds the
+// wait and fails it with streamStallError, matching the stall watch of HTTP streams.
+func readCodexWebsocketMessage(ctx context.Context, sess *codexWebsocketSession, conn *websocket.Conn, readCh chan codexWebsocketRead, idle time.Duration) (int, []byte, error) {
 	if sess == nil {
 		if conn == nil {
 			return 0, nil, fmt.Errorf("codex websockets executor: websocket conn is nil")
 		}
-		_ = conn.SetReadDeadline(time.Now().Add(codexResponsesWebsocketIdleTimeout))
+		deadline := codexResponsesWebsocketIdleTimeout
+		stallDetect := idle > 0 && idle < deadline
+		if stallDetect {
+			deadline = idle
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(deadline))
 		msgType, payload, errRead := conn.ReadMessage()
+		var netErr net.Error
+		if stallDetect && errors.As(errRead, &netErr) && netErr.Timeout() {
+			return 0, nil, streamStallError{idle: idle}
+		}
 		return msgType, payload, errRead
 	}
 	if conn == nil {
@@ -706,10 +741,18 @@ func readCodexWebsocketMessage(ctx context.Context, sess *codexWebsocketSession,
 	if readCh == nil {
 		return 0, nil, fmt.Errorf("codex websockets executor: session read channel is nil")
 	}
+	var stalled <-chan time.Time
+	if idle > 0 {
+		timer := time.NewTimer(idle)
+		defer timer.Stop()
+		stalled = timer.C
+	}
 	for {
 		select {
 		case <-ctx.Done():
 			return 0, nil, ctx.Err()
+		case <-stalled:
+			return 0, nil, streamStallError{idle: idle}
 		case ev, ok := <-readCh:
 			if !ok {
 				return 0, nil, fmt.Errorf("codex websockets executor: session read channel closed")
@@ -13,15 +13,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/redaction"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 )
 
 const (
-	apiAttemptsKey = "API_UPSTREAM_ATTEMPTS"
-	apiRequestKey  = "API_REQUEST"
-	apiResponseKey = "API_RESPONSE"
+	apiAttemptsKey   = "API_UPSTREAM_ATTEMPTS"
+	apiRequestKey    = "API_REQUEST"
+	apiResponseKey   = "API_RESPONSE"
+	apiRedactionsKey = "API_PENDING_REDACTIONS"
 )
 
 // upstreamRequestLog captures the outbound upstream request details for logging.
@@ -76,6 +78,10 @@ func recordAPIRequest(ctx context.Context, cfg *config.Config, info upstreamRequ
 	if auth := formatAuthInfo(info); auth != "" {
 		builder.WriteString(fmt.Sprintf("Auth: %s\n", auth))
 	}
+	if findings := takePendingRedactions(ginCtx); len(findings) > 0 {
+		builder.WriteString("\nRedactions:\n")
+		writeRedactions(builder, findings)
+	}
 	builder.WriteString("\nHeaders:\n")
 	writeHeaders(builder, info.Headers)
 	builder.WriteString("\nBody:\n")
@@ -96,6 +102,39 @@ func recordAPIRequest(ctx context.Context, cfg *config.Config, info upstreamRequ
 	updateAggregatedRequest(ginCtx, attempts)
 }
 
+// recordRedactions stores redaction findings in Gin context; they are written into the next
+// upstream request recorded by recordAPIRequest.
+func recordRedactions(ctx context.Context, cfg *config.Config, findings []redaction.Finding) {
+	if cfg == nil || !cfg.RequestLog || len(findings) == 0 {
+		return
+	}
+	ginCtx := ginContextFrom(ctx)
+	if ginCtx == nil {
+		return
+	}
+	ginCtx.Set(apiRedactionsKey, findings)
+}
+
+func takePendingRedactions(ginCtx *gin.Context) []redaction.Finding {
+	value, exists := ginCtx.Get(apiRedactionsKey)
+	if !exists {
+		return nil
+	}
+	findings, _ := value.([]redaction.Finding)
+	ginCtx.Set(apiRedactionsKey, nil)
+	return findings
+}
+
+func writeRedactions(builder *strings.Builder, findings []redaction.Finding) {
+	for _, finding := range findings {
+		builder.WriteString(fmt.Sprintf("%s: %s at %s", finding.Rule, finding.Action, finding.Path))
+		if finding.Action == redaction.ActionTokenize {
+			builder.WriteString(fmt.Sprintf(" -> %s", finding.Token))
+		}
+		builder.WriteString("\n")
+	}
+}
+
 // recordAPIResponseMetadata captures upstream response status/header information for the latest attempt.
 func recordAPIResponseMetadata(ctx context.Context, cfg *config.Config, status int, headers http.Header) {
 	if cfg == nil || !cfg.RequestLog {
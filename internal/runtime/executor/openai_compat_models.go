@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// contextLengthFields are the model-list fields different OpenAI-compatible servers use
+// for the context window (OpenRouter, Together/Fireworks, vLLM).
+var contextLengthFields = []string{"context_length", "context_window", "max_context_length", "max_model_len"}
+
+// FetchOpenAICompatModels lists the models served by an OpenAI-compatible upstream via
+// GET {base-url}/models, using the auth's API key, custom headers and proxy.
+// Context length, completion limits and pricing are filled in when the upstream reports them.
+func FetchOpenAICompatModels(ctx context.Context, auth *cliproxyauth.Auth, cfg *config.Config) ([]*registry.ModelInfo, error) {
+	exec := &OpenAICompatExecutor{cfg: cfg}
+	baseURL, apiKey := exec.resolveCredentials(auth)
+	if baseURL == "" {
+		return nil, fmt.Errorf("openai compat executor: missing provider baseURL")
+	}
+	modelsURL := strings.TrimSuffix(baseURL, "/") + "/models"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+
+	httpClient := newProxyAwareHTTPClient(ctx, cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("openai compat executor: close models response body error: %v", errClose)
+		}
+	}()
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusMultipleChoices {
+		return nil, statusErr{code: httpResp.StatusCode, msg: fmt.Sprintf("list models: status %d: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), body))}
+	}
+	return parseOpenAICompatModels(body), nil
+}
+
+// parseOpenAICompatModels reads an OpenAI-style model list ({"data":[...]}) or a bare array.
+func parseOpenAICompatModels(body []byte) []*registry.ModelInfo {
+	list := gjson.GetBytes(body, "data")
+	if !list.IsArray() {
+		list = gjson.ParseBytes(body)
+	}
+	if !list.IsArray() {
+		return nil
+	}
+	var models []*registry.ModelInfo
+	seen := make(map[string]struct{})
+	list.ForEach(func(_, item gjson.Result) bool {
+		id := strings.TrimSpace(item.Get("id").String())
+		if id == "" {
+			return true
+		}
+		if _, dup := seen[id]; dup {
+			return true
+		}
+		seen[id] = struct{}{}
+		model := &registry.ModelInfo{
+			ID:          id,
+			Object:      "model",
+			Created:     item.Get("created").Int(),
+			OwnedBy:     item.Get("owned_by").String(),
+			DisplayName: item.Get("name").String(),
+			Description: item.Get("description").String(),
+		}
+		for _, field := range contextLengthFields {
+			if v := item.Get(field).Int(); v > 0 {
+				model.ContextLength = int(v)
+				break
+			}
+		}
+		if v := item.Get("top_provider.max_completion_tokens").Int(); v > 0 {
+			model.MaxCompletionTokens = int(v)
+		} else if v = item.Get("max_completion_tokens").Int(); v > 0 {
+			model.MaxCompletionTokens = int(v)
+		}
+		item.Get("supported_parameters").ForEach(func(_, param gjson.Result) bool {
+			if p := strings.TrimSpace(param.String()); p != "" {
+				model.SupportedParameters = append(model.SupportedParameters, p)
+			}
+			return true
+		})
+		if pricing := item.Get("pricing"); pricing.IsObject() {
+			pricing.ForEach(func(key, value gjson.Result) bool {
+				if value.Type == gjson.String || value.Type == gjson.Number {
+					if model.Pricing == nil {
+						model.Pricing = make(map[string]string)
+					}
+					model.Pricing[key.String()] = value.String()
+				}
+				return true
+			})
+		}
+		models = append(models, model)
+		return true
+	})
+	return models
+}
@@ -1,12 +1,18 @@
 package executor
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 
+	"time"
+
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/payloadscript"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -151,6 +157,50 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	return out
 }
 
+// applyPayloadScripts runs the request scripts of the payload script rules matching model and
+// protocol on the translated payload. A failing script is logged and leaves the payload as it was.
+func applyPayloadScripts(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, opts cliproxyexecutor.Options, model, protocol, root string, payload []byte) []byte {
+	if cfg == nil || len(payload) == 0 {
+		return payload
+	}
+	clientKey := apiKeyFromContext(ctx)
+	requestedModel := payloadRequestedModel(opts, model)
+	rules := cfg.PayloadScriptsFor(clientKey, protocol, payloadModelCandidates(model, requestedModel)...)
+	if len(rules) == 0 {
+		return payload
+	}
+	env := payloadscript.Env{
+		Phase:          payloadscript.PhaseRequest,
+		ClientKey:      clientKey,
+		Model:          model,
+		RequestedModel: requestedModel,
+		Protocol:       protocol,
+		Stream:         opts.Stream,
+	}
+	if auth != nil {
+		env.Provider = auth.Provider
+		env.AuthID = auth.ID
+		env.AuthLabel = auth.Label
+	} else if authID, ok := opts.Metadata[cliproxyexecutor.SelectedAuthMetadataKey].(string); ok {
+		env.AuthID = authID
+	}
+	out := payload
+	for i := range rules {
+		if rules[i].Request == "" {
+			continue
+		}
+		prog, err := payloadscript.CompileCached(rules[i].Request)
+		if err == nil {
+			limits := payloadscript.Limits{Timeout: time.Duration(rules[i].TimeoutMs) * time.Millisecond}
+			out, err = prog.Run(ctx, out, root, env, limits)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"model": model, "protocol": protocol}).Warnf("payload request script skipped: %v", err)
+		}
+	}
+	return out
+}
+
 func payloadModelRulesMatch(rules []config.PayloadModelRule, protocol string, models []string) bool {
 	if len(rules) == 0 || len(models) == 0 {
 		return false
@@ -317,3 +367,26 @@ func matchModelPattern(pattern, model string) bool {
 	}
 	return pi == len(pattern)
 }
+
+// payloadConfigForClient returns cfg with the payload rules of the calling client key's policy merged in.
+// Policy defaults are evaluated before global defaults and policy overrides after global overrides,
+// so per-key rules win in both cases.
+func payloadConfigForClient(ctx context.Context, cfg *config.Config) *config.Config {
+	if cfg == nil || len(cfg.ClientKeyPolicies) == 0 {
+		return cfg
+	}
+	policy := cfg.ClientKeyPolicyFor(apiKeyFromContext(ctx))
+	if policy == nil || policy.Payload == nil {
+		return cfg
+	}
+	rules := policy.Payload
+	merged := *cfg
+	merged.Payload = config.PayloadConfig{
+		Default:     append(append([]config.PayloadRule(nil), rules.Default...), cfg.Payload.Default...),
+		DefaultRaw:  append(append([]config.PayloadRule(nil), rules.DefaultRaw...), cfg.Payload.DefaultRaw...),
+		Override:    append(append([]config.PayloadRule(nil), cfg.Payload.Override...), rules.Override...),
+		OverrideRaw: append(append([]config.PayloadRule(nil), cfg.Payload.OverrideRaw...), rules.OverrideRaw...),
+		Filter:      append(append([]config.PayloadFilterRule(nil), cfg.Payload.Filter...), rules.Filter...),
+	}
+	return &merged
+}
@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestApplyPayloadScripts(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Payload.Scripts = []config.PayloadScriptRule{
+		{
+			Models:  []config.PayloadModelRule{{Name: "gemini-*", Protocol: "gemini"}},
+			Request: "if tokens(payload()) > 5:\n    set(\"generationConfig.maxOutputTokens\", min(get(\"generationConfig.maxOutputTokens\"), 256))",
+		},
+		{
+			Models:  []config.PayloadModelRule{{Name: "gemini-*"}},
+			Request: `set("labels.auth", auth_label + "/" + requested_model)`,
+		},
+		{
+			Models:  []config.PayloadModelRule{{Name: "claude-*"}},
+			Request: `delete("generationConfig")`,
+		},
+	}
+	cfg.SanitizePayloadRules()
+	auth := &cliproxyauth.Auth{ID: "a1", Provider: "gemini-cli", Label: "work"}
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.RequestedModelMetadataKey: "gemini-pro-alias"}}
+	payload := []byte(`{"request":{"generationConfig":{"maxOutputTokens":8192}}}`)
+
+	got := applyPayloadScripts(context.Background(), cfg, auth, opts, "gemini-2.5-pro", "gemini", "request", payload)
+	if want := `{"request":{"generationConfig":{"maxOutputTokens":256},"labels":{"auth":"work/gemini-pro-alias"}}}`; string(got) != want {
+		t.Fatalf("applyPayloadScripts() = %s, want %s", got, want)
+	}
+
+	// A failing script leaves the payload untouched.
+	cfg.Payload.Scripts = []config.PayloadScriptRule{{
+		Models:  []config.PayloadModelRule{{Name: "*"}},
+		Request: `set_raw("a", "{not json")`,
+	}}
+	if got = applyPayloadScripts(context.Background(), cfg, auth, opts, "gemini-2.5-pro", "gemini", "", payload); string(got) != string(payload) {
+		t.Fatalf("applyPayloadScripts() with failing script = %s", got)
+	}
+}
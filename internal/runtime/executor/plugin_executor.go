@@ -0,0 +1,348 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/plugin"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// PluginExecutor forwards requests to an out-of-process plugin. Requests are translated
+// to the format the plugin declares during the handshake, and responses are translated back.
+type PluginExecutor struct {
+	provider string
+	host     *plugin.Host
+	cfg      *config.Config
+}
+
+// NewPluginExecutor creates an executor for provider backed by host.
+func NewPluginExecutor(provider string, host *plugin.Host, cfg *config.Config) *PluginExecutor {
+	return &PluginExecutor{provider: provider, host: host, cfg: cfg}
+}
+
+// Identifier implements cliproxyauth.ProviderExecutor.
+func (e *PluginExecutor) Identifier() string { return e.provider }
+
+// Host returns the plugin host the executor talks to.
+func (e *PluginExecutor) Host() *plugin.Host { return e.host }
+
+func (e *PluginExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	client, to, err := e.connect(ctx)
+	if err != nil {
+		return resp, err
+	}
+	from := opts.SourceFormat
+	params, translated, err := e.buildParams(ctx, auth, req, opts, to, false)
+	if err != nil {
+		return resp, err
+	}
+	e.recordRequest(ctx, auth, plugin.MethodExecute, translated)
+
+	var result plugin.ExecuteResult
+	if err = client.Call(ctx, plugin.MethodExecute, params, &result); err != nil {
+		err = pluginStatusErr(err)
+		recordAPIResponseError(ctx, e.cfg, err)
+		return resp, err
+	}
+	recordAPIResponseMetadata(ctx, e.cfg, http.StatusOK, http.Header(result.Headers))
+	appendAPIResponseChunk(ctx, e.cfg, result.Payload)
+	if to == sdktranslator.FormatOpenAI {
+		reporter.publish(ctx, parseOpenAIUsage(result.Payload))
+	}
+	reporter.ensurePublished(ctx)
+
+	var param any
+	out := sdktranslator.TranslateNonStream(ctx, to, from, req.Model, opts.OriginalRequest, translated, result.Payload, &param)
+	return cliproxyexecutor.Response{Payload: []byte(out), Headers: http.Header(result.Headers)}, nil
+}
+
+func (e *PluginExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (_ *cliproxyexecutor.StreamResult, err error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	client, to, err := e.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from := opts.SourceFormat
+	params, translated, err := e.buildParams(ctx, auth, req, opts, to, true)
+	if err != nil {
+		return nil, err
+	}
+	e.recordRequest(ctx, auth, plugin.MethodExecuteStream, translated)
+
+	// Chunks arrive through a callback rather than a body, so the idle timeout cancels the
+	// call instead of closing a reader. The timer is paused while a chunk is handed to the
+	// client so a slow client does not count as a stall.
+	callCtx, cancelCall := context.WithCancelCause(ctx)
+	idle := streamIdleTimeout(e.cfg, auth, e.Identifier())
+	var stall *time.Timer
+	if idle > 0 {
+		stall = time.AfterFunc(idle, func() {
+			logWithRequestID(ctx).Warnf("plugin stream stalled for %s, aborting", idle)
+			cancelCall(streamStallError{idle: idle})
+		})
+	}
+
+	out := make(chan cliproxyexecutor.StreamChunk)
+	go func() {
+		defer close(out)
+		defer cancelCall(nil)
+		if stall != nil {
+			defer stall.Stop()
+		}
+		var param any
+		errCall := client.CallStream(callCtx, plugin.MethodExecuteStream, params, nil, func(data string) {
+			if stall != nil {
+				stall.Stop()
+				defer stall.Reset(idle)
+			}
+			line := []byte(data)
+			appendAPIResponseChunk(ctx, e.cfg, line)
+			if to == sdktranslator.FormatOpenAI {
+				if detail, ok := parseOpenAIStreamUsage(line); ok {
+					reporter.publish(ctx, detail)
+				}
+			}
+			chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, opts.OriginalRequest, translated, line, &param)
+			for i := range chunks {
+				select {
+				case out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}:
+				case <-ctx.Done():
+				}
+			}
+		})
+		if cause := context.Cause(callCtx); errCall != nil && isStreamStall(cause) {
+			errCall = cause
+		}
+		if errCall != nil {
+			errCall = pluginStatusErr(errCall)
+			recordAPIResponseError(ctx, e.cfg, errCall)
+			reporter.publishFailure(ctx)
+			select {
+			case out <- cliproxyexecutor.StreamChunk{Err: errCall}:
+			case <-ctx.Done():
+			}
+			return
+		}
+		reporter.ensurePublished(ctx)
+	}()
+	return &cliproxyexecutor.StreamResult{Chunks: out}, nil
+}
+
+func (e *PluginExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	client, to, err := e.connect(ctx)
+	if err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
+	params, _, err := e.buildParams(ctx, auth, req, opts, to, false)
+	if err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
+	var result plugin.CountTokensResult
+	if err = client.Call(ctx, plugin.MethodCountTokens, params, &result); err != nil {
+		return cliproxyexecutor.Response{}, pluginStatusErr(err)
+	}
+	raw := []byte(result.Payload)
+	if len(raw) == 0 {
+		raw = buildOpenAIUsageJSON(result.TotalTokens)
+	}
+	translated := sdktranslator.TranslateTokenCount(ctx, to, opts.SourceFormat, result.TotalTokens, raw)
+	return cliproxyexecutor.Response{Payload: []byte(translated)}, nil
+}
+
+// Refresh lets the plugin renew the credential. Attributes and metadata returned by the
+// plugin replace the previous ones.
+func (e *PluginExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*cliproxyauth.Auth, error) {
+	if auth == nil {
+		return nil, nil
+	}
+	client, _, err := e.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var result plugin.RefreshResult
+	if err = client.Call(ctx, plugin.MethodRefresh, plugin.AuthParams{Auth: pluginAuth(auth)}, &result); err != nil {
+		return nil, pluginStatusErr(err)
+	}
+	if result.Auth == nil {
+		return auth, nil
+	}
+	updated := auth.Clone()
+	for k := range updated.Attributes {
+		if strings.HasPrefix(k, plugin.AuthAttributePrefix) {
+			delete(updated.Attributes, k)
+		}
+	}
+	if updated.Attributes == nil {
+		updated.Attributes = make(map[string]string)
+	}
+	for k, v := range result.Auth.Attributes {
+		updated.Attributes[plugin.AuthAttributePrefix+k] = v
+	}
+	if result.Auth.Metadata != nil {
+		updated.Metadata = result.Auth.Metadata
+	}
+	return updated, nil
+}
+
+// HttpRequest is not supported: plugins own their upstream connections.
+func (e *PluginExecutor) HttpRequest(context.Context, *cliproxyauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("plugin executor %s: raw HTTP requests are not supported", e.provider)
+}
+
+// ListModels asks the plugin which models auth serves.
+func (e *PluginExecutor) ListModels(ctx context.Context, auth *cliproxyauth.Auth) ([]*registry.ModelInfo, error) {
+	client, _, err := e.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var result plugin.ListModelsResult
+	if err = client.Call(ctx, plugin.MethodListModels, plugin.AuthParams{Auth: pluginAuth(auth)}, &result); err != nil {
+		return nil, pluginStatusErr(err)
+	}
+	now := time.Now().Unix()
+	models := make([]*registry.ModelInfo, 0, len(result.Models))
+	for _, m := range result.Models {
+		id := strings.TrimSpace(m.ID)
+		if id == "" {
+			continue
+		}
+		displayName := m.DisplayName
+		if displayName == "" {
+			displayName = id
+		}
+		ownedBy := m.OwnedBy
+		if ownedBy == "" {
+			ownedBy = e.provider
+		}
+		models = append(models, &registry.ModelInfo{
+			ID:                  id,
+			Object:              "model",
+			Created:             now,
+			OwnedBy:             ownedBy,
+			Type:                e.provider,
+			DisplayName:         displayName,
+			Description:         m.Description,
+			ContextLength:       m.ContextLength,
+			MaxCompletionTokens: m.MaxCompletionTokens,
+			Pricing:             m.Pricing,
+			UserDefined:         true,
+		})
+	}
+	return models, nil
+}
+
+func (e *PluginExecutor) connect(ctx context.Context) (*plugin.Client, sdktranslator.Format, error) {
+	if e.host == nil {
+		return nil, "", statusErr{code: http.StatusServiceUnavailable, msg: "plugin " + e.provider + " is not configured"}
+	}
+	client, info, err := e.host.Connect(ctx)
+	if err != nil {
+		return nil, "", statusErr{code: http.StatusServiceUnavailable, msg: err.Error()}
+	}
+	return client, sdktranslator.FromString(info.Format), nil
+}
+
+func (e *PluginExecutor) buildParams(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, to sdktranslator.Format, stream bool) (plugin.ExecuteParams, []byte, error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+	from := opts.SourceFormat
+	originalPayload := req.Payload
+	if len(opts.OriginalRequest) > 0 {
+		originalPayload = opts.OriginalRequest
+	}
+	originalTranslated := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, originalPayload, stream)
+	translated := sdktranslator.TranslateRequestContext(ctx, from, to, baseModel, req.Payload, stream)
+	requestedModel := payloadRequestedModel(opts, req.Model)
+	translated = applyPayloadConfigWithRoot(payloadConfigForClient(ctx, e.cfg), baseModel, to.String(), "", translated, originalTranslated, requestedModel)
+	translated = applyPayloadScripts(ctx, e.cfg, auth, opts, baseModel, to.String(), "", translated)
+	translated, err := applyRedaction(ctx, e.cfg, translated)
+	if err != nil {
+		return plugin.ExecuteParams{}, nil, err
+	}
+	payload := json.RawMessage(translated)
+	if !json.Valid(payload) {
+		// The wire field is raw JSON; fall back to a JSON string for non-JSON payloads.
+		payload, _ = json.Marshal(string(translated))
+	}
+	return plugin.ExecuteParams{
+		Auth:    pluginAuth(auth),
+		Model:   baseModel,
+		Payload: payload,
+		Stream:  stream,
+		Headers: opts.Headers,
+	}, translated, nil
+}
+
+func (e *PluginExecutor) recordRequest(ctx context.Context, auth *cliproxyauth.Auth, method string, body []byte) {
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	recordAPIRequest(ctx, e.cfg, upstreamRequestLog{
+		URL:       "plugin://" + e.provider + "/" + method,
+		Method:    http.MethodPost,
+		Body:      body,
+		Provider:  e.Identifier(),
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+}
+
+// pluginAuth converts an auth record to its wire form, exposing only the account attributes.
+func pluginAuth(auth *cliproxyauth.Auth) plugin.Auth {
+	if auth == nil {
+		return plugin.Auth{}
+	}
+	out := plugin.Auth{
+		ID:       auth.ID,
+		Provider: auth.Provider,
+		Label:    auth.Label,
+		ProxyURL: auth.ProxyURL,
+		Metadata: auth.Metadata,
+	}
+	for k, v := range auth.Attributes {
+		if name, ok := strings.CutPrefix(k, plugin.AuthAttributePrefix); ok {
+			if out.Attributes == nil {
+				out.Attributes = make(map[string]string)
+			}
+			out.Attributes[name] = v
+		}
+	}
+	return out
+}
+
+// pluginStatusErr maps a plugin error carrying an upstream status to statusErr so the
+// manager cools the credential down like for built-in providers.
+func pluginStatusErr(err error) error {
+	var perr *plugin.Error
+	if !errors.As(err, &perr) || perr.Status == 0 {
+		return err
+	}
+	out := statusErr{code: perr.Status, msg: perr.Message}
+	if perr.RetryAfter > 0 {
+		retryAfter := perr.RetryAfter
+		out.retryAfter = &retryAfter
+	}
+	return out
+}
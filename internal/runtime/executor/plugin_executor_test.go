@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/plugin"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+type echoPlugin struct{}
+
+func (echoPlugin) ListModels(_ context.Context, auth plugin.Auth) ([]plugin.Model, error) {
+	return []plugin.Model{{ID: "echo-1", ContextLength: 4096}, {ID: "echo-" + auth.Attributes["region"]}}, nil
+}
+
+func (echoPlugin) Execute(_ context.Context, params plugin.ExecuteParams) (plugin.ExecuteResult, error) {
+	if params.Auth.Attributes["api_key"] == "revoked" {
+		return plugin.ExecuteResult{}, &plugin.Error{Message: "key revoked", Status: http.StatusUnauthorized}
+	}
+	payload, _ := json.Marshal(map[string]any{
+		"id":      "echo",
+		"object":  "chat.completion",
+		"model":   params.Model,
+		"choices": []any{map[string]any{"index": 0, "message": map[string]string{"role": "assistant", "content": "hi " + params.Auth.Attributes["api_key"]}, "finish_reason": "stop"}},
+		"usage":   map[string]int{"prompt_tokens": 3, "completion_tokens": 2, "total_tokens": 5},
+	})
+	return plugin.ExecuteResult{Payload: payload}, nil
+}
+
+func (echoPlugin) ExecuteStream(_ context.Context, _ plugin.ExecuteParams, emit func(string) error) error {
+	if err := emit(`data: {"id":"echo","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"hi"}}]}`); err != nil {
+		return err
+	}
+	return emit("data: [DONE]")
+}
+
+func (echoPlugin) CountTokens(context.Context, plugin.ExecuteParams) (plugin.CountTokensResult, error) {
+	return plugin.CountTokensResult{TotalTokens: 11}, nil
+}
+
+func (echoPlugin) Refresh(context.Context, plugin.Auth) (*plugin.Auth, error) { return nil, nil }
+
+func TestPluginExecutorOverUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "echo.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, errAccept := listener.Accept()
+			if errAccept != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_ = plugin.Serve(context.Background(), conn, conn, plugin.Info{Name: "echo"}, echoPlugin{})
+			}()
+		}
+	}()
+
+	host := plugin.NewHost(plugin.Options{Name: "echo", Socket: socket})
+	t.Cleanup(func() { _ = host.Close() })
+	exec := NewPluginExecutor("echo", host, &config.Config{})
+	auth := &cliproxyauth.Auth{ID: "echo-1", Provider: "echo", Attributes: map[string]string{
+		plugin.AuthAttributePlugin:             "echo",
+		plugin.AuthAttributePrefix + "api_key": "k1",
+		plugin.AuthAttributePrefix + "region":  "eu",
+		"source":                               "config:plugin-echo[x]",
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	models, err := exec.ListModels(ctx, auth)
+	if err != nil || len(models) != 2 || models[0].ContextLength != 4096 || models[1].ID != "echo-eu" || models[0].Type != "echo" {
+		t.Fatalf("ListModels = %+v, err = %v", models, err)
+	}
+
+	request := []byte(`{"model":"echo-1","messages":[{"role":"user","content":"hello"}]}`)
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAI, OriginalRequest: request}
+	resp, err := exec.Execute(ctx, auth, cliproxyexecutor.Request{Model: "echo-1", Payload: request}, opts)
+	if err != nil {
+		t.Fatalf("Execute error = %v", err)
+	}
+	if !strings.Contains(string(resp.Payload), `"content":"hi k1"`) {
+		t.Fatalf("Execute payload = %s", resp.Payload)
+	}
+
+	opts.Stream = true
+	stream, err := exec.ExecuteStream(ctx, auth, cliproxyexecutor.Request{Model: "echo-1", Payload: request}, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream error = %v", err)
+	}
+	var streamed strings.Builder
+	for chunk := range stream.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("stream chunk error = %v", chunk.Err)
+		}
+		streamed.Write(chunk.Payload)
+	}
+	if !strings.Contains(streamed.String(), `"content":"hi"`) {
+		t.Fatalf("streamed = %s", streamed.String())
+	}
+
+	revoked := auth.Clone()
+	revoked.Attributes[plugin.AuthAttributePrefix+"api_key"] = "revoked"
+	_, err = exec.Execute(ctx, revoked, cliproxyexecutor.Request{Model: "echo-1", Payload: request}, opts)
+	if se, ok := err.(interface{ StatusCode() int }); !ok || se.StatusCode() != http.StatusUnauthorized {
+		t.Fatalf("revoked Execute error = %#v, want status 401", err)
+	}
+	if got := pluginAuth(auth); got.Attributes["source"] != "" || got.Attributes["api_key"] != "k1" {
+		t.Fatalf("pluginAuth attributes = %v, want only account attributes", got.Attributes)
+	}
+}
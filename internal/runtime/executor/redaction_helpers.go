@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/redaction"
+)
+
+// applyRedaction runs the configured redaction rules on the translated payload. Tokenized
+// matches are tracked on ctx so the caller can restore them in the response, and every
+// finding is recorded for the request log. Redaction fails closed: a matching block rule, an
+// invalid rule set, or a payload that cannot be inspected fails the request with a 400
+// invalid_request_error so it is neither sent nor retried on another credential.
+func applyRedaction(ctx context.Context, cfg *config.Config, payload []byte) ([]byte, error) {
+	if cfg == nil || !cfg.Redaction.Enable || len(payload) == 0 {
+		return payload, nil
+	}
+	rules, err := cfg.Redaction.Rules()
+	if err != nil {
+		logWithRequestID(ctx).Errorf("redaction: %v", err)
+		return nil, statusErr{code: http.StatusBadRequest, msg: redactionErrorMessage("redaction_failed", "request rejected: redaction rules are invalid")}
+	}
+	if len(rules) == 0 {
+		return payload, nil
+	}
+	redactor, err := redaction.Compile(rules)
+	if err != nil {
+		logWithRequestID(ctx).Errorf("redaction: %v", err)
+		return nil, statusErr{code: http.StatusBadRequest, msg: redactionErrorMessage("redaction_failed", "request rejected: redaction rules are invalid")}
+	}
+	out, session, err := redactor.Redact(payload)
+	if err != nil {
+		var blocked *redaction.BlockedError
+		if errors.As(err, &blocked) {
+			logWithRequestID(ctx).Warnf("redaction: %v", blocked)
+			return nil, statusErr{code: http.StatusBadRequest, msg: redactionErrorMessage("redaction_blocked", blocked.Error())}
+		}
+		logWithRequestID(ctx).Warnf("redaction: %v", err)
+		return nil, statusErr{code: http.StatusBadRequest, msg: redactionErrorMessage("redaction_failed", "request rejected: "+err.Error())}
+	}
+	if len(session.Findings) > 0 {
+		redaction.Track(ctx, session)
+		recordRedactions(ctx, cfg, session.Findings)
+		logWithRequestID(ctx).Debugf("redaction: %d match(es) redacted", len(session.Findings))
+	}
+	return out, nil
+}
+
+func redactionErrorMessage(code, message string) string {
+	body := map[string]any{
+		"error": map[string]any{
+			"type":    "invalid_request_error",
+			"code":    code,
+			"message": message,
+		},
+	}
+	data, _ := json.Marshal(body)
+	return string(data)
+}
@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// streamStallError reports an upstream stream that delivered no data within the idle timeout.
+// It maps to 504 so handlers end the client stream with a well-formed error event in the
+// client's format, and so bootstrap retries apply when nothing was sent yet.
+type streamStallError struct {
+	idle time.Duration
+}
+
+func (e streamStallError) Error() string {
+	return fmt.Sprintf("upstream stream stalled: no data received for %s", e.idle)
+}
+
+func (e streamStallError) StatusCode() int { return http.StatusGatewayTimeout }
+
+// isStreamStall reports whether err was raised by a stall watcher.
+func isStreamStall(err error) bool {
+	var stall streamStallError
+	return errors.As(err, &stall)
+}
+
+// streamIdleTimeout resolves the idle timeout for a stream: the override for the credential's
+// provider (the openai-compatibility name for compat credentials), then the executor
+// identifier, then the global value.
+func streamIdleTimeout(cfg *config.Config, auth *cliproxyauth.Auth, identifier string) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+	streaming := cfg.Streaming
+	seconds := streaming.IdleTimeoutSeconds
+	if len(streaming.ProviderIdleTimeoutSeconds) > 0 {
+		var keys []string
+		if auth != nil {
+			keys = append(keys, auth.Provider)
+			if auth.Attributes != nil {
+				keys = append(keys, auth.Attributes["compat_name"])
+			}
+		}
+		keys = append(keys, identifier)
+		for _, key := range keys {
+			key = strings.ToLower(strings.TrimSpace(key))
+			if key == "" {
+				continue
+			}
+			if override, ok := lookupProviderIdleTimeout(streaming.ProviderIdleTimeoutSeconds, key); ok {
+				seconds = override
+				break
+			}
+		}
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func lookupProviderIdleTimeout(overrides map[string]int, key string) (int, bool) {
+	for name, seconds := range overrides {
+		if strings.EqualFold(strings.TrimSpace(name), key) {
+			return seconds, true
+		}
+	}
+	return 0, false
+}
+
+// watchStreamStall wraps an upstream stream body so a read that waits longer than the
+// configured idle timeout closes the body and fails with streamStallError. Time spent while
+// the caller is not reading (for example a slow client) does not count. It returns body
+// unchanged when stall detection is disabled.
+func watchStreamStall(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, identifier string, body io.ReadCloser) io.ReadCloser {
+	timeout := streamIdleTimeout(cfg, auth, identifier)
+	if timeout <= 0 || body == nil {
+		return body
+	}
+	return newStallWatchBody(ctx, body, timeout)
+}
+
+type stallWatchBody struct {
+	ctx     context.Context
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	stalled atomic.Bool
+	once    sync.Once
+}
+
+func newStallWatchBody(ctx context.Context, body io.ReadCloser, timeout time.Duration) *stallWatchBody {
+	b := &stallWatchBody{ctx: ctx, body: body, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, b.fire)
+	b.timer.Stop()
+	return b
+}
+
+func (b *stallWatchBody) fire() {
+	if b.stalled.CompareAndSwap(false, true) {
+		logWithRequestID(b.ctx).Warnf("upstream stream stalled for %s, aborting", b.timeout)
+		_ = b.body.Close()
+	}
+}
+
+func (b *stallWatchBody) Read(p []byte) (int, error) {
+	if b.stalled.Load() {
+		return 0, streamStallError{idle: b.timeout}
+	}
+	b.timer.Reset(b.timeout)
+	n, err := b.body.Read(p)
+	b.timer.Stop()
+	if err != nil && b.stalled.Load() {
+		return n, streamStallError{idle: b.timeout}
+	}
+	return n, err
+}
+
+func (b *stallWatchBody) Close() error {
+	var err error
+	b.once.Do(func() {
+		b.timer.Stop()
+		// A stalled body was already closed by the watcher.
+		if !b.stalled.Load() {
+			err = b.body.Close()
+		}
+	})
+	return err
+}
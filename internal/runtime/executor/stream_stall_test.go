@@ -0,0 +1,248 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/plugin"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// stalledBody returns data and then blocks until closed.
+func stalledBody(data string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() { _, _ = pw.Write([]byte(data)) }()
+	return pr
+}
+
+func TestStallWatchBodyAbortsIdleStream(t *testing.T) {
+	body := newStallWatchBody(context.Background(), stalledBody("data: first\n"), 50*time.Millisecond)
+	defer func() { _ = body.Close() }()
+
+	buf := make([]byte, 64)
+	n, err := body.Read(buf)
+	if err != nil || string(buf[:n]) != "data: first\n" {
+		t.Fatalf("first read = %q, %v", buf[:n], err)
+	}
+	_, err = body.Read(buf)
+	if !isStreamStall(err) {
+		t.Fatalf("expected stall error, got %v", err)
+	}
+	if code := err.(interface{ StatusCode() int }).StatusCode(); code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", code)
+	}
+}
+
+func TestStreamIdleTimeoutPrefersProviderOverride(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Streaming.IdleTimeoutSeconds = 60
+	cfg.Streaming.ProviderIdleTimeoutSeconds = map[string]int{"Claude": 300, "my-vendor": 10}
+
+	if got := streamIdleTimeout(cfg, nil, "claude"); got != 300*time.Second {
+		t.Fatalf("claude timeout = %s", got)
+	}
+	if got := streamIdleTimeout(cfg, nil, "gemini"); got != 60*time.Second {
+		t.Fatalf("gemini timeout = %s", got)
+	}
+	compat := &cliproxyauth.Auth{Provider: "openai-compatibility", Attributes: map[string]string{"compat_name": "my-vendor"}}
+	if got := streamIdleTimeout(cfg, compat, "openai-compatibility"); got != 10*time.Second {
+		t.Fatalf("compat timeout = %s", got)
+	}
+}
+
+func TestClaudeStreamContinuationSplicesPrefilledContinuation(t *testing.T) {
+	first := "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\"}}\n\n" +
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello \"}}\n\n"
+	second := "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_2\"}}\n\n" +
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\" world\"}}\n\n" +
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n" +
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"tool_use\",\"id\":\"t\",\"name\":\"f\"}}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"
+
+	var prefills []string
+	stream := newClaudeStreamContinuation(context.Background(),
+		newStallWatchBody(context.Background(), stalledBody(first), 50*time.Millisecond), 1,
+		func(prefill string) (io.ReadCloser, error) {
+			prefills = append(prefills, prefill)
+			return io.NopCloser(strings.NewReader(second)), nil
+		})
+	out, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(prefills) != 1 || prefills[0] != "Hello" {
+		t.Fatalf("prefills = %q", prefills)
+	}
+
+	var types []string
+	var indices []int64
+	for _, line := range strings.Split(string(out), "\n") {
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		data := strings.TrimPrefix(line, "data: ")
+		types = append(types, gjson.Get(data, "type").String())
+		if idx := gjson.Get(data, "index"); idx.Exists() {
+			indices = append(indices, idx.Int())
+		}
+	}
+	wantTypes := "message_start,content_block_start,content_block_delta,content_block_delta,content_block_stop,content_block_start,message_stop"
+	if got := strings.Join(types, ","); got != wantTypes {
+		t.Fatalf("event types = %s", got)
+	}
+	if len(indices) != 5 || indices[3] != 0 || indices[4] != 1 {
+		t.Fatalf("indices = %v", indices)
+	}
+	if !strings.Contains(string(out), "msg_1") || strings.Contains(string(out), "msg_2") {
+		t.Fatalf("expected only the first message_start, got %s", out)
+	}
+}
+
+func TestClaudeStreamContinuationSkipsToolStreams(t *testing.T) {
+	first := "event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"tool_use\",\"id\":\"t\",\"name\":\"f\"}}\n\n"
+	stream := newClaudeStreamContinuation(context.Background(),
+		newStallWatchBody(context.Background(), stalledBody(first), 50*time.Millisecond), 1,
+		func(string) (io.ReadCloser, error) {
+			t.Fatalf("tool streams must not be continued")
+			return nil, nil
+		})
+	_, err := io.ReadAll(stream)
+	if !isStreamStall(err) {
+		t.Fatalf("expected stall error, got %v", err)
+	}
+}
+
+func TestClaudeStallRecoveryAttempts(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Streaming.IdleTimeoutSeconds = 30
+	cfg.Streaming.StallRecovery = true
+	body := []byte(`{"messages":[{"role":"user","content":"hi"}]}`)
+	if got := claudeStallRecoveryAttempts(cfg, nil, body); got != 1 {
+		t.Fatalf("attempts = %d, want 1", got)
+	}
+	if got := claudeStallRecoveryAttempts(cfg, nil, []byte(`{"thinking":{"type":"enabled"},"messages":[{"role":"user","content":"hi"}]}`)); got != 0 {
+		t.Fatalf("thinking request attempts = %d, want 0", got)
+	}
+	if got := claudeStallRecoveryAttempts(cfg, nil, []byte(`{"messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"x"}]}`)); got != 0 {
+		t.Fatalf("prefilled request attempts = %d, want 0", got)
+	}
+}
+
+// stallResult drains chunks and returns the first error, or nil when the stream ends cleanly.
+func stallResult(t *testing.T, chunks <-chan cliproxyexecutor.StreamChunk) error {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return nil
+			}
+			if chunk.Err != nil {
+				return chunk.Err
+			}
+		case <-timeout:
+			t.Fatal("stream did not end")
+		}
+	}
+}
+
+func TestCodexWebsocketStreamAbortsOnStall(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		if _, _, err = conn.ReadMessage(); err != nil {
+			return
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"response.created","response":{"id":"resp_1"}}`))
+		// Stay silent until the client gives up.
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Streaming.IdleTimeoutSeconds = 1
+	exec := NewCodexWebsocketsExecutor(cfg)
+	auth := &cliproxyauth.Auth{ID: "codex-ws", Provider: "codex", Attributes: map[string]string{"api_key": "k", "base_url": server.URL}}
+	payload := []byte(`{"model":"gpt-5","input":"hi"}`)
+	stream, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{Model: "gpt-5", Payload: payload}, cliproxyexecutor.Options{
+		SourceFormat:    sdktranslator.FromString("openai-response"),
+		OriginalRequest: payload,
+		Stream:          true,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream error = %v", err)
+	}
+	if err = stallResult(t, stream.Chunks); !isStreamStall(err) {
+		t.Fatalf("expected stall error, got %v", err)
+	}
+}
+
+type stallingPlugin struct{ echoPlugin }
+
+func (stallingPlugin) ExecuteStream(ctx context.Context, _ plugin.ExecuteParams, emit func(string) error) error {
+	if err := emit(`data: {"id":"echo","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"hi"}}]}`); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestPluginStreamAbortsOnStall(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "stall.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, errAccept := listener.Accept()
+			if errAccept != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_ = plugin.Serve(context.Background(), conn, conn, plugin.Info{Name: "stall"}, stallingPlugin{})
+			}()
+		}
+	}()
+
+	host := plugin.NewHost(plugin.Options{Name: "stall", Socket: socket})
+	t.Cleanup(func() { _ = host.Close() })
+	cfg := &config.Config{}
+	cfg.Streaming.ProviderIdleTimeoutSeconds = map[string]int{"stall": 1}
+	exec := NewPluginExecutor("stall", host, cfg)
+	auth := &cliproxyauth.Auth{ID: "stall-1", Provider: "stall", Attributes: map[string]string{plugin.AuthAttributePlugin: "stall"}}
+
+	request := []byte(`{"model":"echo-1","messages":[{"role":"user","content":"hello"}]}`)
+	stream, err := exec.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{Model: "echo-1", Payload: request}, cliproxyexecutor.Options{
+		SourceFormat:    sdktranslator.FormatOpenAI,
+		OriginalRequest: request,
+		Stream:          true,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream error = %v", err)
+	}
+	if err = stallResult(t, stream.Chunks); !isStreamStall(err) {
+		t.Fatalf("expected stall error, got %v", err)
+	}
+}
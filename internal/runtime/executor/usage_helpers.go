@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -22,6 +24,9 @@ type usageReporter struct {
 	authIndex   string
 	apiKey      string
 	source      string
+	splitAlias  string
+	splitTarget string
+	hedge       bool
 	requestedAt time.Time
 	once        sync.Once
 }
@@ -35,6 +40,8 @@ func newUsageReporter(ctx context.Context, provider, model string, auth *cliprox
 		apiKey:      apiKey,
 		source:      resolveUsageSource(auth, apiKey),
 	}
+	reporter.splitAlias, reporter.splitTarget = modelSplitFromContext(ctx)
+	reporter.hedge = cliproxyexecutor.HedgeAttempt(ctx)
 	if auth != nil {
 		reporter.authID = auth.ID
 		reporter.authIndex = auth.EnsureIndex()
@@ -83,6 +90,9 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, detail usage.Det
 			RequestedAt: r.requestedAt,
 			Failed:      failed,
 			Detail:      detail,
+			SplitAlias:  r.splitAlias,
+			SplitTarget: r.splitTarget,
+			Hedge:       r.hedge,
 		})
 	})
 }
@@ -106,6 +116,9 @@ func (r *usageReporter) ensurePublished(ctx context.Context) {
 			RequestedAt: r.requestedAt,
 			Failed:      false,
 			Detail:      usage.Detail{},
+			SplitAlias:  r.splitAlias,
+			SplitTarget: r.splitTarget,
+			Hedge:       r.hedge,
 		})
 	})
 }
@@ -131,6 +144,18 @@ func apiKeyFromContext(ctx context.Context) string {
 	return ""
 }
 
+// modelSplitFromContext returns the weighted alias and selected arm recorded by the handler, if any.
+func modelSplitFromContext(ctx context.Context) (string, string) {
+	if ctx == nil {
+		return "", ""
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return "", ""
+	}
+	return ginCtx.GetString(constant.ModelSplitAliasContextKey), ginCtx.GetString(constant.ModelSplitTargetContextKey)
+}
+
 func resolveUsageSource(auth *cliproxyauth.Auth, ctxAPIKey string) string {
 	if auth != nil {
 		provider := strings.TrimSpace(auth.Provider)
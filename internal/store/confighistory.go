@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/confighistory"
+)
+
+const objectStoreHistoryPrefix = "config/history"
+
+// configHistoryDir returns the directory holding config versions inside the git repository.
+func (s *GitTokenStore) configHistoryDir() (*confighistory.DirStore, error) {
+	s.dirLock.RLock()
+	configDir := s.configDir
+	s.dirLock.RUnlock()
+	if configDir == "" {
+		return nil, fmt.Errorf("git token store: config path not configured")
+	}
+	return confighistory.NewDirStore(filepath.Join(configDir, "history")), nil
+}
+
+// SaveConfigVersion writes a config version into the repository and pushes it.
+func (s *GitTokenStore) SaveConfigVersion(ctx context.Context, version confighistory.Version, data []byte) error {
+	if err := s.EnsureRepository(); err != nil {
+		return err
+	}
+	history, err := s.configHistoryDir()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err = history.SaveConfigVersion(ctx, version, data); err != nil {
+		return fmt.Errorf("git token store: save config version: %w", err)
+	}
+	return s.commitHistoryLocked(history, "Record config version "+version.ID, version.ID)
+}
+
+// ListConfigVersions lists the config versions stored in the repository.
+func (s *GitTokenStore) ListConfigVersions(ctx context.Context) ([]confighistory.Version, error) {
+	history, err := s.configHistoryDir()
+	if err != nil {
+		return nil, err
+	}
+	return history.ListConfigVersions(ctx)
+}
+
+// LoadConfigVersion reads a config version from the repository.
+func (s *GitTokenStore) LoadConfigVersion(ctx context.Context, id string) (confighistory.Version, []byte, error) {
+	history, err := s.configHistoryDir()
+	if err != nil {
+		return confighistory.Version{}, nil, err
+	}
+	return history.LoadConfigVersion(ctx, id)
+}
+
+// DeleteConfigVersion removes a config version from the repository and pushes the removal.
+func (s *GitTokenStore) DeleteConfigVersion(ctx context.Context, id string) error {
+	history, err := s.configHistoryDir()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err = history.DeleteConfigVersion(ctx, id); err != nil {
+		return fmt.Errorf("git token store: delete config version: %w", err)
+	}
+	return s.commitHistoryLocked(history, "Prune config version "+id, id)
+}
+
+func (s *GitTokenStore) commitHistoryLocked(history *confighistory.DirStore, message, id string) error {
+	contentPath, metaPath := history.Paths(id)
+	relContent, err := s.relativeToRepo(contentPath)
+	if err != nil {
+		return err
+	}
+	relMeta, err := s.relativeToRepo(metaPath)
+	if err != nil {
+		return err
+	}
+	return s.commitAndPushLocked(message, relContent, relMeta)
+}
+
+func objectStoreHistoryKeys(id string) (content, meta string) {
+	return objectStoreHistoryPrefix + "/" + id + ".yaml", objectStoreHistoryPrefix + "/" + id + ".json"
+}
+
+// SaveConfigVersion uploads a config version and its metadata to the bucket.
+func (s *ObjectTokenStore) SaveConfigVersion(ctx context.Context, version confighistory.Version, data []byte) error {
+	if !confighistory.ValidID(version.ID) {
+		return fmt.Errorf("object store: invalid config version id %q", version.ID)
+	}
+	meta, err := json.Marshal(version)
+	if err != nil {
+		return err
+	}
+	contentKey, metaKey := objectStoreHistoryKeys(version.ID)
+	// Content goes first so a listed version always has its content available.
+	if err = s.putObject(ctx, contentKey, data, "application/x-yaml"); err != nil {
+		return err
+	}
+	return s.putObject(ctx, metaKey, meta, "application/json")
+}
+
+// ListConfigVersions reads the metadata of every config version in the bucket.
+func (s *ObjectTokenStore) ListConfigVersions(ctx context.Context) ([]confighistory.Version, error) {
+	prefix := s.prefixedKey(objectStoreHistoryPrefix + "/")
+	var versions []confighistory.Version
+	for object := range s.client.ListObjects(ctx, s.cfg.Bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("object store: list config versions: %w", object.Err)
+		}
+		if !strings.HasSuffix(object.Key, ".json") {
+			continue
+		}
+		raw, err := s.getObject(ctx, object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("object store: read config version %s: %w", object.Key, err)
+		}
+		var version confighistory.Version
+		if err = json.Unmarshal(raw, &version); err != nil || !confighistory.ValidID(version.ID) {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// LoadConfigVersion downloads a config version from the bucket.
+func (s *ObjectTokenStore) LoadConfigVersion(ctx context.Context, id string) (confighistory.Version, []byte, error) {
+	if !confighistory.ValidID(id) {
+		return confighistory.Version{}, nil, confighistory.ErrVersionNotFound
+	}
+	contentKey, metaKey := objectStoreHistoryKeys(id)
+	raw, err := s.getObject(ctx, s.prefixedKey(metaKey))
+	if err != nil {
+		if isObjectNotFound(err) {
+			return confighistory.Version{}, nil, confighistory.ErrVersionNotFound
+		}
+		return confighistory.Version{}, nil, fmt.Errorf("object store: read config version %s: %w", id, err)
+	}
+	var version confighistory.Version
+	if err = json.Unmarshal(raw, &version); err != nil {
+		return confighistory.Version{}, nil, fmt.Errorf("object store: decode config version %s: %w", id, err)
+	}
+	data, err := s.getObject(ctx, s.prefixedKey(contentKey))
+	if err != nil {
+		if isObjectNotFound(err) {
+			return confighistory.Version{}, nil, confighistory.ErrVersionNotFound
+		}
+		return confighistory.Version{}, nil, fmt.Errorf("object store: read config version %s: %w", id, err)
+	}
+	return version, data, nil
+}
+
+// DeleteConfigVersion removes a config version from the bucket.
+func (s *ObjectTokenStore) DeleteConfigVersion(ctx context.Context, id string) error {
+	if !confighistory.ValidID(id) {
+		return nil
+	}
+	contentKey, metaKey := objectStoreHistoryKeys(id)
+	if err := s.deleteObject(ctx, metaKey); err != nil {
+		return err
+	}
+	return s.deleteObject(ctx, contentKey)
+}
+
+// SaveConfigVersion inserts a config version into the history table.
+func (s *PostgresStore) SaveConfigVersion(ctx context.Context, version confighistory.Version, data []byte) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, created_at, actor, reason, sha256, content)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO NOTHING
+	`, s.fullTableName(s.cfg.HistoryTable))
+	if _, err := s.db.ExecContext(ctx, query, version.ID, version.CreatedAt, version.Actor, version.Reason, version.SHA256, string(data)); err != nil {
+		return fmt.Errorf("postgres store: insert config version: %w", err)
+	}
+	return nil
+}
+
+// ListConfigVersions lists the config versions in the history table.
+func (s *PostgresStore) ListConfigVersions(ctx context.Context) ([]confighistory.Version, error) {
+	query := fmt.Sprintf("SELECT id, created_at, actor, reason, sha256, OCTET_LENGTH(content) FROM %s", s.fullTableName(s.cfg.HistoryTable))
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("postgres store: list config versions: %w", err)
+	}
+	defer rows.Close()
+	var versions []confighistory.Version
+	for rows.Next() {
+		var version confighistory.Version
+		if err = rows.Scan(&version.ID, &version.CreatedAt, &version.Actor, &version.Reason, &version.SHA256, &version.Size); err != nil {
+			return nil, fmt.Errorf("postgres store: scan config version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres store: iterate config versions: %w", err)
+	}
+	return versions, nil
+}
+
+// LoadConfigVersion reads a config version from the history table.
+func (s *PostgresStore) LoadConfigVersion(ctx context.Context, id string) (confighistory.Version, []byte, error) {
+	query := fmt.Sprintf("SELECT id, created_at, actor, reason, sha256, content FROM %s WHERE id = $1", s.fullTableName(s.cfg.HistoryTable))
+	var (
+		version confighistory.Version
+		content string
+	)
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&version.ID, &version.CreatedAt, &version.Actor, &version.Reason, &version.SHA256, &content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return confighistory.Version{}, nil, confighistory.ErrVersionNotFound
+	}
+	if err != nil {
+		return confighistory.Version{}, nil, fmt.Errorf("postgres store: load config version: %w", err)
+	}
+	version.Size = len(content)
+	return version, []byte(content), nil
+}
+
+// DeleteConfigVersion removes a config version from the history table.
+func (s *PostgresStore) DeleteConfigVersion(ctx context.Context, id string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", s.fullTableName(s.cfg.HistoryTable))
+	if _, err := s.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("postgres store: delete config version: %w", err)
+	}
+	return nil
+}
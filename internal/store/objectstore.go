@@ -17,6 +17,7 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
@@ -38,6 +39,8 @@ type ObjectStoreConfig struct {
 	LocalRoot string
 	UseSSL    bool
 	PathStyle bool
+	// SyncInterval controls how often StartSync polls the bucket for remote changes.
+	SyncInterval time.Duration
 }
 
 // ObjectTokenStore persists configuration and authentication metadata using an S3-compatible object storage backend.
@@ -49,6 +52,10 @@ type ObjectTokenStore struct {
 	configPath string
 	authDir    string
 	mu         sync.Mutex
+
+	// remoteAuth and configETag hold the object ETags last seen in the bucket.
+	remoteAuth map[string]string
+	configETag string
 }
 
 // NewObjectTokenStore initializes an object storage backed token store.
@@ -193,18 +200,16 @@ func (s *ObjectTokenStore) Save(ctx context.Context, auth *cliproxyauth.Auth) (s
 			return "", fmt.Errorf("object store: marshal metadata: %w", errMarshal)
 		}
 		if existing, errRead := os.ReadFile(path); errRead == nil {
-			if jsonEqual(existing, raw) {
-				return path, nil
+			if authcrypt.IsSealed(existing) == authcrypt.Enabled() {
+				if plain, errOpen := authcrypt.Open(existing); errOpen == nil && jsonEqual(plain, raw) {
+					return path, nil
+				}
 			}
 		} else if errRead != nil && !errors.Is(errRead, fs.ErrNotExist) {
 			return "", fmt.Errorf("object store: read existing metadata: %w", errRead)
 		}
-		tmp := path + ".tmp"
-		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
-			return "", fmt.Errorf("object store: write temp auth file: %w", errWrite)
-		}
-		if errRename := os.Rename(tmp, path); errRename != nil {
-			return "", fmt.Errorf("object store: rename auth file: %w", errRename)
+		if errWrite := authcrypt.WriteFile(path, raw, 0o600); errWrite != nil {
+			return "", fmt.Errorf("object store: write auth file: %w", errWrite)
 		}
 	default:
 		return "", fmt.Errorf("object store: nothing to persist for %s", auth.ID)
@@ -340,9 +345,10 @@ func (s *ObjectTokenStore) ensureBucket(ctx context.Context) error {
 
 func (s *ObjectTokenStore) syncConfigFromBucket(ctx context.Context, example string) error {
 	key := s.prefixedKey(objectStoreConfigKey)
-	_, err := s.client.StatObject(ctx, s.cfg.Bucket, key, minio.StatObjectOptions{})
+	info, err := s.client.StatObject(ctx, s.cfg.Bucket, key, minio.StatObjectOptions{})
 	switch {
 	case err == nil:
+		s.configETag = info.ETag
 		object, errGet := s.client.GetObject(ctx, s.cfg.Bucket, key, minio.GetObjectOptions{})
 		if errGet != nil {
 			return fmt.Errorf("object store: fetch config: %w", errGet)
@@ -399,6 +405,7 @@ func (s *ObjectTokenStore) syncAuthFromBucket(ctx context.Context) error {
 		Prefix:    prefix,
 		Recursive: true,
 	})
+	remote := make(map[string]string)
 	for object := range objectCh {
 		if object.Err != nil {
 			return fmt.Errorf("object store: list auth objects: %w", object.Err)
@@ -407,17 +414,11 @@ func (s *ObjectTokenStore) syncAuthFromBucket(ctx context.Context) error {
 		if rel == "" || strings.HasSuffix(rel, "/") {
 			continue
 		}
-		relPath := filepath.FromSlash(rel)
-		if filepath.IsAbs(relPath) {
-			log.WithField("key", object.Key).Warn("object store: skip auth outside mirror")
-			continue
-		}
-		cleanRel := filepath.Clean(relPath)
-		if cleanRel == "." || cleanRel == ".." || strings.HasPrefix(cleanRel, ".."+string(os.PathSeparator)) {
+		local, ok := s.mirrorAuthPath(rel)
+		if !ok {
 			log.WithField("key", object.Key).Warn("object store: skip auth outside mirror")
 			continue
 		}
-		local := filepath.Join(s.authDir, cleanRel)
 		if err := os.MkdirAll(filepath.Dir(local), 0o700); err != nil {
 			return fmt.Errorf("object store: prepare auth subdir: %w", err)
 		}
@@ -433,10 +434,28 @@ func (s *ObjectTokenStore) syncAuthFromBucket(ctx context.Context) error {
 		if errWrite := os.WriteFile(local, data, 0o600); errWrite != nil {
 			return fmt.Errorf("object store: write auth %s: %w", local, errWrite)
 		}
+		remote[rel] = object.ETag
 	}
+	s.mu.Lock()
+	s.remoteAuth = remote
+	s.mu.Unlock()
 	return nil
 }
 
+// mirrorAuthPath maps an auth object key (relative to the auth prefix) to its spool path.
+// It returns false for keys that would escape the auth directory.
+func (s *ObjectTokenStore) mirrorAuthPath(rel string) (string, bool) {
+	relPath := filepath.FromSlash(rel)
+	if filepath.IsAbs(relPath) {
+		return "", false
+	}
+	cleanRel := filepath.Clean(relPath)
+	if cleanRel == "." || cleanRel == ".." || strings.HasPrefix(cleanRel, ".."+string(os.PathSeparator)) {
+		return "", false
+	}
+	return filepath.Join(s.authDir, cleanRel), true
+}
+
 func (s *ObjectTokenStore) uploadAuth(ctx context.Context, path string) error {
 	if path == "" {
 		return nil
@@ -554,7 +573,7 @@ func (s *ObjectTokenStore) resolveDeletePath(id string) (string, error) {
 }
 
 func (s *ObjectTokenStore) readAuthFile(path, baseDir string) (*cliproxyauth.Auth, error) {
-	data, err := os.ReadFile(path)
+	data, err := authcrypt.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
 	}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultObjectSyncInterval = 30 * time.Second
+
+// StartSync polls the bucket until ctx is cancelled and mirrors objects whose ETag changed
+// into the local spool directory, where the file watcher picks them up. Objects deleted
+// remotely are removed locally; local files that were never seen remotely are left alone.
+func (s *ObjectTokenStore) StartSync(ctx context.Context) {
+	if s == nil || s.client == nil {
+		return
+	}
+	interval := s.cfg.SyncInterval
+	if interval <= 0 {
+		interval = defaultObjectSyncInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.pollRemote(ctx); err != nil && ctx.Err() == nil {
+					log.WithError(err).Warn("object store: poll remote changes failed")
+				}
+			}
+		}
+	}()
+}
+
+// pollRemote diffs the bucket listing against the last known ETags and mirrors the changes.
+func (s *ObjectTokenStore) pollRemote(ctx context.Context) error {
+	if err := s.pollConfig(ctx); err != nil {
+		return err
+	}
+	prefix := s.prefixedKey(objectStoreAuthPrefix + "/")
+	current := make(map[string]string)
+	for object := range s.client.ListObjects(ctx, s.cfg.Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return fmt.Errorf("list auth objects: %w", object.Err)
+		}
+		rel := strings.TrimPrefix(object.Key, prefix)
+		if rel == "" || strings.HasSuffix(rel, "/") {
+			continue
+		}
+		current[rel] = object.ETag
+	}
+
+	s.mu.Lock()
+	changed, removed := diffRemoteVersions(s.remoteAuth, current)
+	s.mu.Unlock()
+	if len(changed) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	payloads := make(map[string][]byte, len(changed))
+	var failed []string
+	for _, rel := range changed {
+		data, errGet := s.getObject(ctx, prefix+rel)
+		if errGet != nil {
+			failed = append(failed, rel)
+			log.WithError(errGet).Warnf("object store: download auth %s", rel)
+			continue
+		}
+		payloads[rel] = data
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rel := range changed {
+		data, ok := payloads[rel]
+		if !ok {
+			continue
+		}
+		local, okPath := s.mirrorAuthPath(rel)
+		if !okPath {
+			log.WithField("key", rel).Warn("object store: skip auth outside mirror")
+			continue
+		}
+		if written, errWrite := writeSpoolFile(local, data); errWrite != nil {
+			failed = append(failed, rel)
+			log.WithError(errWrite).Warnf("object store: write auth %s", rel)
+		} else if written {
+			log.Infof("object store: pulled remote change for auth %s", rel)
+		}
+	}
+	for _, rel := range removed {
+		local, okPath := s.mirrorAuthPath(rel)
+		if !okPath {
+			continue
+		}
+		if ok, errRemove := removeSpoolFile(local); errRemove != nil {
+			log.WithError(errRemove).Warnf("object store: remove auth %s", rel)
+		} else if ok {
+			log.Infof("object store: removed auth %s deleted remotely", rel)
+		}
+	}
+	// Keep the previous version for objects that could not be mirrored so they are retried.
+	for _, rel := range failed {
+		if prev, ok := s.remoteAuth[rel]; ok {
+			current[rel] = prev
+		} else {
+			delete(current, rel)
+		}
+	}
+	s.remoteAuth = current
+	return nil
+}
+
+// pollConfig mirrors the remote config when its ETag changed. A missing object leaves the local file as is.
+func (s *ObjectTokenStore) pollConfig(ctx context.Context) error {
+	key := s.prefixedKey(objectStoreConfigKey)
+	info, err := s.client.StatObject(ctx, s.cfg.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if isObjectNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("stat config: %w", err)
+	}
+	s.mu.Lock()
+	unchanged := info.ETag == s.configETag
+	s.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+	data, err := s.getObject(ctx, key)
+	if err != nil {
+		return fmt.Errorf("fetch config: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	written, err := writeSpoolConfig(s.configPath, normalizeLineEndingsBytes(data))
+	if err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	s.configETag = info.ETag
+	if written {
+		log.Info("object store: pulled remote config change")
+	}
+	return nil
+}
+
+func (s *ObjectTokenStore) getObject(ctx context.Context, key string) ([]byte, error) {
+	object, err := s.client.GetObject(ctx, s.cfg.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = object.Close()
+	}()
+	return io.ReadAll(object)
+}
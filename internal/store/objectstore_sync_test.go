@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBucket is a minimal S3 endpoint serving ListObjectsV2, HEAD and GET for one bucket.
+type fakeBucket struct {
+	mu      sync.Mutex
+	name    string
+	objects map[string][]byte
+	failGet map[string]bool
+}
+
+func (b *fakeBucket) put(key, content string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[key] = []byte(content)
+}
+
+func (b *fakeBucket) remove(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.objects, key)
+}
+
+func (b *fakeBucket) setFailGet(key string, fail bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failGet[key] = fail
+}
+
+func fakeETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+b.name), "/")
+	if key == "" {
+		prefix := r.URL.Query().Get("prefix")
+		keys := make([]string, 0, len(b.objects))
+		for k := range b.objects {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		var body strings.Builder
+		body.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+		fmt.Fprintf(&body, "<Name>%s</Name><Prefix>%s</Prefix><KeyCount>%d</KeyCount><MaxKeys>1000</MaxKeys><IsTruncated>false</IsTruncated>", b.name, prefix, len(keys))
+		for _, k := range keys {
+			fmt.Fprintf(&body, "<Contents><Key>%s</Key><ETag>%s</ETag><Size>%d</Size><LastModified>2025-01-01T00:00:00.000Z</LastModified></Contents>", k, fakeETag(b.objects[k]), len(b.objects[k]))
+		}
+		body.WriteString("</ListBucketResult>")
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(body.String()))
+		return
+	}
+	data, ok := b.objects[key]
+	if !ok {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+		if r.Method != http.MethodHead {
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`))
+		}
+		return
+	}
+	if r.Method == http.MethodGet && b.failGet[key] {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>AccessDenied</Code><Message>denied</Message></Error>`))
+		return
+	}
+	w.Header().Set("ETag", fakeETag(data))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Last-Modified", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+func TestObjectTokenStorePollRemote(t *testing.T) {
+	bucket := &fakeBucket{name: "tokens", objects: make(map[string][]byte), failGet: make(map[string]bool)}
+	server := httptest.NewServer(bucket)
+	defer server.Close()
+
+	store, err := NewObjectTokenStore(ObjectStoreConfig{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Bucket:    "tokens",
+		AccessKey: "access",
+		SecretKey: "secret",
+		Region:    "us-east-1",
+		Prefix:    "team",
+		LocalRoot: t.TempDir(),
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("NewObjectTokenStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	bucket.put("team/config/config.yaml", "port: 1\r\n")
+	bucket.put("team/auths/a.json", `{"type":"codex"}`)
+	bucket.put("team/auths/nested/b.json", `{"type":"claude"}`)
+	bucket.put("other/auths/c.json", `{"type":"gemini"}`)
+	if err = store.pollRemote(ctx); err != nil {
+		t.Fatalf("pollRemote() error = %v", err)
+	}
+	if got := readSpool(t, store.configPath); got != "port: 1\n" {
+		t.Fatalf("spool config = %q", got)
+	}
+	if got := readSpool(t, filepath.Join(store.authDir, "nested", "b.json")); got != `{"type":"claude"}` {
+		t.Fatalf("nested auth = %q", got)
+	}
+	if _, errStat := os.Stat(filepath.Join(store.authDir, "c.json")); !os.IsNotExist(errStat) {
+		t.Fatalf("object outside prefix was mirrored: %v", errStat)
+	}
+
+	// A failed download keeps the previous version so the next poll retries it.
+	localOnly := filepath.Join(store.authDir, "local.json")
+	if err = os.WriteFile(localOnly, []byte(`{"type":"qwen"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bucket.put("team/auths/a.json", `{"type":"codex","email":"a@example.com"}`)
+	bucket.remove("team/auths/nested/b.json")
+	bucket.setFailGet("team/auths/a.json", true)
+	if err = store.pollRemote(ctx); err != nil {
+		t.Fatalf("second pollRemote() error = %v", err)
+	}
+	if got := readSpool(t, filepath.Join(store.authDir, "a.json")); got != `{"type":"codex"}` {
+		t.Fatalf("auth after failed download = %q", got)
+	}
+	if _, errStat := os.Stat(filepath.Join(store.authDir, "nested", "b.json")); !os.IsNotExist(errStat) {
+		t.Fatalf("removed auth still present: %v", errStat)
+	}
+	if readSpool(t, localOnly) == "" {
+		t.Fatal("local-only auth was removed")
+	}
+
+	bucket.setFailGet("team/auths/a.json", false)
+	if err = store.pollRemote(ctx); err != nil {
+		t.Fatalf("third pollRemote() error = %v", err)
+	}
+	if got := readSpool(t, filepath.Join(store.authDir, "a.json")); !strings.Contains(got, "a@example.com") {
+		t.Fatalf("auth after retry = %q", got)
+	}
+	if len(store.remoteAuth) != 1 {
+		t.Fatalf("remote versions = %v", store.remoteAuth)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
@@ -23,6 +24,15 @@ const (
 	defaultConfigTable = "config_store"
 	defaultAuthTable   = "auth_store"
 	defaultConfigKey   = "config"
+	// defaultHistoryTable stores config versions recorded by the management API.
+	defaultHistoryTable = "config_history"
+	// defaultSignatureTable shares thinking signatures between replicas.
+	defaultSignatureTable = "signature_cache"
+	// defaultNotifyChannel is the LISTEN/NOTIFY channel used to broadcast table changes.
+	defaultNotifyChannel = "cliproxy_store_changes"
+	// postgresResyncInterval bounds how long a missed notification can go unnoticed.
+	postgresResyncInterval = 5 * time.Minute
+	postgresListenBackoff  = 5 * time.Second
 )
 
 // PostgresStoreConfig captures configuration required to initialize a Postgres-backed store.
@@ -32,6 +42,12 @@ type PostgresStoreConfig struct {
 	ConfigTable string
 	AuthTable   string
 	SpoolDir    string
+	// HistoryTable overrides the table holding config history versions.
+	HistoryTable string
+	// NotifyChannel overrides the LISTEN/NOTIFY channel used for live change propagation.
+	NotifyChannel string
+	// SignatureTable overrides the table holding cached thinking signatures.
+	SignatureTable string
 }
 
 // PostgresStore persists configuration and authentication metadata using PostgreSQL as backend
@@ -43,6 +59,9 @@ type PostgresStore struct {
 	configPath string
 	authDir    string
 	mu         sync.Mutex
+
+	// remoteAuth holds the content hash of every auth record last seen in the database.
+	remoteAuth map[string]string
 }
 
 // NewPostgresStore establishes a connection to PostgreSQL and prepares the local workspace.
@@ -58,6 +77,15 @@ func NewPostgresStore(ctx context.Context, cfg PostgresStoreConfig) (*PostgresSt
 	if cfg.AuthTable == "" {
 		cfg.AuthTable = defaultAuthTable
 	}
+	if cfg.NotifyChannel == "" {
+		cfg.NotifyChannel = defaultNotifyChannel
+	}
+	if cfg.HistoryTable == "" {
+		cfg.HistoryTable = defaultHistoryTable
+	}
+	if cfg.SignatureTable == "" {
+		cfg.SignatureTable = defaultSignatureTable
+	}
 
 	spoolRoot := strings.TrimSpace(cfg.SpoolDir)
 	if spoolRoot == "" {
@@ -140,6 +168,74 @@ func (s *PostgresStore) EnsureSchema(ctx context.Context) error {
 	`, authTable)); err != nil {
 		return fmt.Errorf("postgres store: create auth table: %w", err)
 	}
+	historyTable := s.fullTableName(s.cfg.HistoryTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL,
+			actor TEXT NOT NULL DEFAULT '',
+			reason TEXT NOT NULL DEFAULT '',
+			sha256 TEXT NOT NULL,
+			content TEXT NOT NULL
+		)
+	`, historyTable)); err != nil {
+		return fmt.Errorf("postgres store: create config history table: %w", err)
+	}
+	signatureTable := s.fullTableName(s.cfg.SignatureTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			group_key TEXT NOT NULL,
+			text_hash TEXT NOT NULL,
+			signature TEXT NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (group_key, text_hash)
+		)
+	`, signatureTable)); err != nil {
+		return fmt.Errorf("postgres store: create signature cache table: %w", err)
+	}
+	if err := s.ensureChangeNotifications(ctx); err != nil {
+		// Replicas still converge through the periodic resync when triggers cannot be installed.
+		log.WithError(err).Warn("postgres store: live change notifications unavailable")
+	}
+	return nil
+}
+
+// ensureChangeNotifications installs triggers that NOTIFY on every effective change to the
+// config and auth tables. Updates that leave the content unchanged are not broadcast.
+func (s *PostgresStore) ensureChangeNotifications(ctx context.Context) error {
+	function := s.fullTableName("cliproxy_notify_change")
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE OR REPLACE FUNCTION %s() RETURNS trigger AS $$
+		BEGIN
+			IF TG_OP = 'UPDATE' AND OLD.content IS NOT DISTINCT FROM NEW.content THEN
+				RETURN NULL;
+			END IF;
+			PERFORM pg_notify(TG_ARGV[0], json_build_object(
+				'schema', TG_TABLE_SCHEMA,
+				'table', TG_TABLE_NAME,
+				'op', TG_OP,
+				'id', CASE WHEN TG_OP = 'DELETE' THEN OLD.id ELSE NEW.id END
+			)::text);
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql
+	`, function)); err != nil {
+		return fmt.Errorf("create notify function: %w", err)
+	}
+	for _, table := range []string{s.cfg.ConfigTable, s.cfg.AuthTable} {
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+			DO $$
+			BEGIN
+				CREATE TRIGGER cliproxy_notify_change
+				AFTER INSERT OR UPDATE OR DELETE ON %s
+				FOR EACH ROW EXECUTE FUNCTION %s(%s);
+			EXCEPTION WHEN duplicate_object THEN NULL;
+			END
+			$$
+		`, s.fullTableName(table), function, quoteLiteral(s.cfg.NotifyChannel))); err != nil {
+			return fmt.Errorf("create notify trigger on %s: %w", table, err)
+		}
+	}
 	return nil
 }
 
@@ -223,18 +319,16 @@ func (s *PostgresStore) Save(ctx context.Context, auth *cliproxyauth.Auth) (stri
 			return "", fmt.Errorf("postgres store: marshal metadata: %w", errMarshal)
 		}
 		if existing, errRead := os.ReadFile(path); errRead == nil {
-			if jsonEqual(existing, raw) {
-				return path, nil
+			if authcrypt.IsSealed(existing) == authcrypt.Enabled() {
+				if plain, errOpen := authcrypt.Open(existing); errOpen == nil && jsonEqual(plain, raw) {
+					return path, nil
+				}
 			}
 		} else if errRead != nil && !errors.Is(errRead, fs.ErrNotExist) {
 			return "", fmt.Errorf("postgres store: read existing metadata: %w", errRead)
 		}
-		tmp := path + ".tmp"
-		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
-			return "", fmt.Errorf("postgres store: write temp auth file: %w", errWrite)
-		}
-		if errRename := os.Rename(tmp, path); errRename != nil {
-			return "", fmt.Errorf("postgres store: rename auth file: %w", errRename)
+		if errWrite := authcrypt.WriteFile(path, raw, 0o600); errWrite != nil {
+			return "", fmt.Errorf("postgres store: write auth file: %w", errWrite)
 		}
 	default:
 		return "", fmt.Errorf("postgres store: nothing to persist for %s", auth.ID)
@@ -284,8 +378,13 @@ func (s *PostgresStore) List(ctx context.Context) ([]*cliproxyauth.Auth, error)
 			log.WithError(errPath).Warnf("postgres store: skipping auth %s outside spool", id)
 			continue
 		}
+		plain, errOpen := authcrypt.Open([]byte(payload))
+		if errOpen != nil {
+			log.WithError(errOpen).Warnf("postgres store: skipping auth %s that cannot be decrypted", id)
+			continue
+		}
 		metadata := make(map[string]any)
-		if err = json.Unmarshal([]byte(payload), &metadata); err != nil {
+		if err = json.Unmarshal(plain, &metadata); err != nil {
 			log.WithError(err).Warnf("postgres store: skipping auth %s with invalid json", id)
 			continue
 		}
@@ -449,6 +548,7 @@ func (s *PostgresStore) syncAuthFromDatabase(ctx context.Context) error {
 		return fmt.Errorf("postgres store: recreate auth directory: %w", err)
 	}
 
+	remote := make(map[string]string)
 	for rows.Next() {
 		var (
 			id      string
@@ -468,10 +568,14 @@ func (s *PostgresStore) syncAuthFromDatabase(ctx context.Context) error {
 		if err = os.WriteFile(path, []byte(payload), 0o600); err != nil {
 			return fmt.Errorf("postgres store: write auth file: %w", err)
 		}
+		remote[id] = contentVersion([]byte(payload))
 	}
 	if err = rows.Err(); err != nil {
 		return fmt.Errorf("postgres store: iterate auth rows: %w", err)
 	}
+	s.mu.Lock()
+	s.remoteAuth = remote
+	s.mu.Unlock()
 	return nil
 }
 
@@ -619,6 +723,10 @@ func (s *PostgresStore) fullTableName(name string) string {
 	return quoteIdentifier(s.cfg.Schema) + "." + quoteIdentifier(name)
 }
 
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
 func quoteIdentifier(identifier string) string {
 	replaced := strings.ReplaceAll(identifier, "\"", "\"\"")
 	return "\"" + replaced + "\""
@@ -0,0 +1,227 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// postgresChange is the payload broadcast by the change notification trigger.
+type postgresChange struct {
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	Op     string `json:"op"`
+	ID     string `json:"id"`
+}
+
+// StartSync keeps the local spool directory in sync with PostgreSQL until ctx is cancelled.
+// Changes made by other replicas arrive through LISTEN/NOTIFY and are written into the spool,
+// where the file watcher picks them up. A full resync runs after every (re)connect and
+// periodically so notifications missed while disconnected are not lost.
+func (s *PostgresStore) StartSync(ctx context.Context) {
+	if s == nil || s.db == nil {
+		return
+	}
+	go s.listenLoop(ctx)
+}
+
+func (s *PostgresStore) listenLoop(ctx context.Context) {
+	for {
+		err := s.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.WithError(err).Warn("postgres store: change listener disconnected, reconnecting")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(postgresListenBackoff):
+		}
+	}
+}
+
+func (s *PostgresStore) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, s.cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer func() {
+		_ = conn.Close(context.Background())
+	}()
+	if _, err = conn.Exec(ctx, "LISTEN "+quoteIdentifier(s.cfg.NotifyChannel)); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	log.Debugf("postgres store: listening for changes on channel %s", s.cfg.NotifyChannel)
+	if errResync := s.resync(ctx); errResync != nil {
+		log.WithError(errResync).Warn("postgres store: resync failed")
+	}
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, postgresResyncInterval)
+		notification, errWait := conn.WaitForNotification(waitCtx)
+		cancel()
+		if errWait != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(errWait, context.DeadlineExceeded) && !conn.IsClosed() {
+				if errResync := s.resync(ctx); errResync != nil {
+					log.WithError(errResync).Warn("postgres store: resync failed")
+				}
+				continue
+			}
+			return errWait
+		}
+		if errApply := s.applyChange(ctx, notification.Payload); errApply != nil {
+			log.WithError(errApply).Warn("postgres store: apply change notification failed")
+		}
+	}
+}
+
+// applyChange pulls the record named in a change notification into the spool directory.
+func (s *PostgresStore) applyChange(ctx context.Context, payload string) error {
+	var change postgresChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		return fmt.Errorf("decode notification: %w", err)
+	}
+	if s.cfg.Schema != "" && change.Schema != s.cfg.Schema {
+		return nil
+	}
+	switch change.Table {
+	case s.cfg.ConfigTable:
+		if change.ID != defaultConfigKey {
+			return nil
+		}
+		return s.pullConfig(ctx)
+	case s.cfg.AuthTable:
+		return s.pullAuthRecord(ctx, change.ID)
+	}
+	return nil
+}
+
+// pullConfig mirrors the database config into the spool. A missing row leaves the local file as is.
+func (s *PostgresStore) pullConfig(ctx context.Context) error {
+	query := fmt.Sprintf("SELECT content FROM %s WHERE id = $1", s.fullTableName(s.cfg.ConfigTable))
+	var content string
+	err := s.db.QueryRowContext(ctx, query, defaultConfigKey).Scan(&content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	written, err := writeSpoolConfig(s.configPath, []byte(normalizeLineEndings(content)))
+	if err != nil {
+		return fmt.Errorf("write config to spool: %w", err)
+	}
+	if written {
+		log.Info("postgres store: pulled remote config change")
+	}
+	return nil
+}
+
+// pullAuthRecord mirrors one auth record into the spool, removing the local file when the
+// record was deleted.
+func (s *PostgresStore) pullAuthRecord(ctx context.Context, id string) error {
+	path, err := s.absoluteAuthPath(id)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("SELECT content FROM %s WHERE id = $1", s.fullTableName(s.cfg.AuthTable))
+	var payload string
+	err = s.db.QueryRowContext(ctx, query, id).Scan(&payload)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("load auth %s: %w", id, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remoteAuth == nil {
+		s.remoteAuth = make(map[string]string)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		delete(s.remoteAuth, id)
+		removed, errRemove := removeSpoolFile(path)
+		if errRemove != nil {
+			return fmt.Errorf("remove auth %s: %w", id, errRemove)
+		}
+		if removed {
+			log.Infof("postgres store: removed auth %s deleted remotely", id)
+		}
+		return nil
+	}
+	s.remoteAuth[id] = contentVersion([]byte(payload))
+	written, errWrite := writeSpoolFile(path, []byte(payload))
+	if errWrite != nil {
+		return fmt.Errorf("write auth %s: %w", id, errWrite)
+	}
+	if written {
+		log.Infof("postgres store: pulled remote change for auth %s", id)
+	}
+	return nil
+}
+
+// resync compares every database record with the last known state and mirrors the differences.
+func (s *PostgresStore) resync(ctx context.Context) error {
+	if err := s.pullConfig(ctx); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("SELECT id, content FROM %s", s.fullTableName(s.cfg.AuthTable))
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("load auth: %w", err)
+	}
+	defer rows.Close()
+	current := make(map[string]string)
+	payloads := make(map[string][]byte)
+	for rows.Next() {
+		var (
+			id      string
+			payload string
+		)
+		if err = rows.Scan(&id, &payload); err != nil {
+			return fmt.Errorf("scan auth row: %w", err)
+		}
+		current[id] = contentVersion([]byte(payload))
+		payloads[id] = []byte(payload)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("iterate auth rows: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed, removed := diffRemoteVersions(s.remoteAuth, current)
+	for _, id := range changed {
+		path, errPath := s.absoluteAuthPath(id)
+		if errPath != nil {
+			log.WithError(errPath).Warnf("postgres store: skipping auth %s outside spool", id)
+			continue
+		}
+		if written, errWrite := writeSpoolFile(path, payloads[id]); errWrite != nil {
+			log.WithError(errWrite).Warnf("postgres store: write auth %s", id)
+		} else if written {
+			log.Infof("postgres store: pulled remote change for auth %s", id)
+		}
+	}
+	for _, id := range removed {
+		path, errPath := s.absoluteAuthPath(id)
+		if errPath != nil {
+			continue
+		}
+		if ok, errRemove := removeSpoolFile(path); errRemove != nil {
+			log.WithError(errRemove).Warnf("postgres store: remove auth %s", id)
+		} else if ok {
+			log.Infof("postgres store: removed auth %s deleted remotely", id)
+		}
+	}
+	s.remoteAuth = current
+	return nil
+}
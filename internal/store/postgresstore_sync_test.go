@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePostgres answers the SELECT statements issued by the sync code from in-memory tables.
+type fakePostgres struct {
+	mu     sync.Mutex
+	tables map[string]map[string]string
+}
+
+func (f *fakePostgres) set(table, id, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tables[table] == nil {
+		f.tables[table] = make(map[string]string)
+	}
+	f.tables[table][id] = content
+}
+
+func (f *fakePostgres) remove(table, id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.tables[table], id)
+}
+
+func (f *fakePostgres) Connect(context.Context) (driver.Conn, error) { return fakePostgresConn{f}, nil }
+func (f *fakePostgres) Driver() driver.Driver                        { return nil }
+
+type fakePostgresConn struct{ db *fakePostgres }
+
+func (c fakePostgresConn) Prepare(query string) (driver.Stmt, error) {
+	return fakePostgresStmt{db: c.db, query: query}, nil
+}
+func (c fakePostgresConn) Close() error { return nil }
+func (c fakePostgresConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+type fakePostgresStmt struct {
+	db    *fakePostgres
+	query string
+}
+
+func (s fakePostgresStmt) Close() error  { return nil }
+func (s fakePostgresStmt) NumInput() int { return -1 }
+func (s fakePostgresStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec not supported")
+}
+
+func (s fakePostgresStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	var table map[string]string
+	for name, rows := range s.db.tables {
+		if strings.Contains(s.query, quoteIdentifier(name)) {
+			table = rows
+		}
+	}
+	if strings.HasPrefix(s.query, "SELECT content ") {
+		out := &fakePostgresRows{columns: []string{"content"}}
+		if content, ok := table[args[0].(string)]; ok {
+			out.values = append(out.values, []driver.Value{content})
+		}
+		return out, nil
+	}
+	out := &fakePostgresRows{columns: []string{"id", "content"}}
+	ids := make([]string, 0, len(table))
+	for id := range table {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		out.values = append(out.values, []driver.Value{id, table[id]})
+	}
+	return out, nil
+}
+
+type fakePostgresRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakePostgresRows) Columns() []string { return r.columns }
+func (r *fakePostgresRows) Close() error      { return nil }
+func (r *fakePostgresRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newFakePostgresStore(t *testing.T) (*PostgresStore, *fakePostgres) {
+	t.Helper()
+	fake := &fakePostgres{tables: make(map[string]map[string]string)}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { _ = db.Close() })
+	root := t.TempDir()
+	store := &PostgresStore{
+		db:         db,
+		cfg:        PostgresStoreConfig{ConfigTable: defaultConfigTable, AuthTable: defaultAuthTable},
+		spoolRoot:  root,
+		configPath: filepath.Join(root, "config", "config.yaml"),
+		authDir:    filepath.Join(root, "auths"),
+	}
+	return store, fake
+}
+
+func readSpool(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func TestPostgresStoreApplyChange(t *testing.T) {
+	store, fake := newFakePostgresStore(t)
+	ctx := context.Background()
+	authPath := filepath.Join(store.authDir, "a.json")
+
+	fake.set(defaultAuthTable, "a.json", `{"type":"codex","email":"a@example.com"}`)
+	if err := store.applyChange(ctx, `{"table":"auth_store","op":"INSERT","id":"a.json"}`); err != nil {
+		t.Fatalf("applyChange(insert) error = %v", err)
+	}
+	if got := readSpool(t, authPath); got != `{"type":"codex","email":"a@example.com"}` {
+		t.Fatalf("spool after insert = %q", got)
+	}
+
+	fake.set(defaultConfigTable, defaultConfigKey, "port: 1\r\n")
+	if err := store.applyChange(ctx, `{"table":"config_store","op":"UPDATE","id":"config"}`); err != nil {
+		t.Fatalf("applyChange(config) error = %v", err)
+	}
+	if got := readSpool(t, store.configPath); got != "port: 1\n" {
+		t.Fatalf("spool config = %q", got)
+	}
+
+	// Changes on other schemas or tables are ignored.
+	fake.set(defaultAuthTable, "a.json", `{"type":"codex","email":"b@example.com"}`)
+	store.cfg.Schema = "tenant"
+	if err := store.applyChange(ctx, `{"schema":"other","table":"auth_store","op":"UPDATE","id":"a.json"}`); err != nil {
+		t.Fatalf("applyChange(other schema) error = %v", err)
+	}
+	store.cfg.Schema = ""
+	if err := store.applyChange(ctx, `{"table":"unrelated","op":"UPDATE","id":"a.json"}`); err != nil {
+		t.Fatalf("applyChange(other table) error = %v", err)
+	}
+	if got := readSpool(t, authPath); !strings.Contains(got, "a@example.com") {
+		t.Fatalf("spool changed by ignored notification: %q", got)
+	}
+
+	fake.remove(defaultAuthTable, "a.json")
+	if err := store.applyChange(ctx, `{"table":"auth_store","op":"DELETE","id":"a.json"}`); err != nil {
+		t.Fatalf("applyChange(delete) error = %v", err)
+	}
+	if _, err := os.Stat(authPath); !os.IsNotExist(err) {
+		t.Fatalf("auth file after delete: %v", err)
+	}
+	if _, ok := store.remoteAuth["a.json"]; ok {
+		t.Fatal("deleted record still tracked as remote")
+	}
+
+	if err := store.applyChange(ctx, `{"table":"auth_store","id":"../escape.json"}`); err == nil {
+		t.Fatal("expected identifier escaping the spool to be rejected")
+	}
+	if err := store.applyChange(ctx, `not json`); err == nil {
+		t.Fatal("expected invalid payload to be rejected")
+	}
+}
+
+func TestPostgresStoreResync(t *testing.T) {
+	store, fake := newFakePostgresStore(t)
+	ctx := context.Background()
+
+	fake.set(defaultConfigTable, defaultConfigKey, "port: 1\n")
+	fake.set(defaultAuthTable, "a.json", `{"type":"codex"}`)
+	fake.set(defaultAuthTable, "team/b.json", `{"type":"claude"}`)
+	if err := store.resync(ctx); err != nil {
+		t.Fatalf("resync() error = %v", err)
+	}
+	if got := readSpool(t, store.configPath); got != "port: 1\n" {
+		t.Fatalf("spool config = %q", got)
+	}
+	if got := readSpool(t, filepath.Join(store.authDir, "team", "b.json")); got != `{"type":"claude"}` {
+		t.Fatalf("nested auth = %q", got)
+	}
+
+	// A local file never seen remotely survives; remote updates and deletions are mirrored.
+	localOnly := filepath.Join(store.authDir, "local.json")
+	if err := os.WriteFile(localOnly, []byte(`{"type":"gemini"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fake.set(defaultAuthTable, "a.json", `{"type":"codex","email":"a@example.com"}`)
+	fake.remove(defaultAuthTable, "team/b.json")
+	if err := store.resync(ctx); err != nil {
+		t.Fatalf("second resync() error = %v", err)
+	}
+	if got := readSpool(t, filepath.Join(store.authDir, "a.json")); !strings.Contains(got, "a@example.com") {
+		t.Fatalf("updated auth = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(store.authDir, "team", "b.json")); !os.IsNotExist(err) {
+		t.Fatalf("removed auth still present: %v", err)
+	}
+	if got := readSpool(t, localOnly); got == "" {
+		t.Fatal("local-only auth was removed")
+	}
+	if len(store.remoteAuth) != 1 || store.remoteAuth["a.json"] != contentVersion([]byte(`{"type":"codex","email":"a@example.com"}`)) {
+		t.Fatalf("remote versions = %v", store.remoteAuth)
+	}
+}
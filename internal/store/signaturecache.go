@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+)
+
+// LoadSignature implements cache.SignatureBackend so replicas share thinking signatures.
+func (s *PostgresStore) LoadSignature(ctx context.Context, groupKey, textHash string) (cache.SignatureEntry, bool, error) {
+	if s == nil || s.db == nil {
+		return cache.SignatureEntry{}, false, fmt.Errorf("postgres store: not initialized")
+	}
+	query := fmt.Sprintf("SELECT signature, updated_at FROM %s WHERE group_key = $1 AND text_hash = $2", s.fullTableName(s.cfg.SignatureTable))
+	var entry cache.SignatureEntry
+	err := s.db.QueryRowContext(ctx, query, groupKey, textHash).Scan(&entry.Signature, &entry.Timestamp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return cache.SignatureEntry{}, false, nil
+	}
+	if err != nil {
+		return cache.SignatureEntry{}, false, fmt.Errorf("postgres store: load signature: %w", err)
+	}
+	return entry, true, nil
+}
+
+// StoreSignature implements cache.SignatureBackend.
+func (s *PostgresStore) StoreSignature(ctx context.Context, groupKey, textHash string, entry cache.SignatureEntry) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("postgres store: not initialized")
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (group_key, text_hash, signature, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (group_key, text_hash)
+		DO UPDATE SET signature = EXCLUDED.signature, updated_at = GREATEST(%[1]s.updated_at, EXCLUDED.updated_at)
+	`, s.fullTableName(s.cfg.SignatureTable))
+	if _, err := s.db.ExecContext(ctx, query, groupKey, textHash, entry.Signature, entry.Timestamp.UTC()); err != nil {
+		return fmt.Errorf("postgres store: store signature: %w", err)
+	}
+	return nil
+}
+
+// ClearSignatures implements cache.SignatureBackend.
+func (s *PostgresStore) ClearSignatures(ctx context.Context, groupKey string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("postgres store: not initialized")
+	}
+	table := s.fullTableName(s.cfg.SignatureTable)
+	var err error
+	if groupKey == "" {
+		_, err = s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", table))
+	} else {
+		_, err = s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE group_key = $1", table), groupKey)
+	}
+	if err != nil {
+		return fmt.Errorf("postgres store: clear signatures: %w", err)
+	}
+	return nil
+}
+
+// MaintainSignatures implements cache.SignatureMaintainer: it deletes expired rows and keeps
+// the maxEntries most recently used ones.
+func (s *PostgresStore) MaintainSignatures(ctx context.Context, ttl time.Duration, maxEntries int) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("postgres store: not initialized")
+	}
+	table := s.fullTableName(s.cfg.SignatureTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE updated_at < $1", table), time.Now().Add(-ttl).UTC()); err != nil {
+		return fmt.Errorf("postgres store: prune expired signatures: %w", err)
+	}
+	if maxEntries <= 0 {
+		return nil
+	}
+	query := fmt.Sprintf(`
+		DELETE FROM %[1]s WHERE (group_key, text_hash) IN (
+			SELECT group_key, text_hash FROM %[1]s ORDER BY updated_at DESC OFFSET $1
+		)
+	`, table)
+	if _, err := s.db.ExecContext(ctx, query, maxEntries); err != nil {
+		return fmt.Errorf("postgres store: prune signatures: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// writeSpoolFile mirrors a remote payload into the local spool directory. The file is left
+// untouched when the local copy already matches, so the watcher only sees real changes and
+// does not echo them back to the remote. It reports whether the file was written.
+func writeSpoolFile(path string, data []byte) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil {
+		if bytes.Equal(existing, data) {
+			return false, nil
+		}
+		if strings.HasSuffix(strings.ToLower(path), ".json") && jsonEqual(existing, data) {
+			return false, nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return false, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return false, err
+	}
+	tmpName := tmp.Name()
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Chmod(0o600)
+	}
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(tmpName, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return false, err
+	}
+	return true, nil
+}
+
+// writeSpoolConfig mirrors a remote config into the spool. Unlike auth files the config is
+// rewritten in place: the watcher watches the file itself, and replacing it by rename would
+// drop that watch. It reports whether the file was written.
+func writeSpoolConfig(path string, data []byte) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// removeSpoolFile deletes a mirrored file whose remote record disappeared. It reports
+// whether a file was removed.
+func removeSpoolFile(path string) (bool, error) {
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// diffRemoteVersions compares two snapshots of remote record versions (ETag or content hash
+// keyed by record ID). It returns the IDs that are new or changed in current and the IDs that
+// were known before but no longer exist. Records never seen remotely are not reported as
+// removed, so local files that have not been uploaded yet are left alone.
+func diffRemoteVersions(known, current map[string]string) (changed, removed []string) {
+	for id, version := range current {
+		if prev, ok := known[id]; !ok || prev != version {
+			changed = append(changed, id)
+		}
+	}
+	for id := range known {
+		if _, ok := current[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// contentVersion identifies a remote payload for stores that do not expose their own versions.
+func contentVersion(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteSpoolFileSkipsEquivalentContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "auth.json")
+
+	written, err := writeSpoolFile(path, []byte(`{"type":"claude","email":"a@example.com"}`))
+	if err != nil || !written {
+		t.Fatalf("expected initial write, got written=%v err=%v", written, err)
+	}
+	// JSONB round trips reorder keys and whitespace; that must not count as a change.
+	written, err = writeSpoolFile(path, []byte(`{"email": "a@example.com", "type": "claude"}`))
+	if err != nil || written {
+		t.Fatalf("expected equivalent json to be skipped, got written=%v err=%v", written, err)
+	}
+	written, err = writeSpoolFile(path, []byte(`{"type":"claude","email":"b@example.com"}`))
+	if err != nil || !written {
+		t.Fatalf("expected changed json to be written, got written=%v err=%v", written, err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != `{"type":"claude","email":"b@example.com"}` {
+		t.Fatalf("unexpected spool content: %s", data)
+	}
+
+	removed, err := removeSpoolFile(path)
+	if err != nil || !removed {
+		t.Fatalf("expected file removal, got removed=%v err=%v", removed, err)
+	}
+	removed, err = removeSpoolFile(path)
+	if err != nil || removed {
+		t.Fatalf("expected missing file to be ignored, got removed=%v err=%v", removed, err)
+	}
+}
+
+func TestDiffRemoteVersions(t *testing.T) {
+	known := map[string]string{"a.json": "1", "b.json": "1", "c.json": "1"}
+	current := map[string]string{"a.json": "1", "b.json": "2", "d.json": "1"}
+
+	changed, removed := diffRemoteVersions(known, current)
+	if !reflect.DeepEqual(changed, []string{"b.json", "d.json"}) {
+		t.Fatalf("changed = %v", changed)
+	}
+	if !reflect.DeepEqual(removed, []string{"c.json"}) {
+		t.Fatalf("removed = %v", removed)
+	}
+
+	changed, removed = diffRemoteVersions(nil, map[string]string{"a.json": "1"})
+	if !reflect.DeepEqual(changed, []string{"a.json"}) || len(removed) != 0 {
+		t.Fatalf("unexpected diff from empty snapshot: changed=%v removed=%v", changed, removed)
+	}
+}
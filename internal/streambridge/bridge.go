@@ -0,0 +1,123 @@
+// Package streambridge converts responses between streaming and non-streaming shapes for
+// each client-facing format. Aggregate folds the chunks of a streamed response into the body
+// a non-streaming client expects, including text, reasoning, tool calls and usage; Split
+// replays a complete response as the chunks a streaming client expects.
+//
+// Chunks use the same framing the API handlers write: bare JSON objects for OpenAI chat
+// completions and Gemini, and SSE "event:"/"data:" lines for Claude and the Responses API.
+package streambridge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Client-facing formats handled by the bridge. They match the translator format names.
+const (
+	FormatOpenAI         = "openai"
+	FormatOpenAIResponse = "openai-response"
+	FormatClaude         = "claude"
+	FormatGemini         = "gemini"
+	FormatGeminiCLI      = "gemini-cli"
+)
+
+// ErrUnsupportedFormat is returned for formats the bridge cannot convert.
+var ErrUnsupportedFormat = errors.New("streambridge: unsupported format")
+
+// Supports reports whether format can be bridged in both directions.
+func Supports(format string) bool {
+	switch format {
+	case FormatOpenAI, FormatOpenAIResponse, FormatClaude, FormatGemini, FormatGeminiCLI:
+		return true
+	default:
+		return false
+	}
+}
+
+// Aggregate builds the non-streaming response body for format from streamed chunks.
+func Aggregate(format string, chunks [][]byte) ([]byte, error) {
+	switch format {
+	case FormatOpenAI:
+		return aggregateOpenAI(chunks)
+	case FormatOpenAIResponse:
+		return aggregateResponses(chunks)
+	case FormatClaude:
+		return aggregateClaude(chunks)
+	case FormatGemini:
+		return AggregateGemini(chunks, false), nil
+	case FormatGeminiCLI:
+		return AggregateGemini(chunks, true), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// Split replays a non-streaming response body for format as streamed chunks.
+func Split(format string, payload []byte) ([][]byte, error) {
+	switch format {
+	case FormatOpenAI:
+		return splitOpenAI(payload)
+	case FormatOpenAIResponse:
+		return splitResponses(payload)
+	case FormatClaude:
+		return splitClaude(payload)
+	case FormatGemini, FormatGeminiCLI:
+		// A Gemini stream chunk has the same shape as the complete response.
+		payload = bytes.TrimSpace(payload)
+		if len(payload) == 0 {
+			return nil, errors.New("streambridge: empty gemini response")
+		}
+		return [][]byte{bytes.Clone(payload)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// KeepAlive returns a chunk for format that keeps a stream open without carrying output: a
+// ping event for Claude and an SSE comment otherwise. Formats framed as bare JSON objects
+// have no such chunk and get nil.
+func KeepAlive(format string) []byte {
+	switch format {
+	case FormatClaude:
+		return sseEvent("ping", []byte(`{"type":"ping"}`))
+	case FormatOpenAIResponse:
+		// The Responses handlers add the terminating newline.
+		return []byte(": keep-alive\n")
+	default:
+		return nil
+	}
+}
+
+// dataPayloads extracts JSON payloads from chunks that are either bare JSON objects or SSE
+// text with "data:" lines. "[DONE]" markers and comments are skipped.
+func dataPayloads(chunks [][]byte) [][]byte {
+	var out [][]byte
+	for _, chunk := range chunks {
+		for _, line := range bytes.Split(chunk, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 || line[0] == ':' || bytes.HasPrefix(line, []byte("event:")) {
+				continue
+			}
+			if bytes.HasPrefix(line, []byte("data:")) {
+				line = bytes.TrimSpace(line[len("data:"):])
+			}
+			if len(line) == 0 || bytes.Equal(line, []byte("[DONE]")) {
+				continue
+			}
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// sseEvent frames one SSE event the way the Claude handlers expect it.
+func sseEvent(event string, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("event: ")
+	buf.WriteString(event)
+	buf.WriteString("\ndata: ")
+	buf.Write(data)
+	buf.WriteString("\n\n")
+	return buf.Bytes()
+}
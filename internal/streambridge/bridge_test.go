@@ -0,0 +1,123 @@
+package streambridge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestAggregateOpenAIAccumulatesToolCallsAndUsage(t *testing.T) {
+	chunks := [][]byte{
+		[]byte(`{"id":"c1","model":"m","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"think"}}]}`),
+		[]byte(`{"id":"c1","model":"m","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":"}}]}}]}`),
+		[]byte(`{"id":"c1","model":"m","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}`),
+		[]byte(`data: {"id":"c1","model":"m","choices":[],"usage":{"total_tokens":9}}`),
+		[]byte(`data: [DONE]`),
+	}
+	out, err := Aggregate(FormatOpenAI, chunks)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	message := gjson.GetBytes(out, "choices.0.message")
+	if got := message.Get("reasoning_content").String(); got != "think" {
+		t.Fatalf("reasoning_content = %q, want think", got)
+	}
+	if got := message.Get("tool_calls.0.function.arguments").String(); got != `{"city":"Paris"}` {
+		t.Fatalf("arguments = %q", got)
+	}
+	if got := message.Get("tool_calls.0.id").String(); got != "call_1" {
+		t.Fatalf("tool call id = %q, want call_1", got)
+	}
+	if got := gjson.GetBytes(out, "choices.0.finish_reason").String(); got != "tool_calls" {
+		t.Fatalf("finish_reason = %q, want tool_calls", got)
+	}
+	if got := gjson.GetBytes(out, "usage.total_tokens").Int(); got != 9 {
+		t.Fatalf("usage.total_tokens = %d, want 9", got)
+	}
+}
+
+func TestClaudeRoundTrip(t *testing.T) {
+	message := []byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[{"type":"thinking","thinking":"hmm","signature":"sig"},{"type":"text","text":"hi"},{"type":"tool_use","id":"tu_1","name":"lookup","input":{"q":"x"}}],"stop_reason":"tool_use","stop_sequence":null,"usage":{"input_tokens":5,"output_tokens":7}}`)
+	chunks, err := Split(FormatClaude, message)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	out, err := Aggregate(FormatClaude, chunks)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	for path, want := range map[string]string{
+		"content.0.thinking":  "hmm",
+		"content.0.signature": "sig",
+		"content.1.text":      "hi",
+		"content.2.input.q":   "x",
+		"stop_reason":         "tool_use",
+	} {
+		if got := gjson.GetBytes(out, path).String(); got != want {
+			t.Fatalf("%s = %q, want %q", path, got, want)
+		}
+	}
+	if got := gjson.GetBytes(out, "usage.output_tokens").Int(); got != 7 {
+		t.Fatalf("usage.output_tokens = %d, want 7", got)
+	}
+}
+
+func TestResponsesRoundTrip(t *testing.T) {
+	response := []byte(`{"id":"resp_1","object":"response","status":"completed","output":[{"id":"msg_1","type":"message","role":"assistant","status":"completed","content":[{"type":"output_text","text":"hi","annotations":[]}]},{"id":"fc_1","type":"function_call","call_id":"call_1","name":"lookup","arguments":"{}","status":"completed"}],"usage":{"total_tokens":3}}`)
+	chunks, err := Split(FormatOpenAIResponse, response)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	first := gjson.ParseBytes(dataPayloads(chunks[:1])[0])
+	if first.Get("type").String() != "response.created" || first.Get("response.status").String() != "in_progress" {
+		t.Fatalf("first event = %s", first.Raw)
+	}
+	out, err := Aggregate(FormatOpenAIResponse, chunks)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	if got := gjson.GetBytes(out, "output.0.content.0.text").String(); got != "hi" {
+		t.Fatalf("output text = %q, want hi", got)
+	}
+	if got := gjson.GetBytes(out, "output.1.call_id").String(); got != "call_1" {
+		t.Fatalf("call_id = %q, want call_1", got)
+	}
+}
+
+func TestAggregateResponsesFailed(t *testing.T) {
+	chunks := [][]byte{[]byte("event: response.failed\ndata: {\"type\":\"response.failed\",\"response\":{\"error\":{\"message\":\"boom\"}}}")}
+	if _, err := Aggregate(FormatOpenAIResponse, chunks); err == nil {
+		t.Fatal("Aggregate() error = nil, want upstream error")
+	}
+}
+
+func TestAggregateGeminiMergesParts(t *testing.T) {
+	chunks := [][]byte{
+		[]byte(`{"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"a","thought":true,"thought_signature":"s1"}]}}]},"traceId":"t1"}`),
+		[]byte(`{"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"b"},{"text":"c"}]}}]}}`),
+		[]byte(`{"response":{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"f","args":{}}}]},"finishReason":"STOP"}],"usageMetadata":{"totalTokenCount":5}}}`),
+	}
+	out := AggregateGemini(chunks, true)
+	parts := gjson.GetBytes(out, "response.candidates.0.content.parts").Array()
+	if len(parts) != 3 {
+		t.Fatalf("parts = %s, want 3 parts", gjson.GetBytes(out, "response.candidates.0.content.parts").Raw)
+	}
+	if parts[0].Get("thoughtSignature").String() != "s1" || parts[1].Get("text").String() != "bc" || !parts[2].Get("functionCall").Exists() {
+		t.Fatalf("parts = %s", gjson.GetBytes(out, "response.candidates.0.content.parts").Raw)
+	}
+	if gjson.GetBytes(out, "traceId").String() != "t1" || gjson.GetBytes(out, "response.usageMetadata.totalTokenCount").Int() != 5 {
+		t.Fatalf("aggregate = %s", out)
+	}
+
+	plain := AggregateGemini([][]byte{[]byte(`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"x"}]}}]}`)}, false)
+	if got := gjson.GetBytes(plain, "candidates.0.content.parts.0.text").String(); got != "x" {
+		t.Fatalf("plain text = %q, want x", got)
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := Aggregate("codex", nil); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Aggregate() error = %v, want ErrUnsupportedFormat", err)
+	}
+}
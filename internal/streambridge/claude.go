@@ -0,0 +1,217 @@
+package streambridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+type claudeBlock struct {
+	raw       []byte
+	text      strings.Builder
+	thinking  strings.Builder
+	signature string
+	inputJSON strings.Builder
+	citations []string
+}
+
+// aggregateClaude folds Claude Messages SSE events into a message object.
+func aggregateClaude(chunks [][]byte) ([]byte, error) {
+	payloads := dataPayloads(chunks)
+	var message []byte
+	blocks := make(map[int64]*claudeBlock)
+	for _, payload := range payloads {
+		root := gjson.ParseBytes(payload)
+		switch root.Get("type").String() {
+		case "message_start":
+			message = []byte(root.Get("message").Raw)
+		case "content_block_start":
+			blocks[root.Get("index").Int()] = &claudeBlock{raw: []byte(root.Get("content_block").Raw)}
+		case "content_block_delta":
+			block := blocks[root.Get("index").Int()]
+			if block == nil {
+				block = &claudeBlock{raw: []byte(`{"type":"text","text":""}`)}
+				blocks[root.Get("index").Int()] = block
+			}
+			delta := root.Get("delta")
+			switch delta.Get("type").String() {
+			case "text_delta":
+				block.text.WriteString(delta.Get("text").String())
+			case "thinking_delta":
+				block.thinking.WriteString(delta.Get("thinking").String())
+			case "signature_delta":
+				block.signature = delta.Get("signature").String()
+			case "input_json_delta":
+				block.inputJSON.WriteString(delta.Get("partial_json").String())
+			case "citations_delta":
+				block.citations = append(block.citations, delta.Get("citation").Raw)
+			}
+		case "message_delta":
+			if message == nil {
+				message = []byte(`{"type":"message","role":"assistant","content":[]}`)
+			}
+			for _, key := range []string{"stop_reason", "stop_sequence"} {
+				if value := root.Get("delta." + key); value.Exists() {
+					message, _ = sjson.SetRawBytes(message, key, []byte(value.Raw))
+				}
+			}
+			root.Get("usage").ForEach(func(key, value gjson.Result) bool {
+				message, _ = sjson.SetRawBytes(message, "usage."+key.String(), []byte(value.Raw))
+				return true
+			})
+		case "error":
+			return nil, errors.New("streambridge: upstream error: " + root.Get("error").Raw)
+		}
+	}
+	if message == nil {
+		return nil, errors.New("streambridge: claude stream has no message_start")
+	}
+
+	indices := make([]int64, 0, len(blocks))
+	for index := range blocks {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	message, _ = sjson.SetRawBytes(message, "content", []byte(`[]`))
+	for _, index := range indices {
+		message, _ = sjson.SetRawBytes(message, "content.-1", blocks[index].finish())
+	}
+	return message, nil
+}
+
+func (b *claudeBlock) finish() []byte {
+	out := b.raw
+	switch gjson.GetBytes(out, "type").String() {
+	case "text":
+		out, _ = sjson.SetBytes(out, "text", gjson.GetBytes(out, "text").String()+b.text.String())
+		for _, citation := range b.citations {
+			out, _ = sjson.SetRawBytes(out, "citations.-1", []byte(citation))
+		}
+	case "thinking":
+		out, _ = sjson.SetBytes(out, "thinking", gjson.GetBytes(out, "thinking").String()+b.thinking.String())
+		if b.signature != "" {
+			out, _ = sjson.SetBytes(out, "signature", b.signature)
+		}
+	case "tool_use", "server_tool_use", "mcp_tool_use":
+		if b.inputJSON.Len() > 0 {
+			input := strings.TrimSpace(b.inputJSON.String())
+			if gjson.Valid(input) {
+				out, _ = sjson.SetRawBytes(out, "input", []byte(input))
+			}
+		}
+		if !gjson.GetBytes(out, "input").Exists() {
+			out, _ = sjson.SetRawBytes(out, "input", []byte(`{}`))
+		}
+	}
+	return out
+}
+
+// splitClaude replays a message object as Claude Messages SSE events.
+func splitClaude(payload []byte) ([][]byte, error) {
+	root := gjson.ParseBytes(payload)
+	if root.Get("type").String() != "message" {
+		return nil, errors.New("streambridge: claude response is not a message")
+	}
+	start, _ := sjson.SetRawBytes(payload, "content", []byte(`[]`))
+	start, _ = sjson.SetRawBytes(start, "stop_reason", []byte(`null`))
+	start, _ = sjson.SetRawBytes(start, "stop_sequence", []byte(`null`))
+	start, _ = sjson.SetBytes(start, "usage.output_tokens", 0)
+	messageStart, _ := sjson.SetRawBytes([]byte(`{"type":"message_start"}`), "message", start)
+	chunks := [][]byte{sseEvent("message_start", messageStart)}
+
+	for i, block := range root.Get("content").Array() {
+		chunks = append(chunks, claudeBlockEvents(i, block)...)
+	}
+
+	messageDelta := []byte(`{"type":"message_delta","delta":{}}`)
+	messageDelta, _ = sjson.SetRawBytes(messageDelta, "delta.stop_reason", []byte(rawOrNull(root.Get("stop_reason"))))
+	messageDelta, _ = sjson.SetRawBytes(messageDelta, "delta.stop_sequence", []byte(rawOrNull(root.Get("stop_sequence"))))
+	if usage := root.Get("usage"); usage.Exists() {
+		messageDelta, _ = sjson.SetRawBytes(messageDelta, "usage", []byte(usage.Raw))
+	}
+	chunks = append(chunks,
+		sseEvent("message_delta", messageDelta),
+		sseEvent("message_stop", []byte(`{"type":"message_stop"}`)),
+	)
+	return chunks, nil
+}
+
+func claudeBlockEvents(index int, block gjson.Result) [][]byte {
+	blockStart := func(contentBlock []byte) []byte {
+		event, _ := sjson.SetBytes([]byte(`{"type":"content_block_start"}`), "index", index)
+		event, _ = sjson.SetRawBytes(event, "content_block", contentBlock)
+		return sseEvent("content_block_start", event)
+	}
+	blockDelta := func(delta []byte) []byte {
+		event, _ := sjson.SetBytes([]byte(`{"type":"content_block_delta"}`), "index", index)
+		event, _ = sjson.SetRawBytes(event, "delta", delta)
+		return sseEvent("content_block_delta", event)
+	}
+	stopEvent, _ := sjson.SetBytes([]byte(`{"type":"content_block_stop"}`), "index", index)
+	blockStop := sseEvent("content_block_stop", stopEvent)
+
+	raw := []byte(block.Raw)
+	var events [][]byte
+	switch block.Get("type").String() {
+	case "text":
+		empty, _ := sjson.SetBytes(raw, "text", "")
+		empty, _ = sjson.DeleteBytes(empty, "citations")
+		events = append(events, blockStart(empty))
+		if text := block.Get("text").String(); text != "" {
+			delta, _ := sjson.SetBytes([]byte(`{"type":"text_delta"}`), "text", text)
+			events = append(events, blockDelta(delta))
+		}
+		for _, citation := range block.Get("citations").Array() {
+			delta, _ := sjson.SetRawBytes([]byte(`{"type":"citations_delta"}`), "citation", []byte(citation.Raw))
+			events = append(events, blockDelta(delta))
+		}
+	case "thinking":
+		empty, _ := sjson.SetBytes(raw, "thinking", "")
+		empty, _ = sjson.SetBytes(empty, "signature", "")
+		events = append(events, blockStart(empty))
+		if thinking := block.Get("thinking").String(); thinking != "" {
+			delta, _ := sjson.SetBytes([]byte(`{"type":"thinking_delta"}`), "thinking", thinking)
+			events = append(events, blockDelta(delta))
+		}
+		if signature := block.Get("signature").String(); signature != "" {
+			delta, _ := sjson.SetBytes([]byte(`{"type":"signature_delta"}`), "signature", signature)
+			events = append(events, blockDelta(delta))
+		}
+	case "tool_use", "server_tool_use", "mcp_tool_use":
+		empty, _ := sjson.SetRawBytes(raw, "input", []byte(`{}`))
+		events = append(events, blockStart(empty))
+		input := block.Get("input").Raw
+		if input == "" {
+			input = "{}"
+		}
+		compact, err := compactJSON(input)
+		if err == nil {
+			input = compact
+		}
+		delta, _ := sjson.SetBytes([]byte(`{"type":"input_json_delta"}`), "partial_json", input)
+		events = append(events, blockDelta(delta))
+	default:
+		events = append(events, blockStart(raw))
+	}
+	return append(events, blockStop)
+}
+
+func rawOrNull(value gjson.Result) string {
+	if !value.Exists() || value.Raw == "" {
+		return "null"
+	}
+	return value.Raw
+}
+
+func compactJSON(raw string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(raw)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,186 @@
+package streambridge
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+type openAIToolCall struct {
+	id        string
+	kind      string
+	name      string
+	arguments strings.Builder
+}
+
+type openAIChoice struct {
+	role         string
+	content      strings.Builder
+	hasContent   bool
+	reasoning    strings.Builder
+	toolCalls    map[int64]*openAIToolCall
+	finishReason string
+}
+
+// aggregateOpenAI folds chat.completion.chunk objects into a chat.completion.
+func aggregateOpenAI(chunks [][]byte) ([]byte, error) {
+	payloads := dataPayloads(chunks)
+	if len(payloads) == 0 {
+		return nil, errors.New("streambridge: empty openai stream")
+	}
+	out := []byte(`{"object":"chat.completion","choices":[]}`)
+	choices := make(map[int64]*openAIChoice)
+	var usage string
+	for _, payload := range payloads {
+		root := gjson.ParseBytes(payload)
+		if errNode := root.Get("error"); errNode.Exists() {
+			return nil, errors.New("streambridge: upstream error: " + errNode.Raw)
+		}
+		for _, key := range []string{"id", "created", "model", "system_fingerprint", "service_tier"} {
+			if value := root.Get(key); value.Exists() && value.Type != gjson.Null && !gjson.GetBytes(out, key).Exists() {
+				out, _ = sjson.SetRawBytes(out, key, []byte(value.Raw))
+			}
+		}
+		if u := root.Get("usage"); u.Exists() && u.Type != gjson.Null {
+			usage = u.Raw
+		}
+		for _, choice := range root.Get("choices").Array() {
+			index := choice.Get("index").Int()
+			state := choices[index]
+			if state == nil {
+				state = &openAIChoice{toolCalls: make(map[int64]*openAIToolCall)}
+				choices[index] = state
+			}
+			delta := choice.Get("delta")
+			if role := delta.Get("role").String(); role != "" {
+				state.role = role
+			}
+			if content := delta.Get("content"); content.Exists() && content.Type != gjson.Null {
+				state.content.WriteString(content.String())
+				state.hasContent = true
+			}
+			if reasoning := delta.Get("reasoning_content"); reasoning.Exists() && reasoning.Type != gjson.Null {
+				state.reasoning.WriteString(reasoning.String())
+			}
+			for _, call := range delta.Get("tool_calls").Array() {
+				callIndex := call.Get("index").Int()
+				tc := state.toolCalls[callIndex]
+				if tc == nil {
+					tc = &openAIToolCall{kind: "function"}
+					state.toolCalls[callIndex] = tc
+				}
+				if id := call.Get("id").String(); id != "" {
+					tc.id = id
+				}
+				if kind := call.Get("type").String(); kind != "" {
+					tc.kind = kind
+				}
+				tc.name += call.Get("function.name").String()
+				tc.arguments.WriteString(call.Get("function.arguments").String())
+			}
+			if finish := choice.Get("finish_reason").String(); finish != "" {
+				state.finishReason = finish
+			}
+		}
+	}
+
+	indices := make([]int64, 0, len(choices))
+	for index := range choices {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	for _, index := range indices {
+		state := choices[index]
+		choice := []byte(`{"message":{"role":"assistant","content":null},"finish_reason":null}`)
+		choice, _ = sjson.SetBytes(choice, "index", index)
+		if state.role != "" {
+			choice, _ = sjson.SetBytes(choice, "message.role", state.role)
+		}
+		if state.hasContent {
+			choice, _ = sjson.SetBytes(choice, "message.content", state.content.String())
+		}
+		if state.reasoning.Len() > 0 {
+			choice, _ = sjson.SetBytes(choice, "message.reasoning_content", state.reasoning.String())
+		}
+		callIndices := make([]int64, 0, len(state.toolCalls))
+		for callIndex := range state.toolCalls {
+			callIndices = append(callIndices, callIndex)
+		}
+		sort.Slice(callIndices, func(i, j int) bool { return callIndices[i] < callIndices[j] })
+		for _, callIndex := range callIndices {
+			tc := state.toolCalls[callIndex]
+			call := []byte(`{}`)
+			call, _ = sjson.SetBytes(call, "id", tc.id)
+			call, _ = sjson.SetBytes(call, "type", tc.kind)
+			call, _ = sjson.SetBytes(call, "function.name", tc.name)
+			call, _ = sjson.SetBytes(call, "function.arguments", tc.arguments.String())
+			choice, _ = sjson.SetRawBytes(choice, "message.tool_calls.-1", call)
+		}
+		if state.finishReason != "" {
+			choice, _ = sjson.SetBytes(choice, "finish_reason", state.finishReason)
+		}
+		out, _ = sjson.SetRawBytes(out, "choices.-1", choice)
+	}
+	if usage != "" {
+		out, _ = sjson.SetRawBytes(out, "usage", []byte(usage))
+	}
+	return out, nil
+}
+
+// splitOpenAI replays a chat.completion as chat.completion.chunk objects: one delta carrying
+// each choice's message, one chunk with the finish reasons, and a final usage chunk.
+func splitOpenAI(payload []byte) ([][]byte, error) {
+	root := gjson.ParseBytes(payload)
+	if !root.Get("choices").IsArray() {
+		return nil, errors.New("streambridge: openai response has no choices")
+	}
+	base := []byte(`{"object":"chat.completion.chunk","choices":[]}`)
+	for _, key := range []string{"id", "created", "model", "system_fingerprint", "service_tier"} {
+		if value := root.Get(key); value.Exists() && value.Type != gjson.Null {
+			base, _ = sjson.SetRawBytes(base, key, []byte(value.Raw))
+		}
+	}
+
+	content := base
+	finish := base
+	for _, choice := range root.Get("choices").Array() {
+		message := choice.Get("message")
+		delta := []byte(`{"role":"assistant"}`)
+		if role := message.Get("role").String(); role != "" {
+			delta, _ = sjson.SetBytes(delta, "role", role)
+		}
+		if value := message.Get("content"); value.Exists() && value.Type != gjson.Null {
+			delta, _ = sjson.SetRawBytes(delta, "content", []byte(value.Raw))
+		}
+		if value := message.Get("reasoning_content"); value.Exists() && value.Type != gjson.Null {
+			delta, _ = sjson.SetRawBytes(delta, "reasoning_content", []byte(value.Raw))
+		}
+		for i, call := range message.Get("tool_calls").Array() {
+			entry, _ := sjson.SetRawBytes([]byte(`{}`), "function", []byte(call.Get("function").Raw))
+			entry, _ = sjson.SetBytes(entry, "index", i)
+			entry, _ = sjson.SetBytes(entry, "id", call.Get("id").String())
+			entry, _ = sjson.SetBytes(entry, "type", call.Get("type").String())
+			delta, _ = sjson.SetRawBytes(delta, "tool_calls.-1", entry)
+		}
+		index := choice.Get("index").Int()
+		deltaChoice, _ := sjson.SetRawBytes([]byte(`{"finish_reason":null}`), "delta", delta)
+		deltaChoice, _ = sjson.SetBytes(deltaChoice, "index", index)
+		content, _ = sjson.SetRawBytes(content, "choices.-1", deltaChoice)
+
+		finishChoice := []byte(`{"delta":{},"finish_reason":null}`)
+		finishChoice, _ = sjson.SetBytes(finishChoice, "index", index)
+		if reason := choice.Get("finish_reason"); reason.Exists() && reason.Type != gjson.Null {
+			finishChoice, _ = sjson.SetRawBytes(finishChoice, "finish_reason", []byte(reason.Raw))
+		}
+		finish, _ = sjson.SetRawBytes(finish, "choices.-1", finishChoice)
+	}
+	chunks := [][]byte{content, finish}
+	if usage := root.Get("usage"); usage.Exists() && usage.Type != gjson.Null {
+		usageChunk, _ := sjson.SetRawBytes(base, "usage", []byte(usage.Raw))
+		chunks = append(chunks, usageChunk)
+	}
+	return chunks, nil
+}
@@ -0,0 +1,138 @@
+package streambridge
+
+import (
+	"errors"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// aggregateResponses returns the response object carried by the terminal Responses API event.
+// Output items announced by response.output_item.done fill in an empty terminal output.
+func aggregateResponses(chunks [][]byte) ([]byte, error) {
+	var response []byte
+	var items [][]byte
+	for _, payload := range dataPayloads(chunks) {
+		root := gjson.ParseBytes(payload)
+		switch root.Get("type").String() {
+		case "response.completed", "response.incomplete":
+			response = []byte(root.Get("response").Raw)
+		case "response.output_item.done":
+			items = append(items, []byte(root.Get("item").Raw))
+		case "response.failed":
+			return nil, errors.New("streambridge: upstream error: " + root.Get("response.error").Raw)
+		case "error":
+			return nil, errors.New("streambridge: upstream error: " + root.Raw)
+		}
+	}
+	if response == nil {
+		return nil, errors.New("streambridge: responses stream has no terminal event")
+	}
+	if len(gjson.GetBytes(response, "output").Array()) == 0 && len(items) > 0 {
+		response, _ = sjson.SetRawBytes(response, "output", []byte(`[]`))
+		for _, item := range items {
+			response, _ = sjson.SetRawBytes(response, "output.-1", item)
+		}
+	}
+	return response, nil
+}
+
+// splitResponses replays a response object as Responses API events: created, in_progress,
+// the added/delta/done events for each output item, and completed.
+func splitResponses(payload []byte) ([][]byte, error) {
+	root := gjson.ParseBytes(payload)
+	if root.Get("object").String() != "response" {
+		return nil, errors.New("streambridge: responses payload is not a response")
+	}
+	sequence := 0
+	var chunks [][]byte
+	emit := func(eventType string, event []byte) {
+		event, _ = sjson.SetBytes(event, "type", eventType)
+		event, _ = sjson.SetBytes(event, "sequence_number", sequence)
+		sequence++
+		chunks = append(chunks, responsesEvent(eventType, event))
+	}
+
+	pending, _ := sjson.SetBytes(payload, "status", "in_progress")
+	pending, _ = sjson.SetRawBytes(pending, "output", []byte(`[]`))
+	pending, _ = sjson.DeleteBytes(pending, "usage")
+	created, _ := sjson.SetRawBytes([]byte(`{}`), "response", pending)
+	emit("response.created", created)
+	emit("response.in_progress", created)
+
+	for i, item := range root.Get("output").Array() {
+		raw := []byte(item.Raw)
+		itemID := item.Get("id").String()
+		itemEvent := func(eventType string, itemRaw []byte) {
+			event, _ := sjson.SetBytes([]byte(`{}`), "output_index", i)
+			event, _ = sjson.SetRawBytes(event, "item", itemRaw)
+			emit(eventType, event)
+		}
+		fieldEvent := func(eventType string, fields map[string]any) {
+			event, _ := sjson.SetBytes([]byte(`{}`), "item_id", itemID)
+			event, _ = sjson.SetBytes(event, "output_index", i)
+			for key, value := range fields {
+				event, _ = sjson.SetBytes(event, key, value)
+			}
+			emit(eventType, event)
+		}
+
+		switch item.Get("type").String() {
+		case "message":
+			added, _ := sjson.SetRawBytes(raw, "content", []byte(`[]`))
+			added, _ = sjson.SetBytes(added, "status", "in_progress")
+			itemEvent("response.output_item.added", added)
+			for j, part := range item.Get("content").Array() {
+				partRaw := []byte(part.Raw)
+				emptyPart := partRaw
+				if part.Get("type").String() == "output_text" {
+					emptyPart, _ = sjson.SetBytes(partRaw, "text", "")
+				}
+				partEvent := func(eventType string, value []byte) {
+					event, _ := sjson.SetBytes([]byte(`{}`), "item_id", itemID)
+					event, _ = sjson.SetBytes(event, "output_index", i)
+					event, _ = sjson.SetBytes(event, "content_index", j)
+					event, _ = sjson.SetRawBytes(event, "part", value)
+					emit(eventType, event)
+				}
+				partEvent("response.content_part.added", emptyPart)
+				if part.Get("type").String() == "output_text" {
+					text := part.Get("text").String()
+					fieldEvent("response.output_text.delta", map[string]any{"content_index": j, "delta": text})
+					fieldEvent("response.output_text.done", map[string]any{"content_index": j, "text": text})
+				}
+				partEvent("response.content_part.done", partRaw)
+			}
+		case "function_call":
+			added, _ := sjson.SetBytes(raw, "arguments", "")
+			added, _ = sjson.SetBytes(added, "status", "in_progress")
+			itemEvent("response.output_item.added", added)
+			arguments := item.Get("arguments").String()
+			fieldEvent("response.function_call_arguments.delta", map[string]any{"delta": arguments})
+			fieldEvent("response.function_call_arguments.done", map[string]any{"arguments": arguments})
+		case "web_search_call":
+			added, _ := sjson.SetBytes(raw, "status", "in_progress")
+			itemEvent("response.output_item.added", added)
+			fieldEvent("response.web_search_call.in_progress", nil)
+			fieldEvent("response.web_search_call.searching", nil)
+			fieldEvent("response.web_search_call.completed", nil)
+		default:
+			itemEvent("response.output_item.added", raw)
+		}
+		itemEvent("response.output_item.done", raw)
+	}
+
+	completed, _ := sjson.SetRawBytes([]byte(`{}`), "response", payload)
+	emit("response.completed", completed)
+	return chunks, nil
+}
+
+// responsesEvent frames one Responses API event the way the Responses handlers expect it:
+// the handler adds the separating blank lines itself.
+func responsesEvent(event string, data []byte) []byte {
+	out := make([]byte, 0, len(event)+len(data)+15)
+	out = append(out, "event: "...)
+	out = append(out, event...)
+	out = append(out, "\ndata: "...)
+	return append(out, data...)
+}
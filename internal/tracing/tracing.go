@@ -0,0 +1,207 @@
+// Package tracing wires optional OpenTelemetry tracing into the proxy. When tracing is
+// disabled the global tracer provider is a no-op, spans cost next to nothing, and inbound
+// trace context is neither extracted nor forwarded upstream.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// InstrumentationName identifies spans created by the proxy.
+const InstrumentationName = "github.com/router-for-me/CLIProxyAPI/v6"
+
+const defaultServiceName = "cli-proxy-api"
+
+var (
+	enabled    atomic.Bool
+	propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+)
+
+// Enabled reports whether spans are being exported.
+func Enabled() bool { return enabled.Load() }
+
+// Tracer returns the proxy tracer from the current global provider. It is looked up on every
+// call so configuration reloads take effect for new spans.
+func Tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(InstrumentationName)
+}
+
+// Start starts a span named name as a child of any span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SetProvider installs tp as the global tracer provider and enables propagation. It returns a
+// function restoring the previous state; tests use it with an in-memory exporter.
+func SetProvider(tp trace.TracerProvider) (restore func()) {
+	previous := otel.GetTracerProvider()
+	wasEnabled := enabled.Load()
+	otel.SetTracerProvider(tp)
+	enabled.Store(true)
+	return func() {
+		otel.SetTracerProvider(previous)
+		enabled.Store(wasEnabled)
+	}
+}
+
+// Provider owns the exporting tracer provider built from configuration.
+type Provider struct {
+	mu  sync.Mutex
+	cfg config.TracingConfig
+	tp  *sdktrace.TracerProvider
+}
+
+// NewProvider creates an idle provider; call Apply to start exporting.
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Apply reconciles tracing with cfg, replacing the exporter when its settings change.
+func (p *Provider) Apply(ctx context.Context, cfg config.TracingConfig) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tp != nil && reflect.DeepEqual(p.cfg, cfg) {
+		return nil
+	}
+	previous := p.tp
+	p.tp = nil
+	p.cfg = cfg
+	if cfg.Enable {
+		tp, err := newTracerProvider(ctx, cfg)
+		if err != nil {
+			enabled.Store(false)
+			otel.SetTracerProvider(noop.NewTracerProvider())
+			shutdownProvider(previous)
+			return err
+		}
+		p.tp = tp
+		otel.SetTextMapPropagator(propagator)
+		otel.SetTracerProvider(tp)
+		enabled.Store(true)
+		log.Infof("tracing enabled, exporting to %s", describeEndpoint(cfg))
+	} else if previous != nil {
+		enabled.Store(false)
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		log.Info("tracing disabled")
+	}
+	shutdownProvider(previous)
+	return nil
+}
+
+// Shutdown flushes pending spans and disables tracing.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	tp := p.tp
+	p.tp = nil
+	p.mu.Unlock()
+	if tp == nil {
+		return nil
+	}
+	enabled.Store(false)
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	return tp.Shutdown(ctx)
+}
+
+func newTracerProvider(ctx context.Context, cfg config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	var opts []otlptracehttp.Option
+	if endpoint := strings.TrimSpace(cfg.Endpoint); endpoint != "" {
+		if strings.Contains(endpoint, "://") {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		} else {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+			if cfg.Insecure {
+				opts = append(opts, otlptracehttp.WithInsecure())
+			}
+		}
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("tracing: create OTLP exporter: %w", err)
+	}
+
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("service.version", buildinfo.Version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing: build resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio(cfg.SampleRatio)))),
+	), nil
+}
+
+func sampleRatio(ratio float64) float64 {
+	if ratio <= 0 || ratio > 1 {
+		return 1
+	}
+	return ratio
+}
+
+func describeEndpoint(cfg config.TracingConfig) string {
+	if endpoint := strings.TrimSpace(cfg.Endpoint); endpoint != "" {
+		return endpoint
+	}
+	return "the default OTLP endpoint"
+}
+
+func shutdownProvider(tp *sdktrace.TracerProvider) {
+	if tp == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tp.Shutdown(ctx); err != nil {
+		log.Warnf("tracing: shutdown previous provider: %v", err)
+	}
+}
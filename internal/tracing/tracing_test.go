@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const inboundTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestMiddlewareContinuesInboundTraceUpstream(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(SetProvider(tp))
+
+	var upstreamTraceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamTraceparent = r.Header.Get("traceparent")
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Middleware())
+	engine.POST("/v1/chat/completions", func(c *gin.Context) {
+		client := &http.Client{Transport: Transport(nil)}
+		req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, upstream.URL+"/v1/models?key=secret", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			c.Status(http.StatusBadGateway)
+			return
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("traceparent", inboundTraceparent)
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	spans := exporter.GetSpans()
+	var server, client *tracetest.SpanStub
+	for i := range spans {
+		switch spans[i].SpanKind {
+		case trace.SpanKindServer:
+			server = &spans[i]
+		case trace.SpanKindClient:
+			client = &spans[i]
+		}
+	}
+	if server == nil || client == nil {
+		t.Fatalf("expected server and client spans, got %d spans", len(spans))
+	}
+	if server.Name != "POST /v1/chat/completions" {
+		t.Errorf("server span name = %q", server.Name)
+	}
+	if got := server.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("server span did not continue inbound trace, trace id %s", got)
+	}
+	if client.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Error("client span is not a child of the server span")
+	}
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + client.SpanContext.SpanID().String() + "-01"
+	if upstreamTraceparent != want {
+		t.Errorf("upstream traceparent = %q, want %q", upstreamTraceparent, want)
+	}
+	for _, kv := range client.Attributes {
+		if kv.Key == "url.full" && kv.Value.AsString() != upstream.URL+"/v1/models" {
+			t.Errorf("url.full = %q, query should be omitted", kv.Value.AsString())
+		}
+	}
+}
+
+func TestTransportPassesThroughWhenDisabled(t *testing.T) {
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer upstream.Close()
+
+	resp, err := (&http.Client{Transport: Transport(nil)}).Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	_ = resp.Body.Close()
+	if traceparent != "" {
+		t.Fatalf("traceparent forwarded while tracing is disabled: %q", traceparent)
+	}
+}
@@ -19,6 +19,8 @@ const (
 	tabAPIKeys
 	tabOAuth
 	tabUsage
+	tabRequests
+	tabPlayground
 	tabLogs
 )
 
@@ -35,13 +37,15 @@ type App struct {
 	authError      string
 	authConnecting bool
 
-	dashboard dashboardModel
-	config    configTabModel
-	auth      authTabModel
-	keys      keysTabModel
-	oauth     oauthTabModel
-	usage     usageTabModel
-	logs      logsTabModel
+	dashboard  dashboardModel
+	config     configTabModel
+	auth       authTabModel
+	keys       keysTabModel
+	oauth      oauthTabModel
+	usage      usageTabModel
+	requests   requestsTabModel
+	playground playgroundTabModel
+	logs       logsTabModel
 
 	client *Client
 
@@ -50,7 +54,7 @@ type App struct {
 	ready  bool
 
 	// Track which tabs have been initialized (fetched data)
-	initialized [7]bool
+	initialized [9]bool
 }
 
 type authConnectMsg struct {
@@ -82,9 +86,11 @@ func NewApp(port int, secretKey string, hook *LogHook) App {
 		keys:          newKeysTabModel(client),
 		oauth:         newOAuthTabModel(client),
 		usage:         newUsageTabModel(client),
+		requests:      newRequestsTabModel(client),
+		playground:    newPlaygroundTabModel(client),
 		logs:          newLogsTabModel(client, hook),
 		client:        client,
-		initialized: [7]bool{
+		initialized: [9]bool{
 			tabDashboard: true,
 			tabLogs:      true,
 		},
@@ -92,7 +98,7 @@ func NewApp(port int, secretKey string, hook *LogHook) App {
 
 	app.refreshTabs()
 	if authRequired {
-		app.initialized = [7]bool{}
+		app.initialized = [9]bool{}
 	}
 	app.setAuthInputPrompt()
 	return app
@@ -129,6 +135,8 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.keys.SetSize(contentW, contentH)
 		a.oauth.SetSize(contentW, contentH)
 		a.usage.SetSize(contentW, contentH)
+		a.requests.SetSize(contentW, contentH)
+		a.playground.SetSize(contentW, contentH)
 		a.logs.SetSize(contentW, contentH)
 		return a, nil
 
@@ -142,7 +150,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.authenticated = true
 		a.logsEnabled = a.standalone || isLogsEnabledFromConfig(msg.cfg)
 		a.refreshTabs()
-		a.initialized = [7]bool{}
+		a.initialized = [9]bool{}
 		a.initialized[tabDashboard] = true
 		cmds := []tea.Cmd{a.dashboard.Init()}
 		if a.logsEnabled {
@@ -210,26 +218,28 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		switch msg.String() {
-		case "ctrl+c":
+		switch key := msg.String(); {
+		case a.capturingInput() && key != "ctrl+c":
+			// The active tab is taking text input; let it have the key.
+		case key == "ctrl+c":
 			return a, tea.Quit
-		case "q":
+		case key == "q":
 			// Only quit if not in logs tab (where 'q' might be useful)
 			if !a.logsEnabled || a.activeTab != tabLogs {
 				return a, tea.Quit
 			}
-		case "L":
+		case key == "L":
 			ToggleLocale()
 			a.refreshTabs()
 			return a.broadcastToAllTabs(localeChangedMsg{})
-		case "tab":
+		case key == "tab":
 			if len(a.tabs) == 0 {
 				return a, nil
 			}
 			prevTab := a.activeTab
 			a.activeTab = (a.activeTab + 1) % len(a.tabs)
 			return a, a.initTabIfNeeded(prevTab)
-		case "shift+tab":
+		case key == "shift+tab":
 			if len(a.tabs) == 0 {
 				return a, nil
 			}
@@ -260,10 +270,22 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.oauth, cmd = a.oauth.Update(msg)
 	case tabUsage:
 		a.usage, cmd = a.usage.Update(msg)
+	case tabRequests:
+		a.requests, cmd = a.requests.Update(msg)
+	case tabPlayground:
+		a.playground, cmd = a.playground.Update(msg)
 	case tabLogs:
 		a.logs, cmd = a.logs.Update(msg)
 	}
 
+	// Keep a streaming playground reply flowing even when the tab is not active.
+	if a.activeTab != tabPlayground {
+		switch msg.(type) {
+		case playgroundListsMsg, playgroundChunkMsg, playgroundDoneMsg:
+			a.playground, cmd = a.playground.Update(msg)
+		}
+	}
+
 	// Keep logs polling alive even when logs tab is not active.
 	if a.logsEnabled && a.activeTab != tabLogs {
 		switch msg.(type) {
@@ -279,6 +301,17 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, cmd
 }
 
+// capturingInput reports whether the active tab is taking text input, so global shortcuts must not fire.
+func (a App) capturingInput() bool {
+	switch a.activeTab {
+	case tabRequests:
+		return a.requests.capturingInput()
+	case tabPlayground:
+		return a.playground.capturingInput()
+	}
+	return false
+}
+
 // localeChangedMsg is broadcast to all tabs when the user toggles locale.
 type localeChangedMsg struct{}
 
@@ -324,6 +357,10 @@ func (a *App) initTabIfNeeded(_ int) tea.Cmd {
 		return a.oauth.Init()
 	case tabUsage:
 		return a.usage.Init()
+	case tabRequests:
+		return a.requests.Init()
+	case tabPlayground:
+		return a.playground.Init()
 	case tabLogs:
 		if !a.logsEnabled {
 			return nil
@@ -362,6 +399,10 @@ func (a App) View() string {
 		sb.WriteString(a.oauth.View())
 	case tabUsage:
 		sb.WriteString(a.usage.View())
+	case tabRequests:
+		sb.WriteString(a.requests.View())
+	case tabPlayground:
+		sb.WriteString(a.playground.View())
 	case tabLogs:
 		if a.logsEnabled {
 			sb.WriteString(a.logs.View())
@@ -533,6 +574,14 @@ func (a App) broadcastToAllTabs(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if cmd != nil {
 		cmds = append(cmds, cmd)
 	}
+	a.requests, cmd = a.requests.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	a.playground, cmd = a.playground.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 	a.logs, cmd = a.logs.Update(msg)
 	if cmd != nil {
 		cmds = append(cmds, cmd)
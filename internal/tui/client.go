@@ -1,11 +1,15 @@
 package tui
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -239,6 +243,322 @@ func (c *Client) GetLogs(after int64, limit int) ([]string, int64, error) {
 	return lines, latest, nil
 }
 
+// StreamLogs follows the live log stream and sends each log line and request summary to out
+// until ctx is cancelled or the stream breaks. It returns the error that ended the stream.
+func (c *Client) StreamLogs(ctx context.Context, tail int, out chan<- string) error {
+	path := "/v0/management/logs/stream"
+	if tail > 0 {
+		path += "?tail=" + strconv.Itoa(tail)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.secretKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	// The shared client has a short timeout; the stream stays open indefinitely.
+	resp, err := (&http.Client{Transport: c.http.Transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if line := formatStreamEvent([]byte(data)); line != "" {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// formatStreamEvent renders a stream event as a log line; request summaries get a line in the
+// same layout as log lines so level styling and filtering apply to them too.
+func formatStreamEvent(data []byte) string {
+	var event struct {
+		Type      string    `json:"type"`
+		Time      time.Time `json:"time"`
+		Level     string    `json:"level"`
+		Line      string    `json:"line"`
+		RequestID string    `json:"request_id"`
+		Provider  string    `json:"provider"`
+		Model     string    `json:"model"`
+		AuthIndex string    `json:"auth_index"`
+		ClientKey string    `json:"client_key"`
+		Status    int       `json:"status"`
+		LatencyMs int64     `json:"latency_ms"`
+		Tokens    *struct {
+			Total int64 `json:"total"`
+		} `json:"tokens"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return ""
+	}
+	if event.Type != "request" {
+		return event.Line
+	}
+	level := event.Level
+	if level == "warning" {
+		level = "warn"
+	}
+	requestID := event.RequestID
+	if requestID == "" {
+		requestID = "--------"
+	}
+	var tokens int64
+	if event.Tokens != nil {
+		tokens = event.Tokens.Total
+	}
+	return fmt.Sprintf("[%s] [%s] [%-5s] request %s/%s auth=%s key=%s status=%d %dms tokens=%d",
+		event.Time.Local().Format("2006-01-02 15:04:05"), requestID, level,
+		event.Provider, event.Model, event.AuthIndex, event.ClientKey, event.Status, event.LatencyMs, tokens)
+}
+
+// GetPlaygroundModels lists the model IDs available to the playground, as served by /v1/models.
+func (c *Client) GetPlaygroundModels() ([]string, error) {
+	data, err := c.get("/v0/management/playground/models")
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	models := make([]string, 0, len(resp.Data))
+	for _, m := range resp.Data {
+		if m.ID != "" {
+			models = append(models, m.ID)
+		}
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// playgroundServed names the credential that answered a playground request.
+type playgroundServed struct {
+	auth     string
+	provider string
+	account  string
+}
+
+// playgroundUsage is the token usage reported at the end of a playground stream.
+type playgroundUsage struct {
+	prompt     int64
+	completion int64
+	reasoning  int64
+	total      int64
+}
+
+// playgroundChunk is one update from a streaming playground chat. Exactly one field is set.
+type playgroundChunk struct {
+	served    *playgroundServed
+	content   string
+	reasoning string
+	usage     *playgroundUsage
+}
+
+// StreamPlaygroundChat sends prompt to model as a streaming chat completion through the local
+// server, optionally pinned to authID, and forwards parsed updates to out until the stream ends.
+func (c *Client) StreamPlaygroundChat(ctx context.Context, model, authID, prompt string, out chan<- playgroundChunk) error {
+	body, err := json.Marshal(map[string]any{
+		"model":          model,
+		"stream":         true,
+		"stream_options": map[string]any{"include_usage": true},
+		"messages":       []map[string]string{{"role": "user", "content": prompt}},
+	})
+	if err != nil {
+		return err
+	}
+	path := "/v0/management/playground/chat/completions"
+	if authID != "" {
+		path += "?auth_id=" + url.QueryEscape(authID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if c.secretKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := (&http.Client{Transport: c.http.Transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	send := func(chunk playgroundChunk) error {
+		select {
+		case out <- chunk:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	served := &playgroundServed{
+		auth:     resp.Header.Get("X-Playground-Auth"),
+		provider: resp.Header.Get("X-Playground-Provider"),
+		account:  resp.Header.Get("X-Playground-Account"),
+	}
+	if err = send(playgroundChunk{served: served}); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		chunks, errParse := parsePlaygroundChunk([]byte(data))
+		if errParse != nil {
+			return errParse
+		}
+		for _, chunk := range chunks {
+			if err = send(chunk); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// parsePlaygroundChunk extracts text, reasoning and usage from one chat completion chunk.
+func parsePlaygroundChunk(data []byte) ([]playgroundChunk, error) {
+	var chunk struct {
+		Choices []struct {
+			Delta struct {
+				Content          string `json:"content"`
+				ReasoningContent string `json:"reasoning_content"`
+			} `json:"delta"`
+		} `json:"choices"`
+		Usage *struct {
+			PromptTokens            int64 `json:"prompt_tokens"`
+			CompletionTokens        int64 `json:"completion_tokens"`
+			TotalTokens             int64 `json:"total_tokens"`
+			CompletionTokensDetails struct {
+				ReasoningTokens int64 `json:"reasoning_tokens"`
+			} `json:"completion_tokens_details"`
+		} `json:"usage"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, nil
+	}
+	if chunk.Error != nil {
+		return nil, fmt.Errorf("upstream error: %s", chunk.Error.Message)
+	}
+	var out []playgroundChunk
+	for _, choice := range chunk.Choices {
+		if choice.Delta.ReasoningContent != "" {
+			out = append(out, playgroundChunk{reasoning: choice.Delta.ReasoningContent})
+		}
+		if choice.Delta.Content != "" {
+			out = append(out, playgroundChunk{content: choice.Delta.Content})
+		}
+	}
+	if u := chunk.Usage; u != nil && u.TotalTokens > 0 {
+		out = append(out, playgroundChunk{usage: &playgroundUsage{
+			prompt:     u.PromptTokens,
+			completion: u.CompletionTokens,
+			reasoning:  u.CompletionTokensDetails.ReasoningTokens,
+			total:      u.TotalTokens,
+		}})
+	}
+	return out, nil
+}
+
+// ListRequestLogs lists request logs newest first. filters holds the status, model, path and
+// since query parameters of the management endpoint; empty values are omitted.
+func (c *Client) ListRequestLogs(filters map[string]string, limit int) ([]map[string]any, error) {
+	query := url.Values{}
+	for key, value := range filters {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	wrapper, err := c.getJSON("/v0/management/request-logs?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	return extractList(wrapper, "files")
+}
+
+// requestLogAttempt is one upstream attempt of a request log.
+type requestLogAttempt struct {
+	Index          int      `json:"index"`
+	UpstreamURL    string   `json:"upstream_url"`
+	Auth           string   `json:"auth"`
+	RequestBody    string   `json:"request_body"`
+	Status         int      `json:"status"`
+	ResponseChunks []string `json:"response_chunks"`
+	Errors         []string `json:"errors"`
+}
+
+// requestLogDetail is the structured request log returned by the management API.
+type requestLogDetail struct {
+	URL          string              `json:"url"`
+	Method       string              `json:"method"`
+	Model        string              `json:"model"`
+	Status       int                 `json:"status"`
+	Timestamp    time.Time           `json:"timestamp"`
+	RequestBody  string              `json:"request_body"`
+	Attempts     []requestLogAttempt `json:"upstream_attempts"`
+	ResponseBody string              `json:"response_body"`
+	Errors       []struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	} `json:"upstream_errors"`
+}
+
+// GetRequestLog fetches one request log parsed into its sections.
+func (c *Client) GetRequestLog(name string) (*requestLogDetail, error) {
+	data, err := c.get("/v0/management/request-logs/" + url.PathEscape(name))
+	if err != nil {
+		return nil, err
+	}
+	var wrapper struct {
+		Log requestLogDetail `json:"log"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, err
+	}
+	return &wrapper.Log, nil
+}
+
 // GetAPIKeys fetches the list of API keys.
 // API returns {"api-keys": [...]}.
 func (c *Client) GetAPIKeys() ([]string, error) {
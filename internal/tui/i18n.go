@@ -50,8 +50,8 @@ var locales = map[string]map[string]string{
 // ──────────────────────────────────────────
 // Tab names
 // ──────────────────────────────────────────
-var zhTabNames = []string{"仪表盘", "配置", "认证文件", "API 密钥", "OAuth", "使用统计", "日志"}
-var enTabNames = []string{"Dashboard", "Config", "Auth Files", "API Keys", "OAuth", "Usage", "Logs"}
+var zhTabNames = []string{"仪表盘", "配置", "认证文件", "API 密钥", "OAuth", "使用统计", "请求日志", "试验场", "日志"}
+var enTabNames = []string{"Dashboard", "Config", "Auth Files", "API Keys", "OAuth", "Usage", "Requests", "Playground", "Logs"}
 
 // TabNames returns tab names in the current locale.
 func TabNames() []string {
@@ -202,6 +202,47 @@ var zhStrings = map[string]string{
 	"usage_cached":        "缓存",
 	"usage_reasoning":     "思考",
 
+	// ── Requests ──
+	"requests_title":            "🔎 请求日志",
+	"requests_help":             " [↑↓/jk] 选择 • [Enter] 查看 • [f] 过滤 • [r] 刷新",
+	"requests_filter":           "过滤",
+	"requests_filter_none":      "(无) 例: status:5xx model:gpt path:/v1/messages since:1h",
+	"requests_col_time":         "时间",
+	"requests_col_status":       "状态",
+	"requests_col_method":       "方法",
+	"requests_col_model":        "模型",
+	"requests_col_path":         "路径",
+	"requests_col_attempts":     "尝试",
+	"requests_empty":            "  暂无请求日志 (需开启 request-log 或出现错误请求)",
+	"requests_detail_title":     "🔎",
+	"requests_detail_help":      " [Esc] 返回 • [/] 搜索 • [n/N] 下/上一个匹配 • [r] 刷新 • [↑↓] 滚动",
+	"requests_client_request":   "客户端原始请求",
+	"requests_client_response":  "返回客户端的响应",
+	"requests_attempt":          "上游尝试",
+	"requests_upstream_request": "转换后的上游请求",
+	"requests_upstream_chunks":  "上游响应块",
+	"requests_no_upstream":      "(无上游请求记录)",
+
+	// ── Playground ──
+	"playground_title":       "🧪 试验场",
+	"playground_model":       "模型",
+	"playground_auth":        "凭证",
+	"playground_auth_auto":   "自动选择",
+	"playground_help":        " [Enter/i] 输入 • [m] 选择模型 • [a] 选择凭证 • [Esc] 停止 • [c] 清除 • [r] 刷新 • [↑↓] 滚动",
+	"playground_help_input":  " Enter: 发送 • Esc: 取消输入",
+	"playground_idle":        " 输入提示词并按 Enter 发送",
+	"playground_running":     "生成中",
+	"playground_served_by":   "服务凭证",
+	"playground_first_token": "首字延迟",
+	"playground_total":       "总耗时",
+	"playground_tokens":      "Token 输入/输出/总计",
+	"playground_reasoning":   "思考过程",
+	"playground_pick_model":  "选择模型",
+	"playground_pick_auth":   "选择凭证",
+	"playground_pick_help":   "[↑↓] 移动 • Enter: 确认 • Esc: 取消 • 输入以过滤",
+	"playground_filter":      "过滤",
+	"playground_no_match":    "无匹配项",
+
 	// ── Logs ──
 	"logs_title":       "📋 日志",
 	"logs_auto_scroll": "● 自动滚动",
@@ -353,6 +394,47 @@ var enStrings = map[string]string{
 	"usage_cached":        "Cached",
 	"usage_reasoning":     "Reasoning",
 
+	// ── Requests ──
+	"requests_title":            "🔎 Request Logs",
+	"requests_help":             " [↑↓/jk] Select • [Enter] Inspect • [f] Filter • [r] Refresh",
+	"requests_filter":           "Filter",
+	"requests_filter_none":      "(none) e.g. status:5xx model:gpt path:/v1/messages since:1h",
+	"requests_col_time":         "Time",
+	"requests_col_status":       "Status",
+	"requests_col_method":       "Method",
+	"requests_col_model":        "Model",
+	"requests_col_path":         "Path",
+	"requests_col_attempts":     "Tries",
+	"requests_empty":            "  No request logs (enable request-log, or wait for a failed request)",
+	"requests_detail_title":     "🔎",
+	"requests_detail_help":      " [Esc] Back • [/] Search • [n/N] Next/prev match • [r] Reload • [↑↓] Scroll",
+	"requests_client_request":   "Client request",
+	"requests_client_response":  "Client response",
+	"requests_attempt":          "Upstream attempt",
+	"requests_upstream_request": "Translated upstream request",
+	"requests_upstream_chunks":  "Upstream response chunks",
+	"requests_no_upstream":      "(no upstream request recorded)",
+
+	// ── Playground ──
+	"playground_title":       "🧪 Playground",
+	"playground_model":       "Model",
+	"playground_auth":        "Auth",
+	"playground_auth_auto":   "Automatic",
+	"playground_help":        " [Enter/i] Type • [m] Model • [a] Auth • [Esc] Stop • [c] Clear • [r] Refresh • [↑↓] Scroll",
+	"playground_help_input":  " Enter: send • Esc: leave input",
+	"playground_idle":        " Type a prompt and press Enter to send",
+	"playground_running":     "Streaming",
+	"playground_served_by":   "Served by",
+	"playground_first_token": "First token",
+	"playground_total":       "Total",
+	"playground_tokens":      "Tokens in/out/total",
+	"playground_reasoning":   "Reasoning",
+	"playground_pick_model":  "Select model",
+	"playground_pick_auth":   "Select auth",
+	"playground_pick_help":   "[↑↓] Move • Enter: choose • Esc: cancel • type to filter",
+	"playground_filter":      "Filter",
+	"playground_no_match":    "No matches",
+
 	// ── Logs ──
 	"logs_title":       "📋 Logs",
 	"logs_auto_scroll": "● AUTO-SCROLL",
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -23,6 +24,9 @@ type logsTabModel struct {
 	filter     string // "", "debug", "info", "warn", "error"
 	after      int64
 	lastErr    error
+	// stream delivers lines from the live stream endpoint; nil while polling.
+	stream    chan string
+	streamErr chan error
 }
 
 type logsPollMsg struct {
@@ -34,6 +38,13 @@ type logsPollMsg struct {
 type logsTickMsg struct{}
 type logLineMsg string
 
+type logsStreamStartedMsg struct {
+	lines chan string
+	errs  chan error
+}
+
+type logsStreamEndedMsg struct{ err error }
+
 func newLogsTabModel(client *Client, hook *LogHook) logsTabModel {
 	return logsTabModel{
 		client:     client,
@@ -47,7 +58,19 @@ func (m logsTabModel) Init() tea.Cmd {
 	if m.hook != nil {
 		return m.waitForLog
 	}
-	return m.fetchLogs
+	return m.startStream
+}
+
+// startStream follows the server's live log stream. When the stream is unavailable the tab
+// falls back to polling.
+func (m logsTabModel) startStream() tea.Msg {
+	lines := make(chan string, 256)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- m.client.StreamLogs(context.Background(), 200, lines)
+		close(lines)
+	}()
+	return logsStreamStartedMsg{lines: lines, errs: errs}
 }
 
 func (m logsTabModel) fetchLogs() tea.Msg {
@@ -67,7 +90,14 @@ func (m logsTabModel) waitForNextPoll() tea.Cmd {
 
 func (m logsTabModel) waitForLog() tea.Msg {
 	if m.hook == nil {
-		return nil
+		if m.stream == nil {
+			return nil
+		}
+		line, ok := <-m.stream
+		if !ok {
+			return logsStreamEndedMsg{err: <-m.streamErr}
+		}
+		return logLineMsg(line)
 	}
 	line, ok := <-m.hook.Chan()
 	if !ok {
@@ -81,13 +111,22 @@ func (m logsTabModel) Update(msg tea.Msg) (logsTabModel, tea.Cmd) {
 	case localeChangedMsg:
 		m.viewport.SetContent(m.renderLogs())
 		return m, nil
+	case logsStreamStartedMsg:
+		m.stream = msg.lines
+		m.streamErr = msg.errs
+		return m, m.waitForLog
+	case logsStreamEndedMsg:
+		m.stream = nil
+		m.lastErr = msg.err
+		m.viewport.SetContent(m.renderLogs())
+		return m, m.fetchLogs
 	case logsTickMsg:
-		if m.hook != nil {
+		if m.hook != nil || m.stream != nil {
 			return m, nil
 		}
 		return m, m.fetchLogs
 	case logsPollMsg:
-		if m.hook != nil {
+		if m.hook != nil || m.stream != nil {
 			return m, nil
 		}
 		if msg.err != nil {
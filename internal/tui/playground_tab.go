@@ -0,0 +1,533 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// playgroundHeaderLines is the number of lines rendered above the output viewport.
+const playgroundHeaderLines = 7
+
+// playgroundPicker identifies the selection list currently open, if any.
+type playgroundPicker int
+
+const (
+	pickerNone playgroundPicker = iota
+	pickerModel
+	pickerAuth
+)
+
+// playgroundAuth is a credential the playground can pin a request to.
+type playgroundAuth struct {
+	id    string
+	label string
+}
+
+// playgroundTabModel sends test prompts through the local server and renders the reply as it
+// streams, together with the credential that served it, latency and token usage.
+type playgroundTabModel struct {
+	client   *Client
+	viewport viewport.Model
+	prompt   textinput.Model
+	width    int
+	height   int
+	ready    bool
+
+	models  []string
+	model   string
+	auths   []playgroundAuth
+	auth    playgroundAuth // zero value = automatic selection
+	listErr error
+
+	picker       playgroundPicker
+	pickerFilter string
+	pickerCursor int
+
+	running    bool
+	cancel     context.CancelFunc
+	chunks     chan playgroundChunk
+	errs       chan error
+	served     *playgroundServed
+	reasoning  string
+	content    string
+	usage      *playgroundUsage
+	started    time.Time
+	firstToken time.Time
+	finished   time.Time
+	err        error
+}
+
+type playgroundListsMsg struct {
+	models []string
+	auths  []playgroundAuth
+	err    error
+}
+
+type playgroundChunkMsg playgroundChunk
+
+type playgroundDoneMsg struct{ err error }
+
+func newPlaygroundTabModel(client *Client) playgroundTabModel {
+	ti := textinput.New()
+	ti.CharLimit = 8192
+	ti.Prompt = "> "
+	return playgroundTabModel{
+		client: client,
+		prompt: ti,
+	}
+}
+
+func (m playgroundTabModel) Init() tea.Cmd {
+	return m.fetchLists
+}
+
+func (m playgroundTabModel) fetchLists() tea.Msg {
+	models, err := m.client.GetPlaygroundModels()
+	if err != nil {
+		return playgroundListsMsg{err: err}
+	}
+	files, err := m.client.GetAuthFiles()
+	if err != nil {
+		return playgroundListsMsg{models: models, err: err}
+	}
+	auths := make([]playgroundAuth, 0, len(files))
+	for _, f := range files {
+		if getBool(f, "disabled") {
+			continue
+		}
+		id := getString(f, "id")
+		if id == "" {
+			continue
+		}
+		label := getString(f, "name")
+		details := make([]string, 0, 2)
+		if provider := getString(f, "provider"); provider != "" {
+			details = append(details, provider)
+		}
+		if email := getString(f, "email"); email != "" {
+			details = append(details, email)
+		}
+		if len(details) > 0 {
+			label += " (" + strings.Join(details, ", ") + ")"
+		}
+		auths = append(auths, playgroundAuth{id: id, label: label})
+	}
+	return playgroundListsMsg{models: models, auths: auths}
+}
+
+func (m playgroundTabModel) waitForChunk() tea.Msg {
+	chunk, ok := <-m.chunks
+	if !ok {
+		return playgroundDoneMsg{err: <-m.errs}
+	}
+	return playgroundChunkMsg(chunk)
+}
+
+// capturingInput reports whether key presses should go to the tab rather than global shortcuts.
+func (m playgroundTabModel) capturingInput() bool {
+	return m.prompt.Focused() || m.picker != pickerNone
+}
+
+func (m playgroundTabModel) Update(msg tea.Msg) (playgroundTabModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case localeChangedMsg:
+		m.refresh()
+		return m, nil
+	case playgroundListsMsg:
+		m.listErr = msg.err
+		m.models = msg.models
+		m.auths = msg.auths
+		if m.model == "" && len(m.models) > 0 {
+			m.model = m.models[0]
+		}
+		m.refresh()
+		return m, nil
+	case playgroundChunkMsg:
+		m.applyChunk(playgroundChunk(msg))
+		m.refresh()
+		return m, m.waitForChunk
+	case playgroundDoneMsg:
+		m.running = false
+		m.finished = time.Now()
+		if m.cancel != nil {
+			m.cancel()
+			m.cancel = nil
+		}
+		if msg.err != nil && msg.err != context.Canceled {
+			m.err = msg.err
+		}
+		m.refresh()
+		return m, nil
+	case tea.KeyMsg:
+		switch {
+		case m.picker != pickerNone:
+			return m.updatePicker(msg)
+		case m.prompt.Focused():
+			return m.updatePrompt(msg)
+		}
+		switch msg.String() {
+		case "enter", "i":
+			m.prompt.Focus()
+			m.refresh()
+			return m, textinput.Blink
+		case "m":
+			m.openPicker(pickerModel)
+			return m, nil
+		case "a":
+			m.openPicker(pickerAuth)
+			return m, nil
+		case "r":
+			return m, m.fetchLists
+		case "c":
+			if !m.running {
+				m.resetOutput()
+				m.refresh()
+			}
+			return m, nil
+		case "esc":
+			if m.running && m.cancel != nil {
+				m.cancel()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m playgroundTabModel) updatePrompt(msg tea.KeyMsg) (playgroundTabModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.prompt.Blur()
+		m.refresh()
+		return m, nil
+	case "enter":
+		text := strings.TrimSpace(m.prompt.Value())
+		if text == "" || m.running || m.model == "" {
+			return m, nil
+		}
+		m.prompt.Blur()
+		return m.send(text)
+	}
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	m.refresh()
+	return m, cmd
+}
+
+// send starts a streaming chat for text on the selected model and credential.
+func (m playgroundTabModel) send(text string) (playgroundTabModel, tea.Cmd) {
+	m.resetOutput()
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks := make(chan playgroundChunk, 64)
+	errs := make(chan error, 1)
+	client, model, authID := m.client, m.model, m.auth.id
+	go func() {
+		errs <- client.StreamPlaygroundChat(ctx, model, authID, text, chunks)
+		close(chunks)
+	}()
+	m.running = true
+	m.cancel = cancel
+	m.chunks = chunks
+	m.errs = errs
+	m.started = time.Now()
+	m.refresh()
+	return m, m.waitForChunk
+}
+
+func (m *playgroundTabModel) resetOutput() {
+	m.served = nil
+	m.reasoning = ""
+	m.content = ""
+	m.usage = nil
+	m.err = nil
+	m.started = time.Time{}
+	m.firstToken = time.Time{}
+	m.finished = time.Time{}
+}
+
+func (m *playgroundTabModel) applyChunk(chunk playgroundChunk) {
+	switch {
+	case chunk.served != nil:
+		m.served = chunk.served
+	case chunk.usage != nil:
+		m.usage = chunk.usage
+	default:
+		if m.firstToken.IsZero() {
+			m.firstToken = time.Now()
+		}
+		m.reasoning += chunk.reasoning
+		m.content += chunk.content
+	}
+}
+
+func (m *playgroundTabModel) openPicker(picker playgroundPicker) {
+	m.picker = picker
+	m.pickerFilter = ""
+	m.pickerCursor = 0
+	m.refresh()
+}
+
+// pickerItems returns the labels of the open picker matching the filter, with the values they
+// select. The auth picker always offers automatic selection first.
+func (m playgroundTabModel) pickerItems() (labels []string, values []playgroundAuth) {
+	filter := strings.ToLower(m.pickerFilter)
+	if m.picker == pickerModel {
+		for _, model := range m.models {
+			if strings.Contains(strings.ToLower(model), filter) {
+				labels = append(labels, model)
+				values = append(values, playgroundAuth{id: model})
+			}
+		}
+		return labels, values
+	}
+	if filter == "" {
+		labels = append(labels, T("playground_auth_auto"))
+		values = append(values, playgroundAuth{})
+	}
+	for _, auth := range m.auths {
+		if strings.Contains(strings.ToLower(auth.label), filter) {
+			labels = append(labels, auth.label)
+			values = append(values, auth)
+		}
+	}
+	return labels, values
+}
+
+func (m playgroundTabModel) updatePicker(msg tea.KeyMsg) (playgroundTabModel, tea.Cmd) {
+	labels, values := m.pickerItems()
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.picker = pickerNone
+	case tea.KeyUp:
+		if m.pickerCursor > 0 {
+			m.pickerCursor--
+		}
+	case tea.KeyDown:
+		if m.pickerCursor < len(labels)-1 {
+			m.pickerCursor++
+		}
+	case tea.KeyEnter:
+		if m.pickerCursor < len(values) {
+			if m.picker == pickerModel {
+				m.model = values[m.pickerCursor].id
+			} else {
+				m.auth = values[m.pickerCursor]
+			}
+		}
+		m.picker = pickerNone
+	case tea.KeyBackspace:
+		if m.pickerFilter != "" {
+			runes := []rune(m.pickerFilter)
+			m.pickerFilter = string(runes[:len(runes)-1])
+			m.pickerCursor = 0
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.pickerFilter += string(msg.Runes)
+		m.pickerCursor = 0
+	}
+	m.refresh()
+	return m, nil
+}
+
+func (m *playgroundTabModel) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.prompt.Width = w - 4
+	vpHeight := h - playgroundHeaderLines
+	if vpHeight < 1 {
+		vpHeight = 1
+	}
+	if !m.ready {
+		m.viewport = viewport.New(w, vpHeight)
+		m.ready = true
+	} else {
+		m.viewport.Width = w
+		m.viewport.Height = vpHeight
+	}
+	m.refresh()
+}
+
+// refresh re-renders the viewport content, following the output while a reply streams in.
+func (m *playgroundTabModel) refresh() {
+	if !m.ready {
+		return
+	}
+	m.viewport.SetContent(m.renderBody())
+	if m.running {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m playgroundTabModel) View() string {
+	if !m.ready {
+		return T("loading")
+	}
+	var sb strings.Builder
+	model := m.model
+	if model == "" {
+		model = "-"
+	}
+	auth := m.auth.label
+	if m.auth.id == "" {
+		auth = T("playground_auth_auto")
+	}
+	sb.WriteString(titleStyle.Render(fmt.Sprintf(" %s  %s: %s  %s: %s",
+		T("playground_title"), T("playground_model"), model, T("playground_auth"), auth)))
+	sb.WriteString("\n")
+	if m.prompt.Focused() {
+		sb.WriteString(helpStyle.Render(T("playground_help_input")))
+	} else {
+		sb.WriteString(helpStyle.Render(T("playground_help")))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("─", m.width))
+	sb.WriteString("\n")
+	sb.WriteString(m.prompt.View())
+	sb.WriteString("\n")
+	sb.WriteString(m.renderStatus())
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("─", m.width))
+	sb.WriteString("\n")
+	sb.WriteString(m.viewport.View())
+	return sb.String()
+}
+
+// renderStatus summarises the last request on a single line.
+func (m playgroundTabModel) renderStatus() string {
+	if m.started.IsZero() {
+		return subtitleStyle.Render(T("playground_idle"))
+	}
+	parts := make([]string, 0, 4)
+	if m.served != nil && m.served.auth != "" {
+		served := m.served.auth
+		details := make([]string, 0, 2)
+		if m.served.provider != "" {
+			details = append(details, m.served.provider)
+		}
+		if m.served.account != "" {
+			details = append(details, m.served.account)
+		}
+		if len(details) > 0 {
+			served += " (" + strings.Join(details, ", ") + ")"
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", T("playground_served_by"), served))
+	}
+	if !m.firstToken.IsZero() {
+		parts = append(parts, fmt.Sprintf("%s: %s", T("playground_first_token"), m.firstToken.Sub(m.started).Round(time.Millisecond)))
+	}
+	end := m.finished
+	if m.running {
+		end = time.Now()
+	}
+	parts = append(parts, fmt.Sprintf("%s: %s", T("playground_total"), end.Sub(m.started).Round(time.Millisecond)))
+	if m.usage != nil {
+		parts = append(parts, fmt.Sprintf("%s: %d/%d/%d (%s %d)", T("playground_tokens"),
+			m.usage.prompt, m.usage.completion, m.usage.total, T("usage_reasoning"), m.usage.reasoning))
+	}
+	line := " " + strings.Join(parts, " • ")
+	switch {
+	case m.running:
+		return warningStyle.Render("● " + T("playground_running") + line)
+	case m.err != nil:
+		return errorStyle.Render("✗" + line)
+	default:
+		return successStyle.Render("✓" + line)
+	}
+}
+
+func (m playgroundTabModel) renderBody() string {
+	if m.picker != pickerNone {
+		return m.renderPicker()
+	}
+	var sb strings.Builder
+	if m.listErr != nil {
+		sb.WriteString(errorStyle.Render("⚠ " + m.listErr.Error()))
+		sb.WriteString("\n")
+	}
+	if m.reasoning != "" {
+		sb.WriteString(labelStyle.Render(T("playground_reasoning")))
+		sb.WriteString("\n")
+		sb.WriteString(logDebugStyle.Render(wrapParagraphs(m.reasoning, m.width)))
+		sb.WriteString("\n\n")
+	}
+	if m.content != "" {
+		sb.WriteString(wrapParagraphs(m.content, m.width))
+		sb.WriteString("\n")
+	}
+	if m.err != nil {
+		sb.WriteString("\n")
+		sb.WriteString(errorStyle.Render("⚠ " + m.err.Error()))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func (m playgroundTabModel) renderPicker() string {
+	var sb strings.Builder
+	title := T("playground_pick_model")
+	if m.picker == pickerAuth {
+		title = T("playground_pick_auth")
+	}
+	sb.WriteString(labelStyle.Render(title))
+	sb.WriteString("  ")
+	sb.WriteString(helpStyle.Render(T("playground_pick_help")))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("  %s: %s\n", T("playground_filter"), m.pickerFilter))
+
+	labels, _ := m.pickerItems()
+	if len(labels) == 0 {
+		sb.WriteString(subtitleStyle.Render("  " + T("playground_no_match")))
+		return sb.String()
+	}
+	// Keep the cursor visible by showing a window of items around it.
+	rows := m.viewport.Height - 2
+	if rows < 1 {
+		rows = 1
+	}
+	start := 0
+	if m.pickerCursor >= rows {
+		start = m.pickerCursor - rows + 1
+	}
+	for i := start; i < len(labels) && i < start+rows; i++ {
+		if i == m.pickerCursor {
+			sb.WriteString(tableSelectedStyle.Render("▸ " + labels[i]))
+		} else {
+			sb.WriteString("  " + labels[i])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// wrapParagraphs hard-wraps s to width columns, preserving existing line breaks.
+func wrapParagraphs(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	var sb strings.Builder
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		runes := []rune(line)
+		for len(runes) > width {
+			sb.WriteString(string(runes[:width]))
+			sb.WriteString("\n")
+			runes = runes[width:]
+		}
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
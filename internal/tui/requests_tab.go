@@ -0,0 +1,605 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// requestLogListLimit is how many request logs the browser fetches at once.
+const requestLogListLimit = 200
+
+// requestsSideBySideWidth is the narrowest terminal that shows the detail columns side by side.
+const requestsSideBySideWidth = 100
+
+var (
+	searchMatchStyle     = lipgloss.NewStyle().Reverse(true)
+	requestsHeadingStyle = lipgloss.NewStyle().Foreground(colorInfo).Bold(true)
+)
+
+// requestsTabModel browses request logs written by the request logger and inspects one at a
+// time, with the client and upstream sides of the exchange shown next to each other.
+type requestsTabModel struct {
+	client   *Client
+	viewport viewport.Model
+	width    int
+	height   int
+	ready    bool
+
+	files  []map[string]any
+	cursor int
+	err    error
+
+	filterInput textinput.Model
+	filter      string
+
+	// Detail view state; detail is nil while the list is shown.
+	detail     *requestLogDetail
+	detailName string
+	detailErr  error
+
+	searchInput textinput.Model
+	search      string
+	matches     []int // detail rows containing the search term
+	matchIdx    int
+}
+
+type requestLogsMsg struct {
+	files []map[string]any
+	err   error
+}
+
+type requestLogDetailMsg struct {
+	name   string
+	detail *requestLogDetail
+	err    error
+}
+
+// detailLine is one line of a detail column before wrapping.
+type detailLine struct {
+	text    string
+	heading bool
+}
+
+func newRequestsTabModel(client *Client) requestsTabModel {
+	filter := textinput.New()
+	filter.CharLimit = 256
+	filter.Prompt = "  " + T("requests_filter") + ": "
+	filter.Placeholder = "status:5xx model:gpt path:/v1/messages since:1h"
+	search := textinput.New()
+	search.CharLimit = 256
+	search.Prompt = "  / "
+	return requestsTabModel{
+		client:      client,
+		filterInput: filter,
+		searchInput: search,
+	}
+}
+
+func (m requestsTabModel) Init() tea.Cmd {
+	return m.fetchLogs
+}
+
+func (m requestsTabModel) fetchLogs() tea.Msg {
+	files, err := m.client.ListRequestLogs(parseRequestFilter(m.filter), requestLogListLimit)
+	return requestLogsMsg{files: files, err: err}
+}
+
+func (m requestsTabModel) fetchDetail(name string) tea.Cmd {
+	return func() tea.Msg {
+		detail, err := m.client.GetRequestLog(name)
+		return requestLogDetailMsg{name: name, detail: detail, err: err}
+	}
+}
+
+// parseRequestFilter turns "status:5xx model:gpt since:1h" into management query parameters.
+// Words without a known key filter by path.
+func parseRequestFilter(text string) map[string]string {
+	filters := make(map[string]string)
+	for _, word := range strings.Fields(text) {
+		key, value, ok := strings.Cut(word, ":")
+		switch {
+		case ok && (key == "status" || key == "model" || key == "path" || key == "since" || key == "until"):
+			filters[key] = value
+		default:
+			filters["path"] = word
+		}
+	}
+	return filters
+}
+
+// capturingInput reports whether key presses should go to the tab rather than global shortcuts.
+func (m requestsTabModel) capturingInput() bool {
+	return m.filterInput.Focused() || m.searchInput.Focused()
+}
+
+func (m requestsTabModel) Update(msg tea.Msg) (requestsTabModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case localeChangedMsg:
+		m.filterInput.Prompt = "  " + T("requests_filter") + ": "
+		m.refresh()
+		return m, nil
+	case requestLogsMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.files = msg.files
+			if m.cursor >= len(m.files) {
+				m.cursor = max(0, len(m.files)-1)
+			}
+		}
+		m.refresh()
+		return m, nil
+	case requestLogDetailMsg:
+		if msg.name != m.detailName {
+			return m, nil
+		}
+		m.detail = msg.detail
+		m.detailErr = msg.err
+		m.matches = nil
+		m.refresh()
+		m.viewport.GotoTop()
+		return m, nil
+	case tea.KeyMsg:
+		switch {
+		case m.filterInput.Focused():
+			return m.updateFilter(msg)
+		case m.searchInput.Focused():
+			return m.updateSearch(msg)
+		case m.detailName != "":
+			return m.updateDetail(msg)
+		}
+		return m.updateList(msg)
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m requestsTabModel) updateList(msg tea.KeyMsg) (requestsTabModel, tea.Cmd) {
+	switch msg.String() {
+	case "j", "down":
+		if len(m.files) > 0 {
+			m.cursor = (m.cursor + 1) % len(m.files)
+			m.refresh()
+			m.followCursor()
+		}
+		return m, nil
+	case "k", "up":
+		if len(m.files) > 0 {
+			m.cursor = (m.cursor - 1 + len(m.files)) % len(m.files)
+			m.refresh()
+			m.followCursor()
+		}
+		return m, nil
+	case "enter":
+		if m.cursor < len(m.files) {
+			m.detailName = getString(m.files[m.cursor], "name")
+			m.detail = nil
+			m.detailErr = nil
+			m.refresh()
+			return m, m.fetchDetail(m.detailName)
+		}
+		return m, nil
+	case "f", "/":
+		m.filterInput.SetValue(m.filter)
+		m.filterInput.Focus()
+		m.refresh()
+		return m, textinput.Blink
+	case "r":
+		return m, m.fetchLogs
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m requestsTabModel) updateDetail(msg tea.KeyMsg) (requestsTabModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "backspace":
+		m.detailName = ""
+		m.detail = nil
+		m.detailErr = nil
+		m.search = ""
+		m.matches = nil
+		m.refresh()
+		m.followCursor()
+		return m, nil
+	case "/":
+		m.searchInput.SetValue(m.search)
+		m.searchInput.Focus()
+		m.refresh()
+		return m, textinput.Blink
+	case "n":
+		m.jumpToMatch(1)
+		return m, nil
+	case "N":
+		m.jumpToMatch(-1)
+		return m, nil
+	case "r":
+		return m, m.fetchDetail(m.detailName)
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m requestsTabModel) updateFilter(msg tea.KeyMsg) (requestsTabModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.filter = strings.TrimSpace(m.filterInput.Value())
+		m.filterInput.Blur()
+		m.cursor = 0
+		m.refresh()
+		return m, m.fetchLogs
+	case "esc":
+		m.filterInput.Blur()
+		m.refresh()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	m.refresh()
+	return m, cmd
+}
+
+func (m requestsTabModel) updateSearch(msg tea.KeyMsg) (requestsTabModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.search = strings.TrimSpace(m.searchInput.Value())
+		m.searchInput.Blur()
+		m.matchIdx = -1
+		m.refresh()
+		m.jumpToMatch(1)
+		return m, nil
+	case "esc":
+		m.searchInput.Blur()
+		m.refresh()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	m.refresh()
+	return m, cmd
+}
+
+// jumpToMatch scrolls to the next (dir > 0) or previous search match, wrapping around.
+func (m *requestsTabModel) jumpToMatch(dir int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.matchIdx = (m.matchIdx + dir + len(m.matches)) % len(m.matches)
+	m.refresh()
+	m.viewport.SetYOffset(m.matches[m.matchIdx])
+}
+
+// followCursor keeps the selected list row inside the viewport.
+func (m *requestsTabModel) followCursor() {
+	row := m.cursor + requestsListHeaderRows
+	switch {
+	case row < m.viewport.YOffset:
+		m.viewport.SetYOffset(row)
+	case row >= m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(row - m.viewport.Height + 1)
+	}
+}
+
+func (m *requestsTabModel) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.filterInput.Width = w - 20
+	m.searchInput.Width = w - 10
+	if !m.ready {
+		m.viewport = viewport.New(w, h)
+		m.ready = true
+	} else {
+		m.viewport.Width = w
+		m.viewport.Height = h
+	}
+	m.refresh()
+}
+
+func (m *requestsTabModel) refresh() {
+	if !m.ready {
+		return
+	}
+	if m.detailName != "" {
+		m.viewport.SetContent(m.renderDetail())
+		return
+	}
+	m.viewport.SetContent(m.renderList())
+}
+
+func (m requestsTabModel) View() string {
+	if !m.ready {
+		return T("loading")
+	}
+	return m.viewport.View()
+}
+
+// requestsListHeaderRows is the number of rows renderList writes before the first log row.
+const requestsListHeaderRows = 7
+
+func (m requestsTabModel) renderList() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render(T("requests_title")))
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render(T("requests_help")))
+	sb.WriteString("\n")
+	if m.filterInput.Focused() {
+		sb.WriteString(m.filterInput.View())
+	} else {
+		filter := m.filter
+		if filter == "" {
+			filter = T("requests_filter_none")
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %s", T("requests_filter"), filter))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("─", m.width))
+	sb.WriteString("\n")
+	sb.WriteString(tableHeaderStyle.Render(fmt.Sprintf("  %-15s %-6s %-6s %-28s %-32s %s",
+		T("requests_col_time"), T("requests_col_status"), T("requests_col_method"),
+		T("requests_col_model"), T("requests_col_path"), T("requests_col_attempts"))))
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("─", m.width))
+	sb.WriteString("\n")
+
+	if m.err != nil {
+		sb.WriteString(errorStyle.Render(T("error_prefix") + m.err.Error()))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+	if len(m.files) == 0 {
+		sb.WriteString(subtitleStyle.Render(T("requests_empty")))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
+	for i, f := range m.files {
+		ts := getString(f, "timestamp")
+		if parsed, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			ts = parsed.Local().Format("01-02 15:04:05")
+		}
+		status := int(getFloat(f, "status"))
+		statusText := fmt.Sprintf("%-6d", status)
+		if status == 0 {
+			statusText = fmt.Sprintf("%-6s", "-")
+		}
+		switch {
+		case status >= 400 || getBool(f, "error"):
+			statusText = errorStyle.Render(statusText)
+		case status >= 200:
+			statusText = successStyle.Render(statusText)
+		}
+		attempts := int(getFloat(f, "attempts"))
+		row := fmt.Sprintf("%-15s %s %-6s %-28s %-32s %d",
+			ts, statusText, getString(f, "method"),
+			truncate(getString(f, "model"), 28), truncate(getString(f, "url"), 32), attempts)
+		if i == m.cursor {
+			sb.WriteString(tableSelectedStyle.Render("▸ " + row))
+		} else {
+			sb.WriteString("  " + row)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func (m *requestsTabModel) renderDetail() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render(T("requests_detail_title") + " " + m.detailName))
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render(T("requests_detail_help")))
+	sb.WriteString("\n")
+	if m.searchInput.Focused() {
+		sb.WriteString(m.searchInput.View())
+		sb.WriteString("\n")
+	} else if m.search != "" {
+		pos := 0
+		if len(m.matches) > 0 {
+			pos = m.matchIdx + 1
+		}
+		sb.WriteString(fmt.Sprintf("  / %s  (%d/%d)\n", m.search, pos, len(m.matches)))
+	}
+	if m.detailErr != nil {
+		sb.WriteString(errorStyle.Render(T("error_prefix") + m.detailErr.Error()))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+	d := m.detail
+	if d == nil {
+		sb.WriteString(T("loading"))
+		return sb.String()
+	}
+
+	statusStyle := successStyle
+	if d.Status >= 400 {
+		statusStyle = errorStyle
+	}
+	sb.WriteString(fmt.Sprintf("  %s %s  %s %s  %s %s  %s %s\n",
+		requestsHeadingStyle.Render(d.Method), valueStyle.Render(d.URL),
+		requestsHeadingStyle.Render(T("requests_col_model")+":"), valueStyle.Render(d.Model),
+		requestsHeadingStyle.Render(T("requests_col_status")+":"), statusStyle.Render(fmt.Sprint(d.Status)),
+		requestsHeadingStyle.Render(T("requests_col_time")+":"), valueStyle.Render(d.Timestamp.Local().Format(time.DateTime))))
+	sb.WriteString(strings.Repeat("─", m.width))
+	sb.WriteString("\n")
+	headerRows := strings.Count(sb.String(), "\n")
+
+	rows := m.detailRows(d)
+	m.matches = m.matches[:0]
+	needle := strings.ToLower(m.search)
+	for i, row := range rows {
+		if needle != "" && strings.Contains(strings.ToLower(row.plain), needle) {
+			m.matches = append(m.matches, headerRows+i)
+		}
+		sb.WriteString(row.rendered)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// detailRow is one rendered row of the detail view and its unstyled text for searching.
+type detailRow struct {
+	plain    string
+	rendered string
+}
+
+// detailRows lays out the client side (original request, final response) and the upstream side
+// (translated requests, response chunks) next to each other, or stacked on narrow terminals.
+func (m requestsTabModel) detailRows(d *requestLogDetail) []detailRow {
+	client := []detailLine{{text: T("requests_client_request"), heading: true}}
+	client = append(client, prettyLines(d.RequestBody)...)
+	client = append(client, detailLine{}, detailLine{text: T("requests_client_response"), heading: true})
+	client = append(client, prettyLines(d.ResponseBody)...)
+
+	var upstream []detailLine
+	for _, a := range d.Attempts {
+		title := fmt.Sprintf("%s %d", T("requests_attempt"), a.Index)
+		if a.Status > 0 {
+			title += fmt.Sprintf(" • %d", a.Status)
+		}
+		if a.Auth != "" {
+			title += " • " + a.Auth
+		}
+		upstream = append(upstream, detailLine{text: title, heading: true})
+		if a.UpstreamURL != "" {
+			upstream = append(upstream, detailLine{text: a.UpstreamURL})
+		}
+		upstream = append(upstream, detailLine{text: T("requests_upstream_request") + ":"})
+		upstream = append(upstream, prettyLines(a.RequestBody)...)
+		upstream = append(upstream, detailLine{text: fmt.Sprintf("%s (%d):", T("requests_upstream_chunks"), len(a.ResponseChunks))})
+		for _, chunk := range a.ResponseChunks {
+			upstream = append(upstream, prettyLines(chunk)...)
+		}
+		for _, e := range a.Errors {
+			upstream = append(upstream, detailLine{text: "✗ " + e})
+		}
+		upstream = append(upstream, detailLine{})
+	}
+	for _, e := range d.Errors {
+		upstream = append(upstream, detailLine{text: fmt.Sprintf("✗ %d %s", e.Status, e.Message)})
+	}
+	if len(upstream) == 0 {
+		upstream = append(upstream, detailLine{text: T("requests_no_upstream")})
+	}
+
+	if m.width < requestsSideBySideWidth {
+		left := m.wrapColumn(client, m.width)
+		right := m.wrapColumn(upstream, m.width)
+		return append(append(left, detailRow{}), right...)
+	}
+
+	colWidth := (m.width - 3) / 2
+	left := m.wrapColumn(client, colWidth)
+	right := m.wrapColumn(upstream, colWidth)
+	n := max(len(left), len(right))
+	rows := make([]detailRow, 0, n)
+	for i := 0; i < n; i++ {
+		var l, r detailRow
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		pad := colWidth - lipgloss.Width(l.rendered)
+		if pad < 0 {
+			pad = 0
+		}
+		rows = append(rows, detailRow{
+			plain:    l.plain + " " + r.plain,
+			rendered: l.rendered + strings.Repeat(" ", pad) + " │ " + r.rendered,
+		})
+	}
+	return rows
+}
+
+// wrapColumn wraps lines to width and applies heading and search-match styling.
+func (m requestsTabModel) wrapColumn(lines []detailLine, width int) []detailRow {
+	var rows []detailRow
+	for _, line := range lines {
+		for _, piece := range strings.Split(wrapParagraphs(line.text, width), "\n") {
+			rendered := highlightMatches(piece, m.search)
+			if line.heading {
+				rendered = requestsHeadingStyle.Render(rendered)
+			}
+			rows = append(rows, detailRow{plain: piece, rendered: rendered})
+		}
+	}
+	return rows
+}
+
+// highlightMatches marks case-insensitive occurrences of term in s.
+func highlightMatches(s, term string) string {
+	if term == "" {
+		return s
+	}
+	lower := strings.ToLower(s)
+	needle := strings.ToLower(term)
+	if len(lower) != len(s) {
+		return s
+	}
+	var sb strings.Builder
+	for {
+		idx := strings.Index(lower, needle)
+		if idx < 0 {
+			sb.WriteString(s)
+			return sb.String()
+		}
+		sb.WriteString(s[:idx])
+		sb.WriteString(searchMatchStyle.Render(s[idx : idx+len(needle)]))
+		s = s[idx+len(needle):]
+		lower = lower[idx+len(needle):]
+	}
+}
+
+// prettyLines pretty-prints a JSON body, or each JSON payload of an SSE body, and splits it into lines.
+func prettyLines(body string) []detailLine {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return []detailLine{{text: "<empty>"}}
+	}
+	var out []detailLine
+	for _, line := range strings.Split(indentJSON(body), "\n") {
+		prefix, payload := "", line
+		for _, p := range []string{"data: ", "data:"} {
+			if strings.HasPrefix(line, p) {
+				prefix, payload = p, strings.TrimPrefix(line, p)
+				break
+			}
+		}
+		pretty := indentJSON(payload)
+		if pretty == payload {
+			out = append(out, detailLine{text: line})
+			continue
+		}
+		for i, l := range strings.Split(pretty, "\n") {
+			if i == 0 {
+				l = prefix + l
+			}
+			out = append(out, detailLine{text: l})
+		}
+	}
+	return out
+}
+
+// indentJSON returns s indented when it is a JSON object or array, and s unchanged otherwise.
+func indentJSON(s string) string {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return s
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(trimmed), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
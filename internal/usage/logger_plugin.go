@@ -94,6 +94,11 @@ type RequestDetail struct {
 	AuthIndex string     `json:"auth_index"`
 	Tokens    TokenStats `json:"tokens"`
 	Failed    bool       `json:"failed"`
+	// SplitAlias and SplitTarget identify the weighted alias arm that served the request.
+	SplitAlias  string `json:"split_alias,omitempty"`
+	SplitTarget string `json:"split_target,omitempty"`
+	// Hedge marks the duplicate attempt of a hedged request.
+	Hedge bool `json:"hedge,omitempty"`
 }
 
 // TokenStats captures the token usage breakdown for a request.
@@ -197,11 +202,14 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 		s.apis[statsKey] = stats
 	}
 	s.updateAPIStats(stats, modelName, RequestDetail{
-		Timestamp: timestamp,
-		Source:    record.Source,
-		AuthIndex: record.AuthIndex,
-		Tokens:    detail,
-		Failed:    failed,
+		Timestamp:   timestamp,
+		Source:      record.Source,
+		AuthIndex:   record.AuthIndex,
+		Tokens:      detail,
+		Failed:      failed,
+		SplitAlias:  record.SplitAlias,
+		SplitTarget: record.SplitTarget,
+		Hedge:       record.Hedge,
 	})
 
 	s.requestsByDay[dayKey]++
@@ -0,0 +1,78 @@
+package usage
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logstream"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func init() {
+	coreusage.RegisterPlugin(NewStreamPlugin(logstream.Default()))
+}
+
+// StreamPlugin publishes a request summary event for every usage record so live log
+// subscribers can follow traffic as it happens.
+type StreamPlugin struct {
+	broker *logstream.Broker
+}
+
+// NewStreamPlugin creates a plugin publishing to broker.
+func NewStreamPlugin(broker *logstream.Broker) *StreamPlugin {
+	return &StreamPlugin{broker: broker}
+}
+
+// HandleUsage implements coreusage.Plugin.
+func (p *StreamPlugin) HandleUsage(ctx context.Context, record coreusage.Record) {
+	if p == nil || p.broker == nil {
+		return
+	}
+	p.broker.Publish(requestEvent(ctx, record))
+}
+
+func requestEvent(ctx context.Context, record coreusage.Record) logstream.Event {
+	detail := normaliseDetail(record.Detail)
+	e := logstream.Event{
+		Type:      logstream.TypeRequest,
+		Time:      time.Now(),
+		Provider:  record.Provider,
+		Model:     record.Model,
+		AuthIndex: record.AuthIndex,
+		ClientKey: util.HideAPIKey(record.APIKey),
+		Failed:    record.Failed,
+		Tokens: &logstream.Tokens{
+			Input:     detail.InputTokens,
+			Output:    detail.OutputTokens,
+			Reasoning: detail.ReasoningTokens,
+			Cached:    detail.CachedTokens,
+			Total:     detail.TotalTokens,
+		},
+	}
+	e.SetClientKey(record.APIKey)
+	if !record.RequestedAt.IsZero() {
+		e.LatencyMs = time.Since(record.RequestedAt).Milliseconds()
+	}
+	e.RequestID = logging.GetRequestID(ctx)
+	if ctx != nil {
+		if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+			if e.RequestID == "" {
+				e.RequestID = logging.GetGinRequestID(ginCtx)
+			}
+			e.Status = ginCtx.Writer.Status()
+		}
+	}
+	switch {
+	case e.Failed || e.Status >= http.StatusInternalServerError:
+		e.Level = "error"
+	case e.Status >= http.StatusBadRequest:
+		e.Level = "warning"
+	default:
+		e.Level = "info"
+	}
+	return e
+}
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
@@ -89,8 +90,10 @@ func (w *Watcher) reloadClients(rescanAuth bool, affectedOAuthProviders []string
 						w.lastAuthHashes[normalizedPath] = hex.EncodeToString(sum[:])
 						// Parse and cache auth content for future diff comparisons
 						var auth coreauth.Auth
-						if errParse := json.Unmarshal(data, &auth); errParse == nil {
-							w.lastAuthContents[normalizedPath] = &auth
+						if plain, errOpen := authcrypt.Open(data); errOpen == nil {
+							if errParse := json.Unmarshal(plain, &auth); errParse == nil {
+								w.lastAuthContents[normalizedPath] = &auth
+							}
 						}
 					}
 				}
@@ -136,8 +139,13 @@ func (w *Watcher) addOrUpdateClient(path string) {
 	normalized := w.normalizeAuthPath(path)
 
 	// Parse new auth content for diff comparison
+	plain, errOpen := authcrypt.Open(data)
+	if errOpen != nil {
+		log.Errorf("failed to decrypt auth file %s: %v", filepath.Base(path), errOpen)
+		return
+	}
 	var newAuth coreauth.Auth
-	if errParse := json.Unmarshal(data, &newAuth); errParse != nil {
+	if errParse := json.Unmarshal(plain, &newAuth); errParse != nil {
 		log.Errorf("failed to parse auth file %s: %v", filepath.Base(path), errParse)
 		return
 	}
@@ -81,6 +81,25 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.Routing.Strategy != newCfg.Routing.Strategy {
 		changes = append(changes, fmt.Sprintf("routing.strategy: %s -> %s", oldCfg.Routing.Strategy, newCfg.Routing.Strategy))
 	}
+	if oldCfg.CircuitBreaker != newCfg.CircuitBreaker {
+		changes = append(changes, fmt.Sprintf("circuit-breaker: enable %t -> %t, failure-threshold %d -> %d, open-seconds %d -> %d", oldCfg.CircuitBreaker.Enable, newCfg.CircuitBreaker.Enable, oldCfg.CircuitBreaker.FailureThreshold, newCfg.CircuitBreaker.FailureThreshold, oldCfg.CircuitBreaker.OpenSeconds, newCfg.CircuitBreaker.OpenSeconds))
+	}
+	if !reflect.DeepEqual(oldCfg.Hedging, newCfg.Hedging) {
+		changes = append(changes, fmt.Sprintf("hedging: enable %t -> %t, models %d -> %d, budget-ratio %g -> %g", oldCfg.Hedging.Enable, newCfg.Hedging.Enable, len(oldCfg.Hedging.Models), len(newCfg.Hedging.Models), oldCfg.Hedging.BudgetRatio, newCfg.Hedging.BudgetRatio))
+	}
+	if !reflect.DeepEqual(oldCfg.Tracing, newCfg.Tracing) {
+		changes = append(changes, fmt.Sprintf("tracing: enable %t -> %t, endpoint %s -> %s, sample-ratio %g -> %g", oldCfg.Tracing.Enable, newCfg.Tracing.Enable, oldCfg.Tracing.Endpoint, newCfg.Tracing.Endpoint, oldCfg.Tracing.SampleRatio, newCfg.Tracing.SampleRatio))
+	}
+	if !reflect.DeepEqual(oldCfg.HealthCheck, newCfg.HealthCheck) {
+		changes = append(changes, fmt.Sprintf("health-check: enable %t -> %t, interval-seconds %d -> %d, providers %d -> %d", oldCfg.HealthCheck.Enable, newCfg.HealthCheck.Enable, oldCfg.HealthCheck.IntervalSeconds, newCfg.HealthCheck.IntervalSeconds, len(oldCfg.HealthCheck.Providers), len(newCfg.HealthCheck.Providers)))
+	}
+	if !reflect.DeepEqual(oldCfg.Plugins, newCfg.Plugins) {
+		// Plugin env and account attributes may hold secrets; only report the shape of the change.
+		changes = append(changes, fmt.Sprintf("plugins: updated (%d -> %d entries)", len(oldCfg.Plugins), len(newCfg.Plugins)))
+	}
+	if oldCfg.Routing.SessionAffinity != newCfg.Routing.SessionAffinity {
+		changes = append(changes, fmt.Sprintf("routing.session-affinity: enable %t -> %t, ttl-seconds %d -> %d", oldCfg.Routing.SessionAffinity.Enable, newCfg.Routing.SessionAffinity.Enable, oldCfg.Routing.SessionAffinity.TTLSeconds, newCfg.Routing.SessionAffinity.TTLSeconds))
+	}
 
 	// API keys (redacted) and counts
 	if len(oldCfg.APIKeys) != len(newCfg.APIKeys) {
@@ -88,6 +107,14 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	} else if !reflect.DeepEqual(trimStrings(oldCfg.APIKeys), trimStrings(newCfg.APIKeys)) {
 		changes = append(changes, "api-keys: values updated (count unchanged, redacted)")
 	}
+	if len(oldCfg.ClientKeyPolicies) != len(newCfg.ClientKeyPolicies) {
+		changes = append(changes, fmt.Sprintf("client-key-policies count: %d -> %d", len(oldCfg.ClientKeyPolicies), len(newCfg.ClientKeyPolicies)))
+	} else if !reflect.DeepEqual(oldCfg.ClientKeyPolicies, newCfg.ClientKeyPolicies) {
+		changes = append(changes, "client-key-policies: updated (count unchanged, redacted)")
+	}
+	if !reflect.DeepEqual(oldCfg.ModelSplits, newCfg.ModelSplits) {
+		changes = append(changes, fmt.Sprintf("model-splits: updated (%d -> %d entries)", len(oldCfg.ModelSplits), len(newCfg.ModelSplits)))
+	}
 	if len(oldCfg.GeminiKey) != len(newCfg.GeminiKey) {
 		changes = append(changes, fmt.Sprintf("gemini-api-key count: %d -> %d", len(oldCfg.GeminiKey), len(newCfg.GeminiKey)))
 	} else {
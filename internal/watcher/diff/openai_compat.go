@@ -75,6 +75,15 @@ func describeOpenAICompatibilityUpdate(oldEntry, newEntry config.OpenAICompatibi
 	if !equalStringMap(oldEntry.Headers, newEntry.Headers) {
 		details = append(details, "headers updated")
 	}
+	if oldEntry.DiscoverModels != newEntry.DiscoverModels {
+		details = append(details, fmt.Sprintf("discover-models %t -> %t", oldEntry.DiscoverModels, newEntry.DiscoverModels))
+	}
+	if oldEntry.DiscoverIntervalSeconds != newEntry.DiscoverIntervalSeconds {
+		details = append(details, fmt.Sprintf("discover-interval-seconds %d -> %d", oldEntry.DiscoverIntervalSeconds, newEntry.DiscoverIntervalSeconds))
+	}
+	if !equalStringSet(oldEntry.ExcludedModels, newEntry.ExcludedModels) {
+		details = append(details, "excluded-models updated")
+	}
 	if len(details) == 0 {
 		return ""
 	}
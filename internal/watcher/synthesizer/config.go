@@ -2,11 +2,14 @@ package synthesizer
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/plugin"
 )
 
 // ConfigSynthesizer generates Auth entries from configuration API keys.
@@ -35,6 +38,8 @@ func (s *ConfigSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth,
 	out = append(out, s.synthesizeOpenAICompat(ctx)...)
 	// Vertex-compat
 	out = append(out, s.synthesizeVertexCompat(ctx)...)
+	// Executor plugins
+	out = append(out, s.synthesizePlugins(ctx)...)
 
 	return out, nil
 }
@@ -70,6 +75,7 @@ func (s *ConfigSynthesizer) synthesizeGeminiKeys(ctx *SynthesisContext) []*corea
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(entry.Headers, attrs)
+		addConfigTransportToAttrs(entry.Transport, attrs)
 		a := &coreauth.Auth{
 			ID:         id,
 			Provider:   "gemini",
@@ -117,6 +123,7 @@ func (s *ConfigSynthesizer) synthesizeClaudeKeys(ctx *SynthesisContext) []*corea
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(ck.Headers, attrs)
+		addConfigTransportToAttrs(ck.Transport, attrs)
 		proxyURL := strings.TrimSpace(ck.ProxyURL)
 		a := &coreauth.Auth{
 			ID:         id,
@@ -167,6 +174,7 @@ func (s *ConfigSynthesizer) synthesizeCodexKeys(ctx *SynthesisContext) []*coreau
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(ck.Headers, attrs)
+		addConfigTransportToAttrs(ck.Transport, attrs)
 		proxyURL := strings.TrimSpace(ck.ProxyURL)
 		a := &coreauth.Auth{
 			ID:         id,
@@ -224,7 +232,11 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if hash := diff.ComputeOpenAICompatModelsHash(compat.Models); hash != "" {
 				attrs["models_hash"] = hash
 			}
+			if compat.DiscoverModels {
+				attrs["discover_models"] = "true"
+			}
 			addConfigHeadersToAttrs(compat.Headers, attrs)
+			addConfigTransportToAttrs(compat.Transport, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
 				Provider:   providerName,
@@ -236,6 +248,7 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 				CreatedAt:  now,
 				UpdatedAt:  now,
 			}
+			ApplyAuthExcludedModelsMeta(a, cfg, compat.ExcludedModels, "apikey")
 			out = append(out, a)
 			createdEntries++
 		}
@@ -255,7 +268,11 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if hash := diff.ComputeOpenAICompatModelsHash(compat.Models); hash != "" {
 				attrs["models_hash"] = hash
 			}
+			if compat.DiscoverModels {
+				attrs["discover_models"] = "true"
+			}
 			addConfigHeadersToAttrs(compat.Headers, attrs)
+			addConfigTransportToAttrs(compat.Transport, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
 				Provider:   providerName,
@@ -266,6 +283,7 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 				CreatedAt:  now,
 				UpdatedAt:  now,
 			}
+			ApplyAuthExcludedModelsMeta(a, cfg, compat.ExcludedModels, "apikey")
 			out = append(out, a)
 		}
 	}
@@ -304,6 +322,7 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(compat.Headers, attrs)
+		addConfigTransportToAttrs(compat.Transport, attrs)
 		a := &coreauth.Auth{
 			ID:         id,
 			Provider:   providerName,
@@ -320,3 +339,63 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 	}
 	return out
 }
+
+// synthesizePlugins creates Auth entries for the accounts of executor plugins.
+func (s *ConfigSynthesizer) synthesizePlugins(ctx *SynthesisContext) []*coreauth.Auth {
+	cfg := ctx.Config
+	now := ctx.Now
+	idGen := ctx.IDGenerator
+
+	out := make([]*coreauth.Auth, 0, len(cfg.Plugins))
+	for i := range cfg.Plugins {
+		entry := &cfg.Plugins[i]
+		accounts := entry.Accounts
+		if len(accounts) == 0 {
+			accounts = []config.ExecutorPluginAccount{{}}
+		}
+		for j := range accounts {
+			account := &accounts[j]
+			proxyURL := strings.TrimSpace(account.ProxyURL)
+			keys := make([]string, 0, len(account.Attributes))
+			for k := range account.Attributes {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			parts := []string{account.Label, proxyURL}
+			for _, k := range keys {
+				parts = append(parts, k+"="+account.Attributes[k])
+			}
+			id, token := idGen.Next("plugin:"+entry.Name, parts...)
+			attrs := map[string]string{
+				"source":                   fmt.Sprintf("config:plugin-%s[%s]", entry.Name, token),
+				plugin.AuthAttributePlugin: entry.Name,
+			}
+			if entry.Priority != 0 {
+				attrs["priority"] = strconv.Itoa(entry.Priority)
+			}
+			for _, k := range keys {
+				if key := strings.TrimSpace(k); key != "" {
+					attrs[plugin.AuthAttributePrefix+key] = account.Attributes[k]
+				}
+			}
+			label := strings.TrimSpace(account.Label)
+			if label == "" {
+				label = entry.Name
+			}
+			a := &coreauth.Auth{
+				ID:         id,
+				Provider:   entry.Name,
+				Label:      label,
+				Prefix:     entry.Prefix,
+				Status:     coreauth.StatusActive,
+				ProxyURL:   proxyURL,
+				Attributes: attrs,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			ApplyAuthExcludedModelsMeta(a, cfg, entry.ExcludedModels, "apikey")
+			out = append(out, a)
+		}
+	}
+	return out
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/vertex"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/authcrypt"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/geminicli"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 )
@@ -47,7 +49,7 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 			continue
 		}
 		full := filepath.Join(ctx.AuthDir, name)
-		data, errRead := os.ReadFile(full)
+		data, errRead := authcrypt.ReadFile(full)
 		if errRead != nil || len(data) == 0 {
 			continue
 		}
@@ -135,6 +137,18 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 				continue
 			}
 		}
+		if provider == "vertex" {
+			if virtuals := SynthesizeVertexRegionAuths(a, metadata, now); len(virtuals) > 0 {
+				modelLocations := vertex.ModelLocations(metadata)
+				for _, v := range virtuals {
+					excluded := append(vertex.ExcludedModelsForLocation(modelLocations, v.Attributes["vertex_virtual_location"]), perAccountExcluded...)
+					ApplyAuthExcludedModelsMeta(v, cfg, excluded, "oauth")
+				}
+				out = append(out, a)
+				out = append(out, virtuals...)
+				continue
+			}
+		}
 		out = append(out, a)
 	}
 	return out, nil
@@ -226,6 +240,72 @@ func SynthesizeGeminiVirtualAuths(primary *coreauth.Auth, metadata map[string]an
 	return virtuals
 }
 
+// SynthesizeVertexRegionAuths creates virtual Auth entries for Vertex service-account credentials
+// that list several regions. It disables the primary auth and creates one virtual auth per
+// region, so each region keeps its own cooldown and model state. Regions keep their order through
+// descending priorities starting at the primary's priority.
+func SynthesizeVertexRegionAuths(primary *coreauth.Auth, metadata map[string]any, now time.Time) []*coreauth.Auth {
+	if primary == nil || metadata == nil || primary.Disabled {
+		return nil
+	}
+	locations := vertex.Locations(metadata)
+	if len(locations) <= 1 {
+		return nil
+	}
+	primary.Disabled = true
+	primary.Status = coreauth.StatusDisabled
+	if primary.Attributes == nil {
+		primary.Attributes = make(map[string]string)
+	}
+	primary.Attributes["vertex_virtual_primary"] = "true"
+	primary.Attributes["virtual_children"] = strings.Join(locations, ",")
+	basePriority := 0
+	if raw := strings.TrimSpace(primary.Attributes["priority"]); raw != "" {
+		basePriority, _ = strconv.Atoi(raw)
+	}
+	label := primary.Label
+	if label == "" {
+		label = primary.Provider
+	}
+	virtuals := make([]*coreauth.Auth, 0, len(locations))
+	for i, location := range locations {
+		attrs := map[string]string{
+			"runtime_only":            "true",
+			"vertex_virtual_parent":   primary.ID,
+			"vertex_virtual_location": location,
+			"priority":                strconv.Itoa(basePriority - i),
+		}
+		if source := primary.Attributes["source"]; source != "" {
+			attrs["source"] = source
+		}
+		if authPath := primary.Attributes["path"]; authPath != "" {
+			attrs["path"] = authPath
+		}
+		metadataCopy := make(map[string]any, len(metadata)+2)
+		for key, value := range metadata {
+			metadataCopy[key] = value
+		}
+		delete(metadataCopy, "locations")
+		metadataCopy["location"] = location
+		metadataCopy["virtual"] = true
+		metadataCopy["virtual_parent_id"] = primary.ID
+		virtual := &coreauth.Auth{
+			ID:         buildGeminiVirtualID(primary.ID, location),
+			Provider:   primary.Provider,
+			Label:      fmt.Sprintf("%s [%s]", label, location),
+			Status:     coreauth.StatusActive,
+			Attributes: attrs,
+			Metadata:   metadataCopy,
+			ProxyURL:   primary.ProxyURL,
+			Prefix:     primary.Prefix,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		virtuals = append(virtuals, virtual)
+	}
+	return virtuals
+}
+
 // splitGeminiProjectIDs extracts and deduplicates project IDs from metadata.
 func splitGeminiProjectIDs(metadata map[string]any) []string {
 	raw, _ := metadata["project_id"].(string)
@@ -696,6 +696,68 @@ func TestFileSynthesizer_Synthesize_MultiProjectGemini(t *testing.T) {
 	}
 }
 
+func TestFileSynthesizer_Synthesize_MultiRegionVertex(t *testing.T) {
+	tempDir := t.TempDir()
+
+	authData := map[string]any{
+		"type":            "vertex",
+		"email":           "sa@example.iam.gserviceaccount.com",
+		"project_id":      "vertex-project",
+		"location":        "us-central1, europe-west4,global",
+		"priority":        5,
+		"service_account": map[string]any{"client_email": "sa@example.iam.gserviceaccount.com"},
+		"model_locations": map[string]any{"gemini-3-pro-*": []any{"global"}},
+	}
+	data, _ := json.Marshal(authData)
+	if err := os.WriteFile(filepath.Join(tempDir, "vertex-multi.json"), data, 0644); err != nil {
+		t.Fatalf("failed to write auth file: %v", err)
+	}
+
+	synth := NewFileSynthesizer()
+	ctx := &SynthesisContext{
+		Config:      &config.Config{},
+		AuthDir:     tempDir,
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	}
+
+	auths, err := synth.Synthesize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(auths) != 4 {
+		t.Fatalf("expected 4 auths (1 primary + 3 regions), got %d", len(auths))
+	}
+	primary := auths[0]
+	if !primary.Disabled || primary.Attributes["vertex_virtual_primary"] != "true" {
+		t.Fatalf("expected disabled vertex primary, got disabled=%v attrs=%v", primary.Disabled, primary.Attributes)
+	}
+
+	wantRegions := []string{"us-central1", "europe-west4", "global"}
+	wantPriorities := []string{"5", "4", "3"}
+	for i, v := range auths[1:] {
+		if v.Provider != "vertex" || v.Status != coreauth.StatusActive {
+			t.Errorf("region %d: provider=%s status=%s", i, v.Provider, v.Status)
+		}
+		if got := v.Metadata["location"]; got != wantRegions[i] {
+			t.Errorf("region %d: location = %v, want %s", i, got, wantRegions[i])
+		}
+		if got := v.Attributes["priority"]; got != wantPriorities[i] {
+			t.Errorf("region %d: priority = %s, want %s", i, got, wantPriorities[i])
+		}
+		if v.Metadata["service_account"] == nil || v.Metadata["project_id"] != "vertex-project" {
+			t.Errorf("region %d: credentials not copied: %v", i, v.Metadata)
+		}
+		excluded := v.Attributes["excluded_models"]
+		if wantRegions[i] == "global" && excluded != "" {
+			t.Errorf("global region excludes %q, want none", excluded)
+		}
+		if wantRegions[i] != "global" && excluded != "gemini-3-pro-*" {
+			t.Errorf("region %s excludes %q, want gemini-3-pro-*", wantRegions[i], excluded)
+		}
+	}
+}
+
 func TestBuildGeminiVirtualID(t *testing.T) {
 	tests := []struct {
 		name      string
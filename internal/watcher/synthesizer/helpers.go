@@ -105,6 +105,17 @@ func ApplyAuthExcludedModelsMeta(auth *coreauth.Auth, cfg *config.Config, perKey
 
 // addConfigHeadersToAttrs adds header configuration to auth attributes.
 // Headers are prefixed with "header:" in the attributes map.
+// addConfigTransportToAttrs stores credential transport overrides on the auth so executors
+// can build a client from the matching connection pool.
+func addConfigTransportToAttrs(transport *config.TransportConfig, attrs map[string]string) {
+	if attrs == nil {
+		return
+	}
+	if encoded := config.EncodeTransportAttribute(transport); encoded != "" {
+		attrs[config.TransportAttributeKey] = encoded
+	}
+}
+
 func addConfigHeadersToAttrs(headers map[string]string, attrs map[string]string) {
 	if len(headers) == 0 || attrs == nil {
 		return
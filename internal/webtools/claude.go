@@ -0,0 +1,245 @@
+package webtools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// claudeDialect emulates the web_search_* and web_fetch_* server tools of the Messages API.
+type claudeDialect struct{}
+
+func (claudeDialect) prepare(request []byte, searchEnabled bool) ([]byte, map[string]bool) {
+	tools := gjson.GetBytes(request, "tools")
+	if !tools.IsArray() {
+		return request, nil
+	}
+	installed := make(map[string]bool)
+	out := []byte(`[]`)
+	for _, tool := range tools.Array() {
+		kind := tool.Get("type").String()
+		switch {
+		case strings.HasPrefix(kind, "web_search_") && searchEnabled && !installed[ToolSearch]:
+			out, _ = sjson.SetRawBytes(out, "-1", claudeFunctionTool(ToolSearch, searchDescription, searchSchema))
+			installed[ToolSearch] = true
+		case strings.HasPrefix(kind, "web_fetch_") && !installed[ToolFetch]:
+			out, _ = sjson.SetRawBytes(out, "-1", claudeFunctionTool(ToolFetch, fetchDescription, fetchSchema))
+			installed[ToolFetch] = true
+		default:
+			out, _ = sjson.SetRawBytes(out, "-1", []byte(tool.Raw))
+		}
+	}
+	if len(installed) == 0 {
+		return request, nil
+	}
+	request, _ = sjson.SetRawBytes(request, "tools", out)
+	return claudeFlattenHistory(request), installed
+}
+
+func claudeFunctionTool(name, description, schema string) []byte {
+	tool := []byte(`{}`)
+	tool, _ = sjson.SetBytes(tool, "name", name)
+	tool, _ = sjson.SetBytes(tool, "description", description)
+	tool, _ = sjson.SetRawBytes(tool, "input_schema", []byte(schema))
+	return tool
+}
+
+// claudeFlattenHistory rewrites server-tool blocks of earlier turns, which a backend without
+// the server tools cannot read, into plain text blocks.
+func claudeFlattenHistory(request []byte) []byte {
+	for i, message := range gjson.GetBytes(request, "messages").Array() {
+		content := message.Get("content")
+		if !content.IsArray() {
+			continue
+		}
+		changed := false
+		blocks := []byte(`[]`)
+		for _, block := range content.Array() {
+			switch block.Get("type").String() {
+			case "server_tool_use":
+				changed = true
+			case "web_search_tool_result", "web_fetch_tool_result":
+				changed = true
+				if text := claudeServerResultText(block); text != "" {
+					textBlock, _ := sjson.SetBytes([]byte(`{"type":"text"}`), "text", text)
+					blocks, _ = sjson.SetRawBytes(blocks, "-1", textBlock)
+				}
+			default:
+				blocks, _ = sjson.SetRawBytes(blocks, "-1", []byte(block.Raw))
+			}
+		}
+		if changed {
+			request, _ = sjson.SetRawBytes(request, fmt.Sprintf("messages.%d.content", i), blocks)
+		}
+	}
+	return request
+}
+
+func claudeServerResultText(block gjson.Result) string {
+	content := block.Get("content")
+	var b strings.Builder
+	switch block.Get("type").String() {
+	case "web_search_tool_result":
+		if !content.IsArray() {
+			return ""
+		}
+		b.WriteString("Web search results:")
+		for i, result := range content.Array() {
+			fmt.Fprintf(&b, "\n%d. %s\nURL: %s", i+1, result.Get("title").String(), result.Get("url").String())
+		}
+	case "web_fetch_tool_result":
+		if content.Get("type").String() != "web_fetch_result" {
+			return ""
+		}
+		fmt.Fprintf(&b, "Fetched %s:\n%s", content.Get("url").String(), content.Get("content.source.data").String())
+	}
+	return b.String()
+}
+
+func (claudeDialect) calls(response []byte, tools map[string]bool) ([]Call, bool) {
+	var calls []Call
+	onlyEmulated := true
+	for _, block := range gjson.GetBytes(response, "content").Array() {
+		if block.Get("type").String() != "tool_use" {
+			continue
+		}
+		name := block.Get("name").String()
+		if !tools[name] {
+			onlyEmulated = false
+			continue
+		}
+		calls = append(calls, parseCall(block.Get("id").String(), name, block.Get("input").Raw))
+	}
+	return calls, onlyEmulated
+}
+
+func (claudeDialect) extend(request, response []byte, outcomes []Outcome, last bool) ([]byte, error) {
+	content := gjson.GetBytes(response, "content")
+	if !content.IsArray() {
+		return nil, fmt.Errorf("webtools: claude response has no content")
+	}
+	assistant, _ := sjson.SetRawBytes([]byte(`{"role":"assistant"}`), "content", []byte(content.Raw))
+	results := []byte(`{"role":"user","content":[]}`)
+	for _, outcome := range outcomes {
+		block, _ := sjson.SetBytes([]byte(`{"type":"tool_result"}`), "tool_use_id", outcome.Call.ID)
+		block, _ = sjson.SetBytes(block, "content", outcome.text())
+		if outcome.Err != nil {
+			block, _ = sjson.SetBytes(block, "is_error", true)
+		}
+		results, _ = sjson.SetRawBytes(results, "content.-1", block)
+	}
+	var err error
+	if request, err = sjson.SetRawBytes(request, "messages.-1", assistant); err != nil {
+		return nil, err
+	}
+	if request, err = sjson.SetRawBytes(request, "messages.-1", results); err != nil {
+		return nil, err
+	}
+	if last {
+		request, _ = sjson.SetRawBytes(request, "tool_choice", []byte(`{"type":"none"}`))
+	}
+	return request, nil
+}
+
+func (claudeDialect) finish(response []byte, rounds []round, tools map[string]bool) []byte {
+	if !gjson.GetBytes(response, "content").IsArray() {
+		return response
+	}
+	content := []byte(`[]`)
+	var searches, fetches int64
+	for _, r := range rounds {
+		for _, block := range gjson.GetBytes(r.response, "content").Array() {
+			if block.Get("type").String() == "text" && block.Get("text").String() != "" {
+				content, _ = sjson.SetRawBytes(content, "-1", []byte(block.Raw))
+			}
+		}
+		for _, outcome := range r.outcomes {
+			use, result := claudeServerBlocks(outcome)
+			content, _ = sjson.SetRawBytes(content, "-1", use)
+			content, _ = sjson.SetRawBytes(content, "-1", result)
+			if outcome.Call.Name == ToolSearch {
+				searches++
+			} else {
+				fetches++
+			}
+		}
+	}
+	clientToolUse := false
+	for _, block := range gjson.GetBytes(response, "content").Array() {
+		if block.Get("type").String() == "tool_use" {
+			if tools[block.Get("name").String()] {
+				continue
+			}
+			clientToolUse = true
+		}
+		content, _ = sjson.SetRawBytes(content, "-1", []byte(block.Raw))
+	}
+	out, _ := sjson.SetRawBytes(response, "content", content)
+	if !clientToolUse && gjson.GetBytes(out, "stop_reason").String() == "tool_use" {
+		out, _ = sjson.SetBytes(out, "stop_reason", "end_turn")
+	}
+	if len(rounds) == 0 {
+		return out
+	}
+	for _, key := range []string{"input_tokens", "output_tokens"} {
+		total := gjson.GetBytes(out, "usage."+key).Int()
+		for _, r := range rounds {
+			total += gjson.GetBytes(r.response, "usage."+key).Int()
+		}
+		out, _ = sjson.SetBytes(out, "usage."+key, total)
+	}
+	if searches > 0 {
+		out, _ = sjson.SetBytes(out, "usage.server_tool_use.web_search_requests", searches)
+	}
+	if fetches > 0 {
+		out, _ = sjson.SetBytes(out, "usage.server_tool_use.web_fetch_requests", fetches)
+	}
+	return out
+}
+
+// claudeServerBlocks renders an outcome as the server_tool_use block and result block the
+// Messages API returns for its own server tools.
+func claudeServerBlocks(outcome Outcome) (use, result []byte) {
+	id := "srvtoolu_" + strings.TrimPrefix(outcome.Call.ID, "toolu_")
+	use, _ = sjson.SetBytes([]byte(`{"type":"server_tool_use"}`), "id", id)
+	use, _ = sjson.SetBytes(use, "name", outcome.Call.Name)
+	if outcome.Call.Name == ToolSearch {
+		use, _ = sjson.SetBytes(use, "input.query", outcome.Call.Query)
+	} else {
+		use, _ = sjson.SetBytes(use, "input.url", outcome.Call.URL)
+	}
+
+	if outcome.Call.Name == ToolSearch {
+		result, _ = sjson.SetBytes([]byte(`{"type":"web_search_tool_result"}`), "tool_use_id", id)
+		if outcome.Err != nil {
+			result, _ = sjson.SetRawBytes(result, "content", []byte(`{"type":"web_search_tool_result_error","error_code":"unavailable"}`))
+			return use, result
+		}
+		result, _ = sjson.SetRawBytes(result, "content", []byte(`[]`))
+		for _, hit := range outcome.Results {
+			entry := []byte(`{"type":"web_search_result","encrypted_content":"","page_age":null}`)
+			entry, _ = sjson.SetBytes(entry, "title", hit.Title)
+			entry, _ = sjson.SetBytes(entry, "url", hit.URL)
+			result, _ = sjson.SetRawBytes(result, "content.-1", entry)
+		}
+		return use, result
+	}
+
+	result, _ = sjson.SetBytes([]byte(`{"type":"web_fetch_tool_result"}`), "tool_use_id", id)
+	if outcome.Err != nil || outcome.Page == nil {
+		result, _ = sjson.SetRawBytes(result, "content", []byte(`{"type":"web_fetch_tool_error","error_code":"url_not_accessible"}`))
+		return use, result
+	}
+	fetched := []byte(`{"type":"web_fetch_result","content":{"type":"document","source":{"type":"text","media_type":"text/plain"}}}`)
+	fetched, _ = sjson.SetBytes(fetched, "url", outcome.Page.URL)
+	fetched, _ = sjson.SetBytes(fetched, "retrieved_at", outcome.Retrieved.Format(time.RFC3339))
+	fetched, _ = sjson.SetBytes(fetched, "content.source.data", outcome.Page.Text)
+	if outcome.Page.Title != "" {
+		fetched, _ = sjson.SetBytes(fetched, "content.title", outcome.Page.Title)
+	}
+	result, _ = sjson.SetRawBytes(result, "content", fetched)
+	return use, result
+}
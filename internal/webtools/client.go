@@ -0,0 +1,369 @@
+// Package webtools emulates the built-in web search and URL fetch tools of the Claude and
+// Responses APIs for backends that do not run them. It rewrites the built-in tools into function
+// tools, runs the model's calls against a SearXNG-compatible search endpoint or the target URL,
+// feeds the results back, and finally presents the calls to the client as server-tool blocks.
+package webtools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/html"
+)
+
+// ErrSearchDisabled is returned by Search when no search endpoint is configured.
+var ErrSearchDisabled = errors.New("webtools: no search endpoint configured")
+
+// errBlockedAddress rejects fetches of non-public addresses.
+var errBlockedAddress = errors.New("webtools: address is not publicly routable")
+
+const userAgent = "Mozilla/5.0 (compatible; CLIProxyAPI web tools)"
+
+// SearchResult is one hit returned by the search endpoint.
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Content string `json:"content,omitempty"`
+}
+
+// Page is the text content of a fetched URL.
+type Page struct {
+	URL       string `json:"url"`
+	Title     string `json:"title,omitempty"`
+	Text      string `json:"text"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// Client runs searches and fetches with the limits of a WebToolsConfig.
+type Client struct {
+	cfg    config.WebToolsConfig
+	search *http.Client
+	fetch  *http.Client
+}
+
+var (
+	transportOnce    sync.Once
+	searchTransport  http.RoundTripper
+	publicTransport  http.RoundTripper
+	privateTransport http.RoundTripper
+)
+
+func initTransports() {
+	base := http.DefaultTransport.(*http.Transport)
+	searchTransport = base.Clone()
+	private := base.Clone()
+	private.Proxy = nil
+	privateTransport = private
+	public := base.Clone()
+	public.Proxy = nil
+	public.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   rejectNonPublic,
+	}).DialContext
+	publicTransport = public
+}
+
+// NewClient returns a client for cfg. Clients share their connection pools.
+func NewClient(cfg config.WebToolsConfig) *Client {
+	transportOnce.Do(initTransports)
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	fetchTransport := publicTransport
+	if cfg.AllowPrivateNetworks {
+		fetchTransport = privateTransport
+	}
+	return &Client{
+		cfg:    cfg,
+		search: &http.Client{Transport: searchTransport, Timeout: timeout},
+		fetch: &http.Client{
+			Transport: fetchTransport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 5 {
+					return errors.New("webtools: too many redirects")
+				}
+				return checkFetchURL(req.URL)
+			},
+		},
+	}
+}
+
+// SearchEnabled reports whether a search endpoint is configured.
+func (c *Client) SearchEnabled() bool {
+	return c != nil && c.cfg.SearchURL != ""
+}
+
+// Search queries the SearXNG-compatible endpoint and returns at most MaxResults hits.
+func (c *Client) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	if !c.SearchEnabled() {
+		return nil, ErrSearchDisabled
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("webtools: empty search query")
+	}
+	endpoint, errParse := url.Parse(c.cfg.SearchURL)
+	if errParse != nil {
+		return nil, fmt.Errorf("webtools: invalid search url: %w", errParse)
+	}
+	values := endpoint.Query()
+	values.Set("q", query)
+	values.Set("format", "json")
+	endpoint.RawQuery = values.Encode()
+
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if errReq != nil {
+		return nil, errReq
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	resp, errDo := c.search.Do(req)
+	if errDo != nil {
+		return nil, fmt.Errorf("webtools: search failed: %w", errDo)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, errRead := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if errRead != nil {
+		return nil, fmt.Errorf("webtools: read search response: %w", errRead)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("webtools: search returned status %d", resp.StatusCode)
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, errors.New("webtools: search returned invalid json")
+	}
+
+	limit := c.cfg.MaxResults
+	if limit <= 0 {
+		limit = 5
+	}
+	var results []SearchResult
+	for _, item := range gjson.GetBytes(body, "results").Array() {
+		link := strings.TrimSpace(item.Get("url").String())
+		if link == "" {
+			continue
+		}
+		results = append(results, SearchResult{
+			Title:   strings.TrimSpace(item.Get("title").String()),
+			URL:     link,
+			Content: strings.TrimSpace(item.Get("content").String()),
+		})
+		if len(results) >= limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// Fetch downloads rawURL and returns its text, converting HTML to plain text and truncating
+// it to FetchMaxBytes.
+func (c *Client) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	target, errParse := url.Parse(strings.TrimSpace(rawURL))
+	if errParse != nil {
+		return nil, fmt.Errorf("webtools: invalid url: %w", errParse)
+	}
+	if errCheck := checkFetchURL(target); errCheck != nil {
+		return nil, errCheck
+	}
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if errReq != nil {
+		return nil, errReq
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,text/plain;q=0.9,*/*;q=0.5")
+	resp, errDo := c.fetch.Do(req)
+	if errDo != nil {
+		return nil, fmt.Errorf("webtools: fetch failed: %w", errDo)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("webtools: fetch returned status %d", resp.StatusCode)
+	}
+
+	maxBytes := c.cfg.FetchMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 100000
+	}
+	// Markup inflates pages well beyond their text; read a generous multiple of the limit.
+	body, errRead := io.ReadAll(io.LimitReader(resp.Body, int64(maxBytes)*8))
+	if errRead != nil {
+		return nil, fmt.Errorf("webtools: read page: %w", errRead)
+	}
+	page := &Page{URL: resp.Request.URL.String()}
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	if strings.Contains(contentType, "html") || (contentType == "" && looksLikeHTML(body)) {
+		page.Title, page.Text = htmlToText(body)
+	} else {
+		page.Text = strings.TrimSpace(string(body))
+	}
+	if len(page.Text) > maxBytes {
+		page.Text = truncateUTF8(page.Text, maxBytes)
+		page.Truncated = true
+	}
+	return page, nil
+}
+
+func checkFetchURL(u *url.URL) error {
+	if u == nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webtools: only absolute http and https urls can be fetched")
+	}
+	return nil
+}
+
+// deniedPrefixes are the special-purpose ranges (IANA IPv4 and IPv6 special-purpose address
+// registries) that are not covered by the net.IP classification helpers: shared carrier-grade
+// NAT space, "this network", benchmarking, protocol assignments, documentation, relays and
+// translation prefixes that can embed internal IPv4 addresses.
+var deniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("3fff::/20"),
+	netip.MustParsePrefix("5f00::/16"),
+	netip.MustParsePrefix("fec0::/10"),
+}
+
+// rejectNonPublic is a dialer control hook refusing loopback, private, link-local,
+// unspecified, multicast and special-purpose addresses, so model-chosen URLs cannot reach
+// internal services.
+func rejectNonPublic(_, address string, _ syscall.RawConn) error {
+	host, _, errSplit := net.SplitHostPort(address)
+	if errSplit != nil {
+		return errSplit
+	}
+	addr, errParse := netip.ParseAddr(host)
+	if errParse != nil {
+		return errBlockedAddress
+	}
+	addr = addr.Unmap().WithZone("")
+	if !isPublicAddr(addr) {
+		return errBlockedAddress
+	}
+	return nil
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsUnspecified() || addr.IsMulticast() || addr.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, prefix := range deniedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func looksLikeHTML(body []byte) bool {
+	head := strings.ToLower(strings.TrimSpace(string(body[:min(len(body), 512)])))
+	return strings.HasPrefix(head, "<!doctype html") || strings.HasPrefix(head, "<html")
+}
+
+// htmlToText extracts the title and the visible text of an HTML document, one block per line.
+func htmlToText(body []byte) (title, text string) {
+	tokenizer := html.NewTokenizer(strings.NewReader(string(body)))
+	var out strings.Builder
+	var last byte
+	newline := func() {
+		out.WriteByte('\n')
+		last = '\n'
+	}
+	skipDepth := 0
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(title), collapseBlankLines(out.String())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "script", "style", "noscript", "svg", "template":
+				skipDepth++
+			case "title":
+				inTitle = true
+			case "br", "p", "div", "li", "tr", "h1", "h2", "h3", "h4", "h5", "h6", "section", "article", "pre", "blockquote":
+				newline()
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "script", "style", "noscript", "svg", "template":
+				if skipDepth > 0 {
+					skipDepth--
+				}
+			case "title":
+				inTitle = false
+			case "p", "div", "li", "tr", "h1", "h2", "h3", "h4", "h5", "h6", "section", "article", "pre", "blockquote":
+				newline()
+			}
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			chunk := strings.Join(strings.Fields(string(tokenizer.Text())), " ")
+			if chunk == "" {
+				continue
+			}
+			if inTitle {
+				title += chunk
+				continue
+			}
+			if out.Len() > 0 && last != '\n' {
+				out.WriteByte(' ')
+			}
+			out.WriteString(chunk)
+			last = chunk[len(chunk)-1]
+		}
+	}
+}
+
+func collapseBlankLines(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func truncateUTF8(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	for limit > 0 && !isRuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit]
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
@@ -0,0 +1,191 @@
+package webtools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// responsesDialect emulates the web_search tool family of the Responses API. The model gets a
+// fetch function alongside search, matching the built-in tool's open_page action.
+type responsesDialect struct{}
+
+func (responsesDialect) prepare(request []byte, searchEnabled bool) ([]byte, map[string]bool) {
+	tools := gjson.GetBytes(request, "tools")
+	if !tools.IsArray() {
+		return request, nil
+	}
+	installed := make(map[string]bool)
+	out := []byte(`[]`)
+	for _, tool := range tools.Array() {
+		if !strings.HasPrefix(tool.Get("type").String(), "web_search") {
+			out, _ = sjson.SetRawBytes(out, "-1", []byte(tool.Raw))
+			continue
+		}
+		if searchEnabled && !installed[ToolSearch] {
+			out, _ = sjson.SetRawBytes(out, "-1", responsesFunctionTool(ToolSearch, searchDescription, searchSchema))
+			installed[ToolSearch] = true
+		}
+		if !installed[ToolFetch] {
+			out, _ = sjson.SetRawBytes(out, "-1", responsesFunctionTool(ToolFetch, fetchDescription, fetchSchema))
+			installed[ToolFetch] = true
+		}
+	}
+	if len(installed) == 0 {
+		return request, nil
+	}
+	request, _ = sjson.SetRawBytes(request, "tools", out)
+
+	if choice := gjson.GetBytes(request, "tool_choice"); strings.HasPrefix(choice.Get("type").String(), "web_search") {
+		if installed[ToolSearch] {
+			request, _ = sjson.SetRawBytes(request, "tool_choice", []byte(`{"type":"function","name":"web_search"}`))
+		} else {
+			request, _ = sjson.SetBytes(request, "tool_choice", "auto")
+		}
+	}
+	if include := gjson.GetBytes(request, "include"); include.IsArray() {
+		kept := []byte(`[]`)
+		for _, entry := range include.Array() {
+			if !strings.HasPrefix(entry.String(), "web_search_call") {
+				kept, _ = sjson.SetRawBytes(kept, "-1", []byte(entry.Raw))
+			}
+		}
+		request, _ = sjson.SetRawBytes(request, "include", kept)
+	}
+	// Earlier web_search_call items cannot be replayed to a backend without the tool.
+	if input := gjson.GetBytes(request, "input"); input.IsArray() {
+		kept := []byte(`[]`)
+		for _, item := range input.Array() {
+			if item.Get("type").String() != "web_search_call" {
+				kept, _ = sjson.SetRawBytes(kept, "-1", []byte(item.Raw))
+			}
+		}
+		request, _ = sjson.SetRawBytes(request, "input", kept)
+	}
+	return request, installed
+}
+
+func responsesFunctionTool(name, description, schema string) []byte {
+	tool := []byte(`{"type":"function"}`)
+	tool, _ = sjson.SetBytes(tool, "name", name)
+	tool, _ = sjson.SetBytes(tool, "description", description)
+	tool, _ = sjson.SetRawBytes(tool, "parameters", []byte(schema))
+	return tool
+}
+
+func (responsesDialect) calls(response []byte, tools map[string]bool) ([]Call, bool) {
+	var calls []Call
+	onlyEmulated := true
+	for _, item := range gjson.GetBytes(response, "output").Array() {
+		switch item.Get("type").String() {
+		case "message", "reasoning":
+			continue
+		case "function_call":
+			name := item.Get("name").String()
+			if tools[name] {
+				calls = append(calls, parseCall(item.Get("call_id").String(), name, item.Get("arguments").String()))
+				continue
+			}
+		}
+		onlyEmulated = false
+	}
+	return calls, onlyEmulated
+}
+
+func (responsesDialect) extend(request, response []byte, outcomes []Outcome, last bool) ([]byte, error) {
+	output := gjson.GetBytes(response, "output")
+	if !output.IsArray() {
+		return nil, fmt.Errorf("webtools: responses payload has no output")
+	}
+	input := gjson.GetBytes(request, "input")
+	items := []byte(`[]`)
+	switch {
+	case input.IsArray():
+		items = []byte(input.Raw)
+	case input.Type == gjson.String:
+		message, _ := sjson.SetBytes([]byte(`{"type":"message","role":"user","content":[{"type":"input_text"}]}`), "content.0.text", input.String())
+		items, _ = sjson.SetRawBytes(items, "-1", message)
+	}
+	for _, item := range output.Array() {
+		// Reasoning items are tied to the upstream that produced them.
+		if item.Get("type").String() == "reasoning" {
+			continue
+		}
+		items, _ = sjson.SetRawBytes(items, "-1", []byte(item.Raw))
+	}
+	for _, outcome := range outcomes {
+		item, _ := sjson.SetBytes([]byte(`{"type":"function_call_output"}`), "call_id", outcome.Call.ID)
+		item, _ = sjson.SetBytes(item, "output", outcome.text())
+		items, _ = sjson.SetRawBytes(items, "-1", item)
+	}
+	var err error
+	if request, err = sjson.SetRawBytes(request, "input", items); err != nil {
+		return nil, err
+	}
+	if last {
+		request, _ = sjson.SetBytes(request, "tool_choice", "none")
+	}
+	return request, nil
+}
+
+func (responsesDialect) finish(response []byte, rounds []round, tools map[string]bool) []byte {
+	if !gjson.GetBytes(response, "output").IsArray() {
+		return response
+	}
+	output := []byte(`[]`)
+	for _, r := range rounds {
+		for _, item := range gjson.GetBytes(r.response, "output").Array() {
+			if item.Get("type").String() == "message" {
+				output, _ = sjson.SetRawBytes(output, "-1", []byte(item.Raw))
+			}
+		}
+		for _, outcome := range r.outcomes {
+			output, _ = sjson.SetRawBytes(output, "-1", responsesSearchCall(outcome))
+		}
+	}
+	for _, item := range gjson.GetBytes(response, "output").Array() {
+		if item.Get("type").String() == "function_call" && tools[item.Get("name").String()] {
+			continue
+		}
+		output, _ = sjson.SetRawBytes(output, "-1", []byte(item.Raw))
+	}
+	out, _ := sjson.SetRawBytes(response, "output", output)
+	if len(rounds) == 0 {
+		return out
+	}
+	for _, key := range []string{"input_tokens", "output_tokens", "total_tokens"} {
+		if !gjson.GetBytes(out, "usage."+key).Exists() {
+			continue
+		}
+		total := gjson.GetBytes(out, "usage."+key).Int()
+		for _, r := range rounds {
+			total += gjson.GetBytes(r.response, "usage."+key).Int()
+		}
+		out, _ = sjson.SetBytes(out, "usage."+key, total)
+	}
+	return out
+}
+
+// responsesSearchCall renders an outcome as the web_search_call item the Responses API returns
+// for its built-in tool.
+func responsesSearchCall(outcome Outcome) []byte {
+	item := []byte(`{"type":"web_search_call","status":"completed"}`)
+	item, _ = sjson.SetBytes(item, "id", "ws_"+strings.TrimPrefix(outcome.Call.ID, "call_"))
+	if outcome.Err != nil {
+		item, _ = sjson.SetBytes(item, "status", "failed")
+	}
+	if outcome.Call.Name == ToolSearch {
+		item, _ = sjson.SetRawBytes(item, "action", []byte(`{"type":"search"}`))
+		item, _ = sjson.SetBytes(item, "action.query", outcome.Call.Query)
+		for _, hit := range outcome.Results {
+			source, _ := sjson.SetBytes([]byte(`{"type":"url"}`), "url", hit.URL)
+			item, _ = sjson.SetRawBytes(item, "action.sources.-1", source)
+		}
+		return item
+	}
+	item, _ = sjson.SetRawBytes(item, "action", []byte(`{"type":"open_page"}`))
+	item, _ = sjson.SetBytes(item, "action.url", outcome.Call.URL)
+	return item
+}
@@ -0,0 +1,202 @@
+package webtools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// Names of the function tools offered to the model in place of the built-in tools.
+const (
+	ToolSearch = "web_search"
+	ToolFetch  = "web_fetch"
+)
+
+const (
+	searchDescription = "Search the web. Returns the title, URL and a snippet of the top results."
+	fetchDescription  = "Fetch a web page by URL and return its text content."
+	searchSchema      = `{"type":"object","properties":{"query":{"type":"string","description":"The search query."}},"required":["query"]}`
+	fetchSchema       = `{"type":"object","properties":{"url":{"type":"string","description":"The absolute http or https URL to fetch."}},"required":["url"]}`
+)
+
+// Call is one emulated tool call issued by the model.
+type Call struct {
+	ID    string
+	Name  string
+	Query string
+	URL   string
+}
+
+// Outcome is the result of running a Call.
+type Outcome struct {
+	Call      Call
+	Results   []SearchResult
+	Page      *Page
+	Err       error
+	Retrieved time.Time
+}
+
+// dialect adapts the emulation to one client-facing format. Payloads are request and response
+// bodies in that format.
+type dialect interface {
+	// prepare replaces the built-in web tools of a request with function tools and returns the
+	// names it installed.
+	prepare(request []byte, searchEnabled bool) ([]byte, map[string]bool)
+	// calls returns the emulated calls of a response and whether the response calls no other tool.
+	calls(response []byte, tools map[string]bool) ([]Call, bool)
+	// extend appends the assistant turn and the tool results to a request. When last is set the
+	// model is asked to answer without further tool calls.
+	extend(request, response []byte, outcomes []Outcome, last bool) ([]byte, error)
+	// finish presents the previous rounds as server-tool output in the final response.
+	finish(response []byte, rounds []round, tools map[string]bool) []byte
+}
+
+// round records an intermediate response and the calls run for it.
+type round struct {
+	response []byte
+	outcomes []Outcome
+}
+
+// Session runs the emulated tools over the model round trips of one request.
+type Session struct {
+	client        *Client
+	dialect       dialect
+	tools         map[string]bool
+	rounds        []round
+	maxIterations int
+}
+
+// NewSession prepares a request in format for web tool emulation. It returns the rewritten
+// request, or ok false when the format is not supported or the request has no built-in web
+// tools to emulate.
+func NewSession(format string, client *Client, request []byte, maxIterations int) (session *Session, prepared []byte, ok bool) {
+	if client == nil || !gjson.ValidBytes(request) {
+		return nil, request, false
+	}
+	var d dialect
+	switch format {
+	case "claude":
+		d = claudeDialect{}
+	case "openai-response":
+		d = responsesDialect{}
+	default:
+		return nil, request, false
+	}
+	prepared, tools := d.prepare(request, client.SearchEnabled())
+	if len(tools) == 0 {
+		return nil, request, false
+	}
+	if maxIterations <= 0 {
+		maxIterations = 5
+	}
+	return &Session{client: client, dialect: d, tools: tools, maxIterations: maxIterations}, prepared, true
+}
+
+// Next inspects a model response. When the model called emulated tools only, it runs them and
+// returns the request for the next round; otherwise it reports done.
+func (s *Session) Next(ctx context.Context, request, response []byte) (next []byte, done bool, err error) {
+	if len(s.rounds) >= s.maxIterations {
+		return nil, true, nil
+	}
+	calls, onlyEmulated := s.dialect.calls(response, s.tools)
+	if len(calls) == 0 || !onlyEmulated {
+		return nil, true, nil
+	}
+	outcomes := make([]Outcome, 0, len(calls))
+	for _, call := range calls {
+		outcomes = append(outcomes, s.run(ctx, call))
+	}
+	if errCtx := ctx.Err(); errCtx != nil {
+		return nil, true, errCtx
+	}
+	s.rounds = append(s.rounds, round{response: response, outcomes: outcomes})
+	next, err = s.dialect.extend(request, response, outcomes, len(s.rounds) >= s.maxIterations)
+	if err != nil {
+		return nil, true, err
+	}
+	return next, false, nil
+}
+
+// Finish rewrites the final response so the emulated calls of every round appear as
+// server-tool output, and drops emulated calls the model issued after the last round.
+func (s *Session) Finish(response []byte) []byte {
+	return s.dialect.finish(response, s.rounds, s.tools)
+}
+
+func (s *Session) run(ctx context.Context, call Call) Outcome {
+	outcome := Outcome{Call: call, Retrieved: time.Now().UTC()}
+	switch call.Name {
+	case ToolSearch:
+		outcome.Results, outcome.Err = s.client.Search(ctx, call.Query)
+	case ToolFetch:
+		outcome.Page, outcome.Err = s.client.Fetch(ctx, call.URL)
+	default:
+		outcome.Err = fmt.Errorf("webtools: unknown tool %q", call.Name)
+	}
+	if outcome.Err != nil {
+		logCallFailure(ctx, call, outcome.Err)
+	}
+	return outcome
+}
+
+// parseCall builds a Call from a function name and its JSON arguments.
+func parseCall(id, name, arguments string) Call {
+	args := gjson.Parse(arguments)
+	return Call{
+		ID:    id,
+		Name:  name,
+		Query: strings.TrimSpace(args.Get("query").String()),
+		URL:   strings.TrimSpace(args.Get("url").String()),
+	}
+}
+
+// text renders an outcome as the tool result text given to the model.
+func (o Outcome) text() string {
+	if o.Err != nil {
+		return "Error: " + o.Err.Error()
+	}
+	var b strings.Builder
+	switch o.Call.Name {
+	case ToolSearch:
+		if len(o.Results) == 0 {
+			return "No results found."
+		}
+		for i, result := range o.Results {
+			if i > 0 {
+				b.WriteString("\n\n")
+			}
+			fmt.Fprintf(&b, "%d. %s\nURL: %s", i+1, result.Title, result.URL)
+			if result.Content != "" {
+				b.WriteString("\n")
+				b.WriteString(result.Content)
+			}
+		}
+	case ToolFetch:
+		if o.Page == nil {
+			return "Error: empty page"
+		}
+		fmt.Fprintf(&b, "URL: %s\n", o.Page.URL)
+		if o.Page.Title != "" {
+			fmt.Fprintf(&b, "Title: %s\n", o.Page.Title)
+		}
+		b.WriteString("\n")
+		b.WriteString(o.Page.Text)
+		if o.Page.Truncated {
+			b.WriteString("\n\n[content truncated]")
+		}
+	}
+	return b.String()
+}
+
+func logCallFailure(ctx context.Context, call Call, err error) {
+	entry := log.WithField("tool", call.Name)
+	if requestID := logging.GetRequestID(ctx); requestID != "" {
+		entry = entry.WithField("request_id", requestID)
+	}
+	entry.Warnf("web tool call failed: %v", err)
+}
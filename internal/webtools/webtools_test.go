@@ -0,0 +1,217 @@
+package webtools
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+// newStubServer serves a SearXNG-style search endpoint at /search and an HTML page at /page.
+func newStubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") != "json" {
+			http.Error(w, "format", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query":"` + r.URL.Query().Get("q") + `","results":[
+			{"url":"https://example.com/a","title":"Result A","content":"About A"},
+			{"url":"https://example.com/b","title":"Result B","content":"About B"},
+			{"url":"https://example.com/c","title":"Result C","content":"About C"}]}`))
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>Stub Page</title><script>var x = 1;</script></head><body><h1>Heading</h1><p>First paragraph.</p><p>Second <b>bold</b> paragraph.</p></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newStubClient(server *httptest.Server) *Client {
+	return NewClient(config.WebToolsConfig{
+		Enable:               true,
+		SearchURL:            server.URL + "/search",
+		MaxResults:           2,
+		FetchMaxBytes:        1000,
+		TimeoutSeconds:       5,
+		AllowPrivateNetworks: true,
+	})
+}
+
+func TestClientSearchAndFetch(t *testing.T) {
+	server := newStubServer(t)
+	client := newStubClient(server)
+
+	results, err := client.Search(context.Background(), "golang")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Title != "Result A" || results[1].URL != "https://example.com/b" {
+		t.Fatalf("Search() = %+v, want the first two results", results)
+	}
+
+	page, err := client.Fetch(context.Background(), server.URL+"/page")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if page.Title != "Stub Page" {
+		t.Fatalf("title = %q, want Stub Page", page.Title)
+	}
+	if page.Text != "Heading\nFirst paragraph.\nSecond bold paragraph." {
+		t.Fatalf("text = %q", page.Text)
+	}
+}
+
+func TestClientFetchBlocksPrivateAddresses(t *testing.T) {
+	server := newStubServer(t)
+	client := NewClient(config.WebToolsConfig{Enable: true, TimeoutSeconds: 5})
+
+	if _, err := client.Fetch(context.Background(), server.URL+"/page"); !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("Fetch() error = %v, want blocked address", err)
+	}
+	if _, err := client.Fetch(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Fatal("Fetch() error = nil for a file url")
+	}
+}
+
+func TestRejectNonPublicSpecialPurposeRanges(t *testing.T) {
+	blocked := []string{
+		"0.1.2.3", "10.0.0.1", "100.64.0.1", "100.127.255.254", "127.0.0.1", "169.254.169.254",
+		"172.16.0.1", "192.0.0.8", "192.0.2.1", "192.168.1.1", "198.18.0.1", "198.19.255.255",
+		"198.51.100.7", "203.0.113.9", "224.0.0.1", "240.0.0.1", "255.255.255.255",
+		"::", "::1", "::ffff:10.0.0.1", "::ffff:100.64.0.1", "64:ff9b::a00:1", "2001:db8::1",
+		"2002:a00:1::1", "2001::1", "fc00::1", "fe80::1%eth0", "ff02::1",
+	}
+	for _, host := range blocked {
+		if err := rejectNonPublic("tcp", net.JoinHostPort(host, "443"), nil); !errors.Is(err, errBlockedAddress) {
+			t.Errorf("rejectNonPublic(%s) = %v, want blocked", host, err)
+		}
+	}
+	for _, host := range []string{"8.8.8.8", "100.128.0.1", "198.20.0.1", "2606:4700:4700::1111"} {
+		if err := rejectNonPublic("tcp", net.JoinHostPort(host, "443"), nil); err != nil {
+			t.Errorf("rejectNonPublic(%s) = %v, want allowed", host, err)
+		}
+	}
+}
+
+func TestClaudeSessionRunsSearchAndPresentsServerTools(t *testing.T) {
+	server := newStubServer(t)
+	request := []byte(`{"model":"m","messages":[{"role":"user","content":"news?"}],"tools":[{"type":"web_search_20250305","name":"web_search","max_uses":3},{"name":"calc","input_schema":{"type":"object"}}]}`)
+	session, prepared, ok := NewSession("claude", newStubClient(server), request, 3)
+	if !ok {
+		t.Fatal("NewSession() ok = false, want emulation")
+	}
+	if got := gjson.GetBytes(prepared, "tools.0.input_schema.required.0").String(); got != "query" {
+		t.Fatalf("prepared tools = %s", gjson.GetBytes(prepared, "tools").Raw)
+	}
+	if got := gjson.GetBytes(prepared, "tools.1.name").String(); got != "calc" {
+		t.Fatalf("client tool not kept: %s", gjson.GetBytes(prepared, "tools").Raw)
+	}
+
+	first := []byte(`{"type":"message","role":"assistant","content":[{"type":"text","text":"Searching."},{"type":"tool_use","id":"toolu_1","name":"web_search","input":{"query":"news"}}],"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":5}}`)
+	next, done, err := session.Next(context.Background(), prepared, first)
+	if err != nil || done {
+		t.Fatalf("Next() done = %v err = %v, want another round", done, err)
+	}
+	toolResult := gjson.GetBytes(next, "messages.2.content.0")
+	if toolResult.Get("tool_use_id").String() != "toolu_1" || !strings.Contains(toolResult.Get("content").String(), "https://example.com/a") {
+		t.Fatalf("tool result = %s", toolResult.Raw)
+	}
+
+	final := []byte(`{"type":"message","role":"assistant","content":[{"type":"text","text":"Here is the news."}],"stop_reason":"end_turn","usage":{"input_tokens":30,"output_tokens":8}}`)
+	if _, done, err = session.Next(context.Background(), next, final); err != nil || !done {
+		t.Fatalf("Next() done = %v err = %v, want done", done, err)
+	}
+	out := session.Finish(final)
+	types := make([]string, 0)
+	for _, block := range gjson.GetBytes(out, "content").Array() {
+		types = append(types, block.Get("type").String())
+	}
+	if strings.Join(types, ",") != "text,server_tool_use,web_search_tool_result,text" {
+		t.Fatalf("content types = %v", types)
+	}
+	if got := gjson.GetBytes(out, "content.2.content.1.url").String(); got != "https://example.com/b" {
+		t.Fatalf("search result url = %q", got)
+	}
+	if got := gjson.GetBytes(out, "usage.server_tool_use.web_search_requests").Int(); got != 1 {
+		t.Fatalf("web_search_requests = %d, want 1", got)
+	}
+	if got := gjson.GetBytes(out, "usage.input_tokens").Int(); got != 40 {
+		t.Fatalf("input_tokens = %d, want 40", got)
+	}
+}
+
+func TestClaudeSessionStopsOnClientTools(t *testing.T) {
+	server := newStubServer(t)
+	request := []byte(`{"messages":[],"tools":[{"type":"web_search_20250305","name":"web_search"},{"name":"calc"}]}`)
+	session, prepared, _ := NewSession("claude", newStubClient(server), request, 3)
+	response := []byte(`{"type":"message","content":[{"type":"tool_use","id":"toolu_1","name":"web_search","input":{"query":"x"}},{"type":"tool_use","id":"toolu_2","name":"calc","input":{}}],"stop_reason":"tool_use"}`)
+	if _, done, _ := session.Next(context.Background(), prepared, response); !done {
+		t.Fatal("Next() done = false, want the client tool call returned")
+	}
+	out := session.Finish(response)
+	if blocks := gjson.GetBytes(out, "content").Array(); len(blocks) != 1 || blocks[0].Get("name").String() != "calc" {
+		t.Fatalf("content = %s, want only the client tool call", gjson.GetBytes(out, "content").Raw)
+	}
+	if gjson.GetBytes(out, "stop_reason").String() != "tool_use" {
+		t.Fatalf("stop_reason = %s, want tool_use", gjson.GetBytes(out, "stop_reason").String())
+	}
+}
+
+func TestResponsesSessionFetch(t *testing.T) {
+	server := newStubServer(t)
+	request := []byte(`{"model":"m","input":"read the page","tools":[{"type":"web_search"}],"tool_choice":{"type":"web_search"}}`)
+	session, prepared, ok := NewSession("openai-response", newStubClient(server), request, 2)
+	if !ok {
+		t.Fatal("NewSession() ok = false, want emulation")
+	}
+	if got := gjson.GetBytes(prepared, "tool_choice.name").String(); got != ToolSearch {
+		t.Fatalf("tool_choice = %s", gjson.GetBytes(prepared, "tool_choice").Raw)
+	}
+
+	first := []byte(`{"object":"response","output":[{"type":"function_call","id":"fc_1","call_id":"call_1","name":"web_fetch","arguments":"{\"url\":\"` + server.URL + `/page\"}"}],"usage":{"input_tokens":4,"output_tokens":2,"total_tokens":6}}`)
+	next, done, err := session.Next(context.Background(), prepared, first)
+	if err != nil || done {
+		t.Fatalf("Next() done = %v err = %v, want another round", done, err)
+	}
+	input := gjson.GetBytes(next, "input").Array()
+	if len(input) != 3 || input[0].Get("content.0.text").String() != "read the page" {
+		t.Fatalf("input = %s", gjson.GetBytes(next, "input").Raw)
+	}
+	if !strings.Contains(input[2].Get("output").String(), "First paragraph.") {
+		t.Fatalf("function output = %s", input[2].Raw)
+	}
+
+	final := []byte(`{"object":"response","output":[{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Done."}]}],"usage":{"input_tokens":20,"output_tokens":3,"total_tokens":23}}`)
+	if _, done, _ = session.Next(context.Background(), next, final); !done {
+		t.Fatal("Next() done = false, want done")
+	}
+	out := session.Finish(final)
+	call := gjson.GetBytes(out, "output.0")
+	if call.Get("type").String() != "web_search_call" || call.Get("action.type").String() != "open_page" || call.Get("status").String() != "completed" {
+		t.Fatalf("output[0] = %s", call.Raw)
+	}
+	if got := gjson.GetBytes(out, "usage.total_tokens").Int(); got != 29 {
+		t.Fatalf("total_tokens = %d, want 29", got)
+	}
+}
+
+func TestNewSessionSkipsRequestsWithoutWebTools(t *testing.T) {
+	client := NewClient(config.WebToolsConfig{Enable: true})
+	if _, _, ok := NewSession("claude", client, []byte(`{"tools":[{"name":"calc"}]}`), 3); ok {
+		t.Fatal("NewSession() ok = true for a request without web tools")
+	}
+	if _, _, ok := NewSession("openai", client, []byte(`{"tools":[{"type":"web_search"}]}`), 3); ok {
+		t.Fatal("NewSession() ok = true for an unsupported format")
+	}
+}
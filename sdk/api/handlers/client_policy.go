@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"golang.org/x/net/context"
+)
+
+// ClientAPIKey returns the authenticated client API key stored on the gin context by the auth middleware.
+func ClientAPIKey(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	raw, exists := c.Get("apiKey")
+	if !exists {
+		return ""
+	}
+	switch v := raw.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case fmt.Stringer:
+		return strings.TrimSpace(v.String())
+	default:
+		return ""
+	}
+}
+
+func clientAPIKeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok {
+		return ""
+	}
+	return ClientAPIKey(ginCtx)
+}
+
+// ClientKeyPolicy returns the policy configured for the client key authenticated on c, or nil.
+func (h *BaseAPIHandler) ClientKeyPolicy(c *gin.Context) *config.ClientKeyPolicy {
+	if h == nil || h.Cfg == nil {
+		return nil
+	}
+	return h.Cfg.ClientKeyPolicyFor(ClientAPIKey(c))
+}
+
+// FilterModelsForClient drops model listing entries the calling client key may not use.
+// Entries are matched on their "id" field, falling back to the Gemini-style "name" field.
+func (h *BaseAPIHandler) FilterModelsForClient(c *gin.Context, models []map[string]any) []map[string]any {
+	policy := h.ClientKeyPolicy(c)
+	if policy == nil || len(models) == 0 {
+		return models
+	}
+	filtered := make([]map[string]any, 0, len(models))
+	for _, model := range models {
+		id, _ := model["id"].(string)
+		if id == "" {
+			name, _ := model["name"].(string)
+			id = strings.TrimPrefix(name, "models/")
+		}
+		if clientPolicyAllowsListing(policy, id) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
+func clientPolicyAllowsListing(policy *config.ClientKeyPolicy, modelID string) bool {
+	modelID = strings.TrimSpace(modelID)
+	if modelID == "" {
+		return false
+	}
+	if policy.Prefix != "" && !strings.HasPrefix(modelID, policy.Prefix+"/") {
+		return false
+	}
+	return policy.AllowsModel(modelID)
+}
+
+// applyClientKeyPolicy enforces the calling client key's model policy and rewrites the model
+// name onto the policy's forced credential prefix when one is configured.
+func (h *BaseAPIHandler) applyClientKeyPolicy(ctx context.Context, modelName string) (string, *interfaces.ErrorMessage) {
+	if h == nil || h.Cfg == nil || len(h.Cfg.ClientKeyPolicies) == 0 {
+		return modelName, nil
+	}
+	policy := h.Cfg.ClientKeyPolicyFor(clientAPIKeyFromContext(ctx))
+	if policy == nil {
+		return modelName, nil
+	}
+	routed := policy.ApplyPrefix(modelName)
+	if !policy.AllowsModel(thinking.ParseSuffix(routed).ModelName) {
+		return "", &interfaces.ErrorMessage{
+			StatusCode: http.StatusForbidden,
+			Error:      fmt.Errorf("model %s is not allowed for this API key", modelName),
+		}
+	}
+	return routed, nil
+}
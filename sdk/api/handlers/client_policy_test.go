@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func newPolicyTestContext(apiKey string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	if apiKey != "" {
+		c.Set("apiKey", apiKey)
+	}
+	return c
+}
+
+func TestFilterModelsForClient(t *testing.T) {
+	cfg := &sdkconfig.SDKConfig{
+		APIKeys: []string{"team-key", "open-key"},
+		ClientKeyPolicies: []sdkconfig.ClientKeyPolicy{
+			{APIKey: "team-key", AllowedModels: []string{"gemini-*"}, Prefix: "teamA"},
+		},
+	}
+	handler := NewBaseAPIHandlers(cfg, coreauth.NewManager(nil, nil, nil))
+	models := []map[string]any{
+		{"id": "gemini-2.5-pro"},
+		{"id": "teamA/gemini-2.5-pro"},
+		{"id": "teamA/claude-opus-4-5"},
+		{"name": "models/teamA/gemini-2.5-flash"},
+	}
+
+	filtered := handler.FilterModelsForClient(newPolicyTestContext("team-key"), models)
+	if len(filtered) != 2 {
+		t.Fatalf("expected 2 models for team key, got %d: %v", len(filtered), filtered)
+	}
+	if filtered[0]["id"] != "teamA/gemini-2.5-pro" {
+		t.Fatalf("unexpected first model: %v", filtered[0])
+	}
+
+	unrestricted := handler.FilterModelsForClient(newPolicyTestContext("open-key"), models)
+	if len(unrestricted) != len(models) {
+		t.Fatalf("expected unrestricted key to see %d models, got %d", len(models), len(unrestricted))
+	}
+}
+
+func TestApplyClientKeyPolicy(t *testing.T) {
+	cfg := &sdkconfig.SDKConfig{
+		ClientKeyPolicies: []sdkconfig.ClientKeyPolicy{
+			{APIKey: "team-key", AllowedModels: []string{"gemini-*"}, Prefix: "teamA"},
+		},
+	}
+	handler := NewBaseAPIHandlers(cfg, coreauth.NewManager(nil, nil, nil))
+	ctx := context.WithValue(context.Background(), "gin", newPolicyTestContext("team-key"))
+
+	model, errMsg := handler.applyClientKeyPolicy(ctx, "gemini-2.5-pro(high)")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %v", errMsg.Error)
+	}
+	if model != "teamA/gemini-2.5-pro(high)" {
+		t.Fatalf("expected prefixed model, got %q", model)
+	}
+
+	if _, errMsg = handler.applyClientKeyPolicy(ctx, "claude-opus-4-5"); errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected forbidden error, got %+v", errMsg)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
 )
 
@@ -208,6 +209,9 @@ func requestExecutionMetadata(ctx context.Context) map[string]any {
 	if executionSessionID := executionSessionIDFromContext(ctx); executionSessionID != "" {
 		meta[coreexecutor.ExecutionSessionMetadataKey] = executionSessionID
 	}
+	if clientKey := clientAPIKeyFromContext(ctx); clientKey != "" {
+		meta[coreexecutor.ClientAPIKeyMetadataKey] = clientKey
+	}
 	return meta
 }
 
@@ -338,6 +342,21 @@ func (h *BaseAPIHandler) GetContextWithCancel(handler interfaces.APIHandler, c *
 			parentCtx = logging.WithRequestID(parentCtx, requestID)
 		}
 	}
+	if requestCtx != nil {
+		// Routes may pin a credential or observe the selection through the request context.
+		if pinnedAuthIDFromContext(parentCtx) == "" {
+			parentCtx = WithPinnedAuthID(parentCtx, pinnedAuthIDFromContext(requestCtx))
+		}
+		if selectedAuthIDCallbackFromContext(parentCtx) == nil {
+			parentCtx = WithSelectedAuthIDCallback(parentCtx, selectedAuthIDCallbackFromContext(requestCtx))
+		}
+	}
+	if requestCtx != nil && !trace.SpanContextFromContext(parentCtx).IsValid() {
+		// Keep the request's trace span so downstream spans join the handler's trace.
+		if span := trace.SpanFromContext(requestCtx); span.SpanContext().IsValid() {
+			parentCtx = trace.ContextWithSpan(parentCtx, span)
+		}
+	}
 	newCtx, cancel := context.WithCancel(parentCtx)
 	if requestCtx != nil && requestCtx != parentCtx {
 		go func() {
@@ -468,12 +487,20 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 // ExecuteWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, http.Header, *interfaces.ErrorMessage) {
+	modelName = h.applyModelSplit(ctx, modelName, rawJSON)
+	modelName, errMsg := h.applyClientKeyPolicy(ctx, modelName)
+	if errMsg != nil {
+		return nil, nil, errMsg
+	}
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		return nil, nil, errMsg
 	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
+	if sessionKey := conversationKeyFromRequest(ctx, rawJSON); sessionKey != "" {
+		reqMeta[coreexecutor.SessionAffinityMetadataKey] = sessionKey
+	}
 	payload := rawJSON
 	if len(payload) == 0 {
 		payload = nil
@@ -514,12 +541,20 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 // ExecuteCountWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, http.Header, *interfaces.ErrorMessage) {
+	modelName = h.applyModelSplit(ctx, modelName, rawJSON)
+	modelName, errMsg := h.applyClientKeyPolicy(ctx, modelName)
+	if errMsg != nil {
+		return nil, nil, errMsg
+	}
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		return nil, nil, errMsg
 	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
+	if sessionKey := conversationKeyFromRequest(ctx, rawJSON); sessionKey != "" {
+		reqMeta[coreexecutor.SessionAffinityMetadataKey] = sessionKey
+	}
 	payload := rawJSON
 	if len(payload) == 0 {
 		payload = nil
@@ -561,6 +596,14 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 // This path is the only supported execution route.
 // The returned http.Header carries upstream response headers captured before streaming begins.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, http.Header, <-chan *interfaces.ErrorMessage) {
+	modelName = h.applyModelSplit(ctx, modelName, rawJSON)
+	modelName, errMsg := h.applyClientKeyPolicy(ctx, modelName)
+	if errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		return nil, nil, errChan
+	}
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
@@ -570,6 +613,9 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
+	if sessionKey := conversationKeyFromRequest(ctx, rawJSON); sessionKey != "" {
+		reqMeta[coreexecutor.SessionAffinityMetadataKey] = sessionKey
+	}
 	payload := rawJSON
 	if len(payload) == 0 {
 		payload = nil
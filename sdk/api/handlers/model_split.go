@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/context"
+)
+
+const (
+	// ModelSplitAliasContextKey stores the weighted alias requested by the client on the gin context.
+	ModelSplitAliasContextKey = constant.ModelSplitAliasContextKey
+	// ModelSplitTargetContextKey stores the split arm selected for the request on the gin context.
+	ModelSplitTargetContextKey = constant.ModelSplitTargetContextKey
+)
+
+// applyModelSplit resolves a weighted alias into one of its target models.
+// Thinking suffixes on the alias are carried over to the chosen target, and the
+// assignment is recorded on the gin context so usage records can attribute it.
+func (h *BaseAPIHandler) applyModelSplit(ctx context.Context, modelName string, rawJSON []byte) string {
+	if h == nil || h.Cfg == nil || len(h.Cfg.ModelSplits) == 0 {
+		return modelName
+	}
+	parsed := thinking.ParseSuffix(modelName)
+	split := h.Cfg.ModelSplitFor(parsed.ModelName)
+	if split == nil {
+		return modelName
+	}
+	target := split.Pick(modelSplitStickyKey(ctx, split, rawJSON))
+	if target == "" {
+		return modelName
+	}
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+		ginCtx.Set(ModelSplitAliasContextKey, split.Alias)
+		ginCtx.Set(ModelSplitTargetContextKey, target)
+	}
+	if parsed.HasSuffix {
+		return fmt.Sprintf("%s(%s)", target, parsed.RawSuffix)
+	}
+	return target
+}
+
+// modelSplitStickyKey derives the assignment key for a split according to its sticky mode.
+// Conversation stickiness prefers explicit session identifiers, then the OpenAI end-user id,
+// and falls back to the client key.
+func modelSplitStickyKey(ctx context.Context, split *config.ModelSplit, rawJSON []byte) string {
+	switch split.Sticky {
+	case config.ModelSplitStickyNone:
+		return ""
+	case config.ModelSplitStickyConversation:
+		if key := conversationKeyFromRequest(ctx, rawJSON); key != "" {
+			return key
+		}
+		if user := strings.TrimSpace(gjson.GetBytes(rawJSON, "user").String()); user != "" {
+			return user
+		}
+	}
+	return clientAPIKeyFromContext(ctx)
+}
+
+// conversationKeyFromRequest returns the session or conversation identifier a client sent with
+// the request. The OpenAI "user" field names an end user rather than one conversation, so it is
+// not used here.
+func conversationKeyFromRequest(ctx context.Context, rawJSON []byte) string {
+	if ctx != nil {
+		if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil && ginCtx.Request != nil {
+			for _, header := range []string{"X-Session-Id", "Session_id", "Conversation_id"} {
+				if value := strings.TrimSpace(ginCtx.GetHeader(header)); value != "" {
+					return value
+				}
+			}
+		}
+	}
+	if len(rawJSON) == 0 {
+		return ""
+	}
+	for _, path := range []string{"metadata.user_id", "prompt_cache_key"} {
+		if value := strings.TrimSpace(gjson.GetBytes(rawJSON, path).String()); value != "" {
+			return value
+		}
+	}
+	return ""
+}
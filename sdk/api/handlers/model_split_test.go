@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestApplyModelSplit_PreservesSuffixAndRecordsAssignment(t *testing.T) {
+	cfg := &sdkconfig.SDKConfig{
+		ModelSplits: []sdkconfig.ModelSplit{{
+			Alias:   "coder",
+			Sticky:  sdkconfig.ModelSplitStickyClientKey,
+			Targets: []sdkconfig.ModelSplitTarget{{Model: "claude-sonnet-4-5", Weight: 1}},
+		}},
+	}
+	handler := NewBaseAPIHandlers(cfg, coreauth.NewManager(nil, nil, nil))
+	ginCtx := newPolicyTestContext("client-key")
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+
+	got := handler.applyModelSplit(ctx, "coder(high)", nil)
+	if got != "claude-sonnet-4-5(high)" {
+		t.Fatalf("expected split target with suffix, got %q", got)
+	}
+	if alias := ginCtx.GetString(ModelSplitAliasContextKey); alias != "coder" {
+		t.Fatalf("expected recorded alias, got %q", alias)
+	}
+	if target := ginCtx.GetString(ModelSplitTargetContextKey); target != "claude-sonnet-4-5" {
+		t.Fatalf("expected recorded target, got %q", target)
+	}
+
+	if unchanged := handler.applyModelSplit(ctx, "gpt-5", nil); unchanged != "gpt-5" {
+		t.Fatalf("expected unrelated model unchanged, got %q", unchanged)
+	}
+}
+
+func TestModelSplitStickyKey_Conversation(t *testing.T) {
+	split := &sdkconfig.ModelSplit{Alias: "coder", Sticky: sdkconfig.ModelSplitStickyConversation}
+	ctx := context.WithValue(context.Background(), "gin", newPolicyTestContext("client-key"))
+
+	if key := modelSplitStickyKey(ctx, split, []byte(`{"metadata":{"user_id":"session-1"}}`)); key != "session-1" {
+		t.Fatalf("expected conversation key from metadata, got %q", key)
+	}
+	if key := modelSplitStickyKey(ctx, split, []byte(`{}`)); key != "client-key" {
+		t.Fatalf("expected fallback to client key, got %q", key)
+	}
+}
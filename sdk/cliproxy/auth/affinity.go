@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultSessionAffinityTTL     = time.Hour
+	defaultSessionAffinityMaxKeys = 65536
+)
+
+// sessionAffinity pins conversation sessions to the auth that served them so consecutive
+// turns keep hitting the same upstream account (and its prompt / signature caches). Bindings
+// are kept in least-recently-used order so the bound on keys evicts idle sessions first.
+type sessionAffinity struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	maxKeys int
+}
+
+type affinityEntry struct {
+	key       string
+	authID    string
+	expiresAt time.Time
+}
+
+// lookup returns the auth bound to key, dropping the binding when it has expired.
+func (a *sessionAffinity) lookup(key string, now time.Time) string {
+	if a == nil || key == "" {
+		return ""
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	elem, ok := a.entries[key]
+	if !ok {
+		return ""
+	}
+	entry := elem.Value.(*affinityEntry)
+	if !entry.expiresAt.After(now) {
+		a.removeLocked(elem)
+		return ""
+	}
+	a.order.MoveToFront(elem)
+	return entry.authID
+}
+
+// bind associates key with authID for ttl, refreshing any existing binding. When the bound on
+// keys is reached, expired bindings are dropped first and then the least recently used ones.
+func (a *sessionAffinity) bind(key, authID string, ttl time.Duration, now time.Time) {
+	if a == nil || key == "" || authID == "" {
+		return
+	}
+	if ttl <= 0 {
+		ttl = defaultSessionAffinityTTL
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.entries == nil {
+		a.entries = make(map[string]*list.Element)
+		a.order = list.New()
+	}
+	if elem, exists := a.entries[key]; exists {
+		entry := elem.Value.(*affinityEntry)
+		entry.authID = authID
+		entry.expiresAt = now.Add(ttl)
+		a.order.MoveToFront(elem)
+		return
+	}
+	maxKeys := a.maxKeys
+	if maxKeys <= 0 {
+		maxKeys = defaultSessionAffinityMaxKeys
+	}
+	if len(a.entries) >= maxKeys {
+		a.purgeExpiredLocked(now)
+		for len(a.entries) >= maxKeys {
+			a.removeLocked(a.order.Back())
+		}
+	}
+	a.entries[key] = a.order.PushFront(&affinityEntry{key: key, authID: authID, expiresAt: now.Add(ttl)})
+}
+
+func (a *sessionAffinity) purgeExpiredLocked(now time.Time) {
+	for elem := a.order.Back(); elem != nil; {
+		prev := elem.Prev()
+		if !elem.Value.(*affinityEntry).expiresAt.After(now) {
+			a.removeLocked(elem)
+		}
+		elem = prev
+	}
+}
+
+func (a *sessionAffinity) removeLocked(elem *list.Element) {
+	delete(a.entries, elem.Value.(*affinityEntry).key)
+	a.order.Remove(elem)
+}
+
+// sessionAffinitySettings returns the active affinity configuration, or ok=false when disabled.
+func (m *Manager) sessionAffinitySettings() (internalconfig.SessionAffinityConfig, bool) {
+	if m == nil {
+		return internalconfig.SessionAffinityConfig{}, false
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || !cfg.Routing.SessionAffinity.Enable {
+		return internalconfig.SessionAffinityConfig{}, false
+	}
+	return cfg.Routing.SessionAffinity, true
+}
+
+// sessionAffinityKey derives the affinity key for a request. Explicit session identifiers
+// supplied by the handler win; otherwise the long-lived execution session is used, and
+// finally (when enabled) a hash of the conversation prefix.
+func sessionAffinityKey(settings internalconfig.SessionAffinityConfig, opts cliproxyexecutor.Options, model string) string {
+	session := metadataString(opts.Metadata, cliproxyexecutor.SessionAffinityMetadataKey)
+	if session == "" {
+		session = metadataString(opts.Metadata, cliproxyexecutor.ExecutionSessionMetadataKey)
+	}
+	if session == "" && settings.HashConversationPrefix {
+		session = conversationPrefixHash(opts.OriginalRequest)
+	}
+	if session == "" {
+		return ""
+	}
+	return session + "|" + canonicalModelKey(model)
+}
+
+// conversationPrefixHash hashes the stable opening of a conversation (system prompt and
+// first message) across the OpenAI, Claude, Gemini, and Responses request shapes.
+func conversationPrefixHash(raw []byte) string {
+	if len(raw) == 0 || !gjson.ValidBytes(raw) {
+		return ""
+	}
+	root := gjson.ParseBytes(raw)
+	parts := []string{
+		root.Get("system").Raw,
+		root.Get("systemInstruction").Raw,
+		root.Get("instructions").Raw,
+		root.Get("messages.0").Raw,
+		root.Get("contents.0").Raw,
+		root.Get("input.0").Raw,
+	}
+	if strings.Join(parts, "") == "" {
+		return ""
+	}
+	hasher := sha256.New()
+	for _, part := range parts {
+		_, _ = hasher.Write([]byte(part))
+		_, _ = hasher.Write([]byte{0})
+	}
+	return "prefix:" + hex.EncodeToString(hasher.Sum(nil))
+}
+
+func metadataString(meta map[string]any, key string) string {
+	if len(meta) == 0 {
+		return ""
+	}
+	switch v := meta[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []byte:
+		return strings.TrimSpace(string(v))
+	default:
+		return ""
+	}
+}
+
+// affinityCandidate returns the candidate bound to key when it is still usable for model.
+// Bindings to auths that are blocked (cooldown, disabled) are dropped so the caller falls
+// back to the regular selector and rebinds.
+func (m *Manager) affinityCandidate(key, model string, candidates []*Auth, now time.Time) *Auth {
+	authID := m.affinity.lookup(key, now)
+	if authID == "" {
+		return nil
+	}
+	for _, candidate := range candidates {
+		if candidate == nil || candidate.ID != authID {
+			continue
+		}
+		if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); blocked {
+			break
+		}
+		return candidate
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func newAffinityTestManager(t *testing.T) *Manager {
+	t.Helper()
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.SetConfig(&internalconfig.Config{
+		Routing: internalconfig.RoutingConfig{
+			SessionAffinity: internalconfig.SessionAffinityConfig{Enable: true, HashConversationPrefix: true},
+		},
+	})
+	return manager
+}
+
+func TestSessionAffinity_BindLookupExpiry(t *testing.T) {
+	t.Parallel()
+
+	var store sessionAffinity
+	now := time.Now()
+	store.bind("session|model", "auth-a", time.Minute, now)
+	if got := store.lookup("session|model", now.Add(30*time.Second)); got != "auth-a" {
+		t.Fatalf("lookup() = %q, want %q", got, "auth-a")
+	}
+	if got := store.lookup("session|model", now.Add(2*time.Minute)); got != "" {
+		t.Fatalf("lookup() after expiry = %q, want empty", got)
+	}
+	if len(store.entries) != 0 {
+		t.Fatalf("expired entry not removed, entries = %d", len(store.entries))
+	}
+}
+
+func TestSessionAffinity_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	store := sessionAffinity{maxKeys: 2}
+	now := time.Now()
+	store.bind("a", "auth-a", time.Hour, now)
+	store.bind("b", "auth-b", time.Hour, now)
+	if got := store.lookup("a", now); got != "auth-a" {
+		t.Fatalf("lookup(a) = %q, want auth-a", got)
+	}
+	store.bind("c", "auth-c", time.Hour, now)
+	if got := store.lookup("b", now); got != "" {
+		t.Fatalf("lookup(b) = %q, want the least recently used binding evicted", got)
+	}
+	if store.lookup("a", now) != "auth-a" || store.lookup("c", now) != "auth-c" {
+		t.Fatal("recently used bindings were evicted")
+	}
+}
+
+func TestPickWithAffinity_SticksToBoundAuth(t *testing.T) {
+	t.Parallel()
+
+	manager := newAffinityTestManager(t)
+	auths := []*Auth{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.SessionAffinityMetadataKey: "conv-1"}}
+
+	first, err := manager.pickWithAffinity(context.Background(), "gemini", "gemini-2.5-pro", opts, "", auths)
+	if err != nil || first == nil {
+		t.Fatalf("pickWithAffinity() = %v, %v", first, err)
+	}
+	for i := 0; i < 5; i++ {
+		got, errPick := manager.pickWithAffinity(context.Background(), "gemini", "gemini-2.5-pro", opts, "", auths)
+		if errPick != nil {
+			t.Fatalf("pickWithAffinity() #%d error = %v", i, errPick)
+		}
+		if got.ID != first.ID {
+			t.Fatalf("pickWithAffinity() #%d = %q, want sticky %q", i, got.ID, first.ID)
+		}
+	}
+}
+
+func TestPickWithAffinity_FallsBackWhenBoundAuthBlocked(t *testing.T) {
+	t.Parallel()
+
+	manager := newAffinityTestManager(t)
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.SessionAffinityMetadataKey: "conv-1"}}
+
+	first, err := manager.pickWithAffinity(context.Background(), "gemini", "gemini-2.5-pro", opts, "", auths)
+	if err != nil || first == nil {
+		t.Fatalf("pickWithAffinity() = %v, %v", first, err)
+	}
+	first.ModelStates = map[string]*ModelState{
+		"gemini-2.5-pro": {Unavailable: true, NextRetryAfter: time.Now().Add(time.Hour)},
+	}
+	remaining := make([]*Auth, 0, 1)
+	for _, auth := range auths {
+		if auth.ID != first.ID {
+			remaining = append(remaining, auth)
+		}
+	}
+
+	second, err := manager.pickWithAffinity(context.Background(), "gemini", "gemini-2.5-pro", opts, "", remaining)
+	if err != nil || second == nil {
+		t.Fatalf("pickWithAffinity() fallback = %v, %v", second, err)
+	}
+	if second.ID == first.ID {
+		t.Fatalf("pickWithAffinity() returned cooled-down auth %q", first.ID)
+	}
+	third, err := manager.pickWithAffinity(context.Background(), "gemini", "gemini-2.5-pro", opts, "", auths)
+	if err != nil || third == nil {
+		t.Fatalf("pickWithAffinity() rebind = %v, %v", third, err)
+	}
+	if third.ID != second.ID {
+		t.Fatalf("pickWithAffinity() after rebind = %q, want %q", third.ID, second.ID)
+	}
+}
+
+func TestSessionAffinityKey_ConversationPrefixHash(t *testing.T) {
+	t.Parallel()
+
+	settings := internalconfig.SessionAffinityConfig{Enable: true, HashConversationPrefix: true}
+	turnOne := cliproxyexecutor.Options{OriginalRequest: []byte(`{"system":"be nice","messages":[{"role":"user","content":"hi"}]}`)}
+	turnTwo := cliproxyexecutor.Options{OriginalRequest: []byte(`{"system":"be nice","messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"},{"role":"user","content":"more"}]}`)}
+	other := cliproxyexecutor.Options{OriginalRequest: []byte(`{"system":"be nice","messages":[{"role":"user","content":"bye"}]}`)}
+
+	keyOne := sessionAffinityKey(settings, turnOne, "claude-sonnet-4")
+	if keyOne == "" {
+		t.Fatal("sessionAffinityKey() = empty, want prefix hash")
+	}
+	if keyTwo := sessionAffinityKey(settings, turnTwo, "claude-sonnet-4"); keyTwo != keyOne {
+		t.Fatalf("sessionAffinityKey() differs across turns: %q vs %q", keyOne, keyTwo)
+	}
+	if keyOther := sessionAffinityKey(settings, other, "claude-sonnet-4"); keyOther == keyOne {
+		t.Fatal("sessionAffinityKey() collides for different conversations")
+	}
+
+	settings.HashConversationPrefix = false
+	if key := sessionAffinityKey(settings, turnOne, "claude-sonnet-4"); key != "" {
+		t.Fatalf("sessionAffinityKey() without hashing = %q, want empty", key)
+	}
+}
@@ -0,0 +1,488 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitOpenDuration     = 30 * time.Second
+	circuitProbeTimeout            = 30 * time.Second
+)
+
+// CircuitState is the state of an upstream circuit breaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects requests until the open period elapses.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe request through to test the upstream.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerStatus is a snapshot of a single upstream breaker.
+type CircuitBreakerStatus struct {
+	BaseURL             string       `json:"base_url"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	NextProbeAt         *time.Time   `json:"next_probe_at,omitempty"`
+	LastError           string       `json:"last_error,omitempty"`
+}
+
+type circuitBreaker struct {
+	state       CircuitState
+	failures    int
+	openedAt    time.Time
+	nextProbeAt time.Time
+	lastError   string
+	// probe fires the background probe when the open period elapses.
+	probe *time.Timer
+}
+
+// circuitOutcome classifies a request result for the breaker.
+type circuitOutcome int
+
+const (
+	// circuitNeutral results say nothing about the upstream, e.g. a cancelled request.
+	circuitNeutral circuitOutcome = iota
+	// circuitReachable results prove the upstream answered.
+	circuitReachable
+	// circuitFailed results are dial/TLS errors, timeouts and 5xx responses.
+	circuitFailed
+)
+
+// circuitBreakers tracks breaker state per upstream base URL.
+type circuitBreakers struct {
+	mu      sync.Mutex
+	entries map[string]*circuitBreaker
+}
+
+type circuitSettings struct {
+	threshold    int
+	openDuration time.Duration
+}
+
+// circuitBreakerSettings returns the active breaker configuration, or ok=false when disabled.
+func (m *Manager) circuitBreakerSettings() (circuitSettings, bool) {
+	if m == nil {
+		return circuitSettings{}, false
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || !cfg.CircuitBreaker.Enable {
+		return circuitSettings{}, false
+	}
+	settings := circuitSettings{
+		threshold:    cfg.CircuitBreaker.FailureThreshold,
+		openDuration: time.Duration(cfg.CircuitBreaker.OpenSeconds) * time.Second,
+	}
+	if settings.threshold <= 0 {
+		settings.threshold = defaultCircuitFailureThreshold
+	}
+	if settings.openDuration <= 0 {
+		settings.openDuration = defaultCircuitOpenDuration
+	}
+	return settings, true
+}
+
+// circuitKeyForAuth returns the breaker key (normalized base URL) for openai-compatibility auths.
+func circuitKeyForAuth(auth *Auth) string {
+	if auth == nil || len(auth.Attributes) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(auth.Attributes["compat_name"]) == "" && !strings.EqualFold(strings.TrimSpace(auth.Provider), "openai-compatibility") {
+		return ""
+	}
+	base := strings.TrimSpace(auth.Attributes["base_url"])
+	return strings.TrimRight(strings.ToLower(base), "/")
+}
+
+// blocked reports whether requests to key must be skipped right now. An open breaker whose
+// open period has elapsed is not blocked: the next request becomes the half-open probe.
+func (b *circuitBreakers) blocked(key string, now time.Time) bool {
+	if b == nil || key == "" {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry := b.entries[key]
+	if entry == nil || entry.state == CircuitClosed {
+		return false
+	}
+	return now.Before(entry.nextProbeAt)
+}
+
+// acquire records that a request is being sent to key. When the breaker is due for a probe it
+// moves to half-open and blocks further requests until the probe reports back or times out.
+func (b *circuitBreakers) acquire(key string, settings circuitSettings, now time.Time) {
+	if b == nil || key == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry := b.entries[key]
+	if entry == nil || entry.state == CircuitClosed || now.Before(entry.nextProbeAt) {
+		return
+	}
+	entry.state = CircuitHalfOpen
+	entry.nextProbeAt = now.Add(settings.openDuration)
+}
+
+// record feeds a request outcome into the breaker for key and reports whether it opened the
+// breaker. Only connection errors, timeouts and 5xx responses count as failures; any other
+// response proves the upstream is reachable, and results that say nothing about the upstream
+// (such as cancellations) only give back a half-open probe slot.
+func (b *circuitBreakers) record(key string, result Result, settings circuitSettings, now time.Time) bool {
+	if b == nil || key == "" {
+		return false
+	}
+	outcome := classifyCircuitResult(result)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry := b.entries[key]
+	switch outcome {
+	case circuitNeutral:
+		if entry != nil && entry.state == CircuitHalfOpen {
+			entry.state = CircuitOpen
+			entry.nextProbeAt = now
+		}
+		return false
+	case circuitReachable:
+		if entry != nil {
+			if entry.state != CircuitClosed {
+				log.Infof("circuit breaker closed for %s", key)
+			}
+			b.removeLocked(key)
+		}
+		return false
+	}
+	if b.entries == nil {
+		b.entries = make(map[string]*circuitBreaker)
+	}
+	if entry == nil {
+		entry = &circuitBreaker{state: CircuitClosed}
+		b.entries[key] = entry
+	}
+	entry.failures++
+	if result.Error != nil {
+		entry.lastError = result.Error.Message
+	}
+	if entry.state == CircuitHalfOpen || (entry.state == CircuitClosed && entry.failures >= settings.threshold) {
+		entry.state = CircuitOpen
+		entry.openedAt = now
+		entry.nextProbeAt = now.Add(settings.openDuration)
+		log.Warnf("circuit breaker opened for %s after %d consecutive failures", key, entry.failures)
+		return true
+	}
+	return false
+}
+
+// release gives back the half-open probe slot of key taken by a request that ended without a
+// result, such as a cancelled hedge attempt, so the next request or probe can test the upstream.
+func (b *circuitBreakers) release(key string, now time.Time) bool {
+	if b == nil || key == "" {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry := b.entries[key]
+	if entry == nil || entry.state != CircuitHalfOpen {
+		return false
+	}
+	entry.state = CircuitOpen
+	entry.nextProbeAt = now
+	return true
+}
+
+// probeDue reports whether key is open and its open period has elapsed.
+func (b *circuitBreakers) probeDue(key string, now time.Time) bool {
+	if b == nil || key == "" {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry := b.entries[key]
+	return entry != nil && entry.state == CircuitOpen && !now.Before(entry.nextProbeAt)
+}
+
+// halfOpen reports whether a probe of key is in flight.
+func (b *circuitBreakers) halfOpen(key string) bool {
+	if b == nil || key == "" {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry := b.entries[key]
+	return entry != nil && entry.state == CircuitHalfOpen
+}
+
+// schedule arms the background probe of key to fire after delay, replacing any pending one.
+func (b *circuitBreakers) schedule(key string, delay time.Duration, fire func()) {
+	if b == nil || key == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry := b.entries[key]
+	if entry == nil || entry.state == CircuitClosed {
+		return
+	}
+	if entry.probe != nil {
+		entry.probe.Stop()
+	}
+	entry.probe = time.AfterFunc(delay, fire)
+}
+
+// reopen restarts the open period of key after a probe that ended without a verdict.
+func (b *circuitBreakers) reopen(key string, settings circuitSettings, now time.Time) {
+	if b == nil || key == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry := b.entries[key]
+	if entry == nil || entry.state == CircuitClosed {
+		return
+	}
+	entry.state = CircuitOpen
+	entry.nextProbeAt = now.Add(settings.openDuration)
+}
+
+// forget drops the breaker of key, e.g. when no credential uses the upstream any more.
+func (b *circuitBreakers) forget(key string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(key)
+}
+
+func (b *circuitBreakers) removeLocked(key string) {
+	if entry := b.entries[key]; entry != nil && entry.probe != nil {
+		entry.probe.Stop()
+	}
+	delete(b.entries, key)
+}
+
+// classifyCircuitResult decides how a request result bears on the health of the upstream.
+func classifyCircuitResult(result Result) circuitOutcome {
+	if result.Success {
+		return circuitReachable
+	}
+	if result.Error == nil {
+		return circuitNeutral
+	}
+	if status := statusCodeFromResult(result.Error); status >= 500 {
+		return circuitFailed
+	} else if status > 0 {
+		return circuitReachable
+	}
+	if isUpstreamTransportFailure(result.Error.Unwrap()) {
+		return circuitFailed
+	}
+	return circuitNeutral
+}
+
+// isUpstreamTransportFailure reports whether err shows the upstream could not be reached in
+// time: DNS and dial errors, TLS handshake failures and timeouts. Cancellations do not count.
+func isUpstreamTransportFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if netErr, ok := errors.AsType[net.Error](err); ok && netErr.Timeout() {
+		return true
+	}
+	if _, ok := errors.AsType[*net.DNSError](err); ok {
+		return true
+	}
+	if opErr, ok := errors.AsType[*net.OpError](err); ok && opErr.Op == "dial" {
+		return true
+	}
+	if _, ok := errors.AsType[tls.RecordHeaderError](err); ok {
+		return true
+	}
+	if _, ok := errors.AsType[*tls.CertificateVerificationError](err); ok {
+		return true
+	}
+	if _, ok := errors.AsType[tls.AlertError](err); ok {
+		return true
+	}
+	return false
+}
+
+// isCircuitOpenError reports whether err was produced because every candidate upstream is
+// behind an open breaker; waiting for credential cooldowns would not help.
+func isCircuitOpenError(err error) bool {
+	authErr, ok := errors.AsType[*Error](err)
+	return ok && authErr != nil && authErr.Code == "circuit_open"
+}
+
+func (b *circuitBreakers) snapshot() []CircuitBreakerStatus {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]CircuitBreakerStatus, 0, len(b.entries))
+	for key, entry := range b.entries {
+		status := CircuitBreakerStatus{
+			BaseURL:             key,
+			State:               entry.state,
+			ConsecutiveFailures: entry.failures,
+			LastError:           entry.lastError,
+		}
+		if entry.state != CircuitClosed {
+			openedAt, nextProbeAt := entry.openedAt, entry.nextProbeAt
+			status.OpenedAt = &openedAt
+			status.NextProbeAt = &nextProbeAt
+		}
+		out = append(out, status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].BaseURL < out[j].BaseURL })
+	return out
+}
+
+// CircuitBreakers returns the state of every openai-compatibility upstream that has recently failed.
+// Upstreams without recent failures are closed and omitted.
+func (m *Manager) CircuitBreakers() []CircuitBreakerStatus {
+	if m == nil {
+		return nil
+	}
+	return m.circuits.snapshot()
+}
+
+// recordCircuitResult feeds a result into the breaker of key and arms the background probe
+// when the breaker opened or a probe slot became free.
+func (m *Manager) recordCircuitResult(key string, result Result) {
+	settings, ok := m.circuitBreakerSettings()
+	if !ok || key == "" {
+		return
+	}
+	now := time.Now()
+	if m.circuits.record(key, result, settings, now) {
+		m.scheduleCircuitProbe(key, settings.openDuration)
+	} else if m.circuits.probeDue(key, now) {
+		m.scheduleCircuitProbe(key, 0)
+	}
+}
+
+// releaseCircuit gives back the half-open probe slot an attempt on auth may hold when the
+// attempt ends without a result, and lets the background probe take over.
+func (m *Manager) releaseCircuit(auth *Auth) {
+	if _, ok := m.circuitBreakerSettings(); !ok {
+		return
+	}
+	key := circuitKeyForAuth(auth)
+	if m.circuits.release(key, time.Now()) {
+		m.scheduleCircuitProbe(key, 0)
+	}
+}
+
+func (m *Manager) scheduleCircuitProbe(key string, delay time.Duration) {
+	m.circuits.schedule(key, delay, func() { m.probeCircuit(key) })
+}
+
+// probeCircuit sends a probe request to an open upstream once its open period has elapsed, so
+// the breaker closes without waiting for client traffic. The outcome reaches the breaker through
+// MarkResult like any other request; a probe that ends without one reopens the breaker.
+func (m *Manager) probeCircuit(key string) {
+	settings, ok := m.circuitBreakerSettings()
+	if !ok {
+		m.circuits.forget(key)
+		return
+	}
+	if !m.circuits.probeDue(key, time.Now()) {
+		return
+	}
+	auth, model := m.circuitProbeTarget(key)
+	if auth == nil {
+		// Without a credential to probe with, the next client request becomes the probe.
+		log.Debugf("circuit breaker: no credential to probe %s", key)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), circuitProbeTimeout)
+	defer cancel()
+	req, opts := healthProbeRequest(auth, model)
+	if _, err := m.Execute(ctx, []string{auth.Provider}, req, opts); err != nil {
+		log.Debugf("circuit breaker: probe of %s via %s failed: %v", key, auth.ID, err)
+	}
+	now := time.Now()
+	if m.circuits.halfOpen(key) || m.circuits.probeDue(key, now) {
+		m.circuits.reopen(key, settings, now)
+		m.scheduleCircuitProbe(key, settings.openDuration)
+	}
+}
+
+// circuitProbeTarget picks an enabled credential behind the upstream key and a cheap model to
+// probe it with.
+func (m *Manager) circuitProbeTarget(key string) (*Auth, string) {
+	for _, auth := range m.snapshotAuths() {
+		if auth.Disabled || auth.Status == StatusDisabled || circuitKeyForAuth(auth) != key {
+			continue
+		}
+		if m.executorFor(auth.Provider) == nil {
+			continue
+		}
+		if model := cheapestModel(registry.GetGlobalRegistry().GetModelsForClient(auth.ID)); model != "" {
+			return auth, model
+		}
+	}
+	return nil, ""
+}
+
+// filterOpenCircuits drops candidates whose upstream breaker is open. It returns a circuit_open
+// error when every candidate was dropped for that reason.
+func (m *Manager) filterOpenCircuits(candidates []*Auth, now time.Time) ([]*Auth, *Error) {
+	if _, ok := m.circuitBreakerSettings(); !ok || len(candidates) == 0 {
+		return candidates, nil
+	}
+	kept := candidates[:0:0]
+	var openKeys []string
+	for _, candidate := range candidates {
+		if key := circuitKeyForAuth(candidate); m.circuits.blocked(key, now) {
+			openKeys = append(openKeys, key)
+			continue
+		}
+		kept = append(kept, candidate)
+	}
+	if len(kept) == 0 {
+		return nil, &Error{
+			Code:       "circuit_open",
+			Message:    fmt.Sprintf("upstream circuit open for %s", strings.Join(uniqueStrings(openKeys), ", ")),
+			Retryable:  true,
+			HTTPStatus: 503,
+		}
+	}
+	return kept, nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		out = append(out, value)
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func newCircuitTestManager(t *testing.T) (*Manager, *Auth) {
+	t.Helper()
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.SetConfig(&internalconfig.Config{
+		CircuitBreaker: internalconfig.CircuitBreakerConfig{Enable: true, FailureThreshold: 2, OpenSeconds: 60},
+	})
+	auth := &Auth{
+		ID:         "compat-1",
+		Provider:   "openrouter",
+		Attributes: map[string]string{"compat_name": "openrouter", "base_url": "https://OpenRouter.ai/api/v1/"},
+	}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return manager, auth
+}
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	manager, auth := newCircuitTestManager(t)
+	failure := Result{AuthID: auth.ID, Provider: auth.Provider, Error: &Error{Message: "bad gateway", HTTPStatus: 502}}
+
+	manager.MarkResult(context.Background(), failure)
+	if _, errCircuit := manager.filterOpenCircuits([]*Auth{auth}, time.Now()); errCircuit != nil {
+		t.Fatalf("breaker opened before threshold: %v", errCircuit)
+	}
+	manager.MarkResult(context.Background(), failure)
+	if _, errCircuit := manager.filterOpenCircuits([]*Auth{auth}, time.Now()); errCircuit == nil || errCircuit.Code != "circuit_open" {
+		t.Fatalf("filterOpenCircuits() error = %v, want circuit_open", errCircuit)
+	}
+
+	states := manager.CircuitBreakers()
+	if len(states) != 1 {
+		t.Fatalf("CircuitBreakers() len = %d, want 1", len(states))
+	}
+	if states[0].BaseURL != "https://openrouter.ai/api/v1" || states[0].State != CircuitOpen || states[0].ConsecutiveFailures != 2 {
+		t.Fatalf("CircuitBreakers()[0] = %+v", states[0])
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	manager, auth := newCircuitTestManager(t)
+	for i := 0; i < 3; i++ {
+		manager.MarkResult(context.Background(), Result{AuthID: auth.ID, Error: &Error{Message: "unauthorized", HTTPStatus: 401}})
+	}
+	if states := manager.CircuitBreakers(); len(states) != 0 {
+		t.Fatalf("CircuitBreakers() = %+v, want none", states)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	settings := circuitSettings{threshold: 1, openDuration: time.Minute}
+	var breakers circuitBreakers
+	now := time.Now()
+	key := "https://example.com/v1"
+	failure := Result{Error: errorFromExecution(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})}
+
+	breakers.record(key, failure, settings, now)
+	if !breakers.blocked(key, now.Add(30*time.Second)) {
+		t.Fatal("blocked() = false while open")
+	}
+
+	probeAt := now.Add(2 * time.Minute)
+	if breakers.blocked(key, probeAt) {
+		t.Fatal("blocked() = true when probe is due")
+	}
+	breakers.acquire(key, settings, probeAt)
+	if got := breakers.snapshot()[0].State; got != CircuitHalfOpen {
+		t.Fatalf("state after probe acquire = %q, want %q", got, CircuitHalfOpen)
+	}
+	if !breakers.blocked(key, probeAt.Add(time.Second)) {
+		t.Fatal("blocked() = false while probe in flight")
+	}
+
+	breakers.record(key, failure, settings, probeAt.Add(time.Second))
+	if got := breakers.snapshot()[0].State; got != CircuitOpen {
+		t.Fatalf("state after failed probe = %q, want %q", got, CircuitOpen)
+	}
+
+	nextProbe := probeAt.Add(2 * time.Minute)
+	breakers.acquire(key, settings, nextProbe)
+	breakers.record(key, Result{Success: true}, settings, nextProbe)
+	if states := breakers.snapshot(); len(states) != 0 {
+		t.Fatalf("snapshot() after successful probe = %+v, want closed", states)
+	}
+}
+
+func TestCircuitBreaker_CancellationIsNeutral(t *testing.T) {
+	settings := circuitSettings{threshold: 1, openDuration: time.Minute}
+	var breakers circuitBreakers
+	now := time.Now()
+	key := "https://example.com/v1"
+	cancelled := Result{Error: errorFromExecution(context.Canceled)}
+
+	breakers.record(key, cancelled, settings, now)
+	if states := breakers.snapshot(); len(states) != 0 {
+		t.Fatalf("snapshot() after cancellation = %+v, want no failure counted", states)
+	}
+
+	breakers.record(key, Result{Error: errorFromExecution(context.DeadlineExceeded)}, settings, now)
+	probeAt := now.Add(2 * time.Minute)
+	breakers.acquire(key, settings, probeAt)
+	breakers.record(key, cancelled, settings, probeAt)
+	states := breakers.snapshot()
+	if len(states) != 1 || states[0].State != CircuitOpen {
+		t.Fatalf("snapshot() after cancelled probe = %+v, want still open", states)
+	}
+	if breakers.blocked(key, probeAt) {
+		t.Fatal("blocked() = true after the cancelled probe gave back its slot")
+	}
+}
+
+func TestCircuitBreaker_ReleaseFreesHalfOpenSlot(t *testing.T) {
+	settings := circuitSettings{threshold: 1, openDuration: time.Minute}
+	var breakers circuitBreakers
+	now := time.Now()
+	key := "https://example.com/v1"
+
+	breakers.record(key, Result{Error: &Error{Message: "bad gateway", HTTPStatus: 502}}, settings, now)
+	if breakers.release(key, now) {
+		t.Fatal("release() = true while no probe is in flight")
+	}
+	probeAt := now.Add(2 * time.Minute)
+	breakers.acquire(key, settings, probeAt)
+	if !breakers.blocked(key, probeAt) {
+		t.Fatal("blocked() = false while probe in flight")
+	}
+	if !breakers.release(key, probeAt) || breakers.blocked(key, probeAt) {
+		t.Fatal("release() did not free the half-open slot")
+	}
+}
+
+func TestCircuitBreaker_BackgroundProbeCloses(t *testing.T) {
+	var probes int
+	executor := &stubExecutor{
+		provider: "openrouter",
+		execute: func(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+			probes++
+			return cliproxyexecutor.Response{Payload: []byte(`{}`)}, nil
+		},
+	}
+	cfg := &internalconfig.Config{CircuitBreaker: internalconfig.CircuitBreakerConfig{Enable: true, FailureThreshold: 1, OpenSeconds: 60}}
+	auth := &Auth{ID: "compat-probe", Attributes: map[string]string{"compat_name": "openrouter", "base_url": "https://openrouter.ai/api/v1"}}
+	manager := newStubManager(t, cfg, executor, []*Auth{auth}, "probe-mini")
+	key := circuitKeyForAuth(auth)
+
+	manager.MarkResult(context.Background(), Result{AuthID: auth.ID, Error: &Error{Message: "bad gateway", HTTPStatus: 502}})
+	manager.circuits.mu.Lock()
+	entry := manager.circuits.entries[key]
+	if entry == nil || entry.probe == nil {
+		manager.circuits.mu.Unlock()
+		t.Fatal("opening the breaker did not schedule a background probe")
+	}
+	entry.nextProbeAt = time.Now().Add(-time.Second)
+	manager.circuits.mu.Unlock()
+
+	manager.probeCircuit(key)
+	if probes != 1 {
+		t.Fatalf("probes = %d, want 1", probes)
+	}
+	if states := manager.CircuitBreakers(); len(states) != 0 {
+		t.Fatalf("CircuitBreakers() after successful probe = %+v, want closed", states)
+	}
+}
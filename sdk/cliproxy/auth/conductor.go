@@ -17,11 +17,14 @@ import (
 	"github.com/google/uuid"
 	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/redaction"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ProviderExecutor defines the contract required by Manager to execute provider calls.
@@ -151,6 +154,18 @@ type Manager struct {
 	// It is initialized in NewManager; never Load() before first Store().
 	runtimeConfig atomic.Value
 
+	// affinity pins conversation sessions to the auth that served them.
+	affinity sessionAffinity
+
+	// circuits tracks per-upstream circuit breakers for openai-compatibility providers.
+	circuits circuitBreakers
+
+	// hedges tracks latency percentiles and the budget for hedged requests.
+	hedges hedgeTracker
+
+	// health holds health-check probe results and the probe scheduler.
+	health healthChecker
+
 	// Optional HTTP RoundTripper provider injected by host.
 	rtProvider RoundTripperProvider
 
@@ -497,11 +512,13 @@ func (m *Manager) Load(ctx context.Context) error {
 
 // Execute performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
-func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (_ cliproxyexecutor.Response, err error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	ctx, span := startExecuteSpan(ctx, "cliproxy.Execute", normalized, req.Model)
+	defer func() { tracing.End(span, err) }()
 
 	_, maxWait := m.retrySettings()
 
@@ -516,6 +533,7 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 		if !shouldRetry {
 			break
 		}
+		recordRetry(span, attempt, wait, errExec)
 		if errWait := waitForCooldown(ctx, wait); errWait != nil {
 			return cliproxyexecutor.Response{}, errWait
 		}
@@ -528,11 +546,13 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 
 // ExecuteCount performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
-func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (_ cliproxyexecutor.Response, err error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	ctx, span := startExecuteSpan(ctx, "cliproxy.ExecuteCount", normalized, req.Model)
+	defer func() { tracing.End(span, err) }()
 
 	_, maxWait := m.retrySettings()
 
@@ -547,6 +567,7 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 		if !shouldRetry {
 			break
 		}
+		recordRetry(span, attempt, wait, errExec)
 		if errWait := waitForCooldown(ctx, wait); errWait != nil {
 			return cliproxyexecutor.Response{}, errWait
 		}
@@ -559,11 +580,13 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 
 // ExecuteStream performs a streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
-func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (_ *cliproxyexecutor.StreamResult, err error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	ctx, span := startExecuteSpan(ctx, "cliproxy.ExecuteStream", normalized, req.Model)
+	defer func() { tracing.End(span, err) }()
 
 	_, maxWait := m.retrySettings()
 
@@ -578,6 +601,7 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cli
 		if !shouldRetry {
 			break
 		}
+		recordRetry(span, attempt, wait, errStream)
 		if errWait := waitForCooldown(ctx, wait); errWait != nil {
 			return nil, errWait
 		}
@@ -594,11 +618,15 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 	}
 	routeModel := req.Model
 	opts = ensureRequestedModelMetadata(opts, routeModel)
+	if settings, ok := m.hedgeSettings(routeModel); ok {
+		return m.executeMixedHedged(ctx, providers, req, opts, settings)
+	}
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
 		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
 		if errPick != nil {
+			recordPickFailure(ctx, errPick)
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
 			}
@@ -610,25 +638,25 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 		publishSelectedAuthMetadata(opts.Metadata, auth.ID)
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
-		}
 		execReq := req
 		execReq.Model = rewriteModelForAuth(routeModel, auth)
 		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
 		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		resp, errExec := executor.Execute(execCtx, auth, execReq, opts)
+		execCtx, attemptSpan := startAttemptSpan(ctx, auth, provider, execReq.Model, len(tried), false)
+		if rt := m.roundTripperFor(auth); rt != nil {
+			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+		}
+		execCtx, redactions := redaction.WithTracker(execCtx)
+		resp, errExec := m.executeBridged(execCtx, executor, auth, provider, execReq, opts)
+		endAttemptSpan(attemptSpan, errExec)
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			if errCtx := execCtx.Err(); errCtx != nil {
+				m.releaseCircuit(auth)
 				return cliproxyexecutor.Response{}, errCtx
 			}
-			result.Error = &Error{Message: errExec.Error()}
-			if se, ok := errors.AsType[cliproxyexecutor.StatusError](errExec); ok && se != nil {
-				result.Error.HTTPStatus = se.StatusCode()
-			}
+			result.Error = errorFromExecution(errExec)
 			if ra := retryAfterFromError(errExec); ra != nil {
 				result.RetryAfter = ra
 			}
@@ -640,6 +668,8 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 			continue
 		}
 		m.MarkResult(execCtx, result)
+		resp.Payload = redactions.Restore(resp.Payload)
+		resp.Payload = m.responseScriptsFor(auth, opts, routeModel, execReq.Model).apply(ctx, resp.Payload)
 		return resp, nil
 	}
 }
@@ -655,6 +685,7 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 	for {
 		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
 		if errPick != nil {
+			recordPickFailure(ctx, errPick)
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
 			}
@@ -666,25 +697,24 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 		publishSelectedAuthMetadata(opts.Metadata, auth.ID)
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
-		}
 		execReq := req
 		execReq.Model = rewriteModelForAuth(routeModel, auth)
 		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
 		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
+		execCtx, attemptSpan := startAttemptSpan(ctx, auth, provider, execReq.Model, len(tried), false)
+		if rt := m.roundTripperFor(auth); rt != nil {
+			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+		}
 		resp, errExec := executor.CountTokens(execCtx, auth, execReq, opts)
+		endAttemptSpan(attemptSpan, errExec)
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			if errCtx := execCtx.Err(); errCtx != nil {
+				m.releaseCircuit(auth)
 				return cliproxyexecutor.Response{}, errCtx
 			}
-			result.Error = &Error{Message: errExec.Error()}
-			if se, ok := errors.AsType[cliproxyexecutor.StatusError](errExec); ok && se != nil {
-				result.Error.HTTPStatus = se.StatusCode()
-			}
+			result.Error = errorFromExecution(errExec)
 			if ra := retryAfterFromError(errExec); ra != nil {
 				result.RetryAfter = ra
 			}
@@ -711,6 +741,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 	for {
 		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
 		if errPick != nil {
+			recordPickFailure(ctx, errPick)
 			if lastErr != nil {
 				return nil, lastErr
 			}
@@ -722,25 +753,24 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 		publishSelectedAuthMetadata(opts.Metadata, auth.ID)
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
-		}
 		execReq := req
 		execReq.Model = rewriteModelForAuth(routeModel, auth)
 		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
 		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		streamResult, errStream := executor.ExecuteStream(execCtx, auth, execReq, opts)
+		execCtx, attemptSpan := startAttemptSpan(ctx, auth, provider, execReq.Model, len(tried), false)
+		if rt := m.roundTripperFor(auth); rt != nil {
+			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+		}
+		execCtx, redactions := redaction.WithTracker(execCtx)
+		streamResult, errStream := m.executeStreamBridged(execCtx, executor, auth, provider, execReq, opts)
 		if errStream != nil {
+			endAttemptSpan(attemptSpan, errStream)
 			if errCtx := execCtx.Err(); errCtx != nil {
+				m.releaseCircuit(auth)
 				return nil, errCtx
 			}
-			rerr := &Error{Message: errStream.Error()}
-			if se, ok := errors.AsType[cliproxyexecutor.StatusError](errStream); ok && se != nil {
-				rerr.HTTPStatus = se.StatusCode()
-			}
-			result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: false, Error: rerr}
+			result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: false, Error: errorFromExecution(errStream)}
 			result.RetryAfter = retryAfterFromError(errStream)
 			m.MarkResult(execCtx, result)
 			if isRequestInvalidError(errStream) {
@@ -749,26 +779,21 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 			lastErr = errStream
 			continue
 		}
+		scripts := m.responseScriptsFor(auth, opts, routeModel, execReq.Model)
 		out := make(chan cliproxyexecutor.StreamChunk)
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
 			defer close(out)
 			var failed bool
+			var streamErr error
+			defer func() { endAttemptSpan(attemptSpan, streamErr) }()
 			forward := true
-			for chunk := range streamChunks {
-				if chunk.Err != nil && !failed {
-					failed = true
-					rerr := &Error{Message: chunk.Err.Error()}
-					if se, ok := errors.AsType[cliproxyexecutor.StatusError](chunk.Err); ok && se != nil {
-						rerr.HTTPStatus = se.StatusCode()
-					}
-					m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: false, Error: rerr})
-				}
+			send := func(chunk cliproxyexecutor.StreamChunk) {
 				if !forward {
-					continue
+					return
 				}
 				if streamCtx == nil {
 					out <- chunk
-					continue
+					return
 				}
 				select {
 				case <-streamCtx.Done():
@@ -776,6 +801,31 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 				case out <- chunk:
 				}
 			}
+			restorer := redactions.Stream()
+			flush := func() {
+				for _, payload := range restorer.Flush() {
+					send(cliproxyexecutor.StreamChunk{Payload: scripts.applyStream(streamCtx, payload)})
+				}
+			}
+			for chunk := range streamChunks {
+				if chunk.Err != nil && !failed {
+					failed = true
+					streamErr = chunk.Err
+					m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: false, Error: errorFromExecution(chunk.Err)})
+				}
+				if !forward {
+					continue
+				}
+				if chunk.Err != nil {
+					flush()
+					send(chunk)
+					continue
+				}
+				for _, payload := range restorer.Push(chunk.Payload) {
+					send(cliproxyexecutor.StreamChunk{Payload: scripts.applyStream(streamCtx, payload)})
+				}
+			}
+			flush()
 			if !failed {
 				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true})
 			}
@@ -1179,7 +1229,7 @@ func (m *Manager) shouldRetryAfterError(err error, attempt int, providers []stri
 	if status := statusCodeFromError(err); status == http.StatusOK {
 		return 0, false
 	}
-	if isRequestInvalidError(err) {
+	if isRequestInvalidError(err) || isCircuitOpenError(err) {
 		return 0, false
 	}
 	wait, found := m.closestCooldownWait(providers, model, attempt)
@@ -1193,12 +1243,15 @@ func waitForCooldown(ctx context.Context, wait time.Duration) error {
 	if wait <= 0 {
 		return nil
 	}
+	_, span := tracing.Start(ctx, "cliproxy.waitForCooldown", attribute.Int64("cliproxy.wait_ms", wait.Milliseconds()))
 	timer := time.NewTimer(wait)
 	defer timer.Stop()
 	select {
 	case <-ctx.Done():
+		tracing.End(span, ctx.Err())
 		return ctx.Err()
 	case <-timer.C:
+		span.End()
 		return nil
 	}
 }
@@ -1214,10 +1267,12 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	suspendReason := ""
 	clearModelQuota := false
 	setModelQuota := false
+	circuitKey := ""
 
 	m.mu.Lock()
 	if auth, ok := m.auths[result.AuthID]; ok && auth != nil {
 		now := time.Now()
+		circuitKey = circuitKeyForAuth(auth)
 
 		if result.Success {
 			if result.Model != "" {
@@ -1310,6 +1365,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	}
 	m.mu.Unlock()
 
+	m.recordCircuitResult(circuitKey, result)
 	if clearModelQuota && result.Model != "" {
 		registry.GetGlobalRegistry().ClearModelQuotaExceeded(result.AuthID, result.Model)
 	}
@@ -1699,7 +1755,12 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 		m.mu.RUnlock()
 		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
-	selected, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
+	candidates, errCircuit := m.filterOpenCircuits(candidates, time.Now())
+	if errCircuit != nil {
+		m.mu.RUnlock()
+		return nil, nil, errCircuit
+	}
+	selected, errPick := m.pickWithAffinity(ctx, provider, model, opts, pinnedAuthID, candidates)
 	if errPick != nil {
 		m.mu.RUnlock()
 		return nil, nil, errPick
@@ -1776,7 +1837,12 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 		m.mu.RUnlock()
 		return nil, nil, "", &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
-	selected, errPick := m.selector.Pick(ctx, "mixed", model, opts, candidates)
+	candidates, errCircuit := m.filterOpenCircuits(candidates, time.Now())
+	if errCircuit != nil {
+		m.mu.RUnlock()
+		return nil, nil, "", errCircuit
+	}
+	selected, errPick := m.pickWithAffinity(ctx, "mixed", model, opts, pinnedAuthID, candidates)
 	if errPick != nil {
 		m.mu.RUnlock()
 		return nil, nil, "", errPick
@@ -1804,6 +1870,39 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 	return authCopy, executor, providerKey, nil
 }
 
+// pickWithAffinity selects an auth from candidates, honouring session affinity when enabled.
+// A session bound to a still-usable candidate reuses it; otherwise the selector decides and
+// the session is rebound to its choice.
+func (m *Manager) pickWithAffinity(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, pinnedAuthID string, candidates []*Auth) (*Auth, error) {
+	affinityKey := ""
+	var ttl time.Duration
+	if settings, ok := m.sessionAffinitySettings(); ok && pinnedAuthID == "" {
+		affinityKey = sessionAffinityKey(settings, opts, model)
+		ttl = time.Duration(settings.TTLSeconds) * time.Second
+	}
+	now := time.Now()
+	if affinityKey != "" {
+		if bound := m.affinityCandidate(affinityKey, model, candidates, now); bound != nil {
+			if settings, ok := m.circuitBreakerSettings(); ok {
+				m.circuits.acquire(circuitKeyForAuth(bound), settings, now)
+			}
+			m.affinity.bind(affinityKey, bound.ID, ttl, now)
+			return bound, nil
+		}
+	}
+	selected, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
+	if errPick != nil || selected == nil {
+		return selected, errPick
+	}
+	if settings, ok := m.circuitBreakerSettings(); ok {
+		m.circuits.acquire(circuitKeyForAuth(selected), settings, now)
+	}
+	if affinityKey != "" {
+		m.affinity.bind(affinityKey, selected.ID, ttl, now)
+	}
+	return selected, nil
+}
+
 func (m *Manager) persist(ctx context.Context, auth *Auth) error {
 	if m.store == nil || auth == nil {
 		return nil
@@ -2130,7 +2229,12 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 		return
 	}
 	cloned := auth.Clone()
-	updated, err := exec.Refresh(ctx, cloned)
+	refreshCtx, span := tracing.Start(ctx, "cliproxy.refreshAuth",
+		attribute.String("cliproxy.provider", auth.Provider),
+		attribute.String("cliproxy.auth_index", auth.Index),
+	)
+	updated, err := exec.Refresh(refreshCtx, cloned)
+	tracing.End(span, err)
 	if err != nil && errors.Is(err, context.Canceled) {
 		log.Debugf("refresh canceled for %s, %s", auth.Provider, auth.ID)
 		return
@@ -1,5 +1,11 @@
 package auth
 
+import (
+	"errors"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
 // Error describes an authentication related failure in a provider agnostic format.
 type Error struct {
 	// Code is a short machine readable identifier.
@@ -10,6 +16,18 @@ type Error struct {
 	Retryable bool `json:"retryable"`
 	// HTTPStatus optionally records an HTTP-like status code for the error.
 	HTTPStatus int `json:"http_status,omitempty"`
+	// cause is the executor error the result was built from, when known.
+	cause error
+}
+
+// errorFromExecution converts an executor error into a result error, keeping the original
+// error so transport failures can be told apart from cancellations.
+func errorFromExecution(err error) *Error {
+	result := &Error{Message: err.Error(), cause: err}
+	if se, ok := errors.AsType[cliproxyexecutor.StatusError](err); ok && se != nil {
+		result.HTTPStatus = se.StatusCode()
+	}
+	return result
 }
 
 // Error implements the error interface.
@@ -30,3 +48,11 @@ func (e *Error) StatusCode() int {
 	}
 	return e.HTTPStatus
 }
+
+// Unwrap returns the executor error the result was built from, if any.
+func (e *Error) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.cause
+}
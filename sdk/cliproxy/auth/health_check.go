@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHealthCheckInterval    = 30 * time.Minute
+	defaultHealthCheckTimeout     = 60 * time.Second
+	defaultHealthCheckConcurrency = 2
+	// healthCheckTick is how often the scheduler looks for credentials that are due a probe.
+	healthCheckTick = 30 * time.Second
+	// healthProbePrompt is the whole conversation of a probe request.
+	healthProbePrompt = "ping"
+	healthProbeTokens = 8
+)
+
+// cheapModelHints rank model name fragments by how cheap the model usually is; earlier is cheaper.
+var cheapModelHints = []string{"lite", "nano", "mini", "haiku", "flash", "small"}
+
+// expensiveModelHints mark models that are unsuitable for a text probe or billed differently.
+var expensiveModelHints = []string{"image", "embedding", "tts", "audio", "realtime", "vision", "search"}
+
+// HealthProbeResult is the outcome of the most recent health-check probe of a credential.
+type HealthProbeResult struct {
+	At         time.Time `json:"at"`
+	Model      string    `json:"model"`
+	Success    bool      `json:"success"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	LatencyMS  int64     `json:"latency_ms"`
+}
+
+type healthCheckSettings struct {
+	interval    time.Duration
+	timeout     time.Duration
+	concurrency int
+	cfg         internalconfig.HealthCheckConfig
+}
+
+// healthChecker holds probe results and scheduler state.
+type healthChecker struct {
+	mu       sync.Mutex
+	results  map[string]HealthProbeResult
+	inflight map[string]struct{}
+	cancel   context.CancelFunc
+}
+
+// healthCheckSettings returns the probe configuration, or ok=false when probing is disabled.
+func (m *Manager) healthCheckSettings() (healthCheckSettings, bool) {
+	if m == nil {
+		return healthCheckSettings{}, false
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || !cfg.HealthCheck.Enable {
+		return healthCheckSettings{}, false
+	}
+	settings := healthCheckSettings{
+		interval:    time.Duration(cfg.HealthCheck.IntervalSeconds) * time.Second,
+		timeout:     time.Duration(cfg.HealthCheck.TimeoutSeconds) * time.Second,
+		concurrency: cfg.HealthCheck.Concurrency,
+		cfg:         cfg.HealthCheck,
+	}
+	if settings.interval <= 0 {
+		settings.interval = defaultHealthCheckInterval
+	}
+	if settings.timeout <= 0 {
+		settings.timeout = defaultHealthCheckTimeout
+	}
+	if settings.concurrency <= 0 {
+		settings.concurrency = defaultHealthCheckConcurrency
+	}
+	return settings, true
+}
+
+// StartHealthChecks launches the background probe scheduler. It does nothing while
+// health-check is disabled in config, so it can be started unconditionally and follows
+// config reloads. Starting it again replaces the previous run.
+func (m *Manager) StartHealthChecks(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	m.health.mu.Lock()
+	if m.health.cancel != nil {
+		m.health.cancel()
+	}
+	m.health.cancel = cancel
+	m.health.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(healthCheckTick)
+		defer ticker.Stop()
+		m.runDueHealthChecks(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.runDueHealthChecks(ctx)
+			}
+		}
+	}()
+}
+
+// StopHealthChecks cancels the probe scheduler and any probes in flight.
+func (m *Manager) StopHealthChecks() {
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+	if m.health.cancel != nil {
+		m.health.cancel()
+		m.health.cancel = nil
+	}
+}
+
+// HealthProbe returns the last probe result for the auth, if it has been probed.
+func (m *Manager) HealthProbe(authID string) (HealthProbeResult, bool) {
+	if m == nil {
+		return HealthProbeResult{}, false
+	}
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+	result, ok := m.health.results[authID]
+	return result, ok
+}
+
+type healthProbeTarget struct {
+	auth  *Auth
+	model string
+}
+
+// runDueHealthChecks probes every credential whose last probe is older than its interval.
+func (m *Manager) runDueHealthChecks(ctx context.Context) {
+	settings, ok := m.healthCheckSettings()
+	if !ok {
+		return
+	}
+	now := time.Now()
+	auths := m.snapshotAuths()
+	var due []healthProbeTarget
+	m.health.mu.Lock()
+	m.pruneHealthResultsLocked(auths)
+	for _, auth := range auths {
+		if auth.Disabled || auth.Status == StatusDisabled {
+			continue
+		}
+		// A credential cooling down after a real failure has nothing new to tell.
+		if auth.Unavailable && auth.NextRetryAfter.After(now) {
+			continue
+		}
+		override := settings.cfg.ForProvider(auth.Provider)
+		if override.Disable {
+			continue
+		}
+		if _, running := m.health.inflight[auth.ID]; running {
+			continue
+		}
+		interval := settings.interval
+		if override.IntervalSeconds > 0 {
+			interval = time.Duration(override.IntervalSeconds) * time.Second
+		}
+		if last, seen := m.health.results[auth.ID]; seen && now.Sub(last.At) < interval {
+			continue
+		}
+		if m.executorFor(auth.Provider) == nil {
+			continue
+		}
+		model := strings.TrimSpace(override.Model)
+		if model == "" {
+			model = cheapestModel(registry.GetGlobalRegistry().GetModelsForClient(auth.ID))
+		}
+		if model == "" {
+			continue
+		}
+		if m.health.inflight == nil {
+			m.health.inflight = make(map[string]struct{})
+		}
+		m.health.inflight[auth.ID] = struct{}{}
+		due = append(due, healthProbeTarget{auth: auth, model: model})
+	}
+	m.health.mu.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	go func() {
+		sem := make(chan struct{}, settings.concurrency)
+		var wg sync.WaitGroup
+		for _, target := range due {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(target healthProbeTarget) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				result := m.probeAuth(ctx, target.auth, target.model, settings.timeout)
+				m.health.mu.Lock()
+				delete(m.health.inflight, target.auth.ID)
+				if ctx.Err() == nil {
+					if m.health.results == nil {
+						m.health.results = make(map[string]HealthProbeResult)
+					}
+					m.health.results[target.auth.ID] = result
+				}
+				m.health.mu.Unlock()
+			}(target)
+		}
+		wg.Wait()
+	}()
+}
+
+// pruneHealthResultsLocked drops results of credentials that no longer exist.
+func (m *Manager) pruneHealthResultsLocked(auths []*Auth) {
+	if len(m.health.results) == 0 {
+		return
+	}
+	known := make(map[string]struct{}, len(auths))
+	for _, auth := range auths {
+		known[auth.ID] = struct{}{}
+	}
+	for id := range m.health.results {
+		if _, ok := known[id]; !ok {
+			delete(m.health.results, id)
+		}
+	}
+}
+
+// probeAuth sends a minimal chat request pinned to auth. The execution path records the
+// outcome with MarkResult, so a failing credential is demoted like after a real request.
+func (m *Manager) probeAuth(ctx context.Context, auth *Auth, model string, timeout time.Duration) HealthProbeResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, opts := healthProbeRequest(auth, model)
+	start := time.Now()
+	_, err := m.Execute(ctx, []string{auth.Provider}, req, opts)
+	result := HealthProbeResult{
+		At:        start,
+		Model:     model,
+		Success:   err == nil,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.StatusCode = statusCodeFromError(err)
+		result.Error = err.Error()
+		log.Warnf("health check: %s (%s) failed on model %s: %v", auth.ID, auth.Provider, model, err)
+	} else {
+		log.Debugf("health check: %s (%s) ok on model %s in %dms", auth.ID, auth.Provider, model, result.LatencyMS)
+	}
+	return result
+}
+
+// healthProbeRequest builds the minimal chat request used by probes, pinned to auth.
+func healthProbeRequest(auth *Auth, model string) (cliproxyexecutor.Request, cliproxyexecutor.Options) {
+	payload, _ := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": healthProbePrompt}},
+		"max_tokens": healthProbeTokens,
+	})
+	req := cliproxyexecutor.Request{Model: model, Payload: payload}
+	opts := cliproxyexecutor.Options{
+		OriginalRequest: payload,
+		SourceFormat:    sdktranslator.FormatOpenAI,
+		Metadata:        map[string]any{cliproxyexecutor.PinnedAuthMetadataKey: auth.ID},
+	}
+	return req, opts
+}
+
+// cheapestModel guesses the cheapest text model from a credential's model list using
+// common naming conventions, falling back to the alphabetically first model.
+func cheapestModel(models []*registry.ModelInfo) string {
+	candidates := make([]string, 0, len(models))
+	for _, model := range models {
+		if model == nil || model.ID == "" {
+			continue
+		}
+		id := strings.ToLower(model.ID)
+		skip := false
+		for _, hint := range expensiveModelHints {
+			if strings.Contains(id, hint) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			candidates = append(candidates, model.ID)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	rank := func(id string) int {
+		id = strings.ToLower(id)
+		for i, hint := range cheapModelHints {
+			if strings.Contains(id, hint) {
+				return i
+			}
+		}
+		return len(cheapModelHints)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		ri, rj := rank(candidates[i]), rank(candidates[j])
+		if ri != rj {
+			return ri < rj
+		}
+		if len(candidates[i]) != len(candidates[j]) {
+			return len(candidates[i]) < len(candidates[j])
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0]
+}
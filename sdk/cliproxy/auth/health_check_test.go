@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestHealthChecksProbeAndDemoteCredentials(t *testing.T) {
+	// The executor rejects the "revoked" auth and records the model each auth was probed with.
+	var mu sync.Mutex
+	probed := make(map[string]string)
+	executor := &stubExecutor{
+		provider: "probetest",
+		execute: func(_ context.Context, auth *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+			mu.Lock()
+			probed[auth.ID] = req.Model
+			mu.Unlock()
+			if auth.ID == "revoked" {
+				return cliproxyexecutor.Response{}, stubStatusError{code: http.StatusUnauthorized}
+			}
+			return cliproxyexecutor.Response{Payload: []byte(`{}`)}, nil
+		},
+	}
+	auths := []*Auth{{ID: "healthy"}, {ID: "revoked"}, {ID: "off", Disabled: true}}
+	cfg := &internalconfig.Config{HealthCheck: internalconfig.HealthCheckConfig{Enable: true}}
+	manager := newStubManager(t, cfg, executor, auths, "probe-pro", "probe-image-mini", "probe-flash", "probe-flash-lite")
+
+	manager.runDueHealthChecks(context.Background())
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, okHealthy := manager.HealthProbe("healthy")
+		_, okRevoked := manager.HealthProbe("revoked")
+		if okHealthy && okRevoked {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("probes did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	healthy, _ := manager.HealthProbe("healthy")
+	if !healthy.Success || healthy.Model != "probe-flash-lite" {
+		t.Errorf("healthy probe = %+v, want success on probe-flash-lite", healthy)
+	}
+	revoked, _ := manager.HealthProbe("revoked")
+	if revoked.Success || revoked.StatusCode != http.StatusUnauthorized {
+		t.Errorf("revoked probe = %+v, want 401 failure", revoked)
+	}
+	if _, ok := manager.HealthProbe("off"); ok {
+		t.Error("disabled auth should not be probed")
+	}
+	mu.Lock()
+	model := probed["revoked"]
+	mu.Unlock()
+	if model != "probe-flash-lite" {
+		t.Errorf("revoked auth probed with %q; probes must be pinned to their auth", model)
+	}
+	if auth, _ := manager.GetByID("revoked"); auth == nil || auth.Status != StatusError {
+		t.Errorf("revoked auth should be demoted via MarkResult, got %+v", auth)
+	}
+
+	// Results younger than the interval are not probed again.
+	mu.Lock()
+	probed = make(map[string]string)
+	mu.Unlock()
+	manager.runDueHealthChecks(context.Background())
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(probed) != 0 {
+		t.Errorf("probes repeated before the interval elapsed: %v", probed)
+	}
+}
+
+func TestCheapestModelSkipsNonTextModels(t *testing.T) {
+	models := []*registry.ModelInfo{{ID: "gpt-5"}, {ID: "gpt-image-1-mini"}, {ID: "gpt-5-mini"}, {ID: "gpt-5-nano"}}
+	if got := cheapestModel(models); got != "gpt-5-nano" {
+		t.Errorf("cheapestModel = %q, want gpt-5-nano", got)
+	}
+	if got := cheapestModel([]*registry.ModelInfo{{ID: "b-pro"}, {ID: "a-pro"}}); got != "a-pro" {
+		t.Errorf("cheapestModel fallback = %q, want a-pro", got)
+	}
+}
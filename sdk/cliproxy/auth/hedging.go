@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/redaction"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+const (
+	defaultHedgePercentile  = 95
+	defaultHedgeMinDelay    = 50 * time.Millisecond
+	defaultHedgeMaxDelay    = 2 * time.Second
+	defaultHedgeBudgetRatio = 0.1
+	hedgeLatencyWindow      = 128
+	hedgeMinSamples         = 20
+	// hedgeBudgetCap bounds how many hedges can be saved up during quiet periods.
+	hedgeBudgetCap = 10.0
+)
+
+type hedgeSettings struct {
+	percentile float64
+	minDelay   time.Duration
+	maxDelay   time.Duration
+	ratio      float64
+}
+
+// hedgeTracker keeps recent per-model latencies and the hedging budget. Every eligible
+// request deposits ratio tokens and every hedge withdraws one, so hedges never exceed
+// ratio of eligible traffic over time.
+type hedgeTracker struct {
+	mu        sync.Mutex
+	latencies map[string]*latencyWindow
+	tokens    float64
+}
+
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+// hedgeSettings returns the hedging configuration for model, or ok=false when the model is not eligible.
+func (m *Manager) hedgeSettings(model string) (hedgeSettings, bool) {
+	if m == nil {
+		return hedgeSettings{}, false
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || !cfg.Hedging.AppliesTo(thinking.ParseSuffix(model).ModelName) {
+		return hedgeSettings{}, false
+	}
+	settings := hedgeSettings{
+		percentile: cfg.Hedging.Percentile,
+		minDelay:   time.Duration(cfg.Hedging.MinDelayMS) * time.Millisecond,
+		maxDelay:   time.Duration(cfg.Hedging.MaxDelayMS) * time.Millisecond,
+		ratio:      cfg.Hedging.BudgetRatio,
+	}
+	if settings.percentile <= 0 || settings.percentile > 100 {
+		settings.percentile = defaultHedgePercentile
+	}
+	if settings.minDelay <= 0 {
+		settings.minDelay = defaultHedgeMinDelay
+	}
+	if settings.maxDelay <= 0 {
+		settings.maxDelay = defaultHedgeMaxDelay
+	}
+	if settings.maxDelay < settings.minDelay {
+		settings.maxDelay = settings.minDelay
+	}
+	if settings.ratio <= 0 {
+		settings.ratio = defaultHedgeBudgetRatio
+	}
+	return settings, true
+}
+
+func (t *hedgeTracker) observe(model string, latency time.Duration) {
+	if t == nil || latency <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.latencies == nil {
+		t.latencies = make(map[string]*latencyWindow)
+	}
+	window := t.latencies[model]
+	if window == nil {
+		window = &latencyWindow{samples: make([]time.Duration, 0, hedgeLatencyWindow)}
+		t.latencies[model] = window
+	}
+	if len(window.samples) < hedgeLatencyWindow {
+		window.samples = append(window.samples, latency)
+		return
+	}
+	window.samples[window.next] = latency
+	window.next = (window.next + 1) % hedgeLatencyWindow
+}
+
+// delay returns the configured latency percentile for model clamped to the configured bounds.
+// Until enough samples exist the maximum delay is used.
+func (t *hedgeTracker) delay(model string, settings hedgeSettings) time.Duration {
+	if t == nil {
+		return settings.maxDelay
+	}
+	t.mu.Lock()
+	window := t.latencies[model]
+	var samples []time.Duration
+	if window != nil && len(window.samples) >= hedgeMinSamples {
+		samples = append(samples, window.samples...)
+	}
+	t.mu.Unlock()
+	if len(samples) == 0 {
+		return settings.maxDelay
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	idx := int(math.Ceil(settings.percentile/100*float64(len(samples)))) - 1
+	idx = max(0, min(idx, len(samples)-1))
+	return max(settings.minDelay, min(samples[idx], settings.maxDelay))
+}
+
+func (t *hedgeTracker) deposit(ratio float64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.tokens = math.Min(t.tokens+ratio, hedgeBudgetCap)
+	t.mu.Unlock()
+}
+
+func (t *hedgeTracker) withdraw() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tokens < 1 {
+		return false
+	}
+	t.tokens--
+	return true
+}
+
+type hedgeAttempt struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	auth     *Auth
+	provider string
+	model    string
+	started  time.Time
+	// redactions restores the tokens of this attempt's redacted request; the translated
+	// payloads of two attempts may number tokens differently.
+	redactions *redaction.Tracker
+}
+
+type hedgeOutcome struct {
+	attempt *hedgeAttempt
+	resp    cliproxyexecutor.Response
+	err     error
+}
+
+// executeMixedHedged behaves like executeMixedOnce but sends a duplicate request to a second
+// credential when the first has not answered within the hedge delay. The first success wins
+// and the other attempt is cancelled; both attempts are reported to usage by their executors.
+func (m *Manager) executeMixedHedged(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, settings hedgeSettings) (cliproxyexecutor.Response, error) {
+	routeModel := req.Model
+	latencyKey := canonicalModelKey(routeModel)
+	tried := make(map[string]struct{})
+	m.hedges.deposit(settings.ratio)
+	var lastErr error
+	for {
+		outcomes := make(chan hedgeOutcome, 2)
+		primary, errPick := m.launchHedgeAttempt(ctx, providers, req, opts, tried, false, outcomes)
+		if errPick != nil {
+			if lastErr != nil {
+				return cliproxyexecutor.Response{}, lastErr
+			}
+			return cliproxyexecutor.Response{}, errPick
+		}
+		attempts := []*hedgeAttempt{primary}
+		// Cancelled attempts never report a result, so they give back any half-open circuit
+		// probe slot they took.
+		cancelAll := func() {
+			for _, attempt := range attempts {
+				attempt.cancel()
+				m.releaseCircuit(attempt.auth)
+			}
+		}
+
+		timer := time.NewTimer(m.hedges.delay(latencyKey, settings))
+		timerC := timer.C
+		pending := 1
+		var winner *hedgeOutcome
+		for pending > 0 && winner == nil {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				cancelAll()
+				return cliproxyexecutor.Response{}, ctx.Err()
+			case <-timerC:
+				timerC = nil
+				if !m.hedges.withdraw() {
+					continue
+				}
+				hedge, errHedge := m.launchHedgeAttempt(ctx, providers, req, opts, tried, true, outcomes)
+				if errHedge != nil {
+					// No other credential is available; give the token back.
+					m.hedges.deposit(1)
+					continue
+				}
+				logEntryWithRequestID(ctx).Debugf("hedging %s: primary %s slow, sent duplicate to %s", routeModel, primary.auth.ID, hedge.auth.ID)
+				attempts = append(attempts, hedge)
+				pending++
+			case outcome := <-outcomes:
+				pending--
+				if outcome.err == nil {
+					winner = &outcome
+					continue
+				}
+				if errCtx := ctx.Err(); errCtx != nil {
+					timer.Stop()
+					cancelAll()
+					return cliproxyexecutor.Response{}, errCtx
+				}
+				m.MarkResult(outcome.attempt.ctx, executionResult(outcome.attempt, routeModel, outcome.err))
+				if isRequestInvalidError(outcome.err) {
+					timer.Stop()
+					cancelAll()
+					return cliproxyexecutor.Response{}, outcome.err
+				}
+				lastErr = outcome.err
+			}
+		}
+		timer.Stop()
+		if winner == nil {
+			cancelAll()
+			continue
+		}
+		for _, attempt := range attempts {
+			if attempt != winner.attempt {
+				attempt.cancel()
+				m.releaseCircuit(attempt.auth)
+			}
+		}
+		m.hedges.observe(latencyKey, time.Since(winner.attempt.started))
+		m.MarkResult(winner.attempt.ctx, executionResult(winner.attempt, routeModel, nil))
+		winner.attempt.cancel()
+		publishSelectedAuthMetadata(opts.Metadata, winner.attempt.auth.ID)
+		resp := winner.resp
+		resp.Payload = winner.attempt.redactions.Restore(resp.Payload)
+		resp.Payload = m.responseScriptsFor(winner.attempt.auth, opts, routeModel, winner.attempt.model).apply(ctx, resp.Payload)
+		return resp, nil
+	}
+}
+
+// launchHedgeAttempt picks an untried credential and starts executing req on it in the background.
+// Each attempt gets its own metadata copy so concurrent attempts never share a mutable map.
+func (m *Manager) launchHedgeAttempt(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, tried map[string]struct{}, hedge bool, outcomes chan<- hedgeOutcome) (*hedgeAttempt, error) {
+	routeModel := req.Model
+	auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+	if errPick != nil {
+		recordPickFailure(ctx, errPick)
+		return nil, errPick
+	}
+	debugLogAuthSelection(logEntryWithRequestID(ctx), auth, provider, req.Model)
+	tried[auth.ID] = struct{}{}
+
+	execReq := req
+	execReq.Model = rewriteModelForAuth(routeModel, auth)
+	execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
+	execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
+	attemptCtx, attemptSpan := startAttemptSpan(ctx, auth, provider, execReq.Model, len(tried), hedge)
+	attemptCtx, cancel := context.WithCancel(attemptCtx)
+	if hedge {
+		attemptCtx = cliproxyexecutor.WithHedgeAttempt(attemptCtx)
+	}
+	if rt := m.roundTripperFor(auth); rt != nil {
+		attemptCtx = context.WithValue(attemptCtx, roundTripperContextKey{}, rt)
+		attemptCtx = context.WithValue(attemptCtx, "cliproxy.roundtripper", rt)
+	}
+	attemptOpts := opts
+	if len(opts.Metadata) > 0 {
+		attemptOpts.Metadata = make(map[string]any, len(opts.Metadata)+1)
+		for key, value := range opts.Metadata {
+			attemptOpts.Metadata[key] = value
+		}
+		attemptOpts.Metadata[cliproxyexecutor.SelectedAuthMetadataKey] = auth.ID
+	}
+
+	attemptCtx, redactions := redaction.WithTracker(attemptCtx)
+	attempt := &hedgeAttempt{ctx: attemptCtx, cancel: cancel, auth: auth, provider: provider, model: execReq.Model, started: time.Now(), redactions: redactions}
+	go func() {
+		resp, errExec := m.executeBridged(attemptCtx, executor, auth, provider, execReq, attemptOpts)
+		endAttemptSpan(attemptSpan, errExec)
+		outcomes <- hedgeOutcome{attempt: attempt, resp: resp, err: errExec}
+	}()
+	return attempt, nil
+}
+
+func executionResult(attempt *hedgeAttempt, routeModel string, errExec error) Result {
+	result := Result{AuthID: attempt.auth.ID, Provider: attempt.provider, Model: routeModel, Success: errExec == nil}
+	if errExec == nil {
+		return result
+	}
+	result.Error = errorFromExecution(errExec)
+	result.RetryAfter = retryAfterFromError(errExec)
+	return result
+}
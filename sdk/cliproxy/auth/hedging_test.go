@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// hedgeCounters counts the hedged attempts and the cancelled attempts of a hedging test.
+type hedgeCounters struct {
+	cancelled atomic.Int32
+	hedged    atomic.Int32
+}
+
+// newHedgeTestManager returns a manager whose "slow" auth blocks until its attempt is cancelled
+// and whose "zfast" auth answers immediately.
+func newHedgeTestManager(t *testing.T, hedging internalconfig.HedgingConfig) (*Manager, *hedgeCounters) {
+	t.Helper()
+	counters := &hedgeCounters{}
+	executor := &stubExecutor{
+		provider: "hedgetest",
+		execute: func(ctx context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+			if cliproxyexecutor.HedgeAttempt(ctx) {
+				counters.hedged.Add(1)
+			}
+			if auth.ID == "slow" {
+				<-ctx.Done()
+				counters.cancelled.Add(1)
+				return cliproxyexecutor.Response{}, ctx.Err()
+			}
+			return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+		},
+	}
+	manager := newStubManager(t, &internalconfig.Config{Hedging: hedging}, executor, stubAuths("slow", "zfast"), "hedge-model")
+	return manager, counters
+}
+
+func TestExecuteHedged_SecondAttemptWins(t *testing.T) {
+	manager, executor := newHedgeTestManager(t, internalconfig.HedgingConfig{
+		Enable:      true,
+		MinDelayMS:  10,
+		MaxDelayMS:  20,
+		BudgetRatio: 1,
+	})
+
+	resp, err := manager.Execute(context.Background(), []string{"hedgetest"}, cliproxyexecutor.Request{Model: "hedge-model"}, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if string(resp.Payload) != "zfast" {
+		t.Fatalf("Execute() payload = %q, want %q", resp.Payload, "zfast")
+	}
+	if got := executor.hedged.Load(); got != 1 {
+		t.Fatalf("hedged attempts = %d, want 1", got)
+	}
+	deadline := time.Now().Add(time.Second)
+	for executor.cancelled.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if executor.cancelled.Load() != 1 {
+		t.Fatal("losing attempt was not cancelled")
+	}
+}
+
+func TestExecuteHedged_RespectsBudget(t *testing.T) {
+	manager, executor := newHedgeTestManager(t, internalconfig.HedgingConfig{
+		Enable:      true,
+		MinDelayMS:  10,
+		MaxDelayMS:  20,
+		BudgetRatio: 0.5,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := manager.Execute(ctx, []string{"hedgetest"}, cliproxyexecutor.Request{Model: "hedge-model"}, cliproxyexecutor.Options{}); err == nil {
+		t.Fatal("Execute() error = nil, want deadline exceeded without hedge budget")
+	}
+	if got := executor.hedged.Load(); got != 0 {
+		t.Fatalf("hedged attempts = %d, want 0 before budget accrues", got)
+	}
+
+	if _, err := manager.Execute(context.Background(), []string{"hedgetest"}, cliproxyexecutor.Request{Model: "hedge-model"}, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := executor.hedged.Load(); got != 1 {
+		t.Fatalf("hedged attempts = %d, want 1 once budget accrued", got)
+	}
+}
+
+func TestHedgeTrackerDelayUsesPercentile(t *testing.T) {
+	settings := hedgeSettings{percentile: 90, minDelay: time.Millisecond, maxDelay: time.Second}
+	var tracker hedgeTracker
+	if got := tracker.delay("m", settings); got != time.Second {
+		t.Fatalf("delay() without samples = %v, want max delay", got)
+	}
+	for i := 1; i <= 100; i++ {
+		tracker.observe("m", time.Duration(i)*time.Millisecond)
+	}
+	if got := tracker.delay("m", settings); got != 90*time.Millisecond {
+		t.Fatalf("delay() = %v, want 90ms", got)
+	}
+}
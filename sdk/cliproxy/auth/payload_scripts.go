@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/payloadscript"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// responseScripts applies the response scripts of the payload script rules matching a request.
+type responseScripts struct {
+	rules []internalconfig.PayloadScriptRule
+	env   payloadscript.Env
+}
+
+// responseScriptsFor returns the response scripts for a request served by auth, or nil when
+// no rule applies. Rules match the client's format and either the routed or the upstream model.
+func (m *Manager) responseScriptsFor(auth *Auth, opts cliproxyexecutor.Options, routeModel, upstreamModel string) *responseScripts {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || (len(cfg.Payload.Scripts) == 0 && len(cfg.ClientKeyPolicies) == 0) {
+		return nil
+	}
+	clientKey, _ := opts.Metadata[cliproxyexecutor.ClientAPIKeyMetadataKey].(string)
+	requestedModel, _ := opts.Metadata[cliproxyexecutor.RequestedModelMetadataKey].(string)
+	protocol := opts.SourceFormat.String()
+	var rules []internalconfig.PayloadScriptRule
+	for _, rule := range cfg.PayloadScriptsFor(clientKey, protocol, requestedModel, routeModel, upstreamModel) {
+		if rule.Response != "" {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	env := payloadscript.Env{
+		Phase:          payloadscript.PhaseResponse,
+		ClientKey:      clientKey,
+		Model:          upstreamModel,
+		RequestedModel: strings.TrimSpace(requestedModel),
+		Protocol:       protocol,
+		Stream:         opts.Stream,
+	}
+	if auth != nil {
+		env.Provider = auth.Provider
+		env.AuthID = auth.ID
+		env.AuthLabel = auth.Label
+	}
+	return &responseScripts{rules: rules, env: env}
+}
+
+// apply runs the scripts on one response payload. A failing script is logged and leaves the
+// payload as it was.
+func (s *responseScripts) apply(ctx context.Context, payload []byte) []byte {
+	if s == nil || len(payload) == 0 {
+		return payload
+	}
+	out := payload
+	for i := range s.rules {
+		prog, err := payloadscript.CompileCached(s.rules[i].Response)
+		if err == nil {
+			limits := payloadscript.Limits{Timeout: time.Duration(s.rules[i].TimeoutMs) * time.Millisecond}
+			out, err = prog.Run(ctx, out, "", s.env, limits)
+		}
+		if err != nil {
+			logEntryWithRequestID(ctx).Warnf("payload response script skipped: %v", err)
+		}
+	}
+	return out
+}
+
+// applyStream runs the scripts on one streamed chunk. Server-sent event chunks are unframed
+// first so each script sees the JSON object of a data line; event names, comments and [DONE]
+// markers pass through untouched. Chunks that are a bare JSON object are transformed whole.
+func (s *responseScripts) applyStream(ctx context.Context, chunk []byte) []byte {
+	if s == nil || len(chunk) == 0 {
+		return chunk
+	}
+	trimmed := bytes.TrimSpace(chunk)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		start := bytes.Index(chunk, trimmed)
+		out := make([]byte, 0, len(chunk))
+		out = append(out, chunk[:start]...)
+		out = append(out, s.applyEvent(ctx, trimmed)...)
+		return append(out, chunk[start+len(trimmed):]...)
+	}
+	var out []byte
+	for _, line := range bytes.SplitAfter(chunk, []byte("\n")) {
+		body := bytes.TrimRight(line, "\r\n")
+		if !bytes.HasPrefix(body, []byte("data:")) {
+			out = append(out, line...)
+			continue
+		}
+		data := bytes.TrimSpace(body[len("data:"):])
+		if len(data) == 0 || data[0] != '{' {
+			out = append(out, line...)
+			continue
+		}
+		out = append(out, "data: "...)
+		out = append(out, s.applyEvent(ctx, data)...)
+		out = append(out, line[len(body):]...)
+	}
+	return out
+}
+
+// applyEvent runs the scripts on the JSON object of one event and keeps the result on a single
+// line so it can be framed again.
+func (s *responseScripts) applyEvent(ctx context.Context, data []byte) []byte {
+	out := s.apply(ctx, data)
+	if bytes.ContainsAny(out, "\r\n") {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, out); err == nil {
+			return compact.Bytes()
+		}
+		return data
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+func TestResponseScripts_MatchClientFormatAndKey(t *testing.T) {
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	cfg := &internalconfig.Config{}
+	cfg.Payload.Scripts = []internalconfig.PayloadScriptRule{{
+		Models:   []internalconfig.PayloadModelRule{{Name: "gpt-*", Protocol: "openai"}},
+		Response: `set_payload(payload().replace("secret", "[redacted]"))`,
+	}}
+	cfg.ClientKeyPolicies = []internalconfig.ClientKeyPolicy{{
+		APIKey: "team-a",
+		Payload: &internalconfig.PayloadConfig{Scripts: []internalconfig.PayloadScriptRule{{
+			Models:   []internalconfig.PayloadModelRule{{Name: "*"}},
+			Response: "if exists(\"choices.0.message\"):\n    set(\"choices.0.message.via\", auth_id + \"@\" + provider)",
+		}}},
+	}}
+	manager.SetConfig(cfg)
+	auth := &Auth{ID: "auth-1", Provider: "codex"}
+	opts := cliproxyexecutor.Options{
+		SourceFormat: sdktranslator.FormatOpenAI,
+		Metadata: map[string]any{
+			cliproxyexecutor.ClientAPIKeyMetadataKey:   "team-a",
+			cliproxyexecutor.RequestedModelMetadataKey: "gpt-5",
+		},
+	}
+	payload := []byte(`{"choices":[{"message":{"content":"the secret"}}]}`)
+
+	got := manager.responseScriptsFor(auth, opts, "gpt-5", "gpt-5").apply(context.Background(), payload)
+	if want := `{"choices":[{"message":{"content":"the [redacted]","via":"auth-1@codex"}}]}`; string(got) != want {
+		t.Fatalf("apply() = %s, want %s", got, want)
+	}
+
+	// Other client formats and keys only see the rules that match them.
+	opts.SourceFormat = sdktranslator.FormatClaude
+	if got = manager.responseScriptsFor(auth, opts, "gpt-5", "gpt-5").apply(context.Background(), payload); string(got) != `{"choices":[{"message":{"content":"the secret","via":"auth-1@codex"}}]}` {
+		t.Fatalf("apply() for claude format = %s", got)
+	}
+	delete(opts.Metadata, cliproxyexecutor.ClientAPIKeyMetadataKey)
+	if scripts := manager.responseScriptsFor(auth, opts, "gpt-5", "gpt-5"); scripts != nil {
+		t.Fatalf("responseScriptsFor() = %+v, want nil", scripts)
+	}
+}
+
+func TestExecuteStream_ResponseScriptsRunPerEvent(t *testing.T) {
+	cfg := &internalconfig.Config{}
+	cfg.Payload.Scripts = []internalconfig.PayloadScriptRule{{
+		Models:   []internalconfig.PayloadModelRule{{Name: "gpt-*"}},
+		Response: "if exists(\"delta.text\"):\n    set(\"delta.text\", get(\"delta.text\").upper())\nset(\"via\", auth_id)",
+	}}
+	executor := &stubExecutor{
+		provider: "scriptstub",
+		executeStream: func(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+			return stubStream(
+				"event: content_block_delta\ndata: {\"delta\":{\"text\":\"hel\"}}\n\nevent: content_block_delta\ndata:{\"delta\":{\"text\":\"lo\"}}\n\n",
+				": keep-alive\n\n",
+				"{\"delta\":{\"text\":\"bare\"}}\n",
+				"data: [DONE]\n\n",
+			), nil
+		},
+	}
+	manager := newStubManager(t, cfg, executor, stubAuths("script-auth"), "gpt-5")
+
+	req := cliproxyexecutor.Request{Model: "gpt-5", Payload: []byte(`{"model":"gpt-5"}`)}
+	opts := cliproxyexecutor.Options{Stream: true, SourceFormat: sdktranslator.FormatClaude}
+	result, err := manager.ExecuteStream(context.Background(), []string{"scriptstub"}, req, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var got []string
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("chunk error = %v", chunk.Err)
+		}
+		got = append(got, string(chunk.Payload))
+	}
+	want := []string{
+		"event: content_block_delta\ndata: {\"delta\":{\"text\":\"HEL\"},\"via\":\"script-auth\"}\n\nevent: content_block_delta\ndata: {\"delta\":{\"text\":\"LO\"},\"via\":\"script-auth\"}\n\n",
+		": keep-alive\n\n",
+		"{\"delta\":{\"text\":\"BARE\"},\"via\":\"script-auth\"}\n",
+		"data: [DONE]\n\n",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("chunks = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("chunk %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
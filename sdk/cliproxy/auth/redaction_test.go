@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/redaction"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestExecuteStream_RestoresRedactionTokensSplitAcrossChunks(t *testing.T) {
+	executor := &stubExecutor{
+		provider: "redactstub",
+		executeStream: func(ctx context.Context, _ *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+			pattern, _ := redaction.BuiltinPattern("email")
+			redactor, err := redaction.Compile([]redaction.Rule{{Name: "email", Pattern: pattern, Action: redaction.ActionTokenize}})
+			if err != nil {
+				return nil, err
+			}
+			_, session, err := redactor.Redact([]byte(`{"prompt":"mail ann@example.com"}`))
+			if err != nil {
+				return nil, err
+			}
+			redaction.Track(ctx, session)
+			return stubStream(
+				`{"choices":[{"delta":{"content":"sent to [REDACTED_"}}]}`,
+				`{"choices":[{"delta":{"content":"EMAIL_1]."}}]}`,
+			), nil
+		},
+	}
+	manager := newStubManager(t, nil, executor, stubAuths("redact-auth"), "gpt-5")
+
+	req := cliproxyexecutor.Request{Model: "gpt-5", Payload: []byte(`{"model":"gpt-5"}`)}
+	opts := cliproxyexecutor.Options{Stream: true, SourceFormat: sdktranslator.FormatOpenAI}
+	result, err := manager.ExecuteStream(context.Background(), []string{"redactstub"}, req, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var content strings.Builder
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("chunk error = %v", chunk.Err)
+		}
+		content.WriteString(gjson.GetBytes(chunk.Payload, "choices.0.delta.content").String())
+	}
+	if content.String() != "sent to ann@example.com." {
+		t.Fatalf("streamed content = %q", content.String())
+	}
+}
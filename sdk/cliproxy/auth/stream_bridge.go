@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/streambridge"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// streamBridgeMode returns the upstream mode pinned by the stream-bridge rules for a request
+// served by auth, or "" when the request should be sent in the mode the client asked for.
+func (m *Manager) streamBridgeMode(auth *Auth, provider, model string, opts cliproxyexecutor.Options) string {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || len(cfg.StreamBridge) == 0 || auth == nil {
+		return ""
+	}
+	if opts.Alt == "responses/compact" || !streambridge.Supports(opts.SourceFormat.String()) {
+		return ""
+	}
+	mode := cfg.StreamBridgeMode(credentialNames(auth, provider), model)
+	switch {
+	case mode == internalconfig.StreamBridgeModeStream && !opts.Stream:
+		return mode
+	case mode == internalconfig.StreamBridgeModeNonStream && opts.Stream:
+		return mode
+	default:
+		return ""
+	}
+}
+
+// credentialNames lists the names a credential is known by for per-provider rules: its provider
+// identifier, the provider it was picked for, and its openai-compatibility name.
+func credentialNames(auth *Auth, provider string) []string {
+	names := []string{auth.Provider, provider}
+	if auth.Attributes != nil {
+		names = append(names, auth.Attributes["compat_name"])
+	}
+	return names
+}
+
+// executeBridged runs a non-streaming request, emulating web tools and applying stream-bridge
+// rules as configured.
+func (m *Manager) executeBridged(ctx context.Context, executor ProviderExecutor, auth *Auth, provider string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if session, sessionReq, sessionOpts := m.webToolsSession(auth, provider, req, opts); session != nil {
+		return m.executeWithWebTools(ctx, executor, auth, provider, session, sessionReq, sessionOpts)
+	}
+	return m.executeWithStreamBridge(ctx, executor, auth, provider, req, opts)
+}
+
+// executeWithStreamBridge runs a non-streaming request, calling the upstream in streaming mode
+// and aggregating the chunks when a stream-bridge rule asks for it.
+func (m *Manager) executeWithStreamBridge(ctx context.Context, executor ProviderExecutor, auth *Auth, provider string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if m.streamBridgeMode(auth, provider, req.Model, opts) == "" {
+		return executor.Execute(ctx, auth, req, opts)
+	}
+	req, opts = withStreamMode(req, opts, true)
+	streamResult, errStream := executor.ExecuteStream(ctx, auth, req, opts)
+	if errStream != nil {
+		return cliproxyexecutor.Response{}, errStream
+	}
+	var chunks [][]byte
+	for chunk := range streamResult.Chunks {
+		if chunk.Err != nil {
+			// Drain so the executor goroutine can finish.
+			for range streamResult.Chunks {
+			}
+			return cliproxyexecutor.Response{}, chunk.Err
+		}
+		chunks = append(chunks, chunk.Payload)
+	}
+	if errCtx := ctx.Err(); errCtx != nil {
+		return cliproxyexecutor.Response{}, errCtx
+	}
+	payload, errAggregate := streambridge.Aggregate(opts.SourceFormat.String(), chunks)
+	if errAggregate != nil {
+		return cliproxyexecutor.Response{}, &Error{Code: "stream_bridge_failed", Message: errAggregate.Error(), HTTPStatus: http.StatusBadGateway}
+	}
+	return cliproxyexecutor.Response{Payload: payload, Headers: streamResult.Headers}, nil
+}
+
+// executeStreamBridged runs a streaming request, calling the upstream in non-streaming mode
+// and replaying the response as chunks when a stream-bridge rule asks for it. Requests with
+// emulated web tools always run their tool rounds without streaming.
+func (m *Manager) executeStreamBridged(ctx context.Context, executor ProviderExecutor, auth *Auth, provider string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	if session, sessionReq, sessionOpts := m.webToolsSession(auth, provider, req, opts); session != nil {
+		return m.streamWithWebTools(ctx, executor, auth, provider, session, sessionReq, sessionOpts)
+	}
+	if m.streamBridgeMode(auth, provider, req.Model, opts) == "" {
+		return executor.ExecuteStream(ctx, auth, req, opts)
+	}
+	req, opts = withStreamMode(req, opts, false)
+	resp, errExec := executor.Execute(ctx, auth, req, opts)
+	if errExec != nil {
+		return nil, errExec
+	}
+	return replayAsStream(opts.SourceFormat.String(), resp)
+}
+
+// replayAsStream splits a complete response in format into stream chunks.
+func replayAsStream(format string, resp cliproxyexecutor.Response) (*cliproxyexecutor.StreamResult, error) {
+	chunks, errSplit := replayChunks(format, resp)
+	if errSplit != nil {
+		return nil, errSplit
+	}
+	out := make(chan cliproxyexecutor.StreamChunk, len(chunks))
+	for _, chunk := range chunks {
+		out <- cliproxyexecutor.StreamChunk{Payload: chunk}
+	}
+	close(out)
+	return &cliproxyexecutor.StreamResult{Headers: resp.Headers, Chunks: out}, nil
+}
+
+func replayChunks(format string, resp cliproxyexecutor.Response) ([][]byte, error) {
+	chunks, errSplit := streambridge.Split(format, resp.Payload)
+	if errSplit != nil {
+		return nil, &Error{Code: "stream_bridge_failed", Message: errSplit.Error(), HTTPStatus: http.StatusBadGateway}
+	}
+	return chunks, nil
+}
+
+// withStreamMode rewrites the streaming flag of a request for an upstream call in the other
+// mode. Formats that carry the flag in the body (OpenAI chat completions, Responses and Claude)
+// are updated too, since their translators pass it through unchanged.
+func withStreamMode(req cliproxyexecutor.Request, opts cliproxyexecutor.Options, stream bool) (cliproxyexecutor.Request, cliproxyexecutor.Options) {
+	opts.Stream = stream
+	format := opts.SourceFormat.String()
+	switch format {
+	case streambridge.FormatOpenAI, streambridge.FormatOpenAIResponse, streambridge.FormatClaude:
+		req.Payload = setStreamFlag(req.Payload, format, stream)
+		if len(opts.OriginalRequest) > 0 {
+			opts.OriginalRequest = setStreamFlag(opts.OriginalRequest, format, stream)
+		}
+	}
+	return req, opts
+}
+
+func setStreamFlag(payload []byte, format string, stream bool) []byte {
+	if !gjson.ValidBytes(payload) {
+		return payload
+	}
+	out, errSet := sjson.SetBytes(payload, "stream", stream)
+	if errSet != nil {
+		return payload
+	}
+	if format == streambridge.FormatOpenAI {
+		if stream {
+			out, _ = sjson.SetBytes(out, "stream_options.include_usage", true)
+		} else {
+			out, _ = sjson.DeleteBytes(out, "stream_options")
+		}
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// bridgeCalls records the modes the upstream of a stream-bridge test was called in.
+type bridgeCalls struct {
+	streamed      bool
+	nonStreamed   bool
+	streamPayload []byte
+}
+
+// newBridgeTestManager returns a manager whose upstream answers in OpenAI chat completion
+// format in either mode, with a stream-bridge rule pinning mode.
+func newBridgeTestManager(t *testing.T, mode string) (*Manager, *bridgeCalls) {
+	t.Helper()
+	calls := &bridgeCalls{}
+	executor := &stubExecutor{
+		provider: "bridgetest",
+		execute: func(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+			calls.nonStreamed = true
+			return cliproxyexecutor.Response{Payload: []byte(`{"id":"c1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`)}, nil
+		},
+		executeStream: func(_ context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+			calls.streamed = true
+			calls.streamPayload = req.Payload
+			return stubStream(
+				`{"id":"c1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{"role":"assistant","content":"hel"}}]}`,
+				`{"id":"c1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+				`{"id":"c1","object":"chat.completion.chunk","model":"m","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`,
+			), nil
+		},
+	}
+	cfg := &internalconfig.Config{StreamBridge: []internalconfig.StreamBridgeRule{{Provider: "bridgetest", Mode: mode}}}
+	return newStubManager(t, cfg, executor, stubAuths("bridge-auth"), "bridge-model"), calls
+}
+
+func TestExecute_StreamBridgeAggregatesUpstreamStream(t *testing.T) {
+	manager, executor := newBridgeTestManager(t, internalconfig.StreamBridgeModeStream)
+
+	req := cliproxyexecutor.Request{Model: "bridge-model", Payload: []byte(`{"model":"bridge-model","stream":false}`)}
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai")}
+	resp, err := manager.Execute(context.Background(), []string{"bridgetest"}, req, opts)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !executor.streamed || executor.nonStreamed {
+		t.Fatalf("upstream modes: streamed=%v nonStreamed=%v, want stream only", executor.streamed, executor.nonStreamed)
+	}
+	if !gjson.GetBytes(executor.streamPayload, "stream").Bool() || !gjson.GetBytes(executor.streamPayload, "stream_options.include_usage").Bool() {
+		t.Fatalf("upstream payload = %s, want stream with usage", executor.streamPayload)
+	}
+	if got := gjson.GetBytes(resp.Payload, "choices.0.message.content").String(); got != "hello" {
+		t.Fatalf("content = %q, want %q", got, "hello")
+	}
+	if got := gjson.GetBytes(resp.Payload, "usage.total_tokens").Int(); got != 4 {
+		t.Fatalf("usage.total_tokens = %d, want 4", got)
+	}
+}
+
+func TestExecuteStream_StreamBridgeSplitsUpstreamResponse(t *testing.T) {
+	manager, executor := newBridgeTestManager(t, internalconfig.StreamBridgeModeNonStream)
+
+	req := cliproxyexecutor.Request{Model: "bridge-model", Payload: []byte(`{"model":"bridge-model","stream":true}`)}
+	opts := cliproxyexecutor.Options{Stream: true, SourceFormat: sdktranslator.FromString("openai")}
+	result, err := manager.ExecuteStream(context.Background(), []string{"bridgetest"}, req, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var content strings.Builder
+	var finish string
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("chunk error = %v", chunk.Err)
+		}
+		content.WriteString(gjson.GetBytes(chunk.Payload, "choices.0.delta.content").String())
+		if reason := gjson.GetBytes(chunk.Payload, "choices.0.finish_reason").String(); reason != "" {
+			finish = reason
+		}
+	}
+	if executor.streamed || !executor.nonStreamed {
+		t.Fatalf("upstream modes: streamed=%v nonStreamed=%v, want non-stream only", executor.streamed, executor.nonStreamed)
+	}
+	if content.String() != "hello" || finish != "stop" {
+		t.Fatalf("stream content = %q finish = %q, want hello/stop", content.String(), finish)
+	}
+}
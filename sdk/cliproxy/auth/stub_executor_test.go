@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// stubStatusError is an executor error carrying an upstream HTTP status.
+type stubStatusError struct{ code int }
+
+func (e stubStatusError) Error() string   { return http.StatusText(e.code) }
+func (e stubStatusError) StatusCode() int { return e.code }
+
+// stubExecutor is a configurable ProviderExecutor for manager tests. Without an execute hook it
+// answers with the auth ID as payload; without a stream hook streaming is rejected.
+type stubExecutor struct {
+	provider      string
+	execute       func(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error)
+	executeStream func(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error)
+}
+
+func (e *stubExecutor) Identifier() string { return e.provider }
+
+func (e *stubExecutor) Execute(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if e.execute == nil {
+		return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+	}
+	return e.execute(ctx, auth, req, opts)
+}
+
+func (e *stubExecutor) ExecuteStream(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	if e.executeStream == nil {
+		return nil, &Error{Code: "not_implemented", Message: "streaming not supported by stub", HTTPStatus: http.StatusNotImplemented}
+	}
+	return e.executeStream(ctx, auth, req, opts)
+}
+
+func (e *stubExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *stubExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e *stubExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, nil
+}
+
+// newStubManager returns a fill-first manager using cfg and executor, with auths registered for
+// the executor's provider and offering models. Registry entries are removed when the test ends.
+func newStubManager(t *testing.T, cfg *internalconfig.Config, executor *stubExecutor, auths []*Auth, models ...string) *Manager {
+	t.Helper()
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	if cfg != nil {
+		manager.SetConfig(cfg)
+	}
+	manager.RegisterExecutor(executor)
+	infos := make([]*registry.ModelInfo, 0, len(models))
+	for _, model := range models {
+		infos = append(infos, &registry.ModelInfo{ID: model})
+	}
+	reg := registry.GetGlobalRegistry()
+	for _, auth := range auths {
+		if auth.Provider == "" {
+			auth.Provider = executor.provider
+		}
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, err)
+		}
+		id := auth.ID
+		reg.RegisterClient(id, auth.Provider, infos)
+		t.Cleanup(func() { reg.UnregisterClient(id) })
+	}
+	return manager
+}
+
+// stubAuths returns bare auths with the given IDs.
+func stubAuths(ids ...string) []*Auth {
+	auths := make([]*Auth, 0, len(ids))
+	for _, id := range ids {
+		auths = append(auths, &Auth{ID: id})
+	}
+	return auths
+}
+
+// stubStream returns a completed stream result carrying payloads as chunks.
+func stubStream(payloads ...string) *cliproxyexecutor.StreamResult {
+	out := make(chan cliproxyexecutor.StreamChunk, len(payloads))
+	for _, payload := range payloads {
+		out <- cliproxyexecutor.StreamChunk{Payload: []byte(payload)}
+	}
+	close(out)
+	return &cliproxyexecutor.StreamResult{Chunks: out}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startExecuteSpan starts the span covering one Manager.Execute* call including all retries.
+func startExecuteSpan(ctx context.Context, name string, providers []string, model string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		attribute.StringSlice("cliproxy.providers", providers),
+		attribute.String("gen_ai.request.model", model),
+	)
+}
+
+// recordRetry notes on the execute span that attempt failed and the request will be retried
+// after wait.
+func recordRetry(span trace.Span, attempt int, wait time.Duration, err error) {
+	attrs := []attribute.KeyValue{
+		attribute.Int("cliproxy.retry", attempt+1),
+		attribute.Int64("cliproxy.wait_ms", wait.Milliseconds()),
+	}
+	if err != nil {
+		attrs = append(attrs, attribute.String("error.message", err.Error()))
+	}
+	span.AddEvent("retry", trace.WithAttributes(attrs...))
+	span.SetAttributes(attribute.Int("cliproxy.retries", attempt+1))
+}
+
+// recordPickFailure notes on the current span that no further credential could be picked.
+func recordPickFailure(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	trace.SpanFromContext(ctx).AddEvent("pick_failed", trace.WithAttributes(attribute.String("error.message", err.Error())))
+}
+
+// startAttemptSpan starts the span for one executor call on a picked credential. pick counts
+// the credentials tried so far within the current retry.
+func startAttemptSpan(ctx context.Context, auth *Auth, provider, upstreamModel string, pick int, hedge bool) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("cliproxy.provider", provider),
+		attribute.String("cliproxy.upstream_model", upstreamModel),
+		attribute.Int("cliproxy.pick", pick),
+	}
+	if auth != nil && auth.Index != "" {
+		attrs = append(attrs, attribute.String("cliproxy.auth_index", auth.Index))
+	}
+	if hedge {
+		attrs = append(attrs, attribute.Bool("cliproxy.hedge", true))
+	}
+	return tracing.Start(ctx, "cliproxy.attempt", attrs...)
+}
+
+// endAttemptSpan records the upstream status of err, if any, and ends span.
+func endAttemptSpan(span trace.Span, err error) {
+	if se, ok := errors.AsType[cliproxyexecutor.StatusError](err); ok && se != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", se.StatusCode()))
+	}
+	tracing.End(span, err)
+}
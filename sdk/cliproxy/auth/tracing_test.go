@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestExecuteRecordsAttemptSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(tracing.SetProvider(tp))
+
+	// The "a-bad" auth fails with a bad gateway and every other auth succeeds.
+	executor := &stubExecutor{
+		provider: "tracetest",
+		execute: func(_ context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+			if auth.ID == "a-bad" {
+				return cliproxyexecutor.Response{}, stubStatusError{code: http.StatusBadGateway}
+			}
+			return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+		},
+	}
+	manager := newStubManager(t, nil, executor, stubAuths("a-bad", "b-good"), "trace-model")
+
+	if _, err := manager.Execute(context.Background(), []string{"tracetest"}, cliproxyexecutor.Request{Model: "trace-model"}, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	var root *tracetest.SpanStub
+	var attempts []tracetest.SpanStub
+	for i := range spans {
+		switch spans[i].Name {
+		case "cliproxy.Execute":
+			root = &spans[i]
+		case "cliproxy.attempt":
+			attempts = append(attempts, spans[i])
+		}
+	}
+	if root == nil {
+		t.Fatalf("missing cliproxy.Execute span, got %d spans", len(spans))
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("attempt spans = %d, want 2", len(attempts))
+	}
+	for i, attempt := range attempts {
+		if attempt.Parent.SpanID() != root.SpanContext.SpanID() {
+			t.Errorf("attempt %d is not a child of the execute span", i)
+		}
+		if got := spanAttr(attempt.Attributes, "cliproxy.pick").AsInt64(); got != int64(i+1) {
+			t.Errorf("attempt %d pick = %d, want %d", i, got, i+1)
+		}
+	}
+	if attempts[0].Status.Code != codes.Error || spanAttr(attempts[0].Attributes, "http.response.status_code").AsInt64() != http.StatusBadGateway {
+		t.Errorf("first attempt should record the upstream failure, got %+v", attempts[0].Status)
+	}
+	if attempts[1].Status.Code == codes.Error {
+		t.Errorf("second attempt should succeed, got %+v", attempts[1].Status)
+	}
+}
+
+func spanAttr(attrs []attribute.KeyValue, key string) attribute.Value {
+	for _, kv := range attrs {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/streambridge"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/webtools"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// webToolsSession starts web tool emulation for a request served by auth. It returns nil when
+// emulation is off for the credential or the request carries no built-in web tools; otherwise
+// it returns the request rewritten with function tools in their place.
+func (m *Manager) webToolsSession(auth *Auth, provider string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*webtools.Session, cliproxyexecutor.Request, cliproxyexecutor.Options) {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || !cfg.WebTools.Enable || auth == nil || opts.Alt == "responses/compact" {
+		return nil, req, opts
+	}
+	if !cfg.WebTools.Emulates(credentialNames(auth, provider)) {
+		return nil, req, opts
+	}
+	client := webtools.NewClient(cfg.WebTools)
+	session, payload, ok := webtools.NewSession(opts.SourceFormat.String(), client, req.Payload, cfg.WebTools.MaxIterations)
+	if !ok {
+		return nil, req, opts
+	}
+	req.Payload = payload
+	opts.OriginalRequest = payload
+	return session, req, opts
+}
+
+// executeWithWebTools runs the model round trips of a request with emulated web tools: each
+// response that only calls those tools has them run and is followed by another request with the
+// results, until the model answers or the iteration limit is reached.
+func (m *Manager) executeWithWebTools(ctx context.Context, executor ProviderExecutor, auth *Auth, provider string, session *webtools.Session, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	req, opts = withStreamMode(req, opts, false)
+	for {
+		resp, next, errRound := m.webToolsRound(ctx, executor, auth, provider, session, req, opts)
+		if errRound != nil || next == nil {
+			return resp, errRound
+		}
+		req.Payload = next
+		opts.OriginalRequest = next
+	}
+}
+
+// streamWithWebTools is executeWithWebTools for streaming clients. The first round runs before
+// the stream is returned so its errors can still move the request to another credential. Later
+// rounds run while the stream is open, with a keep-alive chunk as each round finishes so the
+// client connection stays busy, and the final response is replayed as stream chunks.
+func (m *Manager) streamWithWebTools(ctx context.Context, executor ProviderExecutor, auth *Auth, provider string, session *webtools.Session, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	format := opts.SourceFormat.String()
+	req, opts = withStreamMode(req, opts, false)
+	resp, next, errRound := m.webToolsRound(ctx, executor, auth, provider, session, req, opts)
+	if errRound != nil {
+		return nil, errRound
+	}
+	if next == nil {
+		return replayAsStream(format, resp)
+	}
+	out := make(chan cliproxyexecutor.StreamChunk)
+	go func() {
+		defer close(out)
+		send := func(chunk cliproxyexecutor.StreamChunk) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case out <- chunk:
+				return true
+			}
+		}
+		for next != nil {
+			if keepAlive := streambridge.KeepAlive(format); keepAlive != nil && !send(cliproxyexecutor.StreamChunk{Payload: keepAlive}) {
+				return
+			}
+			req.Payload = next
+			opts.OriginalRequest = next
+			resp, next, errRound = m.webToolsRound(ctx, executor, auth, provider, session, req, opts)
+			if errRound != nil {
+				send(cliproxyexecutor.StreamChunk{Err: errRound})
+				return
+			}
+		}
+		chunks, errSplit := replayChunks(format, resp)
+		if errSplit != nil {
+			send(cliproxyexecutor.StreamChunk{Err: errSplit})
+			return
+		}
+		for _, chunk := range chunks {
+			if !send(cliproxyexecutor.StreamChunk{Payload: chunk}) {
+				return
+			}
+		}
+	}()
+	return &cliproxyexecutor.StreamResult{Headers: resp.Headers, Chunks: out}, nil
+}
+
+// webToolsRound runs one model round trip. When the model answered it returns the final
+// response; otherwise it runs the emulated tools and returns the request for the next round.
+func (m *Manager) webToolsRound(ctx context.Context, executor ProviderExecutor, auth *Auth, provider string, session *webtools.Session, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, []byte, error) {
+	resp, errExec := m.executeWithStreamBridge(ctx, executor, auth, provider, req, opts)
+	if errExec != nil {
+		return cliproxyexecutor.Response{}, nil, errExec
+	}
+	next, done, errNext := session.Next(ctx, req.Payload, resp.Payload)
+	if errNext != nil {
+		return cliproxyexecutor.Response{}, nil, errNext
+	}
+	if done {
+		resp.Payload = session.Finish(resp.Payload)
+		return resp, nil, nil
+	}
+	return resp, next, nil
+}
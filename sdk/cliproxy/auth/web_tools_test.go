@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// newWebToolsTestManager returns a manager with web tools enabled against a local search stub.
+// Its upstream answers in Claude format: it searches on the first call and answers once the
+// request carries the tool result. The returned slice collects the upstream request bodies.
+func newWebToolsTestManager(t *testing.T) (*Manager, *[][]byte) {
+	t.Helper()
+	search := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"url":"https://example.com/weather","title":"Weather","content":"Sunny all day"}]}`))
+	}))
+	t.Cleanup(search.Close)
+
+	var requests [][]byte
+	executor := &stubExecutor{
+		provider: "webtoolstest",
+		execute: func(_ context.Context, _ *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+			requests = append(requests, req.Payload)
+			if opts.Stream {
+				return cliproxyexecutor.Response{}, &Error{Code: "unexpected_stream", Message: "stream flag set", HTTPStatus: http.StatusBadRequest}
+			}
+			if len(gjson.GetBytes(req.Payload, "messages").Array()) == 1 {
+				return cliproxyexecutor.Response{Payload: []byte(`{"id":"msg_1","type":"message","role":"assistant","model":"m","content":[{"type":"tool_use","id":"toolu_1","name":"web_search","input":{"query":"weather"}}],"stop_reason":"tool_use","usage":{"input_tokens":5,"output_tokens":2}}`)}, nil
+			}
+			return cliproxyexecutor.Response{Payload: []byte(`{"id":"msg_2","type":"message","role":"assistant","model":"m","content":[{"type":"text","text":"Sunny."}],"stop_reason":"end_turn","usage":{"input_tokens":20,"output_tokens":3}}`)}, nil
+		},
+	}
+	cfg := &internalconfig.Config{WebTools: internalconfig.WebToolsConfig{Enable: true, SearchURL: search.URL}}
+	cfg.SanitizeWebTools()
+	return newStubManager(t, cfg, executor, stubAuths("webtools-auth"), "webtools-model"), &requests
+}
+
+const webToolsTestRequest = `{"model":"webtools-model","stream":true,"messages":[{"role":"user","content":"weather?"}],"tools":[{"type":"web_search_20250305","name":"web_search"}]}`
+
+func TestExecuteStream_WebToolsRunsSearchAndReplays(t *testing.T) {
+	manager, requests := newWebToolsTestManager(t)
+
+	req := cliproxyexecutor.Request{Model: "webtools-model", Payload: []byte(webToolsTestRequest)}
+	opts := cliproxyexecutor.Options{Stream: true, SourceFormat: sdktranslator.FromString("claude")}
+	result, err := manager.ExecuteStream(context.Background(), []string{"webtoolstest"}, req, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var stream strings.Builder
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("chunk error = %v", chunk.Err)
+		}
+		stream.Write(chunk.Payload)
+	}
+
+	upstream := *requests
+	if len(upstream) != 2 {
+		t.Fatalf("upstream calls = %d, want 2", len(upstream))
+	}
+	if got := gjson.GetBytes(upstream[0], "tools.0.input_schema.type").String(); got != "object" {
+		t.Fatalf("upstream tools = %s, want a function tool", gjson.GetBytes(upstream[0], "tools").Raw)
+	}
+	if !strings.Contains(gjson.GetBytes(upstream[1], "messages.2.content.0.content").String(), "https://example.com/weather") {
+		t.Fatalf("second request = %s, want the search results", upstream[1])
+	}
+	for _, want := range []string{`"server_tool_use"`, `"web_search_tool_result"`, `"Sunny."`, "message_stop"} {
+		if !strings.Contains(stream.String(), want) {
+			t.Fatalf("stream missing %s: %s", want, stream.String())
+		}
+	}
+}
+
+func TestExecuteStream_WebToolsKeepsStreamAliveBetweenRounds(t *testing.T) {
+	manager, _ := newWebToolsTestManager(t)
+	upstream, _ := manager.Executor("webtoolstest")
+	release := make(chan struct{})
+	calls := 0
+	manager.RegisterExecutor(&stubExecutor{
+		provider: "webtoolstest",
+		execute: func(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+			if calls++; calls > 1 {
+				<-release
+			}
+			return upstream.Execute(ctx, auth, req, opts)
+		},
+	})
+
+	req := cliproxyexecutor.Request{Model: "webtools-model", Payload: []byte(webToolsTestRequest)}
+	opts := cliproxyexecutor.Options{Stream: true, SourceFormat: sdktranslator.FromString("claude")}
+	result, err := manager.ExecuteStream(context.Background(), []string{"webtoolstest"}, req, opts)
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	// The stream is open and signals progress while the second round is still running.
+	first := <-result.Chunks
+	if first.Err != nil || !strings.Contains(string(first.Payload), "event: ping") {
+		t.Fatalf("first chunk = %q, %v, want a ping", first.Payload, first.Err)
+	}
+	close(release)
+	var stream strings.Builder
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("chunk error = %v", chunk.Err)
+		}
+		stream.Write(chunk.Payload)
+	}
+	for _, want := range []string{`"server_tool_use"`, `"Sunny."`, "message_stop"} {
+		if !strings.Contains(stream.String(), want) {
+			t.Fatalf("stream missing %s: %s", want, stream.String())
+		}
+	}
+}
+
+func TestExecute_WebToolsSkippedForNativeProviders(t *testing.T) {
+	cfg := internalconfig.WebToolsConfig{Enable: true}
+	if cfg.Emulates([]string{"claude", "claude", ""}) {
+		t.Fatal("Emulates() = true for native claude credentials")
+	}
+	if !cfg.Emulates([]string{"webtoolstest", "webtoolstest", ""}) {
+		t.Fatal("Emulates() = false for a provider without native web tools")
+	}
+}
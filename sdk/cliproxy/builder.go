@@ -212,7 +212,8 @@ func (b *Builder) Build() (*Service, error) {
 		coreManager = coreauth.NewManager(tokenStore, selector, nil)
 	}
 	// Attach a default RoundTripper provider so providers can opt-in per-auth transports.
-	coreManager.SetRoundTripperProvider(newDefaultRoundTripperProvider())
+	roundTripperProvider := newDefaultRoundTripperProvider(b.cfg)
+	coreManager.SetRoundTripperProvider(roundTripperProvider)
 	coreManager.SetConfig(b.cfg)
 	coreManager.SetOAuthModelAlias(b.cfg.OAuthModelAlias)
 
@@ -227,6 +228,8 @@ func (b *Builder) Build() (*Service, error) {
 		accessManager:  accessManager,
 		coreManager:    coreManager,
 		serverOptions:  append([]api.ServerOption(nil), b.serverOptions...),
+
+		roundTripperProvider: roundTripperProvider,
 	}
 	return service, nil
 }
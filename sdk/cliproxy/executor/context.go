@@ -21,3 +21,23 @@ func DownstreamWebsocket(ctx context.Context) bool {
 	enabled, ok := raw.(bool)
 	return ok && enabled
 }
+
+type hedgeAttemptContextKey struct{}
+
+// WithHedgeAttempt marks the current execution as the duplicate attempt of a hedged request.
+func WithHedgeAttempt(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, hedgeAttemptContextKey{}, true)
+}
+
+// HedgeAttempt reports whether the current execution is the duplicate attempt of a hedged request.
+func HedgeAttempt(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	raw := ctx.Value(hedgeAttemptContextKey{})
+	enabled, ok := raw.(bool)
+	return ok && enabled
+}
@@ -19,6 +19,10 @@ const (
 	SelectedAuthCallbackMetadataKey = "selected_auth_callback"
 	// ExecutionSessionMetadataKey identifies a long-lived downstream execution session.
 	ExecutionSessionMetadataKey = "execution_session_id"
+	// ClientAPIKeyMetadataKey carries the client API key that authenticated the request.
+	ClientAPIKeyMetadataKey = "client_api_key"
+	// SessionAffinityMetadataKey carries a client-supplied conversation identifier used for session-sticky selection.
+	SessionAffinityMetadataKey = "session_affinity_key"
 )
 
 // Request encapsulates the translated payload that will be sent to a provider executor.
@@ -0,0 +1,260 @@
+package cliproxy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultModelDiscoveryInterval = time.Hour
+	// modelDiscoveryTick is how often the scheduler looks for providers that are due a listing.
+	modelDiscoveryTick    = time.Minute
+	modelDiscoveryTimeout = 15 * time.Second
+)
+
+// modelDiscovery caches the upstream model lists of openai-compatibility credentials
+// that opt into discover-models, keyed by auth ID.
+type modelDiscovery struct {
+	mu      sync.Mutex
+	entries map[string]discoveredModels
+	cancel  context.CancelFunc
+}
+
+type discoveredModels struct {
+	models    []*ModelInfo
+	signature string
+	fetchedAt time.Time
+}
+
+// openAICompatConfigFor returns the openai-compatibility entry an auth was synthesized from.
+func openAICompatConfigFor(cfg *config.Config, a *coreauth.Auth) *config.OpenAICompatibility {
+	if cfg == nil {
+		return nil
+	}
+	_, compatName, ok := openAICompatInfoFromAuth(a)
+	if !ok || compatName == "" {
+		return nil
+	}
+	for i := range cfg.OpenAICompatibility {
+		if strings.EqualFold(cfg.OpenAICompatibility[i].Name, compatName) {
+			return &cfg.OpenAICompatibility[i]
+		}
+	}
+	return nil
+}
+
+func modelDiscoveryInterval(compat *config.OpenAICompatibility) time.Duration {
+	if compat.DiscoverIntervalSeconds > 0 {
+		return time.Duration(compat.DiscoverIntervalSeconds) * time.Second
+	}
+	return defaultModelDiscoveryInterval
+}
+
+// discoveredModelsForAuth returns the cached upstream model list of the auth, listing the
+// upstream synchronously the first time the auth is seen.
+func (s *Service) discoveredModelsForAuth(cfg *config.Config, a *coreauth.Auth) []*ModelInfo {
+	s.discovery.mu.Lock()
+	entry, ok := s.discovery.entries[a.ID]
+	s.discovery.mu.Unlock()
+	if ok {
+		return entry.models
+	}
+	s.fetchDiscoveredModels(context.Background(), cfg, a)
+	s.discovery.mu.Lock()
+	defer s.discovery.mu.Unlock()
+	return s.discovery.entries[a.ID].models
+}
+
+// fetchDiscoveredModels lists the upstream models of the auth and caches them. It reports
+// whether the list differs from the cached one. A failed listing keeps the previous list.
+func (s *Service) fetchDiscoveredModels(ctx context.Context, cfg *config.Config, a *coreauth.Auth) bool {
+	ctx, cancel := context.WithTimeout(ctx, modelDiscoveryTimeout)
+	defer cancel()
+	models, err := executor.FetchOpenAICompatModels(ctx, a, cfg)
+
+	s.discovery.mu.Lock()
+	defer s.discovery.mu.Unlock()
+	if s.discovery.entries == nil {
+		s.discovery.entries = make(map[string]discoveredModels)
+	}
+	entry, seen := s.discovery.entries[a.ID]
+	entry.fetchedAt = time.Now()
+	if err != nil {
+		log.Warnf("model discovery: listing models for %s failed: %v", a.ID, err)
+		s.discovery.entries[a.ID] = entry
+		return false
+	}
+	signature := modelListSignature(models)
+	changed := !seen || signature != entry.signature
+	entry.models = models
+	entry.signature = signature
+	s.discovery.entries[a.ID] = entry
+	if changed && seen {
+		log.Infof("model discovery: upstream model list of %s changed (%d models)", a.ID, len(models))
+	}
+	return changed
+}
+
+// startModelDiscovery launches the background scheduler that re-lists upstream models of
+// discover-models providers and re-registers credentials whose list changed.
+func (s *Service) startModelDiscovery(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	s.discovery.mu.Lock()
+	if s.discovery.cancel != nil {
+		s.discovery.cancel()
+	}
+	s.discovery.cancel = cancel
+	s.discovery.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(modelDiscoveryTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.refreshDiscoveredModels(ctx)
+			}
+		}
+	}()
+}
+
+// stopModelDiscovery cancels the discovery scheduler.
+func (s *Service) stopModelDiscovery() {
+	s.discovery.mu.Lock()
+	defer s.discovery.mu.Unlock()
+	if s.discovery.cancel != nil {
+		s.discovery.cancel()
+		s.discovery.cancel = nil
+	}
+}
+
+// refreshDiscoveredModels re-lists every discover-models credential whose listing is older
+// than its interval. Registering the changed list fires the model registry hooks.
+func (s *Service) refreshDiscoveredModels(ctx context.Context) {
+	if s.coreManager == nil {
+		return
+	}
+	s.cfgMu.RLock()
+	cfg := s.cfg
+	s.cfgMu.RUnlock()
+
+	now := time.Now()
+	var due []*coreauth.Auth
+	eligible := make(map[string]struct{})
+	for _, a := range s.coreManager.List() {
+		if a == nil || a.Disabled {
+			continue
+		}
+		compat := openAICompatConfigFor(cfg, a)
+		if compat == nil || !compat.DiscoverModels {
+			continue
+		}
+		eligible[a.ID] = struct{}{}
+		s.discovery.mu.Lock()
+		entry, seen := s.discovery.entries[a.ID]
+		s.discovery.mu.Unlock()
+		if seen && now.Sub(entry.fetchedAt) < modelDiscoveryInterval(compat) {
+			continue
+		}
+		due = append(due, a)
+	}
+	s.discovery.mu.Lock()
+	for id := range s.discovery.entries {
+		if _, ok := eligible[id]; !ok {
+			delete(s.discovery.entries, id)
+		}
+	}
+	s.discovery.mu.Unlock()
+
+	for _, a := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		if s.fetchDiscoveredModels(ctx, cfg, a) {
+			s.registerModelsForAuth(a)
+		}
+	}
+}
+
+// buildOpenAICompatModels converts the configured models of an openai-compatibility provider
+// to registry models and merges in the discovered upstream models. A configured alias takes
+// the upstream metadata of the model it points at, and an upstream model already exposed
+// under an alias is not listed again under its upstream name.
+func buildOpenAICompatModels(compat *config.OpenAICompatibility, discovered []*ModelInfo) []*ModelInfo {
+	upstream := make(map[string]*ModelInfo, len(discovered))
+	for _, model := range discovered {
+		upstream[strings.ToLower(model.ID)] = model
+	}
+	now := time.Now().Unix()
+	covered := make(map[string]struct{}, len(compat.Models)*2)
+	ms := make([]*ModelInfo, 0, len(compat.Models)+len(discovered))
+	for j := range compat.Models {
+		m := compat.Models[j]
+		// Use alias as model ID, fallback to name if alias is empty
+		modelID := m.Alias
+		if modelID == "" {
+			modelID = m.Name
+		}
+		info := &ModelInfo{
+			ID:          modelID,
+			Object:      "model",
+			Created:     now,
+			OwnedBy:     compat.Name,
+			Type:        "openai-compatibility",
+			DisplayName: modelID,
+			UserDefined: true,
+		}
+		if src, ok := upstream[strings.ToLower(strings.TrimSpace(m.Name))]; ok {
+			info.ContextLength = src.ContextLength
+			info.MaxCompletionTokens = src.MaxCompletionTokens
+			info.SupportedParameters = src.SupportedParameters
+			info.Pricing = src.Pricing
+		}
+		covered[strings.ToLower(strings.TrimSpace(m.Name))] = struct{}{}
+		covered[strings.ToLower(modelID)] = struct{}{}
+		ms = append(ms, info)
+	}
+	for _, src := range discovered {
+		if _, ok := covered[strings.ToLower(src.ID)]; ok {
+			continue
+		}
+		info := *src
+		info.Object = "model"
+		if info.Created == 0 {
+			info.Created = now
+		}
+		info.OwnedBy = compat.Name
+		info.Type = "openai-compatibility"
+		if info.DisplayName == "" {
+			info.DisplayName = info.ID
+		}
+		info.UserDefined = true
+		ms = append(ms, &info)
+	}
+	return ms
+}
+
+// modelListSignature summarizes a model list for change detection.
+func modelListSignature(models []*ModelInfo) string {
+	parts := make([]string, 0, len(models))
+	for _, model := range models {
+		pricing := make([]string, 0, len(model.Pricing))
+		for k, v := range model.Pricing {
+			pricing = append(pricing, k+"="+v)
+		}
+		sort.Strings(pricing)
+		parts = append(parts, fmt.Sprintf("%s|%d|%d|%s", model.ID, model.ContextLength, model.MaxCompletionTokens, strings.Join(pricing, ",")))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,131 @@
+package cliproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+type discoveryHook struct {
+	registered chan []*ModelInfo
+}
+
+func (h *discoveryHook) OnModelsRegistered(_ context.Context, _, clientID string, models []*ModelInfo) {
+	if clientID == "compat-discovery" {
+		h.registered <- models
+	}
+}
+
+func (*discoveryHook) OnModelsUnregistered(context.Context, string, string) {}
+
+func TestOpenAICompatModelDiscovery(t *testing.T) {
+	var mu sync.Mutex
+	listing := `{"data":[
+		{"id":"vendor/large","context_length":131072,"pricing":{"prompt":"0.000002","completion":"0.000008"},"top_provider":{"max_completion_tokens":16384}},
+		{"id":"vendor/small","context_length":32768},
+		{"id":"vendor/embed-v1"}
+	]}`
+	var gotAuth string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(listing))
+	}))
+	defer upstream.Close()
+
+	cfg := &config.Config{}
+	cfg.OpenAICompatibility = []config.OpenAICompatibility{{
+		Name:           "vendor",
+		BaseURL:        upstream.URL + "/v1",
+		DiscoverModels: true,
+		Models:         []config.OpenAICompatibilityModel{{Name: "vendor/large", Alias: "large"}},
+		ExcludedModels: []string{"*embed*"},
+	}}
+	manager := coreauth.NewManager(nil, nil, nil)
+	service := &Service{cfg: cfg, coreManager: manager}
+	auth := &coreauth.Auth{
+		ID:       "compat-discovery",
+		Provider: "vendor",
+		Status:   coreauth.StatusActive,
+		Attributes: map[string]string{
+			"base_url":     upstream.URL + "/v1",
+			"api_key":      "sk-test",
+			"compat_name":  "vendor",
+			"provider_key": "vendor",
+		},
+	}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	reg := GlobalModelRegistry()
+	t.Cleanup(func() { reg.UnregisterClient(auth.ID) })
+
+	service.registerModelsForAuth(auth)
+	models := make(map[string]*ModelInfo)
+	for _, model := range registry.GetGlobalRegistry().GetModelsForClient(auth.ID) {
+		models[model.ID] = model
+	}
+	if gotAuth != "Bearer sk-test" {
+		t.Errorf("upstream Authorization = %q, want the entry's key", gotAuth)
+	}
+	if len(models) != 2 {
+		t.Fatalf("registered models = %v, want large and vendor/small", models)
+	}
+	large := models["large"]
+	if large == nil || large.ContextLength != 131072 || large.MaxCompletionTokens != 16384 || large.Pricing["completion"] != "0.000008" {
+		t.Errorf("alias large = %+v, want upstream metadata of vendor/large", large)
+	}
+	if small := models["vendor/small"]; small == nil || small.ContextLength != 32768 || small.OwnedBy != "vendor" {
+		t.Errorf("discovered vendor/small = %+v", small)
+	}
+
+	// A changed upstream list is re-registered and fires the registry hook.
+	hook := &discoveryHook{registered: make(chan []*ModelInfo, 4)}
+	registry.GetGlobalRegistry().SetHook(hook)
+	t.Cleanup(func() { registry.GetGlobalRegistry().SetHook(nil) })
+
+	service.refreshDiscoveredModels(context.Background())
+	select {
+	case <-hook.registered:
+		t.Fatal("hook fired before the listing was due")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mu.Lock()
+	listing = `{"data":[{"id":"vendor/large","context_length":262144},{"id":"vendor/medium"}]}`
+	mu.Unlock()
+	service.discovery.mu.Lock()
+	entry := service.discovery.entries[auth.ID]
+	entry.fetchedAt = time.Time{}
+	service.discovery.entries[auth.ID] = entry
+	service.discovery.mu.Unlock()
+
+	service.refreshDiscoveredModels(context.Background())
+	select {
+	case got := <-hook.registered:
+		ids := make(map[string]int)
+		for _, model := range got {
+			ids[model.ID] = model.ContextLength
+		}
+		if len(ids) != 2 || ids["large"] != 262144 {
+			t.Errorf("hook models = %v, want large (262144) and vendor/medium", ids)
+		}
+		if _, ok := ids["vendor/medium"]; !ok {
+			t.Errorf("hook models = %v, missing vendor/medium", ids)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("registry hook did not fire after the upstream list changed")
+	}
+}
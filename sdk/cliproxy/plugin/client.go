@@ -0,0 +1,294 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxMessageSize bounds a single protocol line.
+const maxMessageSize = 64 << 20
+
+// streamQueueBytes is how much chunk data a stream call may have queued before it is failed.
+// The reader never waits for a slow consumer, so one stalled stream cannot hold up the
+// responses of other calls on the same connection; bursts and slow clients are absorbed up to
+// this size.
+const streamQueueBytes = 8 << 20
+
+// ErrClosed is returned for calls on a connection that has shut down.
+var ErrClosed = errors.New("plugin: connection closed")
+
+// Client is the proxy side of one plugin connection. It multiplexes concurrent calls.
+type Client struct {
+	conn io.ReadWriteCloser
+
+	writeMu sync.Mutex
+	enc     *json.Encoder
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]*pendingCall
+	err     error
+	done    chan struct{}
+}
+
+type pendingCall struct {
+	response chan *rpcMessage
+	// chunks queues stream_chunk data of an execute_stream call; nil for plain calls.
+	chunks *chunkQueue
+}
+
+// chunkQueue holds the stream chunks the reader received and the consumer has not taken yet.
+type chunkQueue struct {
+	mu    sync.Mutex
+	items []string
+	size  int
+	ready chan struct{}
+}
+
+func newChunkQueue() *chunkQueue {
+	return &chunkQueue{ready: make(chan struct{}, 1)}
+}
+
+// push queues data. It reports false when the queue would exceed streamQueueBytes.
+func (q *chunkQueue) push(data string) bool {
+	q.mu.Lock()
+	if q.size+len(data) > streamQueueBytes {
+		q.mu.Unlock()
+		return false
+	}
+	q.items = append(q.items, data)
+	q.size += len(data)
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// take removes and returns the queued chunks in order.
+func (q *chunkQueue) take() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := q.items
+	q.items = nil
+	q.size = 0
+	return items
+}
+
+// NewClient starts reading responses from conn. The client owns conn and closes it on Close.
+func NewClient(conn io.ReadWriteCloser) *Client {
+	c := &Client{
+		conn:    conn,
+		enc:     json.NewEncoder(conn),
+		pending: make(map[int64]*pendingCall),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// Done is closed once the connection has failed or been closed.
+func (c *Client) Done() <-chan struct{} { return c.done }
+
+// Err returns why the connection shut down, or nil while it is open.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close shuts the connection down and fails in-flight calls.
+func (c *Client) Close() error {
+	errClose := c.conn.Close()
+	c.fail(ErrClosed)
+	return errClose
+}
+
+// Call sends a request and decodes its result into result (which may be nil).
+func (c *Client) Call(ctx context.Context, method string, params, result any) error {
+	id, call, err := c.send(method, params, false)
+	if err != nil {
+		return err
+	}
+	return c.wait(ctx, id, call, result, nil)
+}
+
+// CallStream sends a request whose answer is streamed as stream_chunk notifications. Each
+// chunk is passed to onChunk in order; the call returns once the plugin answers the request.
+func (c *Client) CallStream(ctx context.Context, method string, params, result any, onChunk func(data string)) error {
+	id, call, err := c.send(method, params, true)
+	if err != nil {
+		return err
+	}
+	return c.wait(ctx, id, call, result, onChunk)
+}
+
+func (c *Client) send(method string, params any, stream bool) (int64, *pendingCall, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return 0, nil, fmt.Errorf("plugin: encode %s params: %w", method, err)
+	}
+	call := &pendingCall{response: make(chan *rpcMessage, 1)}
+	if stream {
+		call.chunks = newChunkQueue()
+	}
+	c.mu.Lock()
+	if c.err != nil {
+		err = c.err
+		c.mu.Unlock()
+		return 0, nil, err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = call
+	c.mu.Unlock()
+
+	if err = c.write(&rpcMessage{JSONRPC: "2.0", ID: &id, Method: method, Params: raw}); err != nil {
+		c.forget(id)
+		return 0, nil, err
+	}
+	return id, call, nil
+}
+
+func (c *Client) wait(ctx context.Context, id int64, call *pendingCall, result any, onChunk func(string)) error {
+	var ready <-chan struct{}
+	deliver := func() {}
+	if call.chunks != nil {
+		ready = call.chunks.ready
+		deliver = func() {
+			for _, data := range call.chunks.take() {
+				if onChunk != nil {
+					onChunk(data)
+				}
+			}
+		}
+	}
+	for {
+		select {
+		case <-ready:
+			deliver()
+		case msg := <-call.response:
+			if msg == nil {
+				return c.Err()
+			}
+			// Chunks sent before the response are already queued; deliver them first.
+			deliver()
+			if msg.Error != nil {
+				return msg.Error.toError()
+			}
+			if result != nil && len(msg.Result) > 0 && string(msg.Result) != "null" {
+				if err := json.Unmarshal(msg.Result, result); err != nil {
+					return fmt.Errorf("plugin: decode result: %w", err)
+				}
+			}
+			return nil
+		case <-ctx.Done():
+			c.forget(id)
+			c.cancel(id)
+			return ctx.Err()
+		}
+	}
+}
+
+// cancel asks the plugin to abandon request id.
+func (c *Client) cancel(id int64) {
+	raw, _ := json.Marshal(CancelParams{ID: id})
+	_ = c.write(&rpcMessage{JSONRPC: "2.0", Method: NotifyCancel, Params: raw})
+}
+
+func (c *Client) write(msg *rpcMessage) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.enc.Encode(msg); err != nil {
+		c.fail(fmt.Errorf("plugin: write: %w", err))
+		return c.Err()
+	}
+	return nil
+}
+
+func (c *Client) forget(id int64) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+func (c *Client) lookup(id int64, remove bool) *pendingCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call := c.pending[id]
+	if remove {
+		delete(c.pending, id)
+	}
+	return call
+}
+
+func (c *Client) readLoop() {
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(nil, maxMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			c.fail(fmt.Errorf("plugin: malformed message: %w", err))
+			return
+		}
+		switch {
+		case msg.ID != nil && msg.Method == "":
+			if call := c.lookup(*msg.ID, true); call != nil {
+				call.response <- &msg
+			}
+		case msg.Method == NotifyStreamChunk:
+			var chunk StreamChunk
+			if err := json.Unmarshal(msg.Params, &chunk); err != nil {
+				continue
+			}
+			if call := c.lookup(chunk.ID, false); call != nil && call.chunks != nil && !call.chunks.push(chunk.Data) {
+				c.overflow(chunk.ID, call)
+			}
+		}
+	}
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	c.fail(fmt.Errorf("plugin: connection lost: %w", err))
+}
+
+// overflow fails a stream call whose consumer fell streamQueueBytes behind and cancels it on
+// the plugin side. Later chunks and the plugin's answer are dropped.
+func (c *Client) overflow(id int64, call *pendingCall) {
+	if c.lookup(id, true) != call {
+		return
+	}
+	call.response <- &rpcMessage{JSONRPC: "2.0", ID: &id, Error: &rpcError{
+		Code:    codeInternalError,
+		Message: fmt.Sprintf("stream consumer fell more than %d MiB behind", streamQueueBytes>>20),
+	}}
+	go c.cancel(id)
+}
+
+// fail records the first fatal error and releases every waiting call.
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.err = err
+	pending := c.pending
+	c.pending = make(map[int64]*pendingCall)
+	close(c.done)
+	c.mu.Unlock()
+	for _, call := range pending {
+		call.response <- nil
+	}
+}
@@ -0,0 +1,232 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// restartBackoff is the minimum delay between two launches of a plugin that keeps failing.
+const restartBackoff = 2 * time.Second
+
+// Options tells a Host how to reach a plugin. Exactly one of Command and Socket is used;
+// Socket wins when both are set.
+type Options struct {
+	// Name is the provider key the plugin serves.
+	Name string
+	// Command is the executable launched; the protocol runs over its stdin and stdout.
+	Command string
+	Args    []string
+	// Env adds variables to the inherited environment of the launched command.
+	Env map[string]string
+	// Socket is the path of a unix socket served by an already running plugin.
+	Socket string
+}
+
+// Host owns the connection to one plugin. It connects lazily, performs the initialize
+// handshake, and reconnects (relaunching the command) after the plugin exits.
+type Host struct {
+	opts Options
+
+	mu         sync.Mutex
+	client     *Client
+	info       Info
+	cmd        *exec.Cmd
+	lastLaunch time.Time
+	lastErr    error
+	closed     bool
+}
+
+// NewHost returns a Host for opts without connecting.
+func NewHost(opts Options) *Host {
+	return &Host{opts: opts}
+}
+
+// Options returns the options the host was created with.
+func (h *Host) Options() Options { return h.opts }
+
+// SameOptions reports whether opts would start the same plugin as the host.
+func (h *Host) SameOptions(opts Options) bool {
+	a, b := h.opts, opts
+	if len(a.Args) == 0 && len(b.Args) == 0 {
+		a.Args, b.Args = nil, nil
+	}
+	if len(a.Env) == 0 && len(b.Env) == 0 {
+		a.Env, b.Env = nil, nil
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Connect returns a live client and the plugin's Info, starting the plugin if needed.
+func (h *Host) Connect(ctx context.Context) (*Client, Info, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, Info{}, ErrClosed
+	}
+	if h.client != nil {
+		select {
+		case <-h.client.Done():
+			log.Warnf("plugin %s: connection lost: %v", h.opts.Name, h.client.Err())
+			h.reapLocked()
+		default:
+			return h.client, h.info, nil
+		}
+	}
+	if h.lastErr != nil && time.Since(h.lastLaunch) < restartBackoff {
+		return nil, Info{}, h.lastErr
+	}
+	h.lastLaunch = time.Now()
+	client, info, err := h.startLocked(ctx)
+	h.lastErr = err
+	if err != nil {
+		return nil, Info{}, err
+	}
+	h.client, h.info = client, info
+	return client, info, nil
+}
+
+func (h *Host) startLocked(ctx context.Context) (*Client, Info, error) {
+	var conn io.ReadWriteCloser
+	switch {
+	case h.opts.Socket != "":
+		var dialer net.Dialer
+		c, err := dialer.DialContext(ctx, "unix", h.opts.Socket)
+		if err != nil {
+			return nil, Info{}, fmt.Errorf("plugin %s: dial %s: %w", h.opts.Name, h.opts.Socket, err)
+		}
+		conn = c
+	case h.opts.Command != "":
+		c, err := h.launchLocked()
+		if err != nil {
+			return nil, Info{}, err
+		}
+		conn = c
+	default:
+		return nil, Info{}, fmt.Errorf("plugin %s: neither command nor socket configured", h.opts.Name)
+	}
+
+	client := NewClient(conn)
+	var info Info
+	params := InitializeParams{ProtocolVersion: ProtocolVersion, Provider: h.opts.Name}
+	if err := client.Call(ctx, MethodInitialize, params, &info); err != nil {
+		_ = client.Close()
+		h.reapProcessLocked()
+		return nil, Info{}, fmt.Errorf("plugin %s: initialize: %w", h.opts.Name, err)
+	}
+	if info.ProtocolVersion != ProtocolVersion {
+		_ = client.Close()
+		h.reapProcessLocked()
+		return nil, Info{}, fmt.Errorf("plugin %s: unsupported protocol version %d (want %d)", h.opts.Name, info.ProtocolVersion, ProtocolVersion)
+	}
+	if info.Format == "" {
+		info.Format = "openai"
+	}
+	log.Infof("plugin %s: connected (%s %s, format %s)", h.opts.Name, info.Name, info.Version, info.Format)
+	return client, info, nil
+}
+
+// launchLocked starts the plugin command and returns its stdio as one connection.
+func (h *Host) launchLocked() (io.ReadWriteCloser, error) {
+	cmd := exec.Command(h.opts.Command, h.opts.Args...)
+	cmd.Env = os.Environ()
+	keys := make([]string, 0, len(h.opts.Env))
+	for k := range h.opts.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+h.opts.Env[k])
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", h.opts.Name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", h.opts.Name, err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", h.opts.Name, err)
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin %s: start %s: %w", h.opts.Name, h.opts.Command, err)
+	}
+	name := h.opts.Name
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			log.Infof("plugin %s: %s", name, scanner.Text())
+		}
+	}()
+	h.cmd = cmd
+	return &stdioConn{Reader: stdout, stdin: stdin}, nil
+}
+
+// reapLocked drops a dead connection and waits for its process.
+func (h *Host) reapLocked() {
+	if h.client != nil {
+		_ = h.client.Close()
+		h.client = nil
+	}
+	h.reapProcessLocked()
+}
+
+func (h *Host) reapProcessLocked() {
+	cmd := h.cmd
+	h.cmd = nil
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+		<-exited
+	}
+}
+
+// Close disconnects from the plugin and stops a launched command. Closing stdin is the
+// plugin's signal to exit; it is killed if it has not exited after a grace period.
+func (h *Host) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	h.reapLocked()
+	return nil
+}
+
+type stdioConn struct {
+	io.Reader
+	stdin io.WriteCloser
+}
+
+func (c *stdioConn) Write(p []byte) (int, error) { return c.stdin.Write(p) }
+
+func (c *stdioConn) Close() error {
+	err := c.stdin.Close()
+	if errors.Is(err, os.ErrClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,238 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubPluginEnv makes the test binary act as a plugin speaking on stdin/stdout.
+const stubPluginEnv = "CLIPROXY_STUB_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(stubPluginEnv) == "1" {
+		info := Info{Name: "stub", Version: "1.0", Format: "openai"}
+		if err := Serve(context.Background(), os.Stdin, os.Stdout, info, stubHandler{}); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type stubHandler struct{}
+
+func (stubHandler) ListModels(_ context.Context, auth Auth) ([]Model, error) {
+	return []Model{{ID: "stub-" + auth.Attributes["tier"], ContextLength: 8192}}, nil
+}
+
+func (stubHandler) Execute(ctx context.Context, params ExecuteParams) (ExecuteResult, error) {
+	switch params.Model {
+	case "crash":
+		os.Exit(3)
+	case "limited":
+		return ExecuteResult{}, &Error{Message: "slow down", Status: http.StatusTooManyRequests, RetryAfter: 7 * time.Second}
+	case "hang":
+		<-ctx.Done()
+		return ExecuteResult{}, ctx.Err()
+	}
+	payload, _ := json.Marshal(map[string]string{"model": params.Model, "key": params.Auth.Attributes["api_key"]})
+	return ExecuteResult{Payload: payload}, nil
+}
+
+func (stubHandler) ExecuteStream(_ context.Context, params ExecuteParams, emit func(string) error) error {
+	for _, word := range strings.Fields("one two three") {
+		if err := emit("data: " + word); err != nil {
+			return err
+		}
+	}
+	return emit("data: [DONE]")
+}
+
+func (stubHandler) CountTokens(context.Context, ExecuteParams) (CountTokensResult, error) {
+	return CountTokensResult{TotalTokens: 42}, nil
+}
+
+func (stubHandler) Refresh(_ context.Context, auth Auth) (*Auth, error) {
+	auth.Metadata = map[string]any{"refreshed": true}
+	return &auth, nil
+}
+
+func TestHostRunsStubPlugin(t *testing.T) {
+	host := NewHost(Options{Name: "stub", Command: os.Args[0], Env: map[string]string{stubPluginEnv: "1"}})
+	t.Cleanup(func() { _ = host.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, info, err := host.Connect(ctx)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if info.Name != "stub" || info.Format != "openai" || info.ProtocolVersion != ProtocolVersion {
+		t.Fatalf("info = %+v", info)
+	}
+	auth := Auth{ID: "a1", Provider: "stub", Attributes: map[string]string{"api_key": "k1", "tier": "pro"}}
+
+	var models ListModelsResult
+	if err = client.Call(ctx, MethodListModels, AuthParams{Auth: auth}, &models); err != nil {
+		t.Fatalf("list_models error = %v", err)
+	}
+	if len(models.Models) != 1 || models.Models[0].ID != "stub-pro" || models.Models[0].ContextLength != 8192 {
+		t.Fatalf("models = %+v", models)
+	}
+
+	var result ExecuteResult
+	if err = client.Call(ctx, MethodExecute, ExecuteParams{Auth: auth, Model: "m", Payload: json.RawMessage(`{}`)}, &result); err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	if string(result.Payload) != `{"key":"k1","model":"m"}` {
+		t.Fatalf("execute payload = %s", result.Payload)
+	}
+
+	var chunks []string
+	err = client.CallStream(ctx, MethodExecuteStream, ExecuteParams{Auth: auth, Model: "m", Payload: json.RawMessage(`{}`)}, nil, func(data string) {
+		chunks = append(chunks, data)
+	})
+	if err != nil || strings.Join(chunks, "|") != "data: one|data: two|data: three|data: [DONE]" {
+		t.Fatalf("execute_stream chunks = %q, err = %v", chunks, err)
+	}
+
+	err = client.Call(ctx, MethodExecute, ExecuteParams{Auth: auth, Model: "limited"}, nil)
+	var perr *Error
+	if !errors.As(err, &perr) || perr.Status != http.StatusTooManyRequests || perr.RetryAfter != 7*time.Second {
+		t.Fatalf("limited error = %#v, want status 429 with retry-after", err)
+	}
+
+	var refreshed RefreshResult
+	if err = client.Call(ctx, MethodRefresh, AuthParams{Auth: auth}, &refreshed); err != nil || refreshed.Auth == nil || refreshed.Auth.Metadata["refreshed"] != true {
+		t.Fatalf("refresh = %+v, err = %v", refreshed, err)
+	}
+
+	// A cancelled call returns promptly and leaves the connection usable.
+	hangCtx, hangCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	err = client.Call(hangCtx, MethodExecute, ExecuteParams{Auth: auth, Model: "hang"}, nil)
+	hangCancel()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("hang error = %v, want deadline exceeded", err)
+	}
+	var count CountTokensResult
+	if err = client.Call(ctx, MethodCountTokens, ExecuteParams{Auth: auth}, &count); err != nil || count.TotalTokens != 42 {
+		t.Fatalf("count_tokens = %+v, err = %v", count, err)
+	}
+
+	// A crashed plugin fails its in-flight call and is relaunched on the next connect.
+	if err = client.Call(ctx, MethodExecute, ExecuteParams{Auth: auth, Model: "crash"}, nil); err == nil {
+		t.Fatal("call to a crashing plugin succeeded")
+	}
+	<-client.Done()
+	restarted, _, err := host.Connect(ctx)
+	if err != nil {
+		t.Fatalf("reconnect error = %v", err)
+	}
+	if restarted == client {
+		t.Fatal("host reused the dead connection")
+	}
+	if err = restarted.Call(ctx, MethodExecute, ExecuteParams{Auth: auth, Model: "m"}, &result); err != nil {
+		t.Fatalf("execute after restart error = %v", err)
+	}
+}
+
+func TestClientFailsSlowStreamWithoutBlockingOtherCalls(t *testing.T) {
+	proxySide, pluginSide := net.Pipe()
+	client := NewClient(proxySide)
+	t.Cleanup(func() { _ = client.Close() })
+
+	cancelled := make(chan int64, 1)
+	go func() {
+		dec := json.NewDecoder(pluginSide)
+		enc := json.NewEncoder(pluginSide)
+		for {
+			var msg rpcMessage
+			if err := dec.Decode(&msg); err != nil {
+				return
+			}
+			switch msg.Method {
+			case MethodExecuteStream:
+				data := "data: " + strings.Repeat("x", 64<<10)
+				for i := 0; i < streamQueueBytes/len(data)+10; i++ {
+					raw, _ := json.Marshal(StreamChunk{ID: *msg.ID, Data: data})
+					_ = enc.Encode(&rpcMessage{JSONRPC: "2.0", Method: NotifyStreamChunk, Params: raw})
+				}
+			case MethodExecute:
+				_ = enc.Encode(&rpcMessage{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage(`{"payload":{}}`)})
+			case NotifyCancel:
+				var params CancelParams
+				_ = json.Unmarshal(msg.Params, &params)
+				cancelled <- params.ID
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	release := make(chan struct{})
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- client.CallStream(ctx, MethodExecuteStream, ExecuteParams{}, nil, func(string) { <-release })
+	}()
+
+	// The stream consumer is stalled; an unrelated call must still be answered.
+	time.Sleep(50 * time.Millisecond)
+	if err := client.Call(ctx, MethodExecute, ExecuteParams{}, nil); err != nil {
+		t.Fatalf("execute while stream stalled error = %v", err)
+	}
+	close(release)
+	var perr *Error
+	if err := <-streamErr; !errors.As(err, &perr) || !strings.Contains(perr.Message, "behind") {
+		t.Fatalf("stalled stream error = %v, want overflow", err)
+	}
+	select {
+	case <-cancelled:
+	case <-ctx.Done():
+		t.Fatal("overflowing stream was not cancelled on the plugin side")
+	}
+}
+
+func TestClientStreamAbsorbsBurstsForSlowConsumers(t *testing.T) {
+	proxySide, pluginSide := net.Pipe()
+	client := NewClient(proxySide)
+	t.Cleanup(func() { _ = client.Close() })
+
+	const chunks = 1000
+	go func() {
+		dec := json.NewDecoder(pluginSide)
+		enc := json.NewEncoder(pluginSide)
+		var msg rpcMessage
+		if err := dec.Decode(&msg); err != nil {
+			return
+		}
+		for i := 0; i < chunks; i++ {
+			raw, _ := json.Marshal(StreamChunk{ID: *msg.ID, Data: fmt.Sprintf("data: %d", i)})
+			_ = enc.Encode(&rpcMessage{JSONRPC: "2.0", Method: NotifyStreamChunk, Params: raw})
+		}
+		_ = enc.Encode(&rpcMessage{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage(`{}`)})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var got []string
+	err := client.CallStream(ctx, MethodExecuteStream, ExecuteParams{}, nil, func(data string) {
+		if len(got)%100 == 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		got = append(got, data)
+	})
+	if err != nil {
+		t.Fatalf("CallStream() error = %v", err)
+	}
+	if len(got) != chunks || got[0] != "data: 0" || got[chunks-1] != fmt.Sprintf("data: %d", chunks-1) {
+		t.Fatalf("received %d chunks, want %d in order", len(got), chunks)
+	}
+}
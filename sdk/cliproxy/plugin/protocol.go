@@ -0,0 +1,206 @@
+// Package plugin implements out-of-process provider executors.
+//
+// A plugin is an executable launched by the proxy, or a server listening on a unix socket,
+// that speaks JSON-RPC 2.0 with one message per line. When launched, requests arrive on the
+// plugin's stdin and responses are written to its stdout; stderr is copied to the proxy log.
+//
+// The proxy sends these requests:
+//
+//	initialize      {"protocol_version":1,"provider":"<name>"}      -> Info
+//	list_models     {"auth":Auth}                                   -> {"models":[Model]}
+//	execute         ExecuteParams                                   -> ExecuteResult
+//	execute_stream  ExecuteParams                                   -> {} once the stream ends
+//	count_tokens    ExecuteParams                                   -> CountTokensResult
+//	refresh         {"auth":Auth}                                   -> {"auth":Auth|null}
+//
+// While an execute_stream request is open the plugin emits "stream_chunk" notifications
+// ({"id":<request id>,"data":"<provider line>"}) and finally answers the request itself.
+// The proxy sends a "$/cancel" notification ({"id":<request id>}) when the client goes away.
+//
+// Payloads are in the provider format the plugin declares in Info.Format ("openai" by
+// default); the proxy translates from and to the client's format. A failed call returns a
+// JSON-RPC error whose data may carry {"status":<http status>,"retry_after_seconds":<n>} so
+// the proxy can cool the credential down like any built-in provider.
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ProtocolVersion is the protocol revision spoken by this package.
+const ProtocolVersion = 1
+
+// Method names of the plugin protocol.
+const (
+	MethodInitialize    = "initialize"
+	MethodListModels    = "list_models"
+	MethodExecute       = "execute"
+	MethodExecuteStream = "execute_stream"
+	MethodCountTokens   = "count_tokens"
+	MethodRefresh       = "refresh"
+	// NotifyStreamChunk carries one chunk of an open execute_stream request.
+	NotifyStreamChunk = "stream_chunk"
+	// NotifyCancel asks the plugin to abandon an in-flight request.
+	NotifyCancel = "$/cancel"
+)
+
+// InitializeParams is sent once per connection before any other request.
+type InitializeParams struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Provider        string `json:"provider"`
+}
+
+// Info describes a plugin, returned from initialize.
+type Info struct {
+	// Name is a human-readable plugin name.
+	Name string `json:"name,omitempty"`
+	// Version is the plugin's own version string.
+	Version string `json:"version,omitempty"`
+	// ProtocolVersion is the protocol revision the plugin implements.
+	ProtocolVersion int `json:"protocol_version"`
+	// Format is the request/response schema the plugin expects (e.g. "openai", "claude", "gemini").
+	Format string `json:"format,omitempty"`
+}
+
+// Auth is the credential a request is executed with. Attributes carry the account settings
+// configured for the plugin; Metadata is whatever the plugin stored on a previous refresh.
+type Auth struct {
+	ID         string            `json:"id"`
+	Provider   string            `json:"provider"`
+	Label      string            `json:"label,omitempty"`
+	ProxyURL   string            `json:"proxy_url,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Metadata   map[string]any    `json:"metadata,omitempty"`
+}
+
+// Auth records synthesized for a plugin carry the plugin name under AuthAttributePlugin
+// and each configured account attribute under AuthAttributePrefix + key. Only the account
+// attributes, without the prefix, are sent to the plugin.
+const (
+	AuthAttributePlugin = "plugin"
+	AuthAttributePrefix = "plugin:"
+)
+
+// AuthParams is the parameter object of list_models and refresh.
+type AuthParams struct {
+	Auth Auth `json:"auth"`
+}
+
+// RefreshResult is returned from refresh. A nil Auth means the credential is unchanged.
+type RefreshResult struct {
+	Auth *Auth `json:"auth"`
+}
+
+// Model is one model served by a plugin credential.
+type Model struct {
+	ID                  string            `json:"id"`
+	DisplayName         string            `json:"display_name,omitempty"`
+	Description         string            `json:"description,omitempty"`
+	OwnedBy             string            `json:"owned_by,omitempty"`
+	ContextLength       int               `json:"context_length,omitempty"`
+	MaxCompletionTokens int               `json:"max_completion_tokens,omitempty"`
+	Pricing             map[string]string `json:"pricing,omitempty"`
+}
+
+// ListModelsResult is returned from list_models.
+type ListModelsResult struct {
+	Models []Model `json:"models"`
+}
+
+// ExecuteParams is the parameter object of execute, execute_stream and count_tokens.
+type ExecuteParams struct {
+	Auth Auth `json:"auth"`
+	// Model is the upstream model name.
+	Model string `json:"model"`
+	// Payload is the request translated to the plugin's format.
+	Payload json.RawMessage `json:"payload"`
+	Stream  bool            `json:"stream,omitempty"`
+	// Headers are the client request headers forwarded to the provider.
+	Headers map[string][]string `json:"headers,omitempty"`
+}
+
+// ExecuteResult is returned from execute.
+type ExecuteResult struct {
+	// Payload is the provider response in the plugin's format.
+	Payload json.RawMessage     `json:"payload"`
+	Headers map[string][]string `json:"headers,omitempty"`
+}
+
+// StreamChunk is the parameter object of a stream_chunk notification.
+type StreamChunk struct {
+	ID int64 `json:"id"`
+	// Data is one provider stream line, e.g. "data: {...}" for SSE-based formats.
+	Data string `json:"data"`
+}
+
+// CountTokensResult is returned from count_tokens.
+type CountTokensResult struct {
+	TotalTokens int64 `json:"total_tokens"`
+	// Payload optionally carries the provider's own token count response.
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// CancelParams is the parameter object of a $/cancel notification.
+type CancelParams struct {
+	ID int64 `json:"id"`
+}
+
+// Error is a failed plugin call. Status is the HTTP-like status used to update the
+// credential state; zero means the failure is not attributable to the credential.
+type Error struct {
+	Code       int
+	Message    string
+	Status     int
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Status != 0 {
+		return fmt.Sprintf("plugin error (status %d): %s", e.Status, e.Message)
+	}
+	return "plugin error: " + e.Message
+}
+
+// StatusCode reports the HTTP-like status of the failure.
+func (e *Error) StatusCode() int { return e.Status }
+
+// JSON-RPC error codes used by this package.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+	// codeProviderError marks failures reported by the provider behind the plugin.
+	codeProviderError = -32000
+)
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *rpcErrorData `json:"data,omitempty"`
+}
+
+type rpcErrorData struct {
+	Status            int   `json:"status,omitempty"`
+	RetryAfterSeconds int64 `json:"retry_after_seconds,omitempty"`
+}
+
+func (e *rpcError) toError() *Error {
+	err := &Error{Code: e.Code, Message: e.Message}
+	if e.Data != nil {
+		err.Status = e.Data.Status
+		err.RetryAfter = time.Duration(e.Data.RetryAfterSeconds) * time.Second
+	}
+	return err
+}
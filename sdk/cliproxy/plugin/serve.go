@@ -0,0 +1,199 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Handler is implemented by plugins written in Go and served with Serve.
+type Handler interface {
+	ListModels(ctx context.Context, auth Auth) ([]Model, error)
+	Execute(ctx context.Context, params ExecuteParams) (ExecuteResult, error)
+	// ExecuteStream calls emit once per provider stream line, in order.
+	ExecuteStream(ctx context.Context, params ExecuteParams, emit func(data string) error) error
+	CountTokens(ctx context.Context, params ExecuteParams) (CountTokensResult, error)
+	// Refresh returns the updated credential, or nil when nothing changed.
+	Refresh(ctx context.Context, auth Auth) (*Auth, error)
+}
+
+// Serve answers protocol requests read from r on w until r is exhausted, which is how a
+// launched plugin learns that the proxy is shutting down. Requests are handled concurrently;
+// returning an *Error from a Handler method reports an upstream status to the proxy.
+func Serve(ctx context.Context, r io.Reader, w io.Writer, info Info, handler Handler) error {
+	if info.ProtocolVersion == 0 {
+		info.ProtocolVersion = ProtocolVersion
+	}
+	ctx, cancelAll := context.WithCancel(ctx)
+	defer cancelAll()
+
+	s := &server{enc: json.NewEncoder(w), inflight: make(map[int64]context.CancelFunc)}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			s.reply(0, nil, &rpcError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+		if msg.ID == nil {
+			if msg.Method == NotifyCancel {
+				var params CancelParams
+				if json.Unmarshal(msg.Params, &params) == nil {
+					s.cancel(params.ID)
+				}
+			}
+			continue
+		}
+		id := *msg.ID
+		callCtx, cancel := context.WithCancel(ctx)
+		s.track(id, cancel)
+		wg.Add(1)
+		go func(msg rpcMessage) {
+			defer wg.Done()
+			defer s.cancel(id)
+			result, err := s.dispatch(callCtx, id, msg, info, handler)
+			if err != nil {
+				s.reply(id, nil, err)
+				return
+			}
+			s.reply(id, result, nil)
+		}(msg)
+	}
+	return scanner.Err()
+}
+
+type server struct {
+	writeMu  sync.Mutex
+	enc      *json.Encoder
+	mu       sync.Mutex
+	inflight map[int64]context.CancelFunc
+}
+
+func (s *server) track(id int64, cancel context.CancelFunc) {
+	s.mu.Lock()
+	s.inflight[id] = cancel
+	s.mu.Unlock()
+}
+
+func (s *server) cancel(id int64) {
+	s.mu.Lock()
+	cancel := s.inflight[id]
+	delete(s.inflight, id)
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (s *server) dispatch(ctx context.Context, id int64, msg rpcMessage, info Info, handler Handler) (any, *rpcError) {
+	decode := func(v any) *rpcError {
+		if err := json.Unmarshal(msg.Params, v); err != nil {
+			return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return nil
+	}
+	switch msg.Method {
+	case MethodInitialize:
+		return info, nil
+	case MethodListModels:
+		var params AuthParams
+		if errDecode := decode(&params); errDecode != nil {
+			return nil, errDecode
+		}
+		models, err := handler.ListModels(ctx, params.Auth)
+		if err != nil {
+			return nil, rpcErrorFrom(err)
+		}
+		return ListModelsResult{Models: models}, nil
+	case MethodExecute, MethodExecuteStream, MethodCountTokens:
+		var params ExecuteParams
+		if errDecode := decode(&params); errDecode != nil {
+			return nil, errDecode
+		}
+		switch msg.Method {
+		case MethodExecute:
+			result, err := handler.Execute(ctx, params)
+			if err != nil {
+				return nil, rpcErrorFrom(err)
+			}
+			return result, nil
+		case MethodExecuteStream:
+			emit := func(data string) error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				raw, _ := json.Marshal(StreamChunk{ID: id, Data: data})
+				return s.write(&rpcMessage{JSONRPC: "2.0", Method: NotifyStreamChunk, Params: raw})
+			}
+			if err := handler.ExecuteStream(ctx, params, emit); err != nil {
+				return nil, rpcErrorFrom(err)
+			}
+			return struct{}{}, nil
+		default:
+			result, err := handler.CountTokens(ctx, params)
+			if err != nil {
+				return nil, rpcErrorFrom(err)
+			}
+			return result, nil
+		}
+	case MethodRefresh:
+		var params AuthParams
+		if errDecode := decode(&params); errDecode != nil {
+			return nil, errDecode
+		}
+		auth, err := handler.Refresh(ctx, params.Auth)
+		if err != nil {
+			return nil, rpcErrorFrom(err)
+		}
+		return RefreshResult{Auth: auth}, nil
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", msg.Method)}
+	}
+}
+
+func (s *server) reply(id int64, result any, rpcErr *rpcError) {
+	msg := &rpcMessage{JSONRPC: "2.0", ID: &id, Error: rpcErr}
+	if rpcErr == nil {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			msg.Error = &rpcError{Code: codeInternalError, Message: err.Error()}
+		} else {
+			msg.Result = raw
+		}
+	}
+	_ = s.write(msg)
+}
+
+func (s *server) write(msg *rpcMessage) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.enc.Encode(msg)
+}
+
+// rpcErrorFrom converts a Handler error to its wire form.
+func rpcErrorFrom(err error) *rpcError {
+	var perr *Error
+	if errors.As(err, &perr) {
+		out := &rpcError{Code: perr.Code, Message: perr.Message}
+		if out.Code == 0 {
+			out.Code = codeProviderError
+		}
+		if perr.Status != 0 || perr.RetryAfter > 0 {
+			out.Data = &rpcErrorData{Status: perr.Status, RetryAfterSeconds: int64(perr.RetryAfter.Seconds())}
+		}
+		return out
+	}
+	return &rpcError{Code: codeInternalError, Message: err.Error()}
+}
@@ -0,0 +1,109 @@
+package cliproxy
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/plugin"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// pluginListModelsTimeout bounds the list_models call made while registering a plugin credential.
+const pluginListModelsTimeout = 15 * time.Second
+
+// pluginConfigFor returns the plugin entry an auth was synthesized from.
+func pluginConfigFor(cfg *config.Config, a *coreauth.Auth) *config.ExecutorPlugin {
+	if a == nil || a.Attributes == nil {
+		return nil
+	}
+	name := strings.TrimSpace(a.Attributes[plugin.AuthAttributePlugin])
+	if name == "" {
+		return nil
+	}
+	return cfg.PluginByName(name)
+}
+
+func pluginOptions(entry *config.ExecutorPlugin) plugin.Options {
+	return plugin.Options{
+		Name:    entry.Name,
+		Command: entry.Command,
+		Args:    entry.Args,
+		Env:     entry.Env,
+		Socket:  entry.Socket,
+	}
+}
+
+// pluginHost returns the host of a plugin, replacing it when its launch options changed.
+func (s *Service) pluginHost(entry *config.ExecutorPlugin) *plugin.Host {
+	opts := pluginOptions(entry)
+	s.pluginMu.Lock()
+	defer s.pluginMu.Unlock()
+	if host, ok := s.pluginHosts[entry.Name]; ok {
+		if host.SameOptions(opts) {
+			return host
+		}
+		go closePluginHost(host)
+	}
+	if s.pluginHosts == nil {
+		s.pluginHosts = make(map[string]*plugin.Host)
+	}
+	host := plugin.NewHost(opts)
+	s.pluginHosts[entry.Name] = host
+	return host
+}
+
+// prunePluginHosts stops plugins that are no longer configured.
+func (s *Service) prunePluginHosts(cfg *config.Config) {
+	s.pluginMu.Lock()
+	defer s.pluginMu.Unlock()
+	for name, host := range s.pluginHosts {
+		if cfg.PluginByName(name) == nil {
+			delete(s.pluginHosts, name)
+			go closePluginHost(host)
+		}
+	}
+}
+
+// closePluginHosts stops every plugin.
+func (s *Service) closePluginHosts() {
+	s.pluginMu.Lock()
+	hosts := s.pluginHosts
+	s.pluginHosts = nil
+	s.pluginMu.Unlock()
+	for _, host := range hosts {
+		closePluginHost(host)
+	}
+}
+
+func closePluginHost(host *plugin.Host) {
+	if err := host.Close(); err != nil {
+		log.Warnf("plugin %s: close: %v", host.Options().Name, err)
+	}
+}
+
+// pluginModels asks the plugin serving a for its models.
+func (s *Service) pluginModels(a *coreauth.Auth) []*ModelInfo {
+	if s.coreManager == nil {
+		return nil
+	}
+	exec, ok := s.coreManager.Executor(a.Provider)
+	if !ok {
+		return nil
+	}
+	pluginExec, ok := exec.(*executor.PluginExecutor)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pluginListModelsTimeout)
+	defer cancel()
+	models, err := pluginExec.ListModels(ctx, a)
+	if err != nil {
+		log.Warnf("plugin %s: list models for %s failed: %v", a.Provider, a.ID, err)
+		return nil
+	}
+	return models
+}
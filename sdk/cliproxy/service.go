@@ -13,14 +13,19 @@ import (
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/httppool"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/wsrelay"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/plugin"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 	log "github.com/sirupsen/logrus"
@@ -60,6 +65,16 @@ type Service struct {
 	// pprofServer manages the optional pprof HTTP debug server.
 	pprofServer *pprofServer
 
+	// tracing owns the optional OpenTelemetry exporter.
+	tracing *tracing.Provider
+
+	// signatureCacheKey identifies the applied signature cache settings so reloads only
+	// replace the backend when they change.
+	signatureCacheKey string
+
+	// roundTripperProvider hands pooled upstream transports to the core manager.
+	roundTripperProvider *defaultRoundTripperProvider
+
 	// serverErr channel for server startup/shutdown errors.
 	serverErr chan error
 
@@ -89,6 +104,13 @@ type Service struct {
 
 	// wsGateway manages websocket Gemini providers.
 	wsGateway *wsrelay.Manager
+
+	// discovery caches upstream model lists of openai-compatibility providers with discover-models.
+	discovery modelDiscovery
+
+	// pluginHosts owns the connections to executor plugins, keyed by provider name.
+	pluginHosts map[string]*plugin.Host
+	pluginMu    sync.Mutex
 }
 
 // RegisterUsagePlugin registers a usage plugin on the global usage manager.
@@ -386,6 +408,10 @@ func (s *Service) ensureExecutorsForAuthWithMode(a *coreauth.Auth, forceReplace
 	if a.Disabled {
 		return
 	}
+	if entry := pluginConfigFor(s.cfg, a); entry != nil {
+		s.coreManager.RegisterExecutor(executor.NewPluginExecutor(entry.Name, s.pluginHost(entry), s.cfg))
+		return
+	}
 	if compatProviderKey, _, isCompat := openAICompatInfoFromAuth(a); isCompat {
 		if compatProviderKey == "" {
 			compatProviderKey = strings.ToLower(strings.TrimSpace(a.Provider))
@@ -534,6 +560,9 @@ func (s *Service) Run(ctx context.Context) error {
 		s.hooks.OnBeforeStart(s.cfg)
 	}
 
+	s.applyTracingConfig(ctx, s.cfg)
+	s.applySignatureCacheConfig(s.cfg)
+
 	s.serverErr = make(chan error, 1)
 	go func() {
 		if errStart := s.server.Start(); errStart != nil {
@@ -592,8 +621,11 @@ func (s *Service) Run(ctx context.Context) error {
 			s.coreManager.SetSelector(selector)
 		}
 
+		s.prunePluginHosts(newCfg)
 		s.applyRetryConfig(newCfg)
 		s.applyPprofConfig(newCfg)
+		s.applyTracingConfig(context.Background(), newCfg)
+		s.applySignatureCacheConfig(newCfg)
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
 		}
@@ -604,6 +636,9 @@ func (s *Service) Run(ctx context.Context) error {
 			s.coreManager.SetConfig(newCfg)
 			s.coreManager.SetOAuthModelAlias(newCfg.OAuthModelAlias)
 		}
+		if s.roundTripperProvider != nil {
+			s.roundTripperProvider.setConfig(newCfg)
+		}
 		s.rebindExecutors()
 	}
 
@@ -630,7 +665,9 @@ func (s *Service) Run(ctx context.Context) error {
 		interval := 15 * time.Minute
 		s.coreManager.StartAutoRefresh(context.Background(), interval)
 		log.Infof("core auth auto-refresh started (interval=%s)", interval)
+		s.coreManager.StartHealthChecks(context.Background())
 	}
+	s.startModelDiscovery(context.Background())
 
 	select {
 	case <-ctx.Done():
@@ -667,7 +704,10 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		}
 		if s.coreManager != nil {
 			s.coreManager.StopAutoRefresh()
+			s.coreManager.StopHealthChecks()
 		}
+		s.stopModelDiscovery()
+		s.closePluginHosts()
 		if s.watcher != nil {
 			if err := s.watcher.Stop(); err != nil {
 				log.Errorf("failed to stop file watcher: %v", err)
@@ -707,6 +747,15 @@ func (s *Service) Shutdown(ctx context.Context) error {
 			}
 		}
 
+		if errShutdownTracing := s.shutdownTracing(ctx); errShutdownTracing != nil {
+			log.Errorf("failed to flush traces: %v", errShutdownTracing)
+			if shutdownErr == nil {
+				shutdownErr = errShutdownTracing
+			}
+		}
+
+		cache.ShutdownSignatureCache()
+		httppool.Shared().CloseIdleConnections()
 		usage.StopDefault()
 	})
 	return shutdownErr
@@ -837,6 +886,10 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 		models = registry.GetKimiModels()
 		models = applyExcludedModels(models, excluded)
 	default:
+		if pluginConfigFor(s.cfg, a) != nil {
+			models = applyExcludedModels(s.pluginModels(a), excluded)
+			break
+		}
 		// Handle OpenAI-compatibility providers by name using config
 		if s.cfg != nil {
 			providerKey := provider
@@ -879,25 +932,11 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 				compat := &s.cfg.OpenAICompatibility[i]
 				if strings.EqualFold(compat.Name, compatName) {
 					isCompatAuth = true
-					// Convert compatibility models to registry models
-					ms := make([]*ModelInfo, 0, len(compat.Models))
-					for j := range compat.Models {
-						m := compat.Models[j]
-						// Use alias as model ID, fallback to name if alias is empty
-						modelID := m.Alias
-						if modelID == "" {
-							modelID = m.Name
-						}
-						ms = append(ms, &ModelInfo{
-							ID:          modelID,
-							Object:      "model",
-							Created:     time.Now().Unix(),
-							OwnedBy:     compat.Name,
-							Type:        "openai-compatibility",
-							DisplayName: modelID,
-							UserDefined: true,
-						})
+					var discovered []*ModelInfo
+					if compat.DiscoverModels {
+						discovered = s.discoveredModelsForAuth(s.cfg, a)
 					}
+					ms := applyExcludedModels(buildOpenAICompatModels(compat, discovered), compat.ExcludedModels)
 					// Register and return
 					if len(ms) > 0 {
 						if providerKey == "" {
@@ -1092,7 +1131,7 @@ func applyExcludedModels(models []*ModelInfo, excluded []string) []*ModelInfo {
 		modelID := strings.ToLower(strings.TrimSpace(model.ID))
 		blocked := false
 		for _, pattern := range patterns {
-			if matchWildcard(pattern, modelID) {
+			if internalconfig.MatchModelWildcard(pattern, modelID) {
 				blocked = true
 				break
 			}
@@ -1146,50 +1185,6 @@ func applyModelPrefixes(models []*ModelInfo, prefix string, forceModelPrefix boo
 	return out
 }
 
-// matchWildcard performs case-insensitive wildcard matching where '*' matches any substring.
-func matchWildcard(pattern, value string) bool {
-	if pattern == "" {
-		return false
-	}
-
-	// Fast path for exact match (no wildcard present).
-	if !strings.Contains(pattern, "*") {
-		return pattern == value
-	}
-
-	parts := strings.Split(pattern, "*")
-	// Handle prefix.
-	if prefix := parts[0]; prefix != "" {
-		if !strings.HasPrefix(value, prefix) {
-			return false
-		}
-		value = value[len(prefix):]
-	}
-
-	// Handle suffix.
-	if suffix := parts[len(parts)-1]; suffix != "" {
-		if !strings.HasSuffix(value, suffix) {
-			return false
-		}
-		value = value[:len(value)-len(suffix)]
-	}
-
-	// Handle middle segments in order.
-	for i := 1; i < len(parts)-1; i++ {
-		segment := parts[i]
-		if segment == "" {
-			continue
-		}
-		idx := strings.Index(value, segment)
-		if idx < 0 {
-			return false
-		}
-		value = value[idx+len(segment):]
-	}
-
-	return true
-}
-
 type modelEntry interface {
 	GetName() string
 	GetAlias() string
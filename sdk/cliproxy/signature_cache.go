@@ -0,0 +1,69 @@
+package cliproxy
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// applySignatureCacheConfig configures the thinking signature cache backend. A backend that
+// cannot be opened falls back to the in-memory cache.
+func (s *Service) applySignatureCacheConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	settings := cfg.SignatureCache
+	backend := settings.NormalizedBackend()
+	path := ""
+	if backend == "file" {
+		path = s.signatureSnapshotPath(settings.File)
+	}
+	key := fmt.Sprintf("%s|%s|%d|%s", backend, path, settings.EffectiveMaxEntries(), settings.MaintenanceInterval())
+	if key == s.signatureCacheKey {
+		return
+	}
+	s.signatureCacheKey = key
+
+	opts := cache.SignatureCacheOptions{
+		MaxEntries:          settings.EffectiveMaxEntries(),
+		MaintenanceInterval: settings.MaintenanceInterval(),
+		BackendName:         backend,
+	}
+	switch backend {
+	case "file":
+		fileBackend, err := cache.NewFileSignatureBackend(path)
+		if err != nil {
+			log.Errorf("signature cache: file backend unavailable, keeping signatures in memory: %v", err)
+			break
+		}
+		opts.Backend = fileBackend
+	case "postgres":
+		pgBackend, ok := sdkAuth.GetTokenStore().(cache.SignatureBackend)
+		if !ok {
+			log.Warn("signature cache: postgres backend requires the postgres token store, keeping signatures in memory")
+			break
+		}
+		opts.Backend = pgBackend
+	}
+	if opts.Backend == nil {
+		opts.BackendName = "memory"
+	}
+	cache.ConfigureSignatureCache(opts)
+	log.Debugf("signature cache: backend=%s max-entries=%d", opts.BackendName, opts.MaxEntries)
+}
+
+// signatureSnapshotPath resolves the file backend snapshot path relative to the config
+// directory. The auth directory is avoided because its watcher treats JSON files as credentials.
+func (s *Service) signatureSnapshotPath(file string) string {
+	if file == "" {
+		file = config.DefaultSignatureCacheFile
+	}
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(filepath.Dir(s.configPath), file)
+}
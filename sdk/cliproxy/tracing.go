@@ -0,0 +1,28 @@
+package cliproxy
+
+import (
+	"context"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
+	log "github.com/sirupsen/logrus"
+)
+
+func (s *Service) applyTracingConfig(ctx context.Context, cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	if s.tracing == nil {
+		s.tracing = tracing.NewProvider()
+	}
+	if err := s.tracing.Apply(ctx, cfg.Tracing); err != nil {
+		log.Errorf("failed to configure tracing: %v", err)
+	}
+}
+
+func (s *Service) shutdownTracing(ctx context.Context) error {
+	if s == nil || s.tracing == nil {
+		return nil
+	}
+	return s.tracing.Shutdown(ctx)
+}
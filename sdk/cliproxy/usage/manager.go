@@ -19,6 +19,12 @@ type Record struct {
 	RequestedAt time.Time
 	Failed      bool
 	Detail      Detail
+	// SplitAlias is the weighted alias the client requested when a model split routed the request.
+	SplitAlias string
+	// SplitTarget is the split arm that served the request.
+	SplitTarget string
+	// Hedge marks the duplicate attempt of a hedged request.
+	Hedge bool
 }
 
 // Detail holds the token usage breakdown.
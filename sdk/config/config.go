@@ -19,6 +19,15 @@ type PayloadConfig = internalconfig.PayloadConfig
 type PayloadRule = internalconfig.PayloadRule
 type PayloadFilterRule = internalconfig.PayloadFilterRule
 type PayloadModelRule = internalconfig.PayloadModelRule
+type PayloadScriptRule = internalconfig.PayloadScriptRule
+type RedactionConfig = internalconfig.RedactionConfig
+type RedactionPattern = internalconfig.RedactionPattern
+type TransportConfig = internalconfig.TransportConfig
+type StreamBridgeRule = internalconfig.StreamBridgeRule
+type WebToolsConfig = internalconfig.WebToolsConfig
+type ClientKeyPolicy = internalconfig.ClientKeyPolicy
+type ModelSplit = internalconfig.ModelSplit
+type ModelSplitTarget = internalconfig.ModelSplitTarget
 
 type GeminiKey = internalconfig.GeminiKey
 type CodexKey = internalconfig.CodexKey
@@ -28,11 +37,17 @@ type VertexCompatModel = internalconfig.VertexCompatModel
 type OpenAICompatibility = internalconfig.OpenAICompatibility
 type OpenAICompatibilityAPIKey = internalconfig.OpenAICompatibilityAPIKey
 type OpenAICompatibilityModel = internalconfig.OpenAICompatibilityModel
+type ExecutorPlugin = internalconfig.ExecutorPlugin
+type ExecutorPluginAccount = internalconfig.ExecutorPluginAccount
 
 type TLS = internalconfig.TLSConfig
 
 const (
 	DefaultPanelGitHubRepository = internalconfig.DefaultPanelGitHubRepository
+
+	ModelSplitStickyNone         = internalconfig.ModelSplitStickyNone
+	ModelSplitStickyClientKey    = internalconfig.ModelSplitStickyClientKey
+	ModelSplitStickyConversation = internalconfig.ModelSplitStickyConversation
 )
 
 func LoadConfig(configFile string) (*Config, error) { return internalconfig.LoadConfig(configFile) }
@@ -0,0 +1,24 @@
+package translator
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+
+// startSpan starts a translation span using the global tracer provider, which is a no-op
+// unless the host application enables tracing.
+func startSpan(ctx context.Context, name string, from, to Format, model string) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(
+		attribute.String("translator.from", from.String()),
+		attribute.String("translator.to", to.String()),
+		attribute.String("gen_ai.request.model", model),
+	))
+}
@@ -41,6 +41,7 @@ func newAmpTestHandler(t *testing.T) (*management.Handler, string) {
 	}
 
 	h := management.NewHandler(cfg, configPath, nil)
+	t.Cleanup(h.FlushConfigHistory)
 	return h, configPath
 }
 